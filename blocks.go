@@ -0,0 +1,181 @@
+package main
+
+import (
+	"chirpy/internal/database"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type listedUser struct {
+	Id          uuid.UUID `json:"id"`
+	Handle      string    `json:"handle"`
+	DisplayName string    `json:"display_name"`
+	AvatarUrl   string    `json:"avatar_url"`
+	Since       time.Time `json:"since"`
+}
+
+type listedUserPage struct {
+	Users      []listedUser `json:"users"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+var errBlocked = errors.New("interaction is not allowed between these users")
+
+// optionalUser returns the caller if the request carries a valid token. Public
+// read paths use it to hide blocked users' chirps without requiring a login.
+func (cfg *apiConfig) optionalUser(head http.Header) uuid.NullUUID {
+	id, err := cfg.validateUser(head)
+	if err != nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: id, Valid: true}
+}
+
+// blockedAmong returns the users in ids that have blocked viewer or been blocked by them.
+func (cfg *apiConfig) blockedAmong(ctx context.Context, viewer uuid.NullUUID, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	blocked := map[uuid.UUID]bool{}
+	if !viewer.Valid || len(ids) == 0 {
+		return blocked, nil
+	}
+	found, err := cfg.dbQueries.GetBlockedAmong(ctx, database.GetBlockedAmongParams{ViewerID: viewer.UUID, UserIds: ids})
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range found {
+		blocked[id] = true
+	}
+	return blocked, nil
+}
+
+func (cfg *apiConfig) handleBlock(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	self, other, ok := cfg.relationTarget(writer, req, "block")
+	if !ok {
+		return
+	}
+	if err := cfg.blockUser(req.Context(), self, other); err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// blockUser makes self block other, which also ends any follows between them.
+func (cfg *apiConfig) blockUser(ctx context.Context, self, other uuid.UUID) error {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	if err = qtx.BlockUser(ctx, database.BlockUserParams{BlockerID: self, BlockedID: other}); err != nil {
+		return err
+	}
+	if err = qtx.DeleteFollowsBetween(ctx, database.DeleteFollowsBetweenParams{UserA: self, UserB: other}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (cfg *apiConfig) handleUnblock(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	self, other, ok := cfg.relationTarget(writer, req, "unblock")
+	if !ok {
+		return
+	}
+	if err := cfg.dbQueries.UnblockUser(req.Context(), database.UnblockUserParams{BlockerID: self, BlockedID: other}); err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleMute(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	self, other, ok := cfg.relationTarget(writer, req, "mute")
+	if !ok {
+		return
+	}
+	if err := cfg.dbQueries.MuteUser(req.Context(), database.MuteUserParams{MuterID: self, MutedID: other}); err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleUnmute(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	self, other, ok := cfg.relationTarget(writer, req, "unmute")
+	if !ok {
+		return
+	}
+	if err := cfg.dbQueries.UnmuteUser(req.Context(), database.UnmuteUserParams{MuterID: self, MutedID: other}); err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleListBlocks(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	id, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	cur, limit, err := parsePage(req)
+	if err != nil {
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	rows, err := cfg.dbQueries.GetBlocks(req.Context(), database.GetBlocksParams{UserID: id, BeforeTime: cur.time,
+		BeforeID: cur.id, PageSize: limit})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	page := listedUserPage{Users: make([]listedUser, len(rows))}
+	for i, row := range rows {
+		page.Users[i] = listedUser{Id: row.ID, Handle: row.Handle, DisplayName: row.DisplayName, AvatarUrl: row.AvatarUrl,
+			Since: row.CreatedAt}
+	}
+	if len(rows) > 0 {
+		last := rows[len(rows)-1]
+		page.NextCursor = nextCursor(len(rows), limit, last.CreatedAt, last.ID)
+	}
+	handleJsonWrite(writer, http.StatusOK, "blocks", page)
+}
+
+func (cfg *apiConfig) handleListMutes(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	id, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	cur, limit, err := parsePage(req)
+	if err != nil {
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	rows, err := cfg.dbQueries.GetMutes(req.Context(), database.GetMutesParams{UserID: id, BeforeTime: cur.time,
+		BeforeID: cur.id, PageSize: limit})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	page := listedUserPage{Users: make([]listedUser, len(rows))}
+	for i, row := range rows {
+		page.Users[i] = listedUser{Id: row.ID, Handle: row.Handle, DisplayName: row.DisplayName, AvatarUrl: row.AvatarUrl,
+			Since: row.CreatedAt}
+	}
+	if len(rows) > 0 {
+		last := rows[len(rows)-1]
+		page.NextCursor = nextCursor(len(rows), limit, last.CreatedAt, last.ID)
+	}
+	handleJsonWrite(writer, http.StatusOK, "mutes", page)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"chirpy/internal/database"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+// TestBlockedChirpIsNotFound checks that a chirp by someone with a block between
+// them and the viewer looks exactly like a missing one.
+func TestBlockedChirpIsNotFound(t *testing.T) {
+	cfg, mock := mockConfig(t)
+	viewer := uuid.New()
+	now := time.Now().UTC()
+	chirp := database.Chirp{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Body: "hi", UserID: uuid.New(),
+		Kind: chirpKindChirp}
+	mock.ExpectQuery("name: GetChirp ").WithArgs(chirp.ID).WillReturnRows(chirpRows(chirp))
+	mock.ExpectQuery("name: IsBlocked ").WithArgs(viewer, chirp.UserID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/chirps/"+chirp.ID.String(), nil)
+	req.Header.Set("Authorization", authHeader(t, viewer))
+	recorder := httptest.NewRecorder()
+	cfg.routes().ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("GET blocked chirp = %d, want 404: %s", recorder.Code, recorder.Body)
+	}
+}
+
+// TestListingHidesBlockedQuotes checks that listings leave blocked authors out in
+// the query, and show a chirp quoting one of them with a tombstone in its place.
+func TestListingHidesBlockedQuotes(t *testing.T) {
+	cfg, mock := mockConfig(t)
+	viewer, blocked := uuid.New(), uuid.New()
+	now := time.Now().UTC()
+	original := database.Chirp{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Body: "hot take", UserID: blocked,
+		Kind: chirpKindChirp}
+	quote := database.Chirp{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Body: "agreed", UserID: uuid.New(),
+		Kind: chirpKindQuote, RefChirpID: uuid.NullUUID{UUID: original.ID, Valid: true}}
+	mock.ExpectQuery("name: GetNewestChirps ").
+		WithArgs(uuid.NullUUID{UUID: viewer, Valid: true}, uuid.NullUUID{}, false, nil).
+		WillReturnRows(chirpRows(quote))
+	mock.ExpectQuery("name: GetChirpsByIDs ").WillReturnRows(chirpRows(original))
+	mock.ExpectQuery("name: GetBlockedAmong ").WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(blocked))
+	expectChirpDetails(mock, database.GetUserHandlesRow{ID: quote.UserID, Handle: "carol"})
+	mock.ExpectQuery("name: GetMutedWords ").WithArgs(viewer).WillReturnRows(sqlmock.NewRows(
+		[]string{"id", "user_id", "phrase", "whole_word", "action", "expires_at", "created_at"}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/chirps", nil)
+	req.Header.Set("Authorization", authHeader(t, viewer))
+	recorder := httptest.NewRecorder()
+	cfg.routes().ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET /api/v2/chirps = %d: %s", recorder.Code, recorder.Body)
+	}
+	var got []struct {
+		Id       uuid.UUID      `json:"id"`
+		RefChirp chirpTombstone `json:"ref_chirp"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+		t.Fatalf("GET /api/v2/chirps body %q: %v", recorder.Body, err)
+	}
+	want := chirpTombstone{Id: original.ID, Kind: chirpKindTombstone}
+	if len(got) != 1 || got[0].Id != quote.ID || got[0].RefChirp != want {
+		t.Errorf("GET /api/v2/chirps = %+v, want the quote with ref_chirp %+v", got, want)
+	}
+}
@@ -0,0 +1,2009 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: chirpy/v1/chirpy.proto
+
+// The gRPC API mirrors the REST API under /api/v2 for internal services.
+// Calls that act as a user take their access token in the "authorization"
+// metadata as "Bearer <token>", exactly like the REST API's header.
+
+package chirpypb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type LoginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginRequest) Reset() {
+	*x = LoginRequest{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginRequest) ProtoMessage() {}
+
+func (x *LoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginRequest.ProtoReflect.Descriptor instead.
+func (*LoginRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *LoginRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *LoginRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type LoginResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Token         string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	RefreshToken  string                 `protobuf:"bytes,3,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginResponse) Reset() {
+	*x = LoginResponse{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginResponse) ProtoMessage() {}
+
+func (x *LoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginResponse.ProtoReflect.Descriptor instead.
+func (*LoginResponse) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *LoginResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *LoginResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *LoginResponse) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+type RefreshRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshRequest) Reset() {
+	*x = RefreshRequest{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshRequest) ProtoMessage() {}
+
+func (x *RefreshRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshRequest.ProtoReflect.Descriptor instead.
+func (*RefreshRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{2}
+}
+
+type RefreshResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshResponse) Reset() {
+	*x = RefreshResponse{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshResponse) ProtoMessage() {}
+
+func (x *RefreshResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshResponse.ProtoReflect.Descriptor instead.
+func (*RefreshResponse) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RefreshResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type RevokeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeRequest) Reset() {
+	*x = RevokeRequest{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeRequest) ProtoMessage() {}
+
+func (x *RevokeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeRequest.ProtoReflect.Descriptor instead.
+func (*RevokeRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{4}
+}
+
+// User is an account, as its owner sees it.
+type User struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Email         string                 `protobuf:"bytes,4,opt,name=email,proto3" json:"email,omitempty"`
+	Handle        string                 `protobuf:"bytes,5,opt,name=handle,proto3" json:"handle,omitempty"`
+	IsChirpyRed   bool                   `protobuf:"varint,6,opt,name=is_chirpy_red,json=isChirpyRed,proto3" json:"is_chirpy_red,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *User) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *User) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *User) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *User) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *User) GetHandle() string {
+	if x != nil {
+		return x.Handle
+	}
+	return ""
+}
+
+func (x *User) GetIsChirpyRed() bool {
+	if x != nil {
+		return x.IsChirpyRed
+	}
+	return false
+}
+
+// Profile is a user as everyone sees them.
+type Profile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Handle        string                 `protobuf:"bytes,2,opt,name=handle,proto3" json:"handle,omitempty"`
+	DisplayName   string                 `protobuf:"bytes,3,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	Bio           string                 `protobuf:"bytes,4,opt,name=bio,proto3" json:"bio,omitempty"`
+	AvatarUrl     string                 `protobuf:"bytes,5,opt,name=avatar_url,json=avatarUrl,proto3" json:"avatar_url,omitempty"`
+	JoinedAt      *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=joined_at,json=joinedAt,proto3" json:"joined_at,omitempty"`
+	ChirpCount    int64                  `protobuf:"varint,7,opt,name=chirp_count,json=chirpCount,proto3" json:"chirp_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Profile) Reset() {
+	*x = Profile{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Profile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Profile) ProtoMessage() {}
+
+func (x *Profile) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Profile.ProtoReflect.Descriptor instead.
+func (*Profile) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Profile) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Profile) GetHandle() string {
+	if x != nil {
+		return x.Handle
+	}
+	return ""
+}
+
+func (x *Profile) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *Profile) GetBio() string {
+	if x != nil {
+		return x.Bio
+	}
+	return ""
+}
+
+func (x *Profile) GetAvatarUrl() string {
+	if x != nil {
+		return x.AvatarUrl
+	}
+	return ""
+}
+
+func (x *Profile) GetJoinedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.JoinedAt
+	}
+	return nil
+}
+
+func (x *Profile) GetChirpCount() int64 {
+	if x != nil {
+		return x.ChirpCount
+	}
+	return 0
+}
+
+type CreateUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	Handle        string                 `protobuf:"bytes,3,opt,name=handle,proto3" json:"handle,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateUserRequest) Reset() {
+	*x = CreateUserRequest{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateUserRequest) ProtoMessage() {}
+
+func (x *CreateUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateUserRequest.ProtoReflect.Descriptor instead.
+func (*CreateUserRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CreateUserRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *CreateUserRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *CreateUserRequest) GetHandle() string {
+	if x != nil {
+		return x.Handle
+	}
+	return ""
+}
+
+type UpdateUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	Handle        string                 `protobuf:"bytes,3,opt,name=handle,proto3" json:"handle,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateUserRequest) Reset() {
+	*x = UpdateUserRequest{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateUserRequest) ProtoMessage() {}
+
+func (x *UpdateUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateUserRequest.ProtoReflect.Descriptor instead.
+func (*UpdateUserRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdateUserRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *UpdateUserRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *UpdateUserRequest) GetHandle() string {
+	if x != nil {
+		return x.Handle
+	}
+	return ""
+}
+
+type GetUserRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to User:
+	//
+	//	*GetUserRequest_Id
+	//	*GetUserRequest_Handle
+	User          isGetUserRequest_User `protobuf_oneof:"user"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserRequest) Reset() {
+	*x = GetUserRequest{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserRequest) ProtoMessage() {}
+
+func (x *GetUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserRequest.ProtoReflect.Descriptor instead.
+func (*GetUserRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetUserRequest) GetUser() isGetUserRequest_User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *GetUserRequest) GetId() string {
+	if x != nil {
+		if x, ok := x.User.(*GetUserRequest_Id); ok {
+			return x.Id
+		}
+	}
+	return ""
+}
+
+func (x *GetUserRequest) GetHandle() string {
+	if x != nil {
+		if x, ok := x.User.(*GetUserRequest_Handle); ok {
+			return x.Handle
+		}
+	}
+	return ""
+}
+
+type isGetUserRequest_User interface {
+	isGetUserRequest_User()
+}
+
+type GetUserRequest_Id struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3,oneof"`
+}
+
+type GetUserRequest_Handle struct {
+	Handle string `protobuf:"bytes,2,opt,name=handle,proto3,oneof"`
+}
+
+func (*GetUserRequest_Id) isGetUserRequest_User() {}
+
+func (*GetUserRequest_Handle) isGetUserRequest_User() {}
+
+type UpdateProfileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DisplayName   *string                `protobuf:"bytes,1,opt,name=display_name,json=displayName,proto3,oneof" json:"display_name,omitempty"`
+	Bio           *string                `protobuf:"bytes,2,opt,name=bio,proto3,oneof" json:"bio,omitempty"`
+	AvatarUrl     *string                `protobuf:"bytes,3,opt,name=avatar_url,json=avatarUrl,proto3,oneof" json:"avatar_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateProfileRequest) Reset() {
+	*x = UpdateProfileRequest{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateProfileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateProfileRequest) ProtoMessage() {}
+
+func (x *UpdateProfileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateProfileRequest.ProtoReflect.Descriptor instead.
+func (*UpdateProfileRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UpdateProfileRequest) GetDisplayName() string {
+	if x != nil && x.DisplayName != nil {
+		return *x.DisplayName
+	}
+	return ""
+}
+
+func (x *UpdateProfileRequest) GetBio() string {
+	if x != nil && x.Bio != nil {
+		return *x.Bio
+	}
+	return ""
+}
+
+func (x *UpdateProfileRequest) GetAvatarUrl() string {
+	if x != nil && x.AvatarUrl != nil {
+		return *x.AvatarUrl
+	}
+	return ""
+}
+
+type UserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserRequest) Reset() {
+	*x = UserRequest{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserRequest) ProtoMessage() {}
+
+func (x *UserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserRequest.ProtoReflect.Descriptor instead.
+func (*UserRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type ListFollowsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// At most 100. Zero means the default of 20.
+	PageSize int32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_cursor from the previous page.
+	Cursor        string `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFollowsRequest) Reset() {
+	*x = ListFollowsRequest{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFollowsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFollowsRequest) ProtoMessage() {}
+
+func (x *ListFollowsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFollowsRequest.ProtoReflect.Descriptor instead.
+func (*ListFollowsRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListFollowsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListFollowsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListFollowsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type FollowedUser struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Handle        string                 `protobuf:"bytes,2,opt,name=handle,proto3" json:"handle,omitempty"`
+	DisplayName   string                 `protobuf:"bytes,3,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	AvatarUrl     string                 `protobuf:"bytes,4,opt,name=avatar_url,json=avatarUrl,proto3" json:"avatar_url,omitempty"`
+	FollowedAt    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=followed_at,json=followedAt,proto3" json:"followed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FollowedUser) Reset() {
+	*x = FollowedUser{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FollowedUser) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FollowedUser) ProtoMessage() {}
+
+func (x *FollowedUser) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FollowedUser.ProtoReflect.Descriptor instead.
+func (*FollowedUser) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *FollowedUser) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *FollowedUser) GetHandle() string {
+	if x != nil {
+		return x.Handle
+	}
+	return ""
+}
+
+func (x *FollowedUser) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *FollowedUser) GetAvatarUrl() string {
+	if x != nil {
+		return x.AvatarUrl
+	}
+	return ""
+}
+
+func (x *FollowedUser) GetFollowedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FollowedAt
+	}
+	return nil
+}
+
+type FollowPage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Users []*FollowedUser        `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	// Empty on the last page.
+	NextCursor    string `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FollowPage) Reset() {
+	*x = FollowPage{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FollowPage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FollowPage) ProtoMessage() {}
+
+func (x *FollowPage) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FollowPage.ProtoReflect.Descriptor instead.
+func (*FollowPage) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *FollowPage) GetUsers() []*FollowedUser {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *FollowPage) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+// Entity is a span of a chirp's body that refers to something, such as an @mention.
+type Entity struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// Offset in code points, inclusive.
+	Start int32 `protobuf:"varint,2,opt,name=start,proto3" json:"start,omitempty"`
+	// Offset in code points, exclusive.
+	End           int32  `protobuf:"varint,3,opt,name=end,proto3" json:"end,omitempty"`
+	UserId        string `protobuf:"bytes,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Handle        string `protobuf:"bytes,5,opt,name=handle,proto3" json:"handle,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Entity) Reset() {
+	*x = Entity{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Entity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Entity) ProtoMessage() {}
+
+func (x *Entity) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Entity.ProtoReflect.Descriptor instead.
+func (*Entity) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Entity) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Entity) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *Entity) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *Entity) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Entity) GetHandle() string {
+	if x != nil {
+		return x.Handle
+	}
+	return ""
+}
+
+type Chirp struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Id           string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt    *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Body         string                 `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	UserId       string                 `protobuf:"bytes,5,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	AuthorHandle string                 `protobuf:"bytes,6,opt,name=author_handle,json=authorHandle,proto3" json:"author_handle,omitempty"`
+	// chirp, rechirp, quote or reply.
+	Kind string `protobuf:"bytes,7,opt,name=kind,proto3" json:"kind,omitempty"`
+	// What a rechirp, quote or reply points at.
+	//
+	// Types that are valid to be assigned to Ref:
+	//
+	//	*Chirp_RefChirp
+	//	*Chirp_TombstoneId
+	Ref      isChirp_Ref `protobuf_oneof:"ref"`
+	Entities []*Entity   `protobuf:"bytes,10,rep,name=entities,proto3" json:"entities,omitempty"`
+	Likes    int64       `protobuf:"varint,11,opt,name=likes,proto3" json:"likes,omitempty"`
+	// Set when one of the caller's muted words collapses the chirp.
+	Filtered      bool `protobuf:"varint,12,opt,name=filtered,proto3" json:"filtered,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Chirp) Reset() {
+	*x = Chirp{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Chirp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Chirp) ProtoMessage() {}
+
+func (x *Chirp) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Chirp.ProtoReflect.Descriptor instead.
+func (*Chirp) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Chirp) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Chirp) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Chirp) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Chirp) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *Chirp) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Chirp) GetAuthorHandle() string {
+	if x != nil {
+		return x.AuthorHandle
+	}
+	return ""
+}
+
+func (x *Chirp) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Chirp) GetRef() isChirp_Ref {
+	if x != nil {
+		return x.Ref
+	}
+	return nil
+}
+
+func (x *Chirp) GetRefChirp() *Chirp {
+	if x != nil {
+		if x, ok := x.Ref.(*Chirp_RefChirp); ok {
+			return x.RefChirp
+		}
+	}
+	return nil
+}
+
+func (x *Chirp) GetTombstoneId() string {
+	if x != nil {
+		if x, ok := x.Ref.(*Chirp_TombstoneId); ok {
+			return x.TombstoneId
+		}
+	}
+	return ""
+}
+
+func (x *Chirp) GetEntities() []*Entity {
+	if x != nil {
+		return x.Entities
+	}
+	return nil
+}
+
+func (x *Chirp) GetLikes() int64 {
+	if x != nil {
+		return x.Likes
+	}
+	return 0
+}
+
+func (x *Chirp) GetFiltered() bool {
+	if x != nil {
+		return x.Filtered
+	}
+	return false
+}
+
+type isChirp_Ref interface {
+	isChirp_Ref()
+}
+
+type Chirp_RefChirp struct {
+	RefChirp *Chirp `protobuf:"bytes,8,opt,name=ref_chirp,json=refChirp,proto3,oneof"`
+}
+
+type Chirp_TombstoneId struct {
+	// The ID of a chirp that has been deleted, or whose author has a block
+	// with the caller.
+	TombstoneId string `protobuf:"bytes,9,opt,name=tombstone_id,json=tombstoneId,proto3,oneof"`
+}
+
+func (*Chirp_RefChirp) isChirp_Ref() {}
+
+func (*Chirp_TombstoneId) isChirp_Ref() {}
+
+type ChirpRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChirpRequest) Reset() {
+	*x = ChirpRequest{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChirpRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChirpRequest) ProtoMessage() {}
+
+func (x *ChirpRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChirpRequest.ProtoReflect.Descriptor instead.
+func (*ChirpRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ChirpRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type CreateChirpRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// At most 140 characters, or 280 for Chirpy Red members.
+	Body          string `protobuf:"bytes,1,opt,name=body,proto3" json:"body,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateChirpRequest) Reset() {
+	*x = CreateChirpRequest{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateChirpRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateChirpRequest) ProtoMessage() {}
+
+func (x *CreateChirpRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateChirpRequest.ProtoReflect.Descriptor instead.
+func (*CreateChirpRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *CreateChirpRequest) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+type EditChirpRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Body          string                 `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EditChirpRequest) Reset() {
+	*x = EditChirpRequest{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EditChirpRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditChirpRequest) ProtoMessage() {}
+
+func (x *EditChirpRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditChirpRequest.ProtoReflect.Descriptor instead.
+func (*EditChirpRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *EditChirpRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *EditChirpRequest) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+// RespondRequest quotes or replies to the chirp id.
+type RespondRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Body          string                 `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RespondRequest) Reset() {
+	*x = RespondRequest{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RespondRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RespondRequest) ProtoMessage() {}
+
+func (x *RespondRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RespondRequest.ProtoReflect.Descriptor instead.
+func (*RespondRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RespondRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RespondRequest) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+type ListChirpsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only chirps by this user, if set.
+	AuthorId      string `protobuf:"bytes,1,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListChirpsRequest) Reset() {
+	*x = ListChirpsRequest{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListChirpsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListChirpsRequest) ProtoMessage() {}
+
+func (x *ListChirpsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListChirpsRequest.ProtoReflect.Descriptor instead.
+func (*ListChirpsRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListChirpsRequest) GetAuthorId() string {
+	if x != nil {
+		return x.AuthorId
+	}
+	return ""
+}
+
+type ChirpList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chirps        []*Chirp               `protobuf:"bytes,1,rep,name=chirps,proto3" json:"chirps,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChirpList) Reset() {
+	*x = ChirpList{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChirpList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChirpList) ProtoMessage() {}
+
+func (x *ChirpList) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChirpList.ProtoReflect.Descriptor instead.
+func (*ChirpList) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ChirpList) GetChirps() []*Chirp {
+	if x != nil {
+		return x.Chirps
+	}
+	return nil
+}
+
+type PageRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// At most 100. Zero means the default of 20.
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_cursor from the previous page.
+	Cursor        string `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PageRequest) Reset() {
+	*x = PageRequest{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PageRequest) ProtoMessage() {}
+
+func (x *PageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PageRequest.ProtoReflect.Descriptor instead.
+func (*PageRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *PageRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *PageRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type ChirpPage struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Chirps []*Chirp               `protobuf:"bytes,1,rep,name=chirps,proto3" json:"chirps,omitempty"`
+	// Empty on the last page.
+	NextCursor    string `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChirpPage) Reset() {
+	*x = ChirpPage{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChirpPage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChirpPage) ProtoMessage() {}
+
+func (x *ChirpPage) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChirpPage.ProtoReflect.Descriptor instead.
+func (*ChirpPage) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ChirpPage) GetChirps() []*Chirp {
+	if x != nil {
+		return x.Chirps
+	}
+	return nil
+}
+
+func (x *ChirpPage) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+// StreamChirpsRequest narrows the stream. Fields left empty match everything.
+type StreamChirpsRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	AuthorId string                 `protobuf:"bytes,1,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Hashtag  string                 `protobuf:"bytes,2,opt,name=hashtag,proto3" json:"hashtag,omitempty"`
+	// Only chirps by the people the caller follows. Needs a login.
+	Timeline bool `protobuf:"varint,3,opt,name=timeline,proto3" json:"timeline,omitempty"`
+	// The cursor of the last chirp received, to catch up on what was missed.
+	ResumeAfter   string `protobuf:"bytes,4,opt,name=resume_after,json=resumeAfter,proto3" json:"resume_after,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamChirpsRequest) Reset() {
+	*x = StreamChirpsRequest{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamChirpsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamChirpsRequest) ProtoMessage() {}
+
+func (x *StreamChirpsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamChirpsRequest.ProtoReflect.Descriptor instead.
+func (*StreamChirpsRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *StreamChirpsRequest) GetAuthorId() string {
+	if x != nil {
+		return x.AuthorId
+	}
+	return ""
+}
+
+func (x *StreamChirpsRequest) GetHashtag() string {
+	if x != nil {
+		return x.Hashtag
+	}
+	return ""
+}
+
+func (x *StreamChirpsRequest) GetTimeline() bool {
+	if x != nil {
+		return x.Timeline
+	}
+	return false
+}
+
+func (x *StreamChirpsRequest) GetResumeAfter() string {
+	if x != nil {
+		return x.ResumeAfter
+	}
+	return ""
+}
+
+type StreamedChirp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chirp         *Chirp                 `protobuf:"bytes,1,opt,name=chirp,proto3" json:"chirp,omitempty"`
+	Cursor        string                 `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamedChirp) Reset() {
+	*x = StreamedChirp{}
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamedChirp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamedChirp) ProtoMessage() {}
+
+func (x *StreamedChirp) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_v1_chirpy_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamedChirp.ProtoReflect.Descriptor instead.
+func (*StreamedChirp) Descriptor() ([]byte, []int) {
+	return file_chirpy_v1_chirpy_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *StreamedChirp) GetChirp() *Chirp {
+	if x != nil {
+		return x.Chirp
+	}
+	return nil
+}
+
+func (x *StreamedChirp) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+var File_chirpy_v1_chirpy_proto protoreflect.FileDescriptor
+
+const file_chirpy_v1_chirpy_proto_rawDesc = "" +
+	"\n" +
+	"\x16chirpy/v1/chirpy.proto\x12\tchirpy.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"@\n" +
+	"\fLoginRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"o\n" +
+	"\rLoginResponse\x12#\n" +
+	"\x04user\x18\x01 \x01(\v2\x0f.chirpy.v1.UserR\x04user\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12#\n" +
+	"\rrefresh_token\x18\x03 \x01(\tR\frefreshToken\"\x10\n" +
+	"\x0eRefreshRequest\"'\n" +
+	"\x0fRefreshResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"\x0f\n" +
+	"\rRevokeRequest\"\xde\x01\n" +
+	"\x04User\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x14\n" +
+	"\x05email\x18\x04 \x01(\tR\x05email\x12\x16\n" +
+	"\x06handle\x18\x05 \x01(\tR\x06handle\x12\"\n" +
+	"\ris_chirpy_red\x18\x06 \x01(\bR\visChirpyRed\"\xdf\x01\n" +
+	"\aProfile\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06handle\x18\x02 \x01(\tR\x06handle\x12!\n" +
+	"\fdisplay_name\x18\x03 \x01(\tR\vdisplayName\x12\x10\n" +
+	"\x03bio\x18\x04 \x01(\tR\x03bio\x12\x1d\n" +
+	"\n" +
+	"avatar_url\x18\x05 \x01(\tR\tavatarUrl\x127\n" +
+	"\tjoined_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\bjoinedAt\x12\x1f\n" +
+	"\vchirp_count\x18\a \x01(\x03R\n" +
+	"chirpCount\"]\n" +
+	"\x11CreateUserRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\x12\x16\n" +
+	"\x06handle\x18\x03 \x01(\tR\x06handle\"]\n" +
+	"\x11UpdateUserRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\x12\x16\n" +
+	"\x06handle\x18\x03 \x01(\tR\x06handle\"D\n" +
+	"\x0eGetUserRequest\x12\x10\n" +
+	"\x02id\x18\x01 \x01(\tH\x00R\x02id\x12\x18\n" +
+	"\x06handle\x18\x02 \x01(\tH\x00R\x06handleB\x06\n" +
+	"\x04user\"\xa1\x01\n" +
+	"\x14UpdateProfileRequest\x12&\n" +
+	"\fdisplay_name\x18\x01 \x01(\tH\x00R\vdisplayName\x88\x01\x01\x12\x15\n" +
+	"\x03bio\x18\x02 \x01(\tH\x01R\x03bio\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"avatar_url\x18\x03 \x01(\tH\x02R\tavatarUrl\x88\x01\x01B\x0f\n" +
+	"\r_display_nameB\x06\n" +
+	"\x04_bioB\r\n" +
+	"\v_avatar_url\"&\n" +
+	"\vUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"b\n" +
+	"\x12ListFollowsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x16\n" +
+	"\x06cursor\x18\x03 \x01(\tR\x06cursor\"\xb5\x01\n" +
+	"\fFollowedUser\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06handle\x18\x02 \x01(\tR\x06handle\x12!\n" +
+	"\fdisplay_name\x18\x03 \x01(\tR\vdisplayName\x12\x1d\n" +
+	"\n" +
+	"avatar_url\x18\x04 \x01(\tR\tavatarUrl\x12;\n" +
+	"\vfollowed_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"followedAt\"\\\n" +
+	"\n" +
+	"FollowPage\x12-\n" +
+	"\x05users\x18\x01 \x03(\v2\x17.chirpy.v1.FollowedUserR\x05users\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"u\n" +
+	"\x06Entity\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x14\n" +
+	"\x05start\x18\x02 \x01(\x05R\x05start\x12\x10\n" +
+	"\x03end\x18\x03 \x01(\x05R\x03end\x12\x17\n" +
+	"\auser_id\x18\x04 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06handle\x18\x05 \x01(\tR\x06handle\"\xb1\x03\n" +
+	"\x05Chirp\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x12\n" +
+	"\x04body\x18\x04 \x01(\tR\x04body\x12\x17\n" +
+	"\auser_id\x18\x05 \x01(\tR\x06userId\x12#\n" +
+	"\rauthor_handle\x18\x06 \x01(\tR\fauthorHandle\x12\x12\n" +
+	"\x04kind\x18\a \x01(\tR\x04kind\x12/\n" +
+	"\tref_chirp\x18\b \x01(\v2\x10.chirpy.v1.ChirpH\x00R\brefChirp\x12#\n" +
+	"\ftombstone_id\x18\t \x01(\tH\x00R\vtombstoneId\x12-\n" +
+	"\bentities\x18\n" +
+	" \x03(\v2\x11.chirpy.v1.EntityR\bentities\x12\x14\n" +
+	"\x05likes\x18\v \x01(\x03R\x05likes\x12\x1a\n" +
+	"\bfiltered\x18\f \x01(\bR\bfilteredB\x05\n" +
+	"\x03ref\"\x1e\n" +
+	"\fChirpRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"(\n" +
+	"\x12CreateChirpRequest\x12\x12\n" +
+	"\x04body\x18\x01 \x01(\tR\x04body\"6\n" +
+	"\x10EditChirpRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04body\x18\x02 \x01(\tR\x04body\"4\n" +
+	"\x0eRespondRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04body\x18\x02 \x01(\tR\x04body\"0\n" +
+	"\x11ListChirpsRequest\x12\x1b\n" +
+	"\tauthor_id\x18\x01 \x01(\tR\bauthorId\"5\n" +
+	"\tChirpList\x12(\n" +
+	"\x06chirps\x18\x01 \x03(\v2\x10.chirpy.v1.ChirpR\x06chirps\"B\n" +
+	"\vPageRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\"V\n" +
+	"\tChirpPage\x12(\n" +
+	"\x06chirps\x18\x01 \x03(\v2\x10.chirpy.v1.ChirpR\x06chirps\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"\x8b\x01\n" +
+	"\x13StreamChirpsRequest\x12\x1b\n" +
+	"\tauthor_id\x18\x01 \x01(\tR\bauthorId\x12\x18\n" +
+	"\ahashtag\x18\x02 \x01(\tR\ahashtag\x12\x1a\n" +
+	"\btimeline\x18\x03 \x01(\bR\btimeline\x12!\n" +
+	"\fresume_after\x18\x04 \x01(\tR\vresumeAfter\"O\n" +
+	"\rStreamedChirp\x12&\n" +
+	"\x05chirp\x18\x01 \x01(\v2\x10.chirpy.v1.ChirpR\x05chirp\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor2\xc7\x01\n" +
+	"\vAuthService\x12:\n" +
+	"\x05Login\x12\x17.chirpy.v1.LoginRequest\x1a\x18.chirpy.v1.LoginResponse\x12@\n" +
+	"\aRefresh\x12\x19.chirpy.v1.RefreshRequest\x1a\x1a.chirpy.v1.RefreshResponse\x12:\n" +
+	"\x06Revoke\x12\x18.chirpy.v1.RevokeRequest\x1a\x16.google.protobuf.Empty2\x89\x06\n" +
+	"\vUserService\x12;\n" +
+	"\n" +
+	"CreateUser\x12\x1c.chirpy.v1.CreateUserRequest\x1a\x0f.chirpy.v1.User\x12;\n" +
+	"\n" +
+	"UpdateUser\x12\x1c.chirpy.v1.UpdateUserRequest\x1a\x0f.chirpy.v1.User\x128\n" +
+	"\aGetUser\x12\x19.chirpy.v1.GetUserRequest\x1a\x12.chirpy.v1.Profile\x12D\n" +
+	"\rUpdateProfile\x12\x1f.chirpy.v1.UpdateProfileRequest\x1a\x12.chirpy.v1.Profile\x12<\n" +
+	"\n" +
+	"FollowUser\x12\x16.chirpy.v1.UserRequest\x1a\x16.google.protobuf.Empty\x12>\n" +
+	"\fUnfollowUser\x12\x16.chirpy.v1.UserRequest\x1a\x16.google.protobuf.Empty\x12;\n" +
+	"\tBlockUser\x12\x16.chirpy.v1.UserRequest\x1a\x16.google.protobuf.Empty\x12=\n" +
+	"\vUnblockUser\x12\x16.chirpy.v1.UserRequest\x1a\x16.google.protobuf.Empty\x12:\n" +
+	"\bMuteUser\x12\x16.chirpy.v1.UserRequest\x1a\x16.google.protobuf.Empty\x12<\n" +
+	"\n" +
+	"UnmuteUser\x12\x16.chirpy.v1.UserRequest\x1a\x16.google.protobuf.Empty\x12E\n" +
+	"\rListFollowers\x12\x1d.chirpy.v1.ListFollowsRequest\x1a\x15.chirpy.v1.FollowPage\x12E\n" +
+	"\rListFollowing\x12\x1d.chirpy.v1.ListFollowsRequest\x1a\x15.chirpy.v1.FollowPage2\xb6\x06\n" +
+	"\fChirpService\x12>\n" +
+	"\vCreateChirp\x12\x1d.chirpy.v1.CreateChirpRequest\x1a\x10.chirpy.v1.Chirp\x125\n" +
+	"\bGetChirp\x12\x17.chirpy.v1.ChirpRequest\x1a\x10.chirpy.v1.Chirp\x12@\n" +
+	"\n" +
+	"ListChirps\x12\x1c.chirpy.v1.ListChirpsRequest\x1a\x14.chirpy.v1.ChirpList\x12:\n" +
+	"\tEditChirp\x12\x1b.chirpy.v1.EditChirpRequest\x1a\x10.chirpy.v1.Chirp\x12>\n" +
+	"\vDeleteChirp\x12\x17.chirpy.v1.ChirpRequest\x1a\x16.google.protobuf.Empty\x124\n" +
+	"\aRechirp\x12\x17.chirpy.v1.ChirpRequest\x1a\x10.chirpy.v1.Chirp\x129\n" +
+	"\n" +
+	"QuoteChirp\x12\x19.chirpy.v1.RespondRequest\x1a\x10.chirpy.v1.Chirp\x12;\n" +
+	"\fReplyToChirp\x12\x19.chirpy.v1.RespondRequest\x1a\x10.chirpy.v1.Chirp\x12<\n" +
+	"\vListReplies\x12\x17.chirpy.v1.ChirpRequest\x1a\x14.chirpy.v1.ChirpList\x12<\n" +
+	"\tLikeChirp\x12\x17.chirpy.v1.ChirpRequest\x1a\x16.google.protobuf.Empty\x12>\n" +
+	"\vUnlikeChirp\x12\x17.chirpy.v1.ChirpRequest\x1a\x16.google.protobuf.Empty\x12;\n" +
+	"\vGetTimeline\x12\x16.chirpy.v1.PageRequest\x1a\x14.chirpy.v1.ChirpPage\x12J\n" +
+	"\fStreamChirps\x12\x1e.chirpy.v1.StreamChirpsRequest\x1a\x18.chirpy.v1.StreamedChirp0\x01B\x11Z\x0fchirpy/chirpypbb\x06proto3"
+
+var (
+	file_chirpy_v1_chirpy_proto_rawDescOnce sync.Once
+	file_chirpy_v1_chirpy_proto_rawDescData []byte
+)
+
+func file_chirpy_v1_chirpy_proto_rawDescGZIP() []byte {
+	file_chirpy_v1_chirpy_proto_rawDescOnce.Do(func() {
+		file_chirpy_v1_chirpy_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_chirpy_v1_chirpy_proto_rawDesc), len(file_chirpy_v1_chirpy_proto_rawDesc)))
+	})
+	return file_chirpy_v1_chirpy_proto_rawDescData
+}
+
+var file_chirpy_v1_chirpy_proto_msgTypes = make([]protoimpl.MessageInfo, 27)
+var file_chirpy_v1_chirpy_proto_goTypes = []any{
+	(*LoginRequest)(nil),          // 0: chirpy.v1.LoginRequest
+	(*LoginResponse)(nil),         // 1: chirpy.v1.LoginResponse
+	(*RefreshRequest)(nil),        // 2: chirpy.v1.RefreshRequest
+	(*RefreshResponse)(nil),       // 3: chirpy.v1.RefreshResponse
+	(*RevokeRequest)(nil),         // 4: chirpy.v1.RevokeRequest
+	(*User)(nil),                  // 5: chirpy.v1.User
+	(*Profile)(nil),               // 6: chirpy.v1.Profile
+	(*CreateUserRequest)(nil),     // 7: chirpy.v1.CreateUserRequest
+	(*UpdateUserRequest)(nil),     // 8: chirpy.v1.UpdateUserRequest
+	(*GetUserRequest)(nil),        // 9: chirpy.v1.GetUserRequest
+	(*UpdateProfileRequest)(nil),  // 10: chirpy.v1.UpdateProfileRequest
+	(*UserRequest)(nil),           // 11: chirpy.v1.UserRequest
+	(*ListFollowsRequest)(nil),    // 12: chirpy.v1.ListFollowsRequest
+	(*FollowedUser)(nil),          // 13: chirpy.v1.FollowedUser
+	(*FollowPage)(nil),            // 14: chirpy.v1.FollowPage
+	(*Entity)(nil),                // 15: chirpy.v1.Entity
+	(*Chirp)(nil),                 // 16: chirpy.v1.Chirp
+	(*ChirpRequest)(nil),          // 17: chirpy.v1.ChirpRequest
+	(*CreateChirpRequest)(nil),    // 18: chirpy.v1.CreateChirpRequest
+	(*EditChirpRequest)(nil),      // 19: chirpy.v1.EditChirpRequest
+	(*RespondRequest)(nil),        // 20: chirpy.v1.RespondRequest
+	(*ListChirpsRequest)(nil),     // 21: chirpy.v1.ListChirpsRequest
+	(*ChirpList)(nil),             // 22: chirpy.v1.ChirpList
+	(*PageRequest)(nil),           // 23: chirpy.v1.PageRequest
+	(*ChirpPage)(nil),             // 24: chirpy.v1.ChirpPage
+	(*StreamChirpsRequest)(nil),   // 25: chirpy.v1.StreamChirpsRequest
+	(*StreamedChirp)(nil),         // 26: chirpy.v1.StreamedChirp
+	(*timestamppb.Timestamp)(nil), // 27: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),         // 28: google.protobuf.Empty
+}
+var file_chirpy_v1_chirpy_proto_depIdxs = []int32{
+	5,  // 0: chirpy.v1.LoginResponse.user:type_name -> chirpy.v1.User
+	27, // 1: chirpy.v1.User.created_at:type_name -> google.protobuf.Timestamp
+	27, // 2: chirpy.v1.User.updated_at:type_name -> google.protobuf.Timestamp
+	27, // 3: chirpy.v1.Profile.joined_at:type_name -> google.protobuf.Timestamp
+	27, // 4: chirpy.v1.FollowedUser.followed_at:type_name -> google.protobuf.Timestamp
+	13, // 5: chirpy.v1.FollowPage.users:type_name -> chirpy.v1.FollowedUser
+	27, // 6: chirpy.v1.Chirp.created_at:type_name -> google.protobuf.Timestamp
+	27, // 7: chirpy.v1.Chirp.updated_at:type_name -> google.protobuf.Timestamp
+	16, // 8: chirpy.v1.Chirp.ref_chirp:type_name -> chirpy.v1.Chirp
+	15, // 9: chirpy.v1.Chirp.entities:type_name -> chirpy.v1.Entity
+	16, // 10: chirpy.v1.ChirpList.chirps:type_name -> chirpy.v1.Chirp
+	16, // 11: chirpy.v1.ChirpPage.chirps:type_name -> chirpy.v1.Chirp
+	16, // 12: chirpy.v1.StreamedChirp.chirp:type_name -> chirpy.v1.Chirp
+	0,  // 13: chirpy.v1.AuthService.Login:input_type -> chirpy.v1.LoginRequest
+	2,  // 14: chirpy.v1.AuthService.Refresh:input_type -> chirpy.v1.RefreshRequest
+	4,  // 15: chirpy.v1.AuthService.Revoke:input_type -> chirpy.v1.RevokeRequest
+	7,  // 16: chirpy.v1.UserService.CreateUser:input_type -> chirpy.v1.CreateUserRequest
+	8,  // 17: chirpy.v1.UserService.UpdateUser:input_type -> chirpy.v1.UpdateUserRequest
+	9,  // 18: chirpy.v1.UserService.GetUser:input_type -> chirpy.v1.GetUserRequest
+	10, // 19: chirpy.v1.UserService.UpdateProfile:input_type -> chirpy.v1.UpdateProfileRequest
+	11, // 20: chirpy.v1.UserService.FollowUser:input_type -> chirpy.v1.UserRequest
+	11, // 21: chirpy.v1.UserService.UnfollowUser:input_type -> chirpy.v1.UserRequest
+	11, // 22: chirpy.v1.UserService.BlockUser:input_type -> chirpy.v1.UserRequest
+	11, // 23: chirpy.v1.UserService.UnblockUser:input_type -> chirpy.v1.UserRequest
+	11, // 24: chirpy.v1.UserService.MuteUser:input_type -> chirpy.v1.UserRequest
+	11, // 25: chirpy.v1.UserService.UnmuteUser:input_type -> chirpy.v1.UserRequest
+	12, // 26: chirpy.v1.UserService.ListFollowers:input_type -> chirpy.v1.ListFollowsRequest
+	12, // 27: chirpy.v1.UserService.ListFollowing:input_type -> chirpy.v1.ListFollowsRequest
+	18, // 28: chirpy.v1.ChirpService.CreateChirp:input_type -> chirpy.v1.CreateChirpRequest
+	17, // 29: chirpy.v1.ChirpService.GetChirp:input_type -> chirpy.v1.ChirpRequest
+	21, // 30: chirpy.v1.ChirpService.ListChirps:input_type -> chirpy.v1.ListChirpsRequest
+	19, // 31: chirpy.v1.ChirpService.EditChirp:input_type -> chirpy.v1.EditChirpRequest
+	17, // 32: chirpy.v1.ChirpService.DeleteChirp:input_type -> chirpy.v1.ChirpRequest
+	17, // 33: chirpy.v1.ChirpService.Rechirp:input_type -> chirpy.v1.ChirpRequest
+	20, // 34: chirpy.v1.ChirpService.QuoteChirp:input_type -> chirpy.v1.RespondRequest
+	20, // 35: chirpy.v1.ChirpService.ReplyToChirp:input_type -> chirpy.v1.RespondRequest
+	17, // 36: chirpy.v1.ChirpService.ListReplies:input_type -> chirpy.v1.ChirpRequest
+	17, // 37: chirpy.v1.ChirpService.LikeChirp:input_type -> chirpy.v1.ChirpRequest
+	17, // 38: chirpy.v1.ChirpService.UnlikeChirp:input_type -> chirpy.v1.ChirpRequest
+	23, // 39: chirpy.v1.ChirpService.GetTimeline:input_type -> chirpy.v1.PageRequest
+	25, // 40: chirpy.v1.ChirpService.StreamChirps:input_type -> chirpy.v1.StreamChirpsRequest
+	1,  // 41: chirpy.v1.AuthService.Login:output_type -> chirpy.v1.LoginResponse
+	3,  // 42: chirpy.v1.AuthService.Refresh:output_type -> chirpy.v1.RefreshResponse
+	28, // 43: chirpy.v1.AuthService.Revoke:output_type -> google.protobuf.Empty
+	5,  // 44: chirpy.v1.UserService.CreateUser:output_type -> chirpy.v1.User
+	5,  // 45: chirpy.v1.UserService.UpdateUser:output_type -> chirpy.v1.User
+	6,  // 46: chirpy.v1.UserService.GetUser:output_type -> chirpy.v1.Profile
+	6,  // 47: chirpy.v1.UserService.UpdateProfile:output_type -> chirpy.v1.Profile
+	28, // 48: chirpy.v1.UserService.FollowUser:output_type -> google.protobuf.Empty
+	28, // 49: chirpy.v1.UserService.UnfollowUser:output_type -> google.protobuf.Empty
+	28, // 50: chirpy.v1.UserService.BlockUser:output_type -> google.protobuf.Empty
+	28, // 51: chirpy.v1.UserService.UnblockUser:output_type -> google.protobuf.Empty
+	28, // 52: chirpy.v1.UserService.MuteUser:output_type -> google.protobuf.Empty
+	28, // 53: chirpy.v1.UserService.UnmuteUser:output_type -> google.protobuf.Empty
+	14, // 54: chirpy.v1.UserService.ListFollowers:output_type -> chirpy.v1.FollowPage
+	14, // 55: chirpy.v1.UserService.ListFollowing:output_type -> chirpy.v1.FollowPage
+	16, // 56: chirpy.v1.ChirpService.CreateChirp:output_type -> chirpy.v1.Chirp
+	16, // 57: chirpy.v1.ChirpService.GetChirp:output_type -> chirpy.v1.Chirp
+	22, // 58: chirpy.v1.ChirpService.ListChirps:output_type -> chirpy.v1.ChirpList
+	16, // 59: chirpy.v1.ChirpService.EditChirp:output_type -> chirpy.v1.Chirp
+	28, // 60: chirpy.v1.ChirpService.DeleteChirp:output_type -> google.protobuf.Empty
+	16, // 61: chirpy.v1.ChirpService.Rechirp:output_type -> chirpy.v1.Chirp
+	16, // 62: chirpy.v1.ChirpService.QuoteChirp:output_type -> chirpy.v1.Chirp
+	16, // 63: chirpy.v1.ChirpService.ReplyToChirp:output_type -> chirpy.v1.Chirp
+	22, // 64: chirpy.v1.ChirpService.ListReplies:output_type -> chirpy.v1.ChirpList
+	28, // 65: chirpy.v1.ChirpService.LikeChirp:output_type -> google.protobuf.Empty
+	28, // 66: chirpy.v1.ChirpService.UnlikeChirp:output_type -> google.protobuf.Empty
+	24, // 67: chirpy.v1.ChirpService.GetTimeline:output_type -> chirpy.v1.ChirpPage
+	26, // 68: chirpy.v1.ChirpService.StreamChirps:output_type -> chirpy.v1.StreamedChirp
+	41, // [41:69] is the sub-list for method output_type
+	13, // [13:41] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_chirpy_v1_chirpy_proto_init() }
+func file_chirpy_v1_chirpy_proto_init() {
+	if File_chirpy_v1_chirpy_proto != nil {
+		return
+	}
+	file_chirpy_v1_chirpy_proto_msgTypes[9].OneofWrappers = []any{
+		(*GetUserRequest_Id)(nil),
+		(*GetUserRequest_Handle)(nil),
+	}
+	file_chirpy_v1_chirpy_proto_msgTypes[10].OneofWrappers = []any{}
+	file_chirpy_v1_chirpy_proto_msgTypes[16].OneofWrappers = []any{
+		(*Chirp_RefChirp)(nil),
+		(*Chirp_TombstoneId)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_chirpy_v1_chirpy_proto_rawDesc), len(file_chirpy_v1_chirpy_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   27,
+			NumExtensions: 0,
+			NumServices:   3,
+		},
+		GoTypes:           file_chirpy_v1_chirpy_proto_goTypes,
+		DependencyIndexes: file_chirpy_v1_chirpy_proto_depIdxs,
+		MessageInfos:      file_chirpy_v1_chirpy_proto_msgTypes,
+	}.Build()
+	File_chirpy_v1_chirpy_proto = out.File
+	file_chirpy_v1_chirpy_proto_goTypes = nil
+	file_chirpy_v1_chirpy_proto_depIdxs = nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"chirpy/internal/auth"
+	"chirpy/internal/broker"
+	"chirpy/internal/database"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+var chirpColumns = []string{"id", "created_at", "updated_at", "body", "user_id", "kind", "ref_chirp_id"}
+
+// mockConfig returns an apiConfig whose queries go to a sqlmock database, which
+// must have seen every query expected of it by the end of the test. Queries are
+// matched on their sqlc name, as in mock.ExpectQuery("name: GetChirp "). Events
+// are published to a memory broker.
+func mockConfig(t *testing.T) (*apiConfig, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() returned error %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+	})
+	return &apiConfig{publicURL: "https://chirpy.test", sekrit: testSecret, db: db, dbQueries: database.New(db),
+		broker: broker.NewMemory()}, mock
+}
+
+// authHeader returns an Authorization header logging user in to a mockConfig.
+func authHeader(t *testing.T, user uuid.UUID) string {
+	t.Helper()
+	token, err := auth.MakeJWT(user, testSecret, time.Minute)
+	if err != nil {
+		t.Fatalf("MakeJWT() returned error %v", err)
+	}
+	return "Bearer " + token
+}
+
+// chirpRows returns chirps as the result of a chirp query.
+func chirpRows(chirps ...database.Chirp) *sqlmock.Rows {
+	rows := sqlmock.NewRows(chirpColumns)
+	for _, chirp := range chirps {
+		rows.AddRow(chirp.ID, chirp.CreatedAt, chirp.UpdatedAt, chirp.Body, chirp.UserID, chirp.Kind, chirp.RefChirpID)
+	}
+	return rows
+}
+
+// userRows returns users as the result of a query for whole users.
+func userRows(users ...database.User) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "email", "hashed_password", "handle",
+		"handle_changed_at", "display_name", "bio", "avatar_url", "is_chirpy_red"})
+	for _, user := range users {
+		rows.AddRow(user.ID, user.CreatedAt, user.UpdatedAt, user.Email, user.HashedPassword, user.Handle,
+			user.HandleChangedAt, user.DisplayName, user.Bio, user.AvatarUrl, user.IsChirpyRed)
+	}
+	return rows
+}
+
+// expectChirpDetails answers the queries chirpsConv makes once it has the chirps
+// themselves: no mentions, no likes, and authors for the handles.
+func expectChirpDetails(mock sqlmock.Sqlmock, authors ...database.GetUserHandlesRow) {
+	mock.ExpectQuery("name: GetMentionsForChirps ").WillReturnRows(
+		sqlmock.NewRows([]string{"chirp_id", "user_id", "start_offset", "end_offset", "handle"}))
+	mock.ExpectQuery("name: GetLikeCounts ").WillReturnRows(sqlmock.NewRows([]string{"chirp_id", "likes"}))
+	handles := sqlmock.NewRows([]string{"id", "handle"})
+	for _, author := range authors {
+		handles.AddRow(author.ID, author.Handle)
+	}
+	mock.ExpectQuery("name: GetUserHandles ").WillReturnRows(handles)
+}
+
+// expectNotification answers the CreateNotification notify makes when actor does
+// kind to recipient. Notifications that stand alone get a random group key.
+func expectNotification(mock sqlmock.Sqlmock, kind string, recipient, actor uuid.UUID, chirp uuid.NullUUID) {
+	group := notificationGroup(kind, recipient, chirp, time.Now())
+	var groupArg any = group
+	if kind == notifyReply || kind == notifyMention || kind == notifyQuote {
+		groupArg = sqlmock.AnyArg()
+	}
+	mock.ExpectQuery("name: CreateNotification ").WithArgs(recipient, actor, kind, chirp, groupArg).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "actor_id", "type", "chirp_id", "group_key",
+			"created_at", "read_at"}).AddRow(uuid.New(), recipient, actor, kind, chirp, group, time.Now().UTC(), nil))
+}
+
+// published returns the events waiting on a subscription, without blocking.
+func published(events <-chan broker.Event) []broker.Event {
+	var got []broker.Event
+	for {
+		select {
+		case event := <-events:
+			got = append(got, event)
+		default:
+			return got
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"chirpy/internal/validate"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxRequestBody bounds the JSON bodies the API accepts. The largest real one
+// is well under a kilobyte.
+const maxRequestBody = 64 << 10
+
+// bodyValidator is a request body with rules that its validate tags cannot
+// express. validate runs once the tags pass, and may tidy the body up.
+type bodyValidator interface {
+	validate() error
+}
+
+// decodeRequest reads req's JSON body into dst and checks it against dst's
+// validate tags and validate method, answering with an error itself and
+// returning false if anything is wrong. Every broken tag rule is reported at
+// once. From v2 on the body must be sent as application/json and hold one
+// object with only the fields dst has; v1 clients send extras, like user_id
+// with a chirp, and keep working.
+func decodeRequest(writer http.ResponseWriter, req *http.Request, dst any) bool {
+	strict := requestVersion(req) >= apiV2
+	if strict {
+		mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if err != nil || mediaType != jsonContent {
+			handleError(writer, req, http.StatusUnsupportedMediaType, "Content-Type must be "+jsonContent)
+			return false
+		}
+	}
+	decoder := json.NewDecoder(http.MaxBytesReader(writer, req.Body, maxRequestBody))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	err := decoder.Decode(dst)
+	if strict && err == nil && decoder.More() {
+		err = errors.New("body must hold a single JSON object")
+	}
+	var tooBig *http.MaxBytesError
+	if errors.As(err, &tooBig) {
+		handleError(writer, req, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("body must be at most %d bytes", tooBig.Limit))
+		return false
+	} else if field, ok := unknownField(err); ok {
+		handleInvalid(writer, req, []validate.FieldError{{Field: field, Rule: "unknown",
+			Message: field + " is not a known field"}})
+		return false
+	} else if err != nil {
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return false
+	}
+	if errs := validate.Struct(dst); len(errs) > 0 {
+		handleInvalid(writer, req, errs)
+		return false
+	}
+	if body, ok := dst.(bodyValidator); ok {
+		if err = body.validate(); err != nil {
+			handleError(writer, req, http.StatusBadRequest, err.Error())
+			return false
+		}
+	}
+	return true
+}
+
+// unknownField is the field that DisallowUnknownFields rejected, which
+// encoding/json only reports in the error's text.
+func unknownField(err error) (string, bool) {
+	if err == nil {
+		return "", false
+	}
+	quoted, ok := strings.CutPrefix(err.Error(), "json: unknown field ")
+	if !ok {
+		return "", false
+	}
+	field, err := strconv.Unquote(quoted)
+	return field, err == nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"chirpy/internal/validate"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeRequest(t *testing.T) {
+	routes := (&apiConfig{}).routes()
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantErrors  []validate.FieldError
+	}{
+		{"v2 needs a JSON content type", "/api/v2/users", "text/plain", `{}`, http.StatusUnsupportedMediaType, nil},
+		{"v2 rejects unknown fields", "/api/v2/users", jsonContent,
+			`{"email":"a@example.com","password":"pw","handle":"alice","admin":true}`, http.StatusBadRequest,
+			[]validate.FieldError{{Field: "admin", Rule: "unknown", Message: "admin is not a known field"}}},
+		{"v2 rejects trailing data", "/api/v2/users", jsonContent, `{"email":"a@example.com"} {}`,
+			http.StatusBadRequest, nil},
+		{"every field error at once", "/api/v2/users", jsonContent, `{"email":"nope"}`, http.StatusBadRequest,
+			[]validate.FieldError{
+				{Field: "password", Rule: "required", Message: "password is required"},
+				{Field: "email", Rule: "email", Message: "email must be an email address"},
+				{Field: "handle", Rule: "required", Message: "handle is required"},
+			}},
+		{"bodies are limited", "/api/users", "", `{"email":"` + strings.Repeat("a", maxRequestBody) + `"}`,
+			http.StatusRequestEntityTooLarge, nil},
+		{"v1 ignores extra fields", "/api/chirps", "", `{"body":"hi","user_id":"x"}`, http.StatusUnauthorized, nil},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+		if tc.contentType != "" {
+			req.Header.Set("Content-Type", tc.contentType)
+		}
+		recorder := httptest.NewRecorder()
+		routes.ServeHTTP(recorder, req)
+		if recorder.Code != tc.wantStatus {
+			t.Errorf("%s: status %d, want %d: %s", tc.name, recorder.Code, tc.wantStatus, recorder.Body)
+			continue
+		}
+		if tc.wantErrors == nil {
+			continue
+		}
+		var msg problem
+		if err := json.Unmarshal(recorder.Body.Bytes(), &msg); err != nil {
+			t.Errorf("%s: body = %q: %v", tc.name, recorder.Body, err)
+		} else if msg.Code != codeValidationFailed || !reflect.DeepEqual(msg.Errors, tc.wantErrors) {
+			t.Errorf("%s: body = %+v, want errors %+v", tc.name, msg, tc.wantErrors)
+		}
+	}
+}
@@ -0,0 +1,774 @@
+package main
+
+import (
+	"chirpy/internal/activitypub"
+	"chirpy/internal/database"
+	"chirpy/internal/netguard"
+	"chirpy/internal/webhooks"
+	"context"
+	"crypto/rsa"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type remoteFollowMsg struct {
+	Account string `json:"account" validate:"required"`
+}
+
+type remoteActorResp struct {
+	Id          uuid.UUID `json:"id"`
+	Uri         string    `json:"uri"`
+	Account     string    `json:"account"`
+	DisplayName string    `json:"display_name"`
+}
+
+type remoteFollowResp struct {
+	remoteActorResp
+	Accepted  bool      `json:"accepted"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type remotePostResp struct {
+	Id          uuid.UUID       `json:"id"`
+	Uri         string          `json:"uri"`
+	Url         string          `json:"url"`
+	Body        string          `json:"body"`
+	InReplyTo   string          `json:"in_reply_to,omitempty"`
+	PublishedAt time.Time       `json:"published_at"`
+	Author      remoteActorResp `json:"author"`
+}
+
+type remotePostPage struct {
+	Posts      []remotePostResp `json:"posts"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+const federationInterval = 5 * time.Second
+const federationBatch = 20
+const federationMaxAttempts = 8
+const federationLease = 5 * time.Minute
+const maxInboxBody = 1 << 20
+const keyRefetchInterval = time.Minute
+const keyFetchesMax = 10000
+
+var federationClient = newFederationClient(false)
+
+// newFederationClient returns a client that only speaks https, and only to
+// public addresses, since the servers it reaches are named by whoever sends us
+// an activity. A development instance, on localhost, may reach its peers there.
+func newFederationClient(local bool) *http.Client {
+	guard := netguard.Guard{AllowLoopback: local}
+	return &http.Client{Transport: activitypub.RequireHTTPS(guard.Transport()), Timeout: 10 * time.Second}
+}
+
+// keyFetches remembers when the owner of each signing key was last fetched, so
+// that signatures naming a key, good or bad, cannot have us fetch it again and
+// again.
+type keyFetches struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+// allow reports whether keyID's owner may be fetched now, and if so notes that
+// it is being fetched.
+func (fetches *keyFetches) allow(keyID string, now time.Time) bool {
+	fetches.mu.Lock()
+	defer fetches.mu.Unlock()
+	if fetches.last == nil {
+		fetches.last = map[string]time.Time{}
+	}
+	if last, ok := fetches.last[keyID]; ok && now.Sub(last) < keyRefetchInterval {
+		return false
+	}
+	// Key ids are whatever senders say they are, so only recent fetches are kept.
+	if len(fetches.last) >= keyFetchesMax {
+		for fetched, last := range fetches.last {
+			if now.Sub(last) >= keyRefetchInterval {
+				delete(fetches.last, fetched)
+			}
+		}
+		if len(fetches.last) >= keyFetchesMax {
+			clear(fetches.last)
+		}
+	}
+	fetches.last[keyID] = now
+	return true
+}
+
+func (cfg *apiConfig) actorURL(id uuid.UUID) string {
+	return cfg.publicURL + "/ap/users/" + id.String()
+}
+
+func (cfg *apiConfig) noteURL(id uuid.UUID) string {
+	return cfg.publicURL + "/ap/chirps/" + id.String()
+}
+
+// domain is the host other servers know this instance by, as in alice@domain.
+func (cfg *apiConfig) domain() string {
+	public, err := url.Parse(cfg.publicURL)
+	if err != nil {
+		return ""
+	}
+	return public.Host
+}
+
+// actorKey returns user's signing key, making one the first time it is needed.
+func (cfg *apiConfig) actorKey(ctx context.Context, user uuid.UUID) (database.ActorKey, error) {
+	key, err := cfg.dbQueries.GetActorKey(ctx, user)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return key, err
+	}
+	privatePEM, publicPEM, err := activitypub.GenerateKey()
+	if err != nil {
+		return database.ActorKey{}, err
+	}
+	// Two requests may race to make the key; whichever lands first is kept.
+	err = cfg.dbQueries.CreateActorKey(ctx, database.CreateActorKeyParams{UserID: user, PublicKeyPem: publicPEM,
+		PrivateKeyPem: privatePEM})
+	if err != nil {
+		return database.ActorKey{}, err
+	}
+	return cfg.dbQueries.GetActorKey(ctx, user)
+}
+
+// chirpNote is the Note a chirp is published as. Chirps are public, so they are
+// addressed to everyone and copied to the author's followers.
+func (cfg *apiConfig) chirpNote(chirp database.Chirp) activitypub.Note {
+	actor := cfg.actorURL(chirp.UserID)
+	note := activitypub.Note{ID: cfg.noteURL(chirp.ID), Type: "Note", AttributedTo: actor,
+		Content: activitypub.HTML(chirp.Body), Published: chirp.CreatedAt.UTC(), URL: cfg.publicURL + chirpPath(chirp.ID),
+		To: []string{activitypub.Public}, Cc: []string{actor + "/followers"}}
+	if chirp.RefChirpID.Valid && chirp.Kind == chirpKindReply {
+		note.InReplyTo = cfg.noteURL(chirp.RefChirpID.UUID)
+	}
+	if chirp.UpdatedAt.After(chirp.CreatedAt) {
+		updated := chirp.UpdatedAt.UTC()
+		note.Updated = &updated
+	}
+	return note
+}
+
+// noteActivity wraps a chirp's Note, or its tombstone for a Delete, in an activity by its author.
+func (cfg *apiConfig) noteActivity(kind string, chirp database.Chirp) (activitypub.Activity, error) {
+	note := cfg.chirpNote(chirp)
+	activity := activitypub.Activity{Context: activitypub.Context, Type: kind, Actor: note.AttributedTo,
+		To: note.To, Cc: note.Cc}
+	var object any = note
+	switch kind {
+	case "Create":
+		activity.ID = note.ID + "/activity"
+		activity.Published = &note.Published
+	case "Update":
+		activity.ID = fmt.Sprintf("%s#updates/%d", note.ID, chirp.UpdatedAt.UnixNano())
+	case "Delete":
+		activity.ID = note.ID + "#delete"
+		object = activitypub.Tombstone{ID: note.ID, Type: "Tombstone"}
+	}
+	raw, err := json.Marshal(object)
+	activity.Object = raw
+	return activity, err
+}
+
+// enqueueFederation queues a chirp activity to the inboxes of its author's remote
+// followers, in the transaction of the write it is about. Rechirps are not
+// federated: there is no local Note for them to point at.
+func (cfg *apiConfig) enqueueFederation(ctx context.Context, qtx *database.Queries, kind string, chirp database.Chirp) error {
+	if chirp.Kind == chirpKindRechirp {
+		return nil
+	}
+	activity, err := cfg.noteActivity(kind, chirp)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+	return qtx.EnqueueFollowerDeliveries(ctx, database.EnqueueFollowerDeliveriesParams{UserID: chirp.UserID,
+		Payload: string(payload)})
+}
+
+// enqueueActivity queues one activity by user to a single inbox.
+func enqueueActivity(ctx context.Context, qtx *database.Queries, user uuid.UUID, inbox string, activity any) error {
+	payload, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+	return qtx.EnqueueFederationDelivery(ctx, database.EnqueueFederationDeliveryParams{UserID: user, Inbox: inbox,
+		Payload: string(payload)})
+}
+
+// storeActor caches a fetched remote actor, keeping only what we need to deliver
+// to it, verify it and show it.
+func (cfg *apiConfig) storeActor(ctx context.Context, actor activitypub.Actor) (database.RemoteActor, error) {
+	id, err := url.Parse(actor.ID)
+	if err != nil {
+		return database.RemoteActor{}, err
+	}
+	params := database.UpsertRemoteActorParams{Uri: actor.ID, Handle: actor.PreferredUsername, Domain: id.Host,
+		DisplayName: actor.Name, Inbox: actor.Inbox, KeyID: actor.PublicKey.ID, PublicKeyPem: actor.PublicKey.PublicKeyPem}
+	if actor.Endpoints != nil && actor.Endpoints.SharedInbox != "" {
+		params.SharedInbox = sql.NullString{String: actor.Endpoints.SharedInbox, Valid: true}
+	}
+	return cfg.dbQueries.UpsertRemoteActor(ctx, params)
+}
+
+func (cfg *apiConfig) fetchActor(ctx context.Context, uri string) (database.RemoteActor, error) {
+	actor, err := activitypub.FetchActor(ctx, federationClient, nil, uri)
+	if err != nil {
+		return database.RemoteActor{}, err
+	}
+	return cfg.storeActor(ctx, actor)
+}
+
+// remoteKey finds the key a signature names, fetching its owner if the key is new
+// to us or refresh is set. Each key's owner is fetched at most once every
+// keyRefetchInterval.
+func (cfg *apiConfig) remoteKey(refresh bool) activitypub.KeyFetcher {
+	return func(ctx context.Context, keyID string) (*rsa.PublicKey, error) {
+		remote, err := cfg.dbQueries.GetRemoteActorByKeyID(ctx, keyID)
+		if refresh || errors.Is(err, sql.ErrNoRows) {
+			if !cfg.keyFetches.allow(keyID, time.Now()) {
+				return nil, fmt.Errorf("key %s was fetched too recently", keyID)
+			}
+			owner, _, _ := strings.Cut(keyID, "#")
+			remote, err = cfg.fetchActor(ctx, owner)
+			if err == nil && remote.KeyID != keyID {
+				err = fmt.Errorf("actor %s does not own key %s", owner, keyID)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return activitypub.ParsePublicKey(remote.PublicKeyPem)
+	}
+}
+
+func remoteActorConv(actor database.RemoteActor) remoteActorResp {
+	return remoteActorResp{Id: actor.ID, Uri: actor.Uri, Account: actor.Handle + "@" + actor.Domain,
+		DisplayName: actor.DisplayName}
+}
+
+// localUser loads the user named in the request path.
+func (cfg *apiConfig) localUser(req *http.Request) (database.User, error) {
+	id, err := parseID(req)
+	if err != nil {
+		return database.User{}, sql.ErrNoRows
+	}
+	return cfg.dbQueries.GetUserByID(req.Context(), id)
+}
+
+func writeActivityJSON(writer http.ResponseWriter, contentType string, msg string, doc any) {
+	writer.Header()["Content-Type"] = []string{contentType}
+	handleJsonWrite(writer, http.StatusOK, msg, doc)
+}
+
+func (cfg *apiConfig) handleWebFinger(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	resource := req.URL.Query().Get("resource")
+	handle, domain, err := activitypub.SplitAccount(resource)
+	if err != nil {
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !strings.EqualFold(domain, cfg.domain()) {
+		handleError(writer, req, http.StatusNotFound, "Unknown domain")
+		return
+	}
+	user, err := cfg.dbQueries.GetUserByHandle(req.Context(), handle)
+	if errors.Is(err, sql.ErrNoRows) {
+		handleError(writer, req, http.StatusNotFound, "User not found")
+		return
+	} else if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	actor := cfg.actorURL(user.ID)
+	writeActivityJSON(writer, activitypub.JRDContentType, resource, activitypub.WebFinger{
+		Subject: "acct:" + user.Handle + "@" + cfg.domain(), Aliases: []string{actor},
+		Links: []activitypub.WebFingerLink{{Rel: "self", Type: activitypub.ContentType, Href: actor}}})
+}
+
+func (cfg *apiConfig) handleActor(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	user, err := cfg.localUser(req)
+	if errors.Is(err, sql.ErrNoRows) {
+		handleError(writer, req, http.StatusNotFound, "User not found")
+		return
+	} else if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	key, err := cfg.actorKey(req.Context(), user.ID)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	id := cfg.actorURL(user.ID)
+	actor := activitypub.Actor{Context: activitypub.Context, ID: id, Type: "Person", PreferredUsername: user.Handle,
+		Name: user.DisplayName, Summary: user.Bio, URL: cfg.publicURL + profilePath(user.ID), Inbox: id + "/inbox",
+		Outbox: id + "/outbox", Followers: id + "/followers", Following: id + "/following",
+		PublicKey: activitypub.PublicKey{ID: id + "#main-key", Owner: id, PublicKeyPem: key.PublicKeyPem}}
+	if user.AvatarUrl != "" {
+		actor.Icon = &activitypub.Image{Type: "Image", URL: user.AvatarUrl}
+	}
+	writeActivityJSON(writer, activitypub.ContentType, "actor", actor)
+}
+
+func (cfg *apiConfig) handleNote(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	id, err := parseID(req)
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, err.Error())
+		return
+	}
+	chirp, err := cfg.dbQueries.GetChirp(req.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && chirp.Kind == chirpKindRechirp) {
+		handleError(writer, req, http.StatusNotFound, "Chirp not found")
+		return
+	} else if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	note := cfg.chirpNote(chirp)
+	note.Context = activitypub.Context
+	writeActivityJSON(writer, activitypub.ContentType, "note", note)
+}
+
+// handleOutbox serves a user's chirps as Create activities. Without a page
+// parameter it returns only the collection, as other servers expect.
+func (cfg *apiConfig) handleOutbox(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	cur, limit, err := parsePage(req)
+	if err != nil {
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	user, err := cfg.localUser(req)
+	if errors.Is(err, sql.ErrNoRows) {
+		handleError(writer, req, http.StatusNotFound, "User not found")
+		return
+	} else if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	outbox := cfg.actorURL(user.ID) + "/outbox"
+	if req.URL.Query().Get("page") == "" {
+		total, err := cfg.dbQueries.CountOutboxChirps(req.Context(), user.ID)
+		if err != nil {
+			handleFault(writer, req, http.StatusInternalServerError, err)
+			return
+		}
+		writeActivityJSON(writer, activitypub.ContentType, "outbox", activitypub.OrderedCollection{
+			Context: activitypub.Context, ID: outbox, Type: "OrderedCollection", TotalItems: total,
+			First: outbox + "?page=true"})
+		return
+	}
+	chirps, err := cfg.dbQueries.GetOutboxChirps(req.Context(), database.GetOutboxChirpsParams{UserID: user.ID,
+		BeforeTime: cur.time, BeforeID: cur.id, PageSize: limit})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	page := activitypub.OrderedCollectionPage{Context: activitypub.Context, ID: outbox + "?" + req.URL.RawQuery,
+		Type: "OrderedCollectionPage", PartOf: outbox, OrderedItems: make([]any, len(chirps))}
+	for i := range chirps {
+		if page.OrderedItems[i], err = cfg.noteActivity("Create", chirps[i]); err != nil {
+			handleFault(writer, req, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	if len(chirps) > 0 {
+		last := chirps[len(chirps)-1]
+		if next := nextCursor(len(chirps), limit, last.CreatedAt, last.ID); next != "" {
+			page.Next = outbox + "?page=true&cursor=" + next
+		}
+	}
+	writeActivityJSON(writer, activitypub.ContentType, "outbox", page)
+}
+
+// handleFollowCollection serves the followers and following collections. Only
+// their sizes are public, counting local and remote accounts alike.
+func (cfg *apiConfig) handleFollowCollection(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	collection := req.PathValue("collection")
+	if collection != "followers" && collection != "following" {
+		handleError(writer, req, http.StatusNotFound, "Not found")
+		return
+	}
+	user, err := cfg.localUser(req)
+	if errors.Is(err, sql.ErrNoRows) {
+		handleError(writer, req, http.StatusNotFound, "User not found")
+		return
+	} else if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	counts, err := cfg.dbQueries.CountActorFollows(req.Context(), user.ID)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	total := counts.Followers
+	if collection == "following" {
+		total = counts.Following
+	}
+	writeActivityJSON(writer, activitypub.ContentType, collection, activitypub.OrderedCollection{
+		Context: activitypub.Context, ID: cfg.actorURL(user.ID) + "/" + collection, Type: "OrderedCollection",
+		TotalItems: total})
+}
+
+// handleInbox takes activities from other servers. Every delivery must be signed
+// by the actor it claims to come from.
+func (cfg *apiConfig) handleInbox(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	user, err := cfg.localUser(req)
+	if errors.Is(err, sql.ErrNoRows) {
+		handleError(writer, req, http.StatusNotFound, "User not found")
+		return
+	} else if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(writer, req.Body, maxInboxBody))
+	if err != nil {
+		handleError(writer, req, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	keyID, err := activitypub.Verify(req, body, cfg.remoteKey(false))
+	if errors.Is(err, activitypub.ErrBadSignature) {
+		// The sender may have rotated its key since we cached it.
+		keyID, err = activitypub.Verify(req, body, cfg.remoteKey(true))
+	}
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	remote, err := cfg.dbQueries.GetRemoteActorByKeyID(req.Context(), keyID)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	var activity activitypub.Activity
+	if err = json.Unmarshal(body, &activity); err != nil {
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	if activity.Actor != remote.Uri {
+		handleError(writer, req, http.StatusForbidden, "Activity is not by the signer")
+		return
+	}
+	if err = cfg.receiveActivity(req.Context(), user, remote, activity, body); errors.Is(err, errBadActivity) {
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return
+	} else if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	writer.WriteHeader(http.StatusAccepted)
+}
+
+var errBadActivity = errors.New("activity is malformed")
+
+// receiveActivity applies an activity remote sent to user. Activities we have no
+// use for are accepted and dropped.
+func (cfg *apiConfig) receiveActivity(ctx context.Context, user database.User, remote database.RemoteActor,
+	activity activitypub.Activity, body []byte) error {
+	switch activity.Type {
+	case "Follow":
+		if activity.ObjectID() != cfg.actorURL(user.ID) {
+			return errBadActivity
+		}
+		return cfg.acceptFollow(ctx, user, remote, activity, body)
+	case "Undo":
+		if activity.ObjectType() == "Follow" {
+			return cfg.dbQueries.RemoveRemoteFollower(ctx, database.RemoveRemoteFollowerParams{UserID: user.ID,
+				ActorID: remote.ID})
+		}
+	case "Accept":
+		return cfg.dbQueries.AcceptRemoteFollow(ctx, database.AcceptRemoteFollowParams{
+			FollowUri: activity.ObjectID(), ActorID: remote.ID})
+	case "Reject":
+		return cfg.dbQueries.RejectRemoteFollow(ctx, database.RejectRemoteFollowParams{
+			FollowUri: activity.ObjectID(), ActorID: remote.ID})
+	case "Create", "Update":
+		if activity.ObjectType() == "Note" {
+			return cfg.storeRemoteNote(ctx, remote, activity)
+		}
+	case "Delete":
+		if activity.ObjectID() == remote.Uri {
+			return cfg.dbQueries.DeleteRemoteActor(ctx, remote.Uri)
+		}
+		return cfg.dbQueries.DeleteRemotePost(ctx, database.DeleteRemotePostParams{Uri: activity.ObjectID(),
+			ActorID: remote.ID})
+	}
+	return nil
+}
+
+// acceptFollow records a remote follower and queues the Accept that tells its
+// server the follow went through. Chirpy accounts are public, so every follow is
+// accepted.
+func (cfg *apiConfig) acceptFollow(ctx context.Context, user database.User, remote database.RemoteActor,
+	follow activitypub.Activity, body []byte) error {
+	if _, err := cfg.actorKey(ctx, user.ID); err != nil {
+		return err
+	}
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	err = qtx.AddRemoteFollower(ctx, database.AddRemoteFollowerParams{UserID: user.ID, ActorID: remote.ID,
+		FollowUri: follow.ID})
+	if err != nil {
+		return err
+	}
+	actor := cfg.actorURL(user.ID)
+	accept := activitypub.Activity{Context: activitypub.Context, ID: actor + "#accepts/" + uuid.NewString(),
+		Type: "Accept", Actor: actor, Object: json.RawMessage(body), To: []string{remote.Uri}}
+	if err = enqueueActivity(ctx, qtx, user.ID, remote.Inbox, accept); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// storeRemoteNote keeps a remote post for the timelines of local users who follow
+// its author. Posts from anyone else are dropped.
+func (cfg *apiConfig) storeRemoteNote(ctx context.Context, remote database.RemoteActor, activity activitypub.Activity) error {
+	var note activitypub.Note
+	if err := json.Unmarshal(activity.Object, &note); err != nil || note.ID == "" {
+		return errBadActivity
+	}
+	if note.AttributedTo != remote.Uri {
+		return errBadActivity
+	}
+	followed, err := cfg.dbQueries.IsRemoteActorFollowed(ctx, remote.ID)
+	if err != nil || !followed {
+		return err
+	}
+	params := database.UpsertRemotePostParams{Uri: note.ID, ActorID: remote.ID, Body: activitypub.PlainText(note.Content),
+		Url: note.URL, PublishedAt: note.Published}
+	if params.Url == "" {
+		params.Url = note.ID
+	}
+	if params.PublishedAt.IsZero() {
+		params.PublishedAt = time.Now().UTC()
+	}
+	if note.InReplyTo != "" {
+		params.InReplyTo = sql.NullString{String: note.InReplyTo, Valid: true}
+	}
+	return cfg.dbQueries.UpsertRemotePost(ctx, params)
+}
+
+// handleFollowRemote follows an account on another server. The follow stays
+// pending until that server sends an Accept.
+func (cfg *apiConfig) handleFollowRemote(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	msg := remoteFollowMsg{}
+	if !decodeRequest(writer, req, &msg) {
+		return
+	}
+	if _, _, err := activitypub.SplitAccount(msg.Account); err != nil {
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	id, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	actorID, err := activitypub.Resolve(req.Context(), federationClient, msg.Account)
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, err.Error())
+		return
+	}
+	remote, err := cfg.fetchActor(req.Context(), actorID)
+	if err != nil {
+		handleFault(writer, req, http.StatusBadGateway, err)
+		return
+	}
+	if _, err = cfg.actorKey(req.Context(), id); err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	actor := cfg.actorURL(id)
+	object, _ := json.Marshal(remote.Uri)
+	follow := activitypub.Activity{Context: activitypub.Context, ID: actor + "#follows/" + uuid.NewString(),
+		Type: "Follow", Actor: actor, Object: object, To: []string{remote.Uri}}
+	err = qtx.FollowRemoteActor(req.Context(), database.FollowRemoteActorParams{UserID: id, ActorID: remote.ID,
+		FollowUri: follow.ID})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	if err = enqueueActivity(req.Context(), qtx, id, remote.Inbox, follow); err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	if err = tx.Commit(); err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	handleJsonWrite(writer, http.StatusAccepted, msg.Account, remoteFollowResp{remoteActorResp: remoteActorConv(remote),
+		CreatedAt: time.Now().UTC()})
+}
+
+func (cfg *apiConfig) handleListRemoteFollows(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	id, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	follows, err := cfg.dbQueries.GetRemoteFollowing(req.Context(), id)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	jsonFollows := make([]remoteFollowResp, len(follows))
+	for i, follow := range follows {
+		jsonFollows[i] = remoteFollowResp{remoteActorResp: remoteActorResp{Id: follow.ID, Uri: follow.Uri,
+			Account: follow.Handle + "@" + follow.Domain, DisplayName: follow.DisplayName},
+			Accepted: follow.Accepted, CreatedAt: follow.CreatedAt}
+	}
+	handleJsonWrite(writer, http.StatusOK, "follows", jsonFollows)
+}
+
+// handleUnfollowRemote drops a remote follow and sends an Undo for it.
+func (cfg *apiConfig) handleUnfollowRemote(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	userID, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	id, err := parseID(req)
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, err.Error())
+		return
+	}
+	remote, err := cfg.dbQueries.GetRemoteActor(req.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		handleError(writer, req, http.StatusNotFound, "Account not found")
+		return
+	} else if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	followURI, err := qtx.UnfollowRemoteActor(req.Context(), database.UnfollowRemoteActorParams{UserID: userID,
+		ActorID: remote.ID})
+	if errors.Is(err, sql.ErrNoRows) {
+		handleError(writer, req, http.StatusNotFound, "Not following that account")
+		return
+	} else if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	actor := cfg.actorURL(userID)
+	target, _ := json.Marshal(remote.Uri)
+	follow, _ := json.Marshal(activitypub.Activity{ID: followURI, Type: "Follow", Actor: actor, Object: target})
+	undo := activitypub.Activity{Context: activitypub.Context, ID: followURI + "/undo", Type: "Undo", Actor: actor,
+		Object: follow, To: []string{remote.Uri}}
+	if err = enqueueActivity(req.Context(), qtx, userID, remote.Inbox, undo); err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	if err = tx.Commit(); err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// handleRemoteTimeline lists posts by the remote accounts the user follows, newest first.
+func (cfg *apiConfig) handleRemoteTimeline(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	id, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	cur, limit, err := parsePage(req)
+	if err != nil {
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	posts, err := cfg.dbQueries.GetRemoteTimeline(req.Context(), database.GetRemoteTimelineParams{UserID: id,
+		BeforeTime: cur.time, BeforeID: cur.id, PageSize: limit})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	page := remotePostPage{Posts: make([]remotePostResp, len(posts))}
+	for i, post := range posts {
+		page.Posts[i] = remotePostResp{Id: post.ID, Uri: post.Uri, Url: post.Url, Body: post.Body,
+			InReplyTo: post.InReplyTo.String, PublishedAt: post.PublishedAt, Author: remoteActorResp{
+				Uri: post.ActorUri, Account: post.Handle + "@" + post.Domain, DisplayName: post.DisplayName}}
+	}
+	if len(posts) > 0 {
+		last := posts[len(posts)-1]
+		page.NextCursor = nextCursor(len(posts), limit, last.PublishedAt, last.ID)
+	}
+	handleJsonWrite(writer, http.StatusOK, "timeline", page)
+}
+
+// attemptFederation signs and sends one claimed delivery as the user it belongs
+// to, and records the outcome the same way webhook deliveries do.
+func (cfg *apiConfig) attemptFederation(ctx context.Context, claimed database.ClaimFederationDeliveriesRow) error {
+	key, err := activitypub.ParsePrivateKey(claimed.PrivateKeyPem)
+	if err == nil {
+		signer := &activitypub.Signer{KeyID: cfg.actorURL(claimed.UserID) + "#main-key", Key: key}
+		err = activitypub.Deliver(ctx, federationClient, signer, claimed.Inbox, []byte(claimed.Payload))
+	}
+	params := database.FinishFederationAttemptParams{ID: claimed.ID, Status: deliverySucceeded}
+	if err != nil {
+		attempts := int(claimed.Attempts) + 1
+		params.LastError = sql.NullString{String: err.Error(), Valid: true}
+		params.Status = deliveryPending
+		params.RetrySeconds = webhooks.Backoff(attempts).Seconds()
+		if attempts >= federationMaxAttempts {
+			params.Status = deliveryFailed
+		}
+	}
+	return cfg.dbQueries.FinishFederationAttempt(ctx, params)
+}
+
+// runFederation works through the outgoing activity queue each interval until ctx is done.
+func (cfg *apiConfig) runFederation(ctx context.Context, interval time.Duration) {
+	queue := deliveryQueue[database.ClaimFederationDeliveriesRow]{
+		name:  "federation",
+		batch: federationBatch,
+		claim: func(ctx context.Context) ([]database.ClaimFederationDeliveriesRow, error) {
+			return cfg.dbQueries.ClaimFederationDeliveries(ctx, database.ClaimFederationDeliveriesParams{
+				LeaseSeconds: federationLease.Seconds(), BatchSize: federationBatch})
+		},
+		attempt: cfg.attemptFederation,
+		id:      func(delivery database.ClaimFederationDeliveriesRow) uuid.UUID { return delivery.ID },
+	}
+	queue.run(ctx, interval)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"chirpy/internal/activitypub"
+	"chirpy/internal/database"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNoteActivity(t *testing.T) {
+	cfg := &apiConfig{publicURL: "http://localhost:8081"}
+	created := time.Date(2024, time.May, 4, 12, 0, 0, 0, time.UTC)
+	parent := uuid.New()
+	chirp := database.Chirp{ID: uuid.New(), CreatedAt: created, UpdatedAt: created, Body: "fish & chips\n<3",
+		UserID: uuid.New(), Kind: chirpKindReply, RefChirpID: uuid.NullUUID{UUID: parent, Valid: true}}
+	note := cfg.chirpNote(chirp)
+	if note.ID != "http://localhost:8081/ap/chirps/"+chirp.ID.String() {
+		t.Errorf("note id = %q", note.ID)
+	}
+	if note.AttributedTo != cfg.actorURL(chirp.UserID) || note.InReplyTo != cfg.noteURL(parent) {
+		t.Errorf("note attributedTo = %q, inReplyTo = %q", note.AttributedTo, note.InReplyTo)
+	}
+	if activitypub.PlainText(note.Content) != chirp.Body || note.Updated != nil {
+		t.Errorf("note content = %q, updated = %v", note.Content, note.Updated)
+	}
+
+	chirp.UpdatedAt = created.Add(time.Minute)
+	tests := []struct {
+		kind       string
+		objectType string
+	}{
+		{"Create", "Note"},
+		{"Update", "Note"},
+		{"Delete", "Tombstone"},
+	}
+	ids := map[string]bool{}
+	for _, tc := range tests {
+		activity, err := cfg.noteActivity(tc.kind, chirp)
+		if err != nil {
+			t.Fatalf("noteActivity(%s) returned error %v", tc.kind, err)
+		}
+		if activity.Actor != note.AttributedTo || activity.ObjectID() != note.ID || activity.ObjectType() != tc.objectType {
+			t.Errorf("%s: actor %q, object %q of type %q", tc.kind, activity.Actor, activity.ObjectID(), activity.ObjectType())
+		}
+		if ids[activity.ID] {
+			t.Errorf("%s: activity id %q is not unique", tc.kind, activity.ID)
+		}
+		ids[activity.ID] = true
+	}
+	update, _ := cfg.noteActivity("Update", chirp)
+	var updated activitypub.Note
+	if err := json.Unmarshal(update.Object, &updated); err != nil || updated.Updated == nil {
+		t.Errorf("updated note = %+v, %v", updated, err)
+	}
+}
+
+func TestKeyFetchesLimitsRefetches(t *testing.T) {
+	var fetches keyFetches
+	now := time.Now()
+	key := "https://example.com/users/alice#main-key"
+	if !fetches.allow(key, now) {
+		t.Fatal("first fetch of a key was refused")
+	}
+	if fetches.allow(key, now.Add(keyRefetchInterval/2)) {
+		t.Error("refetch within keyRefetchInterval was allowed")
+	}
+	if !fetches.allow("https://example.com/users/bob#main-key", now) {
+		t.Error("fetch of another key was refused")
+	}
+	if !fetches.allow(key, now.Add(keyRefetchInterval)) {
+		t.Error("refetch after keyRefetchInterval was refused")
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"chirpy/internal/entities"
+	"chirpy/internal/feeds"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// cachedFeed is a rendered feed, kept so that readers polling within feedTTL of
+// each other are answered without touching the database.
+type cachedFeed struct {
+	body    []byte
+	etag    string
+	expires time.Time
+}
+
+// feedCache holds rendered feeds by path. Its zero value is ready to use.
+type feedCache struct {
+	mu    sync.Mutex
+	feeds map[string]cachedFeed
+}
+
+const feedTTL = time.Minute
+const feedEntries = 50
+const feedCacheMax = 1024
+
+func (cache *feedCache) get(key string) (cachedFeed, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	feed, ok := cache.feeds[key]
+	if !ok || time.Now().After(feed.expires) {
+		return cachedFeed{}, false
+	}
+	return feed, true
+}
+
+func (cache *feedCache) put(key string, feed cachedFeed) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.feeds == nil {
+		cache.feeds = map[string]cachedFeed{}
+	}
+	// Hashtag feeds can be asked for under any name, so the cache is bounded:
+	// expired feeds go first, and if that is not enough everything does.
+	if len(cache.feeds) >= feedCacheMax {
+		now := time.Now()
+		for cached, old := range cache.feeds {
+			if now.After(old.expires) {
+				delete(cache.feeds, cached)
+			}
+		}
+		if len(cache.feeds) >= feedCacheMax {
+			clear(cache.feeds)
+		}
+	}
+	cache.feeds[key] = feed
+}
+
+func (cfg *apiConfig) chirpLink(id uuid.UUID) string {
+	return cfg.publicURL + chirpPath(id)
+}
+
+// feedOf builds a feed from chirps, newest first as the chirp loaders return them,
+// keeping the first feedEntries. Rechirps have no text of their own and are left
+// out. The feed's updated time is the latest change to any entry, or since if
+// there are none, so it only moves when the feed does.
+func (cfg *apiConfig) feedOf(feed feeds.Feed, chirps []chirpResp, since time.Time) feeds.Feed {
+	feed.Updated = since
+	for _, chirp := range chirps {
+		if len(feed.Entries) == feedEntries {
+			break
+		}
+		if chirp.Kind == chirpKindRechirp {
+			continue
+		}
+		feed.Entries = append(feed.Entries, feeds.Entry{ID: "urn:uuid:" + chirp.Id.String(), Link: cfg.chirpLink(chirp.Id),
+			Author: chirp.AuthorHandle, Content: chirp.Body, Published: chirp.CreatedAt, Updated: chirp.UpdatedAt})
+		if chirp.UpdatedAt.After(feed.Updated) {
+			feed.Updated = chirp.UpdatedAt
+		}
+	}
+	return feed
+}
+
+// serveFeed answers a feed request from the cache, or builds, renders and caches
+// the feed. Readers sending the current ETag in If-None-Match get a 304.
+func (cfg *apiConfig) serveFeed(writer http.ResponseWriter, req *http.Request, build func() (feeds.Feed, error)) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	rss := strings.HasSuffix(req.URL.Path, ".rss")
+	cached, ok := cfg.feeds.get(req.URL.Path)
+	if !ok {
+		feed, err := build()
+		if errors.Is(err, sql.ErrNoRows) {
+			handleError(writer, req, http.StatusNotFound, "Feed not found")
+			return
+		} else if err != nil {
+			handleFault(writer, req, http.StatusInternalServerError, err)
+			return
+		}
+		feed.Self = cfg.publicURL + req.URL.Path
+		render := feeds.Atom
+		if rss {
+			render = feeds.RSS
+		}
+		body, err := render(feed)
+		if err != nil {
+			handleFault(writer, req, http.StatusInternalServerError, err)
+			return
+		}
+		cached = cachedFeed{body: body, etag: feeds.ETag(body), expires: time.Now().Add(feedTTL)}
+		cfg.feeds.put(req.URL.Path, cached)
+	}
+	writer.Header()["Content-Type"] = []string{feeds.AtomContentType}
+	if rss {
+		writer.Header()["Content-Type"] = []string{feeds.RSSContentType}
+	}
+	writer.Header()["Etag"] = []string{cached.etag}
+	writer.Header()["Cache-Control"] = []string{"public, max-age=60"}
+	if feeds.Matches(req.Header.Get("If-None-Match"), cached.etag) {
+		writer.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(cached.body)
+}
+
+// handleUserFeed serves a user's chirps as Atom or RSS. Feed readers do not log
+// in, so it shows what an anonymous visitor would see.
+func (cfg *apiConfig) handleUserFeed(writer http.ResponseWriter, req *http.Request) {
+	cfg.serveFeed(writer, req, func() (feeds.Feed, error) {
+		id, err := parseID(req)
+		if err != nil {
+			return feeds.Feed{}, sql.ErrNoRows
+		}
+		user, err := cfg.dbQueries.GetUserByID(req.Context(), id)
+		if err != nil {
+			return feeds.Feed{}, err
+		}
+		chirps, err := cfg.loadChirps(req.Context(), uuid.NullUUID{}, uuid.NullUUID{UUID: user.ID, Valid: true}, feedEntries, true)
+		if err != nil {
+			return feeds.Feed{}, err
+		}
+		title := "Chirps by @" + user.Handle
+		if user.DisplayName != "" {
+			title = "Chirps by " + user.DisplayName + " (@" + user.Handle + ")"
+		}
+		return cfg.feedOf(feeds.Feed{ID: "urn:uuid:" + user.ID.String(), Title: title,
+			Link: cfg.publicURL + profilePath(user.ID)}, chirps, user.CreatedAt), nil
+	})
+}
+
+// handleHashtagFeed serves the chirps tagged with a hashtag as Atom or RSS.
+func (cfg *apiConfig) handleHashtagFeed(writer http.ResponseWriter, req *http.Request) {
+	cfg.serveFeed(writer, req, func() (feeds.Feed, error) {
+		tag := entities.NormalizeHashtag(req.PathValue("tag"))
+		if tag == "" {
+			return feeds.Feed{}, sql.ErrNoRows
+		}
+		chirps, err := cfg.loadHashtagChirps(req.Context(), uuid.NullUUID{}, tag, feedEntries)
+		if err != nil {
+			return feeds.Feed{}, err
+		}
+		link := cfg.publicURL + "/api/hashtags/" + tag + "/chirps"
+		// A tag nobody has used yet has no date of its own; the epoch keeps its
+		// feed, and so its ETag, the same until somebody does.
+		return cfg.feedOf(feeds.Feed{ID: link, Title: "Chirps tagged #" + tag, Link: link}, chirps, time.Unix(0, 0)), nil
+	})
+}
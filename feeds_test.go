@@ -0,0 +1,47 @@
+package main
+
+import (
+	"chirpy/internal/feeds"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestServeFeedCaches checks that polling readers are answered from the cache,
+// and that a reader sending the current ETag gets no body.
+func TestServeFeedCaches(t *testing.T) {
+	cfg := &apiConfig{publicURL: "https://chirpy.test"}
+	builds := 0
+	build := func() (feeds.Feed, error) {
+		builds++
+		return feeds.Feed{ID: "urn:uuid:ba0f3b30-2d6a-4bd5-9ed4-7d0ea5bc1fbb", Title: "Chirps by @alice",
+			Updated: time.Date(2024, time.May, 4, 12, 0, 0, 0, time.UTC)}, nil
+	}
+	get := func(path, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		recorder := httptest.NewRecorder()
+		cfg.serveFeed(recorder, req, build)
+		return recorder
+	}
+	first := get("/users/alice/feed.atom", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" || first.Header().Get("Content-Type") != feeds.AtomContentType {
+		t.Fatalf("first poll = %d, ETag %q, Content-Type %q", first.Code, etag, first.Header().Get("Content-Type"))
+	}
+	if again := get("/users/alice/feed.atom", etag); again.Code != http.StatusNotModified || again.Body.Len() != 0 {
+		t.Errorf("poll with current ETag = %d with %d bytes", again.Code, again.Body.Len())
+	}
+	if stale := get("/users/alice/feed.atom", `"old"`); stale.Code != http.StatusOK || stale.Body.String() != first.Body.String() {
+		t.Errorf("poll with old ETag = %d", stale.Code)
+	}
+	if builds != 1 {
+		t.Errorf("feed was built %d times, want 1", builds)
+	}
+	if rss := get("/users/alice/feed.rss", etag); rss.Code != http.StatusOK || rss.Header().Get("Content-Type") != feeds.RSSContentType {
+		t.Errorf("RSS poll = %d, Content-Type %q", rss.Code, rss.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"chirpy/internal/broker"
+	"chirpy/internal/database"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type followUser struct {
+	Id          uuid.UUID `json:"id"`
+	Handle      string    `json:"handle"`
+	DisplayName string    `json:"display_name"`
+	AvatarUrl   string    `json:"avatar_url"`
+	FollowedAt  time.Time `json:"followed_at"`
+}
+
+type followPage struct {
+	Users      []followUser `json:"users"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+type chirpPage struct {
+	Chirps     []chirpResp `json:"chirps"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// relationTarget authenticates the caller and finds the other user named in the
+// path, writing the error response itself if either cannot be done.
+func (cfg *apiConfig) relationTarget(writer http.ResponseWriter, req *http.Request, msg string) (uuid.UUID, uuid.UUID, bool) {
+	self, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return uuid.UUID{}, uuid.UUID{}, false
+	}
+	other, err := parseID(req)
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, err.Error())
+		return uuid.UUID{}, uuid.UUID{}, false
+	}
+	if self == other {
+		handleError(writer, req, http.StatusBadRequest, "Users cannot "+msg+" themselves")
+		return uuid.UUID{}, uuid.UUID{}, false
+	}
+	if _, err = cfg.dbQueries.GetUserByID(req.Context(), other); err != nil {
+		handleError(writer, req, http.StatusNotFound, "User not found")
+		return uuid.UUID{}, uuid.UUID{}, false
+	}
+	return self, other, true
+}
+
+func (cfg *apiConfig) handleFollow(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	self, other, ok := cfg.relationTarget(writer, req, "follow")
+	if !ok {
+		return
+	}
+	if err := cfg.followUser(req.Context(), self, other); errors.Is(err, errBlocked) {
+		handleProblem(writer, req, http.StatusForbidden, codeBlocked, err.Error())
+		return
+	} else if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// followUser makes self follow other and notifies other the first time. It
+// returns errBlocked if there is a block between them.
+func (cfg *apiConfig) followUser(ctx context.Context, self, other uuid.UUID) error {
+	blocked, err := cfg.dbQueries.IsBlocked(ctx, database.IsBlockedParams{UserA: self, UserB: other})
+	if err != nil {
+		return err
+	} else if blocked {
+		return errBlocked
+	}
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	rows, err := qtx.FollowUser(ctx, database.FollowUserParams{UserID: self, FolloweeID: other})
+	if err != nil {
+		return err
+	}
+	var notification *database.Notification
+	if rows > 0 {
+		if notification, err = notify(ctx, qtx, notifyFollow, other, self, uuid.NullUUID{}); err != nil {
+			return err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	if notification != nil {
+		cfg.publish(ctx, broker.Event{Notification: notification})
+	}
+	return nil
+}
+
+func (cfg *apiConfig) handleUnfollow(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	self, other, ok := cfg.relationTarget(writer, req, "unfollow")
+	if !ok {
+		return
+	}
+	if err := cfg.unfollowUser(req.Context(), self, other); err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// unfollowUser stops self following other and takes back the notification it caused.
+func (cfg *apiConfig) unfollowUser(ctx context.Context, self, other uuid.UUID) error {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	rows, err := qtx.UnfollowUser(ctx, database.UnfollowUserParams{UserID: self, FolloweeID: other})
+	if err != nil {
+		return err
+	}
+	if rows > 0 {
+		err = qtx.DeleteNotification(ctx, database.DeleteNotificationParams{UserID: other, ActorID: self,
+			Type: notifyFollow})
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (cfg *apiConfig) handleFollowers(writer http.ResponseWriter, req *http.Request) {
+	cfg.handleFollowPage(writer, req, "followers", false)
+}
+
+func (cfg *apiConfig) handleFollowing(writer http.ResponseWriter, req *http.Request) {
+	cfg.handleFollowPage(writer, req, "following", true)
+}
+
+func (cfg *apiConfig) handleFollowPage(writer http.ResponseWriter, req *http.Request, msg string, following bool) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	id, err := parseID(req)
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, err.Error())
+		return
+	}
+	cur, limit, err := parsePage(req)
+	if err != nil {
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := cfg.followPage(req.Context(), id, following, cur, limit)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	handleJsonWrite(writer, http.StatusOK, msg, page)
+}
+
+// followPage loads a page of the people following user, or of those user
+// follows, most recent first.
+func (cfg *apiConfig) followPage(ctx context.Context, user uuid.UUID, following bool, cur pageCursor, limit int32) (followPage, error) {
+	var rows []database.GetFollowersRow
+	if following {
+		found, err := cfg.dbQueries.GetFollowing(ctx, database.GetFollowingParams{UserID: user, BeforeTime: cur.time,
+			BeforeID: cur.id, PageSize: limit})
+		if err != nil {
+			return followPage{}, err
+		}
+		for _, row := range found {
+			rows = append(rows, database.GetFollowersRow(row))
+		}
+	} else {
+		var err error
+		rows, err = cfg.dbQueries.GetFollowers(ctx, database.GetFollowersParams{UserID: user, BeforeTime: cur.time,
+			BeforeID: cur.id, PageSize: limit})
+		if err != nil {
+			return followPage{}, err
+		}
+	}
+	page := followPage{Users: make([]followUser, len(rows))}
+	for i, row := range rows {
+		page.Users[i] = followUser{Id: row.ID, Handle: row.Handle, DisplayName: row.DisplayName, AvatarUrl: row.AvatarUrl,
+			FollowedAt: row.FollowedAt}
+	}
+	if len(rows) > 0 {
+		last := rows[len(rows)-1]
+		page.NextCursor = nextCursor(len(rows), limit, last.FollowedAt, last.ID)
+	}
+	return page, nil
+}
+
+func (cfg *apiConfig) handleTimeline(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	id, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	cur, limit, err := parsePage(req)
+	if err != nil {
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := cfg.timelinePage(req.Context(), id, cur, limit)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	handleJsonWrite(writer, http.StatusOK, "timeline", page)
+}
+
+// timelinePage loads a page of the chirps by the people user follows, newest first.
+func (cfg *apiConfig) timelinePage(ctx context.Context, user uuid.UUID, cur pageCursor, limit int32) (chirpPage, error) {
+	// Blocking removes follows in both directions, so only mutes need filtering here.
+	chirps, err := cfg.dbQueries.GetTimeline(ctx, database.GetTimelineParams{UserID: user, BeforeTime: cur.time,
+		BeforeID: cur.id, PageSize: limit})
+	if err != nil {
+		return chirpPage{}, err
+	}
+	viewer := uuid.NullUUID{UUID: user, Valid: true}
+	page := chirpPage{}
+	if page.Chirps, err = cfg.chirpsConv(ctx, viewer, chirps); err == nil {
+		page.Chirps, err = cfg.filterChirps(ctx, viewer, page.Chirps)
+	}
+	if err != nil {
+		return chirpPage{}, err
+	}
+	if len(chirps) > 0 {
+		last := chirps[len(chirps)-1]
+		page.NextCursor = nextCursor(len(chirps), limit, last.CreatedAt, last.ID)
+	}
+	return page, nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"chirpy/internal/broker"
+	"chirpy/internal/database"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+// TestFollow checks that following someone notifies them the first time, and
+// that nobody can follow themselves, someone missing or someone they have a
+// block with.
+func TestFollow(t *testing.T) {
+	self, other := uuid.New(), uuid.New()
+	now := time.Now().UTC()
+	tests := []struct {
+		name    string
+		target  uuid.UUID
+		found   bool
+		blocked bool
+		rows    int64
+		want    int
+	}{
+		{"new follow", other, true, false, 1, http.StatusNoContent},
+		{"follow again", other, true, false, 0, http.StatusNoContent},
+		{"themselves", self, true, false, 0, http.StatusBadRequest},
+		{"missing user", other, false, false, 0, http.StatusNotFound},
+		{"blocked", other, true, true, 0, http.StatusForbidden},
+	}
+	for _, tc := range tests {
+		cfg, mock := mockConfig(t)
+		events, cancel := cfg.broker.Subscribe(4, broker.User(other))
+		if tc.target != self {
+			if tc.found {
+				mock.ExpectQuery("name: GetUserByID ").WithArgs(other).WillReturnRows(userRows(database.User{ID: other,
+					CreatedAt: now, UpdatedAt: now, Email: "bob@example.com", Handle: "bob"}))
+				mock.ExpectQuery("name: IsBlocked ").WithArgs(self, other).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tc.blocked))
+			} else {
+				mock.ExpectQuery("name: GetUserByID ").WithArgs(other).WillReturnError(sql.ErrNoRows)
+			}
+		}
+		if tc.want == http.StatusNoContent {
+			mock.ExpectBegin()
+			mock.ExpectExec("name: FollowUser ").WithArgs(self, other).WillReturnResult(sqlmock.NewResult(0, tc.rows))
+			if tc.rows > 0 {
+				expectNotification(mock, notifyFollow, other, self, uuid.NullUUID{})
+			}
+			mock.ExpectCommit()
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/users/"+tc.target.String()+"/follow", nil)
+		req.Header.Set("Authorization", authHeader(t, self))
+		recorder := httptest.NewRecorder()
+		cfg.routes().ServeHTTP(recorder, req)
+		if recorder.Code != tc.want {
+			t.Errorf("%s: POST follow = %d, want %d: %s", tc.name, recorder.Code, tc.want, recorder.Body)
+		}
+		if got := published(events); (len(got) > 0) != (tc.rows > 0) {
+			t.Errorf("%s: published %+v", tc.name, got)
+		}
+		cancel()
+	}
+}
+
+// TestUnfollow checks that unfollowing takes back the notification the follow
+// caused, and that unfollowing someone not followed changes nothing.
+func TestUnfollow(t *testing.T) {
+	self, other := uuid.New(), uuid.New()
+	now := time.Now().UTC()
+	for _, rows := range []int64{1, 0} {
+		cfg, mock := mockConfig(t)
+		mock.ExpectQuery("name: GetUserByID ").WithArgs(other).WillReturnRows(userRows(database.User{ID: other,
+			CreatedAt: now, UpdatedAt: now, Email: "bob@example.com", Handle: "bob"}))
+		mock.ExpectBegin()
+		mock.ExpectExec("name: UnfollowUser ").WithArgs(self, other).WillReturnResult(sqlmock.NewResult(0, rows))
+		if rows > 0 {
+			mock.ExpectExec("name: DeleteNotification ").WithArgs(other, self, notifyFollow, uuid.NullUUID{}).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		mock.ExpectCommit()
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/v2/users/"+other.String()+"/follow", nil)
+		req.Header.Set("Authorization", authHeader(t, self))
+		recorder := httptest.NewRecorder()
+		cfg.routes().ServeHTTP(recorder, req)
+		if recorder.Code != http.StatusNoContent {
+			t.Errorf("DELETE follow with %d follows = %d, want 204: %s", rows, recorder.Code, recorder.Body)
+		}
+	}
+}
+
+// TestFollowPages checks that both follow lists page through their users most
+// recent first, each next_cursor leading on from the last user of its page.
+func TestFollowPages(t *testing.T) {
+	user := uuid.New()
+	now := time.Now().UTC()
+	followColumns := []string{"id", "handle", "display_name", "avatar_url", "followed_at"}
+	first, second, third := uuid.New(), uuid.New(), uuid.New()
+	for _, list := range []struct{ path, query string }{
+		{"followers", "name: GetFollowers "},
+		{"following", "name: GetFollowing "},
+	} {
+		cfg, mock := mockConfig(t)
+		routes := cfg.routes()
+		mock.ExpectQuery(list.query).WithArgs(user, firstPage.time, firstPage.id, 2).WillReturnRows(
+			sqlmock.NewRows(followColumns).
+				AddRow(first, "alice", "Alice", "", now).
+				AddRow(second, "bob", "", "", now.Add(-time.Hour)))
+		mock.ExpectQuery(list.query).WithArgs(user, now.Add(-time.Hour), second, 2).WillReturnRows(
+			sqlmock.NewRows(followColumns).AddRow(third, "carol", "", "", now.Add(-2*time.Hour)))
+
+		path := "/api/v2/users/" + user.String() + "/" + list.path + "?limit=2"
+		var handles []string
+		for page := 0; page < 2; page++ {
+			recorder := httptest.NewRecorder()
+			routes.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+			var got followPage
+			if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil || recorder.Code != http.StatusOK {
+				t.Fatalf("GET %s = %d, %q: %v", path, recorder.Code, recorder.Body, err)
+			}
+			for _, user := range got.Users {
+				handles = append(handles, user.Handle)
+			}
+			if (got.NextCursor == "") != (page == 1) {
+				t.Errorf("GET %s next_cursor = %q on page %d of 2", path, got.NextCursor, page+1)
+			}
+			path = "/api/v2/users/" + user.String() + "/" + list.path + "?limit=2&cursor=" + got.NextCursor
+		}
+		if len(handles) != 3 || handles[0] != "alice" || handles[1] != "bob" || handles[2] != "carol" {
+			t.Errorf("%s = %v, want alice, bob and carol", list.path, handles)
+		}
+	}
+}
+
+// TestTimeline checks that the timeline pages through the chirps of the people
+// the caller follows, newest first, and is only for logged-in users.
+func TestTimeline(t *testing.T) {
+	cfg, mock := mockConfig(t)
+	routes := cfg.routes()
+	user, author := uuid.New(), uuid.New()
+	now := time.Now().UTC()
+	chirp := func(body string, age time.Duration) database.Chirp {
+		return database.Chirp{ID: uuid.New(), CreatedAt: now.Add(-age), UpdatedAt: now.Add(-age), Body: body,
+			UserID: author, Kind: chirpKindChirp}
+	}
+	newer, older := chirp("second", time.Minute), chirp("first", time.Hour)
+	noMutedWords := sqlmock.NewRows([]string{"id", "user_id", "phrase", "whole_word", "action", "expires_at",
+		"created_at"})
+	mock.ExpectQuery("name: GetTimeline ").WithArgs(user, firstPage.time, firstPage.id, 2).
+		WillReturnRows(chirpRows(newer, older))
+	expectChirpDetails(mock, database.GetUserHandlesRow{ID: author, Handle: "alice"})
+	mock.ExpectQuery("name: GetMutedWords ").WithArgs(user).WillReturnRows(noMutedWords)
+	mock.ExpectQuery("name: GetTimeline ").WithArgs(user, older.CreatedAt, older.ID, 2).WillReturnRows(chirpRows())
+	expectChirpDetails(mock)
+	mock.ExpectQuery("name: GetMutedWords ").WithArgs(user).WillReturnRows(noMutedWords)
+
+	recorder := httptest.NewRecorder()
+	routes.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v2/timeline", nil))
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("GET /api/v2/timeline without a token = %d, want 401", recorder.Code)
+	}
+	path := "/api/v2/timeline?limit=2"
+	for _, want := range [][]string{{"second", "first"}, {}} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", authHeader(t, user))
+		recorder := httptest.NewRecorder()
+		routes.ServeHTTP(recorder, req)
+		var got chirpPage
+		if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil || recorder.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d, %q: %v", path, recorder.Code, recorder.Body, err)
+		}
+		if len(got.Chirps) != len(want) {
+			t.Errorf("GET %s = %+v, want %v", path, got.Chirps, want)
+		}
+		for i := range min(len(got.Chirps), len(want)) {
+			if got.Chirps[i].Body != want[i] {
+				t.Errorf("GET %s = %+v, want %v", path, got.Chirps, want)
+			}
+		}
+		path = "/api/v2/timeline?limit=2&cursor=" + got.NextCursor
+	}
+}
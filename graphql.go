@@ -0,0 +1,710 @@
+package main
+
+import (
+	"chirpy/internal/database"
+	"chirpy/internal/dataloader"
+	"chirpy/internal/querylimit"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// graphqlLimits keeps one query from asking for more than a screenful of
+// chirps, each with its author and a page of replies.
+var graphqlLimits = querylimit.Limits{MaxDepth: 10, MaxComplexity: 5000, ListSize: defaultPageSize}
+
+type graphqlBody struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+type graphqlLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type graphqlError struct {
+	Message    string            `json:"message"`
+	Locations  []graphqlLocation `json:"locations,omitempty"`
+	Path       []any             `json:"path,omitempty"`
+	Extensions map[string]any    `json:"extensions,omitempty"`
+}
+
+type graphqlResponse struct {
+	Data   any            `json:"data,omitempty"`
+	Errors []graphqlError `json:"errors,omitempty"`
+}
+
+// graphqlProblem is an error a resolver means the client to read. Its code is
+// one of the problem codes the REST API uses, and is sent in the extensions.
+type graphqlProblem struct {
+	code string
+	msg  string
+}
+
+func (err graphqlProblem) Error() string {
+	return err.msg
+}
+
+func (err graphqlProblem) Extensions() map[string]any {
+	return map[string]any{"code": err.code}
+}
+
+func badRequest(err error) graphqlProblem {
+	return graphqlProblem{code: statusProblemCode(http.StatusBadRequest), msg: err.Error()}
+}
+
+var errChirpNotFound = graphqlProblem{code: statusProblemCode(http.StatusNotFound), msg: "Chirp not found"}
+
+// refProblem is what the client learns when resolveRef fails: that the chirp is
+// out of bounds to them or, as in the REST API, that it cannot be found.
+func refProblem(err error) graphqlProblem {
+	if errors.Is(err, errBlocked) {
+		return graphqlProblem{code: codeBlocked, msg: err.Error()}
+	}
+	return errChirpNotFound
+}
+
+// graphqlErrors converts the errors of a request for the response. Any error a
+// resolver returned that is not a graphqlProblem is a fault on our side, which
+// is logged and reported only as an internal error.
+func graphqlErrors(req *http.Request, errs []gqlerrors.FormattedError) []graphqlError {
+	out := make([]graphqlError, len(errs))
+	for i, err := range errs {
+		out[i] = graphqlError{Message: err.Message, Path: err.Path, Extensions: err.Extensions}
+		var located *gqlerrors.Error
+		var problem graphqlProblem
+		if errors.As(err.OriginalError(), &located) && located.OriginalError != nil &&
+			!errors.As(located.OriginalError, &problem) {
+			logFault(req, located.OriginalError)
+			out[i].Message = "internal error"
+			out[i].Extensions = map[string]any{"code": statusProblemCode(http.StatusInternalServerError)}
+		}
+		for _, loc := range err.Locations {
+			out[i].Locations = append(out[i].Locations, graphqlLocation{Line: loc.Line, Column: loc.Column})
+		}
+	}
+	return out
+}
+
+func graphqlFail(writer http.ResponseWriter, code int, msg string) {
+	handleJsonWrite(writer, code, "graphql", graphqlResponse{Errors: []graphqlError{{Message: msg}}})
+}
+
+// handleGraphQL runs a query over GET or a query or mutation over POST. The
+// caller is whoever the bearer token says, as for the REST API; a token that
+// does not check out is refused rather than ignored.
+func (cfg *apiConfig) handleGraphQL(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	body := graphqlBody{}
+	if req.Method == http.MethodGet {
+		query := req.URL.Query()
+		body.Query, body.OperationName = query.Get("query"), query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &body.Variables); err != nil {
+				graphqlFail(writer, http.StatusBadRequest, "invalid variables: "+err.Error())
+				return
+			}
+		}
+	} else if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		graphqlFail(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+	viewer := uuid.NullUUID{}
+	if req.Header.Get("Authorization") != "" {
+		id, err := cfg.validateUser(req.Header)
+		if err != nil {
+			graphqlFail(writer, http.StatusUnauthorized, err.Error())
+			return
+		}
+		viewer = uuid.NullUUID{UUID: id, Valid: true}
+	}
+	schema, err := graphqlSchema()
+	if err != nil {
+		logFault(req, err)
+		graphqlFail(writer, http.StatusInternalServerError, "internal error")
+		return
+	}
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(body.Query),
+		Name: "GraphQL request"})})
+	if err != nil {
+		handleJsonWrite(writer, http.StatusBadRequest, "graphql",
+			graphqlResponse{Errors: graphqlErrors(req, []gqlerrors.FormattedError{gqlerrors.FormatError(err)})})
+		return
+	}
+	if result := graphql.ValidateDocument(&schema, doc, nil); !result.IsValid {
+		handleJsonWrite(writer, http.StatusBadRequest, "graphql", graphqlResponse{Errors: graphqlErrors(req, result.Errors)})
+		return
+	}
+	op, err := querylimit.Operation(doc, body.OperationName)
+	if err != nil {
+		graphqlFail(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Method == http.MethodGet && op.Operation != ast.OperationTypeQuery {
+		writer.Header().Set("Allow", http.MethodPost)
+		graphqlFail(writer, http.StatusMethodNotAllowed, "Only queries may be sent with GET")
+		return
+	}
+	if _, err = graphqlLimits.Check(&schema, doc, body.OperationName, body.Variables); err != nil {
+		graphqlFail(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+	ctx := context.WithValue(req.Context(), graphqlKey{}, cfg.newGraphQLSession(viewer))
+	result := graphql.Execute(graphql.ExecuteParams{Schema: schema, AST: doc, OperationName: body.OperationName,
+		Args: body.Variables, Context: ctx})
+	resp := graphqlResponse{Data: result.Data, Errors: graphqlErrors(req, result.Errors)}
+	if resp.Data == nil {
+		// The query ran, so data is sent even when an error nulled all of it.
+		resp.Data = json.RawMessage("null")
+	}
+	handleJsonWrite(writer, http.StatusOK, "graphql", resp)
+}
+
+type graphqlKey struct{}
+
+// pageKey asks for one page of a listing that belongs to a user.
+type pageKey struct {
+	id     uuid.UUID
+	cursor pageCursor
+	limit  int32
+}
+
+// pageWindow is a pageKey without its user. The pages of every user asked for
+// with the same window are loaded in one query.
+type pageWindow struct {
+	cursor pageCursor
+	limit  int32
+}
+
+// userPage is a page of the users related to someone.
+type userPage struct {
+	ids        []uuid.UUID
+	nextCursor string
+}
+
+// graphqlSession holds what one GraphQL request knows: who is asking, and the
+// loaders that batch the database reads its resolvers make.
+type graphqlSession struct {
+	cfg       *apiConfig
+	viewer    uuid.NullUUID
+	users     *dataloader.Loader[uuid.UUID, userProfile]
+	replies   *dataloader.Loader[uuid.UUID, []chirpResp]
+	chirps    *dataloader.Loader[pageKey, chirpPage]
+	followers *dataloader.Loader[pageKey, userPage]
+	following *dataloader.Loader[pageKey, userPage]
+}
+
+func (cfg *apiConfig) newGraphQLSession(viewer uuid.NullUUID) *graphqlSession {
+	session := &graphqlSession{cfg: cfg, viewer: viewer}
+	session.users = dataloader.New(session.loadUsers)
+	session.replies = dataloader.New(session.loadReplies)
+	session.chirps = dataloader.New(session.loadChirpPages)
+	session.followers = dataloader.New(session.loadRelations(false))
+	session.following = dataloader.New(session.loadRelations(true))
+	return session
+}
+
+func sessionOf(ctx context.Context) *graphqlSession {
+	return ctx.Value(graphqlKey{}).(*graphqlSession)
+}
+
+var errLoginRequired = graphqlProblem{code: statusProblemCode(http.StatusUnauthorized), msg: "authentication required"}
+
+// user is the caller, who must have logged in.
+func (session *graphqlSession) user() (uuid.UUID, error) {
+	if !session.viewer.Valid {
+		return uuid.UUID{}, errLoginRequired
+	}
+	return session.viewer.UUID, nil
+}
+
+func (session *graphqlSession) loadUsers(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]userProfile, error) {
+	rows, err := session.cfg.dbQueries.GetUserProfiles(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	users := make(map[uuid.UUID]userProfile, len(rows))
+	for _, row := range rows {
+		users[row.ID] = profileConv(database.GetUserProfileRow(row))
+	}
+	return users, nil
+}
+
+// convert turns chirps into what the viewer sees of them, dropping the ones
+// their muted words hide.
+func (session *graphqlSession) convert(ctx context.Context, chirps []database.Chirp) ([]chirpResp, error) {
+	jsonChirps, err := session.cfg.chirpsConv(ctx, session.viewer, chirps)
+	if err != nil {
+		return nil, err
+	}
+	return session.cfg.filterChirps(ctx, session.viewer, jsonChirps)
+}
+
+func (session *graphqlSession) loadReplies(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]chirpResp, error) {
+	rows, err := session.cfg.dbQueries.GetRepliesTo(ctx, database.GetRepliesToParams{ChirpIds: ids,
+		ViewerID: session.viewer})
+	if err != nil {
+		return nil, err
+	}
+	parents := make(map[uuid.UUID]uuid.UUID, len(rows))
+	for _, row := range rows {
+		parents[row.ID] = row.RefChirpID.UUID
+	}
+	replies, err := session.convert(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+	byParent := make(map[uuid.UUID][]chirpResp, len(ids))
+	for _, id := range ids {
+		byParent[id] = []chirpResp{}
+	}
+	for _, reply := range replies {
+		parent := parents[reply.Id]
+		byParent[parent] = append(byParent[parent], reply)
+	}
+	return byParent, nil
+}
+
+func windows(keys []pageKey) map[pageWindow][]uuid.UUID {
+	out := map[pageWindow][]uuid.UUID{}
+	for _, key := range keys {
+		window := pageWindow{cursor: key.cursor, limit: key.limit}
+		out[window] = append(out[window], key.id)
+	}
+	return out
+}
+
+// loadChirpPages loads a page of each user's chirps, newest first.
+func (session *graphqlSession) loadChirpPages(ctx context.Context, keys []pageKey) (map[pageKey]chirpPage, error) {
+	pages := make(map[pageKey]chirpPage, len(keys))
+	for window, ids := range windows(keys) {
+		rows, err := session.cfg.dbQueries.GetChirpsByAuthors(ctx, database.GetChirpsByAuthorsParams{UserIds: ids,
+			BeforeTime: window.cursor.time, BeforeID: window.cursor.id, ViewerID: session.viewer,
+			PageSize: window.limit})
+		if err != nil {
+			return nil, err
+		}
+		chirps, err := session.convert(ctx, rows)
+		if err != nil {
+			return nil, err
+		}
+		counts := map[uuid.UUID]int{}
+		last := map[uuid.UUID]database.Chirp{}
+		for _, row := range rows {
+			counts[row.UserID]++
+			last[row.UserID] = row
+		}
+		for _, id := range ids {
+			page := chirpPage{Chirps: []chirpResp{}}
+			if counts[id] > 0 {
+				page.NextCursor = nextCursor(counts[id], window.limit, last[id].CreatedAt, last[id].ID)
+			}
+			pages[pageKey{id: id, cursor: window.cursor, limit: window.limit}] = page
+		}
+		for _, chirp := range chirps {
+			key := pageKey{id: chirp.UserId, cursor: window.cursor, limit: window.limit}
+			page := pages[key]
+			page.Chirps = append(page.Chirps, chirp)
+			pages[key] = page
+		}
+	}
+	return pages, nil
+}
+
+// loadRelations loads a page of the people each user follows, or of those who
+// follow them, most recent first.
+func (session *graphqlSession) loadRelations(following bool) dataloader.BatchFunc[pageKey, userPage] {
+	return func(ctx context.Context, keys []pageKey) (map[pageKey]userPage, error) {
+		pages := make(map[pageKey]userPage, len(keys))
+		for window, ids := range windows(keys) {
+			var rows []database.GetFollowersOfUsersRow
+			var err error
+			if following {
+				var found []database.GetFollowingOfUsersRow
+				found, err = session.cfg.dbQueries.GetFollowingOfUsers(ctx, database.GetFollowingOfUsersParams{
+					UserIds: ids, BeforeTime: window.cursor.time, BeforeID: window.cursor.id, PageSize: window.limit})
+				for _, row := range found {
+					rows = append(rows, database.GetFollowersOfUsersRow(row))
+				}
+			} else {
+				rows, err = session.cfg.dbQueries.GetFollowersOfUsers(ctx, database.GetFollowersOfUsersParams{
+					UserIds: ids, BeforeTime: window.cursor.time, BeforeID: window.cursor.id, PageSize: window.limit})
+			}
+			if err != nil {
+				return nil, err
+			}
+			for _, id := range ids {
+				pages[pageKey{id: id, cursor: window.cursor, limit: window.limit}] = userPage{ids: []uuid.UUID{}}
+			}
+			for _, row := range rows {
+				key := pageKey{id: row.SubjectID, cursor: window.cursor, limit: window.limit}
+				page := pages[key]
+				page.ids = append(page.ids, row.ID)
+				page.nextCursor = nextCursor(len(page.ids), window.limit, row.FollowedAt, row.ID)
+				pages[key] = page
+			}
+		}
+		return pages, nil
+	}
+}
+
+// thunk defers a loader's result until the executor needs it, by which time
+// every sibling field has queued its key. A missing value resolves to null.
+func thunk[V any](load func() (V, error)) func() (any, error) {
+	return func() (any, error) {
+		value, err := load()
+		if errors.Is(err, dataloader.ErrNotFound) {
+			return nil, nil
+		}
+		return value, err
+	}
+}
+
+func idArg(p graphql.ResolveParams, name string) (uuid.UUID, error) {
+	raw, _ := p.Args[name].(string)
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.UUID{}, badRequest(fmt.Errorf("invalid %s", name))
+	}
+	return id, nil
+}
+
+func pageArgs(p graphql.ResolveParams) (pageCursor, int32, error) {
+	cursor, _ := p.Args["cursor"].(string)
+	limit, _ := p.Args["limit"].(int)
+	cur, size, err := pageOf(cursor, limit)
+	if err != nil {
+		return pageCursor{}, 0, badRequest(err)
+	}
+	return cur, size, nil
+}
+
+var pageArguments = graphql.FieldConfigArgument{
+	"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+	"cursor": &graphql.ArgumentConfig{Type: graphql.String, Description: "nextCursor from the previous page."},
+}
+
+// pageOfUser resolves a listing field of User through loader.
+func pageOfUser[V any](loader func(*graphqlSession) *dataloader.Loader[pageKey, V]) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		cur, limit, err := pageArgs(p)
+		if err != nil {
+			return nil, err
+		}
+		session := sessionOf(p.Context)
+		user := p.Source.(userProfile)
+		return thunk(loader(session).Load(p.Context, pageKey{id: user.Id, cursor: cur, limit: limit})), nil
+	}
+}
+
+func chirpField(get func(chirpResp) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		return get(p.Source.(chirpResp)), nil
+	}
+}
+
+func refChirp(chirp chirpResp) *chirpResp {
+	ref, _ := chirp.RefChirp.(*chirpResp)
+	return ref
+}
+
+func (session *graphqlSession) loadChirp(ctx context.Context, id uuid.UUID) (any, error) {
+	chirp, err := session.cfg.loadChirp(ctx, session.viewer, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return chirp, err
+}
+
+// graphqlSchema is built once; resolvers find the request's session in their context.
+var graphqlSchema = sync.OnceValues(newGraphQLSchema)
+
+func newGraphQLSchema() (graphql.Schema, error) {
+	var user, chirp *graphql.Object
+	entity := graphql.NewObject(graphql.ObjectConfig{Name: "Entity",
+		Description: "A span of a chirp's body that refers to something, such as an @mention.",
+		Fields: graphql.Fields{
+			"type":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"start":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "Offset of the span in code points."},
+			"end":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "Offset just past it, in code points."},
+			"handle": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"userId": &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(chirpEntity).UserId.String(), nil
+			}},
+		}})
+	chirpPageType := graphql.NewObject(graphql.ObjectConfig{Name: "ChirpPage", Fields: graphql.FieldsThunk(func() graphql.Fields {
+		return graphql.Fields{
+			"chirps": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(chirp))),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(chirpPage).Chirps, nil
+				}},
+			"nextCursor": &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) {
+				if cursor := p.Source.(chirpPage).NextCursor; cursor != "" {
+					return cursor, nil
+				}
+				return nil, nil
+			}},
+		}
+	})})
+	userPageType := graphql.NewObject(graphql.ObjectConfig{Name: "UserPage", Fields: graphql.FieldsThunk(func() graphql.Fields {
+		return graphql.Fields{
+			"users": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(user))),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					session := sessionOf(p.Context)
+					ids := p.Source.(userPage).ids
+					users := make([]any, len(ids))
+					for i, id := range ids {
+						users[i] = thunk(session.users.Load(p.Context, id))
+					}
+					return users, nil
+				}},
+			"nextCursor": &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) {
+				if cursor := p.Source.(userPage).nextCursor; cursor != "" {
+					return cursor, nil
+				}
+				return nil, nil
+			}},
+		}
+	})})
+	user = graphql.NewObject(graphql.ObjectConfig{Name: "User", Fields: graphql.Fields{
+		"id": &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(userProfile).Id.String(), nil
+		}},
+		"handle":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"displayName": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"bio":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"avatarUrl":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"joinedAt":    &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		"chirpCount":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"chirps": &graphql.Field{Type: graphql.NewNonNull(chirpPageType), Args: pageArguments,
+			Description: "Their chirps, newest first.",
+			Resolve: pageOfUser(func(session *graphqlSession) *dataloader.Loader[pageKey, chirpPage] {
+				return session.chirps
+			})},
+		"followers": &graphql.Field{Type: graphql.NewNonNull(userPageType), Args: pageArguments,
+			Description: "The people following them, most recent first.",
+			Resolve: pageOfUser(func(session *graphqlSession) *dataloader.Loader[pageKey, userPage] {
+				return session.followers
+			})},
+		"following": &graphql.Field{Type: graphql.NewNonNull(userPageType), Args: pageArguments,
+			Description: "The people they follow, most recent first.",
+			Resolve: pageOfUser(func(session *graphqlSession) *dataloader.Loader[pageKey, userPage] {
+				return session.following
+			})},
+	}})
+	chirp = graphql.NewObject(graphql.ObjectConfig{Name: "Chirp", Fields: graphql.FieldsThunk(func() graphql.Fields {
+		return graphql.Fields{
+			"id": &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: chirpField(func(c chirpResp) any {
+				return c.Id.String()
+			})},
+			"body": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: chirpField(func(c chirpResp) any {
+				return c.Body
+			})},
+			"kind": &graphql.Field{Type: graphql.NewNonNull(graphql.String),
+				Description: "chirp, rechirp, quote or reply."},
+			"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime),
+				Resolve: chirpField(func(c chirpResp) any { return c.CreatedAt })},
+			"updatedAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime),
+				Resolve: chirpField(func(c chirpResp) any { return c.UpdatedAt })},
+			"likes": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"filtered": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean),
+				Description: "Whether one of the caller's muted words matches it."},
+			"entities": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(entity)))},
+			"author": &graphql.Field{Type: graphql.NewNonNull(user), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return thunk(sessionOf(p.Context).users.Load(p.Context, p.Source.(chirpResp).UserId)), nil
+			}},
+			"refChirp": &graphql.Field{Type: chirp,
+				Description: "What it rechirps, quotes or replies to; null if there is nothing or it is gone.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					if ref := refChirp(p.Source.(chirpResp)); ref != nil {
+						return *ref, nil
+					}
+					return nil, nil
+				}},
+			"replies": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(chirp))),
+				Description: "Its direct replies, oldest first.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return thunk(sessionOf(p.Context).replies.Load(p.Context, p.Source.(chirpResp).Id)), nil
+				}},
+		}
+	})})
+	idArgs := graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}}
+	query := graphql.NewObject(graphql.ObjectConfig{Name: "Query", Fields: graphql.Fields{
+		"viewer": &graphql.Field{Type: user, Description: "The caller, or null if they have not logged in.",
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				session := sessionOf(p.Context)
+				if !session.viewer.Valid {
+					return nil, nil
+				}
+				return thunk(session.users.Load(p.Context, session.viewer.UUID)), nil
+			}},
+		"user": &graphql.Field{Type: user, Description: "A user by ID or by handle.",
+			Args: graphql.FieldConfigArgument{
+				"id":     &graphql.ArgumentConfig{Type: graphql.ID},
+				"handle": &graphql.ArgumentConfig{Type: graphql.String},
+			},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				session := sessionOf(p.Context)
+				name, _ := p.Args["id"].(string)
+				if handle, ok := p.Args["handle"].(string); ok {
+					name = "@" + handle
+				}
+				id, err := session.cfg.profileUser(p.Context, name)
+				if errors.Is(err, sql.ErrNoRows) {
+					return nil, nil
+				} else if err != nil {
+					return nil, err
+				}
+				return thunk(session.users.Load(p.Context, id)), nil
+			}},
+		"chirp": &graphql.Field{Type: chirp, Args: idArgs, Resolve: func(p graphql.ResolveParams) (any, error) {
+			id, err := idArg(p, "id")
+			if err != nil {
+				return nil, err
+			}
+			return sessionOf(p.Context).loadChirp(p.Context, id)
+		}},
+		"timeline": &graphql.Field{Type: graphql.NewNonNull(chirpPageType), Args: pageArguments,
+			Description: "Chirps by the people the caller follows, newest first.",
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				session := sessionOf(p.Context)
+				self, err := session.user()
+				if err != nil {
+					return nil, err
+				}
+				cur, limit, err := pageArgs(p)
+				if err != nil {
+					return nil, err
+				}
+				return session.cfg.timelinePage(p.Context, self, cur, limit)
+			}},
+	}})
+	mutation := graphql.NewObject(graphql.ObjectConfig{Name: "Mutation", Fields: graphql.Fields{
+		"postChirp": &graphql.Field{Type: graphql.NewNonNull(chirp), Description: "Post a chirp, or a reply to one.",
+			Args: graphql.FieldConfigArgument{
+				"body":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"replyTo": &graphql.ArgumentConfig{Type: graphql.ID},
+			},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return sessionOf(p.Context).postChirp(p)
+			}},
+		"deleteChirp": &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Args: idArgs,
+			Description: "Delete one of the caller's chirps, returning its ID.",
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return sessionOf(p.Context).deleteChirp(p)
+			}},
+		"likeChirp": &graphql.Field{Type: graphql.NewNonNull(chirp), Args: idArgs,
+			Description: "Like a chirp. Liking a rechirp likes the chirp it amplifies.",
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return sessionOf(p.Context).like(p, true)
+			}},
+		"unlikeChirp": &graphql.Field{Type: graphql.NewNonNull(chirp), Args: idArgs,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return sessionOf(p.Context).like(p, false)
+			}},
+	}})
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+func (session *graphqlSession) postChirp(p graphql.ResolveParams) (any, error) {
+	self, err := session.user()
+	if err != nil {
+		return nil, err
+	}
+	body := p.Args["body"].(string)
+	if tooLong, err := session.cfg.chirpTooLong(p.Context, self, body); err != nil {
+		return nil, err
+	} else if tooLong {
+		return nil, graphqlProblem{code: codeChirpTooLong, msg: "Chirp is too long"}
+	}
+	params := database.CreateRefChirpParams{Body: clean(body), UserID: self, Kind: chirpKindChirp}
+	if _, ok := p.Args["replyTo"]; ok {
+		parent, err := idArg(p, "replyTo")
+		if err != nil {
+			return nil, err
+		}
+		if parent, err = session.cfg.resolveRef(p.Context, self, parent); err != nil {
+			return nil, refProblem(err)
+		}
+		params.Kind, params.RefChirpID = chirpKindReply, uuid.NullUUID{UUID: parent, Valid: true}
+	}
+	stored, err := session.cfg.storeChirp(p.Context, params)
+	if err != nil {
+		return nil, err
+	}
+	chirps, err := session.cfg.chirpsConv(p.Context, session.viewer, []database.Chirp{stored})
+	if err != nil {
+		return nil, err
+	}
+	return chirps[0], nil
+}
+
+func (session *graphqlSession) deleteChirp(p graphql.ResolveParams) (any, error) {
+	self, err := session.user()
+	if err != nil {
+		return nil, err
+	}
+	id, err := idArg(p, "id")
+	if err != nil {
+		return nil, err
+	}
+	chirp, err := session.cfg.dbQueries.GetChirp(p.Context, id)
+	if err != nil {
+		return nil, errChirpNotFound
+	}
+	if err = session.cfg.deleteChirp(p.Context, chirp, self); errors.Is(err, errNotAuthor) {
+		return nil, graphqlProblem{code: codeNotAuthor, msg: err.Error()}
+	} else if err != nil {
+		return nil, err
+	}
+	return id.String(), nil
+}
+
+// like likes or unlikes a chirp and returns it with its new like count.
+func (session *graphqlSession) like(p graphql.ResolveParams, like bool) (any, error) {
+	self, err := session.user()
+	if err != nil {
+		return nil, err
+	}
+	id, err := idArg(p, "id")
+	if err != nil {
+		return nil, err
+	}
+	var chirp database.Chirp
+	if like {
+		var target uuid.UUID
+		if target, err = session.cfg.resolveRef(p.Context, self, id); err != nil {
+			return nil, refProblem(err)
+		}
+		chirp, err = session.cfg.dbQueries.GetChirp(p.Context, target)
+	} else {
+		chirp, err = session.cfg.unlikeTarget(p.Context, id)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errChirpNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if like {
+		err = session.cfg.likeChirp(p.Context, chirp, self)
+	} else {
+		err = session.cfg.unlikeChirp(p.Context, chirp, self)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return session.cfg.loadChirp(p.Context, session.viewer, chirp.ID)
+}
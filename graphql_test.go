@@ -0,0 +1,176 @@
+package main
+
+import (
+	"chirpy/internal/database"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+func TestGraphQLRequests(t *testing.T) {
+	routes := (&apiConfig{publicURL: "https://chirpy.test", sekrit: "secret"}).routes()
+	deep := "{ chirp(id: \"x\") { " + strings.Repeat("refChirp { ", 10) + "body" + strings.Repeat(" }", 11) + " }"
+	wide := "{ viewer { followers { users { followers { users { followers { users { handle } } } } } } } }"
+	tests := []struct {
+		name   string
+		method string
+		query  string
+		auth   string
+		code   int
+		data   string
+		error  string
+	}{
+		{"anonymous viewer", http.MethodGet, "{ viewer { handle } }", "", http.StatusOK, `{"viewer":null}`, ""},
+		{"introspection", http.MethodPost, "{ __schema { mutationType { name } } }", "", http.StatusOK,
+			`{"__schema":{"mutationType":{"name":"Mutation"}}}`, ""},
+		{"bad token", http.MethodPost, "{ viewer { handle } }", "Bearer nope", http.StatusUnauthorized, "", ""},
+		{"syntax", http.MethodPost, "{ viewer {", "", http.StatusBadRequest, "", ""},
+		{"unknown field", http.MethodPost, "{ viewer { password } }", "", http.StatusBadRequest, "", "password"},
+		{"too deep", http.MethodPost, deep, "", http.StatusBadRequest, "", "nested"},
+		{"too complex", http.MethodPost, wide, "", http.StatusBadRequest, "", "complexity"},
+		{"mutation over GET", http.MethodGet, "mutation { deleteChirp(id: \"x\") }", "", http.StatusMethodNotAllowed,
+			"", ""},
+		{"mutation needs login", http.MethodPost, "mutation { deleteChirp(id: \"x\") }", "", http.StatusOK, "null",
+			errLoginRequired.Error()},
+	}
+	for _, tc := range tests {
+		var req *http.Request
+		if tc.method == http.MethodGet {
+			req = httptest.NewRequest(tc.method, "/graphql?query="+url.QueryEscape(tc.query), nil)
+		} else {
+			body, _ := json.Marshal(graphqlBody{Query: tc.query})
+			req = httptest.NewRequest(tc.method, "/graphql", strings.NewReader(string(body)))
+		}
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		recorder := httptest.NewRecorder()
+		routes.ServeHTTP(recorder, req)
+		if recorder.Code != tc.code {
+			t.Errorf("%s: status %d, want %d: %s", tc.name, recorder.Code, tc.code, recorder.Body)
+			continue
+		}
+		var resp struct {
+			Data   json.RawMessage `json:"data"`
+			Errors []graphqlError  `json:"errors"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+			t.Errorf("%s: %v: %s", tc.name, err, recorder.Body)
+			continue
+		}
+		if tc.data != "" && string(resp.Data) != tc.data {
+			t.Errorf("%s: data %s, want %s", tc.name, resp.Data, tc.data)
+		}
+		if tc.code != http.StatusOK && len(resp.Errors) == 0 {
+			t.Errorf("%s: no errors in %s", tc.name, recorder.Body)
+		}
+		if tc.error != "" && (len(resp.Errors) == 0 || !strings.Contains(resp.Errors[0].Message, tc.error)) {
+			t.Errorf("%s: errors %+v, want one mentioning %q", tc.name, resp.Errors, tc.error)
+		}
+	}
+}
+
+func TestGraphQLLimitsAllowTimeline(t *testing.T) {
+	schema, err := graphqlSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// What the home screen asks for: a page of chirps, who wrote them, and
+	// each one's replies with their authors.
+	doc, err := parser.Parse(parser.ParseParams{Source: `{ timeline { nextCursor chirps { body likes
+		author { handle avatarUrl } refChirp { body author { handle } }
+		replies { body author { handle } } } } }`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = graphqlLimits.Check(&schema, doc, "", nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestGraphQLChirpLength checks that postChirp counts characters, not bytes,
+// against the caller's limit.
+func TestGraphQLChirpLength(t *testing.T) {
+	user := uuid.New()
+	now := time.Now().UTC()
+	tests := []struct {
+		name  string
+		body  string
+		error string
+	}{
+		{"at the limit", strings.Repeat("é", lengthLimit), ""},
+		{"over the limit", strings.Repeat("é", lengthLimit+1), "Chirp is too long"},
+	}
+	for _, tc := range tests {
+		cfg, mock := mockConfig(t)
+		mock.ExpectQuery("name: GetUserByID ").WithArgs(user).WillReturnRows(userRows(database.User{ID: user,
+			CreatedAt: now, UpdatedAt: now, Email: "alice@example.com", Handle: "alice"}))
+		if tc.error == "" {
+			chirp := database.Chirp{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Body: tc.body, UserID: user,
+				Kind: chirpKindChirp}
+			mock.ExpectBegin()
+			mock.ExpectQuery("name: CreateRefChirp ").WithArgs(tc.body, user, chirpKindChirp, uuid.NullUUID{}).
+				WillReturnRows(chirpRows(chirp))
+			mock.ExpectExec("name: EnqueueWebhookDeliveries ").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("name: EnqueueFollowerDeliveries ").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectCommit()
+			expectChirpDetails(mock, database.GetUserHandlesRow{ID: user, Handle: "alice"})
+		}
+		body, _ := json.Marshal(graphqlBody{Query: "mutation($body: String!) { postChirp(body: $body) { body } }",
+			Variables: map[string]any{"body": tc.body}})
+		req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+		req.Header.Set("Authorization", authHeader(t, user))
+		recorder := httptest.NewRecorder()
+		cfg.routes().ServeHTTP(recorder, req)
+		var resp graphqlResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil || recorder.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", tc.name, recorder.Code, recorder.Body)
+		}
+		if tc.error == "" && len(resp.Errors) > 0 {
+			t.Errorf("%s: errors %+v", tc.name, resp.Errors)
+		} else if tc.error != "" && (len(resp.Errors) == 0 || resp.Errors[0].Message != tc.error) {
+			t.Errorf("%s: errors %+v, want %q", tc.name, resp.Errors, tc.error)
+		}
+	}
+}
+
+// TestGraphQLErrorCodes checks that errors meant for the client keep their
+// message and get a code, while anything else is reported as an internal error.
+func TestGraphQLErrorCodes(t *testing.T) {
+	cfg, mock := mockConfig(t)
+	routes := cfg.routes()
+	chirp := uuid.New()
+	mock.ExpectQuery("name: GetChirp ").WithArgs(chirp).WillReturnError(errors.New("pq: connection reset"))
+	tests := []struct {
+		name    string
+		query   string
+		message string
+		code    string
+	}{
+		{"login required", `mutation { deleteChirp(id: "x") }`, errLoginRequired.Error(), "unauthorized"},
+		{"invalid id", `{ chirp(id: "x") { body } }`, "invalid id", "bad_request"},
+		{"fault", `{ chirp(id: "` + chirp.String() + `") { body } }`, "internal error", "internal_server_error"},
+	}
+	for _, tc := range tests {
+		body, _ := json.Marshal(graphqlBody{Query: tc.query})
+		recorder := httptest.NewRecorder()
+		routes.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+		var resp graphqlResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil || len(resp.Errors) != 1 {
+			t.Errorf("%s: %s", tc.name, recorder.Body)
+			continue
+		}
+		if got := resp.Errors[0]; got.Message != tc.message || got.Extensions["code"] != tc.code {
+			t.Errorf("%s: error %+v, want %q with code %q", tc.name, got, tc.message, tc.code)
+		}
+	}
+}
@@ -0,0 +1,748 @@
+package main
+
+//go:generate protoc -I proto --go_out=. --go_opt=module=chirpy --go-grpc_out=. --go-grpc_opt=module=chirpy chirpy/v1/chirpy.proto
+
+import (
+	"chirpy/chirpypb"
+	"chirpy/internal/auth"
+	"chirpy/internal/broker"
+	"chirpy/internal/database"
+	"chirpy/internal/entities"
+	"chirpy/internal/validate"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const grpcPortEnv = "GRPC_PORT"
+const defaultGRPCPort = "9090"
+
+// refreshTokenMethods take a refresh token in place of the access token, and
+// check it themselves.
+var refreshTokenMethods = map[string]bool{
+	chirpypb.AuthService_Refresh_FullMethodName: true,
+	chirpypb.AuthService_Revoke_FullMethodName:  true,
+}
+
+// newGRPCServer serves the gRPC API, which mirrors the REST API under /api/v2.
+func (cfg *apiConfig) newGRPCServer() *grpc.Server {
+	server := grpc.NewServer(grpc.UnaryInterceptor(cfg.grpcUnaryAuth), grpc.StreamInterceptor(cfg.grpcStreamAuth))
+	chirpypb.RegisterAuthServiceServer(server, &authService{cfg: cfg})
+	chirpypb.RegisterUserServiceServer(server, &userService{cfg: cfg})
+	chirpypb.RegisterChirpServiceServer(server, &chirpService{cfg: cfg})
+	return server
+}
+
+// serveGRPC serves the gRPC API on port until it fails.
+func (cfg *apiConfig) serveGRPC(port string) {
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(cfg.newGRPCServer().Serve(listener))
+}
+
+type grpcUserKey struct{}
+
+// grpcHeader holds the call's authorization metadata as the header the REST
+// API would have received it in.
+func grpcHeader(ctx context.Context) http.Header {
+	head := http.Header{}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		head.Add("Authorization", value)
+	}
+	return head
+}
+
+// grpcAuthenticate puts the caller named by the call's bearer token in ctx. A
+// call without one is anonymous; a token that does not check out is refused
+// rather than ignored.
+func (cfg *apiConfig) grpcAuthenticate(ctx context.Context, method string) (context.Context, error) {
+	head := grpcHeader(ctx)
+	if refreshTokenMethods[method] || head.Get("Authorization") == "" {
+		return ctx, nil
+	}
+	token, err := auth.GetBearerToken(head)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	id, err := auth.ValidateJWT(token, cfg.sekrit)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return context.WithValue(ctx, grpcUserKey{}, id), nil
+}
+
+func (cfg *apiConfig) grpcUnaryAuth(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := cfg.grpcAuthenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// authedStream is a server stream whose context carries the caller.
+type authedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream authedStream) Context() context.Context {
+	return stream.ctx
+}
+
+func (cfg *apiConfig) grpcStreamAuth(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := cfg.grpcAuthenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, authedStream{ServerStream: stream, ctx: ctx})
+}
+
+// grpcViewer is the caller, if they sent a token.
+func grpcViewer(ctx context.Context) uuid.NullUUID {
+	id, ok := ctx.Value(grpcUserKey{}).(uuid.UUID)
+	return uuid.NullUUID{UUID: id, Valid: ok}
+}
+
+// grpcUser is the caller, who must have sent a token.
+func grpcUser(ctx context.Context) (uuid.UUID, error) {
+	viewer := grpcViewer(ctx)
+	if !viewer.Valid {
+		return uuid.UUID{}, status.Error(codes.Unauthenticated, errLoginRequired.Error())
+	}
+	return viewer.UUID, nil
+}
+
+func grpcID(value, name string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.UUID{}, status.Error(codes.InvalidArgument, "invalid "+name)
+	}
+	return id, nil
+}
+
+// grpcPage checks a listing's cursor and page size. A page size of zero is the default.
+func grpcPage(cursor string, size int32) (pageCursor, int32, error) {
+	limit := int(size)
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	cur, pageSize, err := pageOf(cursor, limit)
+	if err != nil {
+		return pageCursor{}, 0, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return cur, pageSize, nil
+}
+
+// grpcValidate checks a request against the validate tags of the REST body it
+// mirrors, listing every broken rule in the status's BadRequest details.
+func grpcValidate(body any) error {
+	errs := validate.Struct(body)
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(errs))
+	details := &errdetails.BadRequest{}
+	for i, err := range errs {
+		msgs[i] = err.Message
+		details.FieldViolations = append(details.FieldViolations,
+			&errdetails.BadRequest_FieldViolation{Field: err.Field, Description: err.Message, Reason: err.Rule})
+	}
+	st, detailErr := status.New(codes.InvalidArgument, strings.Join(msgs, "; ")).WithDetails(details)
+	if detailErr != nil {
+		return status.Error(codes.InvalidArgument, strings.Join(msgs, "; "))
+	}
+	return st.Err()
+}
+
+// grpcInternal logs a fault on our side and tells the client only that something went wrong.
+func grpcInternal(err error) error {
+	fmt.Printf("grpc: %v\n", err)
+	return status.Error(codes.Internal, "Something went wrong on our side")
+}
+
+func userProto(user addedUser) *chirpypb.User {
+	return &chirpypb.User{Id: user.Id.String(), CreatedAt: timestamppb.New(user.CreatedAt),
+		UpdatedAt: timestamppb.New(user.UpdatedAt), Email: user.Email, Handle: user.Handle, IsChirpyRed: user.IsChirpyRed}
+}
+
+func profileProto(profile userProfile) *chirpypb.Profile {
+	return &chirpypb.Profile{Id: profile.Id.String(), Handle: profile.Handle, DisplayName: profile.DisplayName,
+		Bio: profile.Bio, AvatarUrl: profile.AvatarUrl, JoinedAt: timestamppb.New(profile.JoinedAt),
+		ChirpCount: profile.ChirpCount}
+}
+
+func chirpProto(chirp chirpResp) *chirpypb.Chirp {
+	out := &chirpypb.Chirp{Id: chirp.Id.String(), CreatedAt: timestamppb.New(chirp.CreatedAt),
+		UpdatedAt: timestamppb.New(chirp.UpdatedAt), Body: chirp.Body, UserId: chirp.UserId.String(),
+		AuthorHandle: chirp.AuthorHandle, Kind: chirp.Kind, Likes: chirp.Likes, Filtered: chirp.Filtered,
+		Entities: make([]*chirpypb.Entity, len(chirp.Entities))}
+	for i, entity := range chirp.Entities {
+		out.Entities[i] = &chirpypb.Entity{Type: entity.Type, Start: int32(entity.Start), End: int32(entity.End),
+			UserId: entity.UserId.String(), Handle: entity.Handle}
+	}
+	switch ref := chirp.RefChirp.(type) {
+	case *chirpResp:
+		out.Ref = &chirpypb.Chirp_RefChirp{RefChirp: chirpProto(*ref)}
+	case chirpTombstone:
+		out.Ref = &chirpypb.Chirp_TombstoneId{TombstoneId: ref.Id.String()}
+	}
+	return out
+}
+
+func chirpsProto(chirps []chirpResp) []*chirpypb.Chirp {
+	out := make([]*chirpypb.Chirp, len(chirps))
+	for i, chirp := range chirps {
+		out[i] = chirpProto(chirp)
+	}
+	return out
+}
+
+type authService struct {
+	chirpypb.UnimplementedAuthServiceServer
+	cfg *apiConfig
+}
+
+func (svc *authService) Login(ctx context.Context, req *chirpypb.LoginRequest) (*chirpypb.LoginResponse, error) {
+	user, err := svc.cfg.dbQueries.GetUserByEmail(ctx, req.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.Unauthenticated, "Incorrect email or password")
+	} else if err != nil {
+		return nil, grpcInternal(err)
+	}
+	if err = auth.CheckPasswordHash(req.Password, user.HashedPassword); err != nil {
+		return nil, status.Error(codes.Unauthenticated, "Incorrect email or password")
+	}
+	token, err := auth.MakeJWT(user.ID, svc.cfg.sekrit, time.Hour)
+	if err != nil {
+		return nil, grpcInternal(err)
+	}
+	refresh, err := svc.cfg.dbQueries.AddRefreshToken(ctx, database.AddRefreshTokenParams{Token: auth.MakeRefreshToken(),
+		Email: req.Email})
+	if err != nil {
+		return nil, grpcInternal(err)
+	}
+	logged := loginConv(user)
+	return &chirpypb.LoginResponse{User: userProto(addedUser{createHeader: logged.createHeader, Email: logged.Email,
+		Handle: logged.Handle, IsChirpyRed: logged.IsChirpyRed}), Token: token, RefreshToken: refresh}, nil
+}
+
+func (svc *authService) Refresh(ctx context.Context, req *chirpypb.RefreshRequest) (*chirpypb.RefreshResponse, error) {
+	token, err := auth.GetBearerToken(grpcHeader(ctx))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	found, err := svc.cfg.dbQueries.GetUserByToken(ctx, token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "Unknown refresh token")
+	}
+	if found.RevokedAt.Valid || found.ExpiresAt.Compare(time.Now()) < 0 {
+		return nil, status.Error(codes.Unauthenticated, "Refresh token has expired or been revoked")
+	}
+	access, err := auth.MakeJWT(found.UserID, svc.cfg.sekrit, time.Hour)
+	if err != nil {
+		return nil, grpcInternal(err)
+	}
+	return &chirpypb.RefreshResponse{Token: access}, nil
+}
+
+func (svc *authService) Revoke(ctx context.Context, req *chirpypb.RevokeRequest) (*emptypb.Empty, error) {
+	token, err := auth.GetBearerToken(grpcHeader(ctx))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err = svc.cfg.dbQueries.RevokeToken(ctx, token); err != nil {
+		return nil, grpcInternal(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+type userService struct {
+	chirpypb.UnimplementedUserServiceServer
+	cfg *apiConfig
+}
+
+func (svc *userService) CreateUser(ctx context.Context, req *chirpypb.CreateUserRequest) (*chirpypb.User, error) {
+	if err := grpcValidate(addUser{Email: req.Email, Password: req.Password, Handle: req.Handle}); err != nil {
+		return nil, err
+	}
+	if err := entities.ValidateHandle(req.Handle); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	hashed, err := auth.HashPassword(req.Password)
+	if err != nil {
+		return nil, grpcInternal(err)
+	}
+	user, err := svc.cfg.createUser(ctx, database.CreateUserParams{Email: req.Email, HashedPassword: hashed,
+		Handle: req.Handle})
+	if isUniqueViolation(err, handleIndex) {
+		return nil, status.Error(codes.AlreadyExists, "Handle is already taken")
+	} else if err != nil {
+		return nil, grpcInternal(err)
+	}
+	return userProto(createUserConv(user)), nil
+}
+
+func (svc *userService) UpdateUser(ctx context.Context, req *chirpypb.UpdateUserRequest) (*chirpypb.User, error) {
+	if err := grpcValidate(updateUser{Email: req.Email, Password: req.Password, Handle: req.Handle}); err != nil {
+		return nil, err
+	}
+	if req.Handle != "" {
+		if err := entities.ValidateHandle(req.Handle); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	id, err := grpcUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hashed, err := auth.HashPassword(req.Password)
+	if err != nil {
+		return nil, grpcInternal(err)
+	}
+	user, err := svc.cfg.updateUser(ctx, database.UpdateUserParams{Email: req.Email, HashedPassword: hashed, ID: id},
+		req.Handle)
+	var cooldown handleCooldownError
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.Unauthenticated, "User not found")
+	} else if errors.As(err, &cooldown) {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	} else if isUniqueViolation(err, handleIndex) {
+		return nil, status.Error(codes.AlreadyExists, "Handle is already taken")
+	} else if err != nil {
+		return nil, grpcInternal(err)
+	}
+	return userProto(updateUserConv(user)), nil
+}
+
+func (svc *userService) GetUser(ctx context.Context, req *chirpypb.GetUserRequest) (*chirpypb.Profile, error) {
+	var id uuid.UUID
+	switch user := req.User.(type) {
+	case *chirpypb.GetUserRequest_Id:
+		var err error
+		if id, err = grpcID(user.Id, "id"); err != nil {
+			return nil, err
+		}
+	case *chirpypb.GetUserRequest_Handle:
+		found, err := svc.cfg.dbQueries.GetUserByHandle(ctx, user.Handle)
+		if err != nil {
+			return nil, status.Error(codes.NotFound, "User not found")
+		}
+		id = found.ID
+	default:
+		return nil, status.Error(codes.InvalidArgument, "id or handle is required")
+	}
+	profile, err := svc.cfg.loadProfile(ctx, id)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "User not found")
+	}
+	return profileProto(profile), nil
+}
+
+func (svc *userService) UpdateProfile(ctx context.Context, req *chirpypb.UpdateProfileRequest) (*chirpypb.Profile, error) {
+	patch := profilePatch{DisplayName: req.DisplayName, Bio: req.Bio, AvatarUrl: req.AvatarUrl}
+	if err := patch.validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	id, err := grpcUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = svc.cfg.dbQueries.UpdateUserProfile(ctx, database.UpdateUserProfileParams{
+		DisplayName: optionalString(patch.DisplayName), Bio: optionalString(patch.Bio),
+		AvatarUrl: optionalString(patch.AvatarUrl), ID: id})
+	if err != nil {
+		return nil, grpcInternal(err)
+	}
+	profile, err := svc.cfg.loadProfile(ctx, id)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "User not found")
+	}
+	return profileProto(profile), nil
+}
+
+// relation authenticates the caller and finds the other user in req, as
+// relationTarget does for the REST API.
+func (svc *userService) relation(ctx context.Context, req *chirpypb.UserRequest, verb string) (uuid.UUID, uuid.UUID, error) {
+	self, err := grpcUser(ctx)
+	if err != nil {
+		return uuid.UUID{}, uuid.UUID{}, err
+	}
+	other, err := grpcID(req.UserId, "user_id")
+	if err != nil {
+		return uuid.UUID{}, uuid.UUID{}, err
+	}
+	if self == other {
+		return uuid.UUID{}, uuid.UUID{}, status.Error(codes.InvalidArgument, "Users cannot "+verb+" themselves")
+	}
+	if _, err = svc.cfg.dbQueries.GetUserByID(ctx, other); err != nil {
+		return uuid.UUID{}, uuid.UUID{}, status.Error(codes.NotFound, "User not found")
+	}
+	return self, other, nil
+}
+
+// relate runs change on the caller and the other user in req.
+func (svc *userService) relate(ctx context.Context, req *chirpypb.UserRequest, verb string, change func(ctx context.Context, self, other uuid.UUID) error) (*emptypb.Empty, error) {
+	self, other, err := svc.relation(ctx, req, verb)
+	if err != nil {
+		return nil, err
+	}
+	if err = change(ctx, self, other); errors.Is(err, errBlocked) {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	} else if err != nil {
+		return nil, grpcInternal(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (svc *userService) FollowUser(ctx context.Context, req *chirpypb.UserRequest) (*emptypb.Empty, error) {
+	return svc.relate(ctx, req, "follow", svc.cfg.followUser)
+}
+
+func (svc *userService) UnfollowUser(ctx context.Context, req *chirpypb.UserRequest) (*emptypb.Empty, error) {
+	return svc.relate(ctx, req, "unfollow", svc.cfg.unfollowUser)
+}
+
+func (svc *userService) BlockUser(ctx context.Context, req *chirpypb.UserRequest) (*emptypb.Empty, error) {
+	return svc.relate(ctx, req, "block", svc.cfg.blockUser)
+}
+
+func (svc *userService) UnblockUser(ctx context.Context, req *chirpypb.UserRequest) (*emptypb.Empty, error) {
+	return svc.relate(ctx, req, "unblock", func(ctx context.Context, self, other uuid.UUID) error {
+		return svc.cfg.dbQueries.UnblockUser(ctx, database.UnblockUserParams{BlockerID: self, BlockedID: other})
+	})
+}
+
+func (svc *userService) MuteUser(ctx context.Context, req *chirpypb.UserRequest) (*emptypb.Empty, error) {
+	return svc.relate(ctx, req, "mute", func(ctx context.Context, self, other uuid.UUID) error {
+		return svc.cfg.dbQueries.MuteUser(ctx, database.MuteUserParams{MuterID: self, MutedID: other})
+	})
+}
+
+func (svc *userService) UnmuteUser(ctx context.Context, req *chirpypb.UserRequest) (*emptypb.Empty, error) {
+	return svc.relate(ctx, req, "unmute", func(ctx context.Context, self, other uuid.UUID) error {
+		return svc.cfg.dbQueries.UnmuteUser(ctx, database.UnmuteUserParams{MuterID: self, MutedID: other})
+	})
+}
+
+func (svc *userService) listFollows(ctx context.Context, req *chirpypb.ListFollowsRequest, following bool) (*chirpypb.FollowPage, error) {
+	id, err := grpcID(req.UserId, "user_id")
+	if err != nil {
+		return nil, err
+	}
+	cur, limit, err := grpcPage(req.Cursor, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	page, err := svc.cfg.followPage(ctx, id, following, cur, limit)
+	if err != nil {
+		return nil, grpcInternal(err)
+	}
+	out := &chirpypb.FollowPage{Users: make([]*chirpypb.FollowedUser, len(page.Users)), NextCursor: page.NextCursor}
+	for i, user := range page.Users {
+		out.Users[i] = &chirpypb.FollowedUser{Id: user.Id.String(), Handle: user.Handle, DisplayName: user.DisplayName,
+			AvatarUrl: user.AvatarUrl, FollowedAt: timestamppb.New(user.FollowedAt)}
+	}
+	return out, nil
+}
+
+func (svc *userService) ListFollowers(ctx context.Context, req *chirpypb.ListFollowsRequest) (*chirpypb.FollowPage, error) {
+	return svc.listFollows(ctx, req, false)
+}
+
+func (svc *userService) ListFollowing(ctx context.Context, req *chirpypb.ListFollowsRequest) (*chirpypb.FollowPage, error) {
+	return svc.listFollows(ctx, req, true)
+}
+
+type chirpService struct {
+	chirpypb.UnimplementedChirpServiceServer
+	cfg *apiConfig
+}
+
+// respond converts a chirp the caller has just written.
+func (svc *chirpService) respond(ctx context.Context, chirp database.Chirp) (*chirpypb.Chirp, error) {
+	chirps, err := svc.cfg.chirpsConv(ctx, grpcViewer(ctx), []database.Chirp{chirp})
+	if err != nil {
+		return nil, grpcInternal(err)
+	}
+	return chirpProto(chirps[0]), nil
+}
+
+// target resolves the chirp in a rechirp, quote, reply or like by user, as refTarget does.
+func (svc *chirpService) target(ctx context.Context, user uuid.UUID, id string) (uuid.UUID, error) {
+	chirpID, err := grpcID(id, "id")
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	ref, err := svc.cfg.resolveRef(ctx, user, chirpID)
+	if errors.Is(err, errBlocked) {
+		return uuid.UUID{}, status.Error(codes.PermissionDenied, err.Error())
+	} else if err != nil {
+		return uuid.UUID{}, status.Error(codes.NotFound, err.Error())
+	}
+	return ref, nil
+}
+
+// post stores a chirp of kind by the caller, pointing at the chirp ref if it is set.
+func (svc *chirpService) post(ctx context.Context, body, kind, ref string) (*chirpypb.Chirp, error) {
+	if utf8.RuneCountInString(body) > redLengthLimit {
+		return nil, status.Error(codes.InvalidArgument, "Chirp is too long")
+	}
+	user, err := grpcUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tooLong, err := svc.cfg.chirpTooLong(ctx, user, body); err != nil {
+		return nil, grpcInternal(err)
+	} else if tooLong {
+		return nil, status.Error(codes.InvalidArgument, "Chirp is too long")
+	}
+	params := database.CreateRefChirpParams{Body: clean(body), UserID: user, Kind: kind}
+	if kind != chirpKindChirp {
+		refID, err := svc.target(ctx, user, ref)
+		if err != nil {
+			return nil, err
+		}
+		params.RefChirpID = uuid.NullUUID{UUID: refID, Valid: true}
+	}
+	chirp, err := svc.cfg.storeChirp(ctx, params)
+	if err != nil {
+		return nil, grpcInternal(err)
+	}
+	return svc.respond(ctx, chirp)
+}
+
+func (svc *chirpService) CreateChirp(ctx context.Context, req *chirpypb.CreateChirpRequest) (*chirpypb.Chirp, error) {
+	return svc.post(ctx, req.Body, chirpKindChirp, "")
+}
+
+func (svc *chirpService) QuoteChirp(ctx context.Context, req *chirpypb.RespondRequest) (*chirpypb.Chirp, error) {
+	return svc.post(ctx, req.Body, chirpKindQuote, req.Id)
+}
+
+func (svc *chirpService) ReplyToChirp(ctx context.Context, req *chirpypb.RespondRequest) (*chirpypb.Chirp, error) {
+	return svc.post(ctx, req.Body, chirpKindReply, req.Id)
+}
+
+func (svc *chirpService) Rechirp(ctx context.Context, req *chirpypb.ChirpRequest) (*chirpypb.Chirp, error) {
+	user, err := grpcUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := svc.target(ctx, user, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	chirp, err := svc.cfg.storeChirp(ctx, database.CreateRefChirpParams{UserID: user, Kind: chirpKindRechirp,
+		RefChirpID: uuid.NullUUID{UUID: ref, Valid: true}})
+	if isUniqueViolation(err, rechirpIndex) {
+		return nil, status.Error(codes.AlreadyExists, "Chirp has already been rechirped")
+	} else if err != nil {
+		return nil, grpcInternal(err)
+	}
+	return svc.respond(ctx, chirp)
+}
+
+func (svc *chirpService) GetChirp(ctx context.Context, req *chirpypb.ChirpRequest) (*chirpypb.Chirp, error) {
+	id, err := grpcID(req.Id, "id")
+	if err != nil {
+		return nil, err
+	}
+	chirp, err := svc.cfg.loadChirp(ctx, grpcViewer(ctx), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "Chirp not found")
+	} else if err != nil {
+		return nil, grpcInternal(err)
+	}
+	return chirpProto(chirp), nil
+}
+
+func (svc *chirpService) ListChirps(ctx context.Context, req *chirpypb.ListChirpsRequest) (*chirpypb.ChirpList, error) {
+	var author uuid.NullUUID
+	if req.AuthorId != "" {
+		id, err := grpcID(req.AuthorId, "author_id")
+		if err != nil {
+			return nil, err
+		}
+		author = uuid.NullUUID{UUID: id, Valid: true}
+	}
+	chirps, err := svc.cfg.loadChirps(ctx, grpcViewer(ctx), author, 0, false)
+	if err != nil {
+		return nil, grpcInternal(err)
+	}
+	slices.Reverse(chirps)
+	return &chirpypb.ChirpList{Chirps: chirpsProto(chirps)}, nil
+}
+
+func (svc *chirpService) EditChirp(ctx context.Context, req *chirpypb.EditChirpRequest) (*chirpypb.Chirp, error) {
+	if utf8.RuneCountInString(req.Body) > redLengthLimit {
+		return nil, status.Error(codes.InvalidArgument, "Chirp is too long")
+	}
+	id, err := grpcUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := svc.cfg.dbQueries.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, grpcInternal(err)
+	} else if !user.IsChirpyRed {
+		return nil, status.Error(codes.PermissionDenied, "Editing chirps needs Chirpy Red")
+	}
+	chirpID, err := grpcID(req.Id, "id")
+	if err != nil {
+		return nil, err
+	}
+	old, err := svc.cfg.dbQueries.GetChirp(ctx, chirpID)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "Chirp not found")
+	} else if old.UserID != id {
+		return nil, status.Error(codes.PermissionDenied, "Chirp belongs to someone else")
+	} else if old.Kind == chirpKindRechirp {
+		return nil, status.Error(codes.InvalidArgument, "Rechirps have no text to edit")
+	}
+	chirp, err := svc.cfg.editChirp(ctx, old, clean(req.Body))
+	if err != nil {
+		return nil, grpcInternal(err)
+	}
+	return svc.respond(ctx, chirp)
+}
+
+func (svc *chirpService) DeleteChirp(ctx context.Context, req *chirpypb.ChirpRequest) (*emptypb.Empty, error) {
+	user, err := grpcUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := grpcID(req.Id, "id")
+	if err != nil {
+		return nil, err
+	}
+	chirp, err := svc.cfg.dbQueries.GetChirp(ctx, id)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "Chirp not found")
+	}
+	if err = svc.cfg.deleteChirp(ctx, chirp, user); errors.Is(err, errNotAuthor) {
+		return nil, status.Error(codes.PermissionDenied, "Chirp belongs to someone else")
+	} else if err != nil {
+		return nil, grpcInternal(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (svc *chirpService) ListReplies(ctx context.Context, req *chirpypb.ChirpRequest) (*chirpypb.ChirpList, error) {
+	id, err := grpcID(req.Id, "id")
+	if err != nil {
+		return nil, err
+	}
+	replies, err := svc.cfg.loadReplies(ctx, grpcViewer(ctx), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "Chirp not found")
+	} else if err != nil {
+		return nil, grpcInternal(err)
+	}
+	return &chirpypb.ChirpList{Chirps: chirpsProto(replies)}, nil
+}
+
+func (svc *chirpService) LikeChirp(ctx context.Context, req *chirpypb.ChirpRequest) (*emptypb.Empty, error) {
+	user, err := grpcUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := svc.target(ctx, user, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	chirp, err := svc.cfg.dbQueries.GetChirp(ctx, id)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "Chirp not found")
+	}
+	if err = svc.cfg.likeChirp(ctx, chirp, user); err != nil {
+		return nil, grpcInternal(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (svc *chirpService) UnlikeChirp(ctx context.Context, req *chirpypb.ChirpRequest) (*emptypb.Empty, error) {
+	user, err := grpcUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := grpcID(req.Id, "id")
+	if err != nil {
+		return nil, err
+	}
+	chirp, err := svc.cfg.unlikeTarget(ctx, id)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "Chirp not found")
+	}
+	if err = svc.cfg.unlikeChirp(ctx, chirp, user); err != nil {
+		return nil, grpcInternal(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (svc *chirpService) GetTimeline(ctx context.Context, req *chirpypb.PageRequest) (*chirpypb.ChirpPage, error) {
+	user, err := grpcUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cur, limit, err := grpcPage(req.Cursor, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	page, err := svc.cfg.timelinePage(ctx, user, cur, limit)
+	if err != nil {
+		return nil, grpcInternal(err)
+	}
+	return &chirpypb.ChirpPage{Chirps: chirpsProto(page.Chirps), NextCursor: page.NextCursor}, nil
+}
+
+// StreamChirps sends chirps as handleStreamChirps does, with resume_after in
+// place of Last-Event-ID. The call ends with Unavailable if the client falls
+// too far behind, and should be resumed from the last cursor it received.
+func (svc *chirpService) StreamChirps(req *chirpypb.StreamChirpsRequest, server chirpypb.ChirpService_StreamChirpsServer) error {
+	ctx := server.Context()
+	viewer := grpcViewer(ctx)
+	filter, err := svc.cfg.newStreamFilter(ctx, viewer, req.AuthorId, req.Hashtag, req.Timeline)
+	if errors.Is(err, errNotLoggedIn) {
+		return status.Error(codes.Unauthenticated, err.Error())
+	} else if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	stream := &chirpStream{cfg: svc.cfg, viewer: viewer, filter: filter}
+	stream.emit = func(cursor pageCursor, chirp *chirpResp) error {
+		return server.Send(&chirpypb.StreamedChirp{Chirp: chirpProto(*chirp), Cursor: cursor.String()})
+	}
+	if req.ResumeAfter != "" {
+		if stream.replayed, err = parseCursor(req.ResumeAfter); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	events, cancel := svc.cfg.broker.Subscribe(streamBuffer, broker.Chirps)
+	defer cancel()
+	if err = stream.run(ctx, events, req.ResumeAfter != ""); err != nil {
+		return err
+	}
+	if ctx.Err() == nil {
+		return status.Error(codes.Unavailable, "fell too far behind; resume from the last cursor received")
+	}
+	return nil
+}
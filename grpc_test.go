@@ -0,0 +1,140 @@
+package main
+
+import (
+	"chirpy/chirpypb"
+	"chirpy/internal/auth"
+	"chirpy/internal/broker"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// grpcTestConn serves the gRPC API without a database over an in-process
+// listener, which is enough for calls that fail before they reach it.
+func grpcTestConn(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := (&apiConfig{sekrit: testSecret, broker: broker.NewMemory()}).newGRPCServer()
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient() returned error %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// grpcLogin is ctx with an access token for user in its metadata.
+func grpcLogin(t *testing.T, ctx context.Context, user uuid.UUID) context.Context {
+	t.Helper()
+	token, err := auth.MakeJWT(user, testSecret, time.Minute)
+	if err != nil {
+		t.Fatalf("MakeJWT() returned error %v", err)
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+func TestGRPCErrors(t *testing.T) {
+	conn := grpcTestConn(t)
+	chirps := chirpypb.NewChirpServiceClient(conn)
+	users := chirpypb.NewUserServiceClient(conn)
+	authClient := chirpypb.NewAuthServiceClient(conn)
+	user := uuid.New()
+	anon := context.Background()
+	badToken := metadata.AppendToOutgoingContext(anon, "authorization", "Bearer nope")
+	loggedIn := grpcLogin(t, anon, user)
+	long := make([]byte, redLengthLimit+1)
+	for i := range long {
+		long[i] = 'a'
+	}
+	tests := []struct {
+		name string
+		call func() error
+		code codes.Code
+	}{
+		{"delete without login", func() error {
+			_, err := chirps.DeleteChirp(anon, &chirpypb.ChirpRequest{Id: uuid.NewString()})
+			return err
+		}, codes.Unauthenticated},
+		{"bad token", func() error {
+			_, err := chirps.GetChirp(badToken, &chirpypb.ChirpRequest{Id: uuid.NewString()})
+			return err
+		}, codes.Unauthenticated},
+		{"chirp too long", func() error {
+			_, err := chirps.CreateChirp(loggedIn, &chirpypb.CreateChirpRequest{Body: string(long)})
+			return err
+		}, codes.InvalidArgument},
+		{"follow self", func() error {
+			_, err := users.FollowUser(loggedIn, &chirpypb.UserRequest{UserId: user.String()})
+			return err
+		}, codes.InvalidArgument},
+		{"bad email", func() error {
+			_, err := users.CreateUser(anon, &chirpypb.CreateUserRequest{Email: "nope", Password: "pw", Handle: "alice"})
+			return err
+		}, codes.InvalidArgument},
+		{"bad id", func() error {
+			_, err := chirps.GetChirp(anon, &chirpypb.ChirpRequest{Id: "nope"})
+			return err
+		}, codes.InvalidArgument},
+		{"timeline without login", func() error {
+			_, err := chirps.GetTimeline(anon, &chirpypb.PageRequest{})
+			return err
+		}, codes.Unauthenticated},
+		{"page too big", func() error {
+			_, err := chirps.GetTimeline(loggedIn, &chirpypb.PageRequest{PageSize: 1000})
+			return err
+		}, codes.InvalidArgument},
+		{"refresh without token", func() error {
+			_, err := authClient.Refresh(anon, &chirpypb.RefreshRequest{})
+			return err
+		}, codes.Unauthenticated},
+		{"stream timeline without login", func() error {
+			stream, err := chirps.StreamChirps(anon, &chirpypb.StreamChirpsRequest{Timeline: true})
+			if err != nil {
+				return err
+			}
+			_, err = stream.Recv()
+			return err
+		}, codes.Unauthenticated},
+		{"stream bad author", func() error {
+			stream, err := chirps.StreamChirps(anon, &chirpypb.StreamChirpsRequest{AuthorId: "nope"})
+			if err != nil {
+				return err
+			}
+			_, err = stream.Recv()
+			return err
+		}, codes.InvalidArgument},
+	}
+	for _, tc := range tests {
+		if code := status.Code(tc.call()); code != tc.code {
+			t.Errorf("%s: code %v, want %v", tc.name, code, tc.code)
+		}
+	}
+}
+
+func TestGRPCStreamEndsWithCall(t *testing.T) {
+	conn := grpcTestConn(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := chirpypb.NewChirpServiceClient(conn).StreamChirps(ctx, &chirpypb.StreamChirpsRequest{Hashtag: "go"})
+	if err != nil {
+		t.Fatalf("StreamChirps() returned error %v", err)
+	}
+	cancel()
+	if _, err = stream.Recv(); status.Code(err) != codes.Canceled {
+		t.Errorf("Recv() returned %v, want Canceled", err)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"chirpy/internal/database"
+	"chirpy/internal/entities"
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type trendingWindow struct {
+	name   string
+	length time.Duration
+	decay  time.Duration
+}
+
+type trendingTag struct {
+	Tag   string  `json:"tag"`
+	Score float64 `json:"score"`
+	Uses  int64   `json:"uses"`
+}
+
+const trendingInterval = time.Minute
+const defaultTrendingWindow = "24h"
+const defaultTrendingLimit = 10
+const maxTrendingLimit = 100
+
+// Each window only counts hashtags used within its length, and weights every use by
+// exp(-age/decay) so that fresh activity ranks above a burst that has gone quiet.
+var trendingWindows = []trendingWindow{
+	{name: "1h", length: time.Hour, decay: 15 * time.Minute},
+	{name: "24h", length: 24 * time.Hour, decay: 6 * time.Hour},
+	{name: "7d", length: 7 * 24 * time.Hour, decay: 24 * time.Hour},
+}
+
+func (cfg *apiConfig) refreshTrending(ctx context.Context, window trendingWindow) error {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	if err = qtx.ClearTrending(ctx, window.name); err != nil {
+		return err
+	}
+	err = qtx.RefreshTrending(ctx, database.RefreshTrendingParams{TimeWindow: window.name,
+		DecaySeconds: window.decay.Seconds(), WindowSeconds: window.length.Seconds()})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// runTrending recomputes the trending scores for every window each interval until ctx is done.
+func (cfg *apiConfig) runTrending(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, window := range trendingWindows {
+			if err := cfg.refreshTrending(ctx, window); err != nil {
+				fmt.Printf("trending %s: %v\n", window.name, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (cfg *apiConfig) handleHashtagChirps(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	tag := entities.NormalizeHashtag(req.PathValue("tag"))
+	if tag == "" {
+		handleError(writer, req, http.StatusNotFound, "invalid hashtag")
+		return
+	}
+	jsonChirps, err := cfg.loadHashtagChirps(req.Context(), cfg.optionalUser(req.Header), tag, 0)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	// The API lists chirps oldest first.
+	slices.Reverse(jsonChirps)
+	handleJsonWrite(writer, http.StatusOK, tag, jsonChirps)
+}
+
+// loadHashtagChirps loads the newest limit chirps tagged with tag that viewer may
+// see, newest first, converted and filtered for viewer. A limit of 0 loads them all.
+func (cfg *apiConfig) loadHashtagChirps(ctx context.Context, viewer uuid.NullUUID, tag string, limit int) ([]chirpResp, error) {
+	chirps, err := cfg.dbQueries.GetNewestChirpsByHashtag(ctx, database.GetNewestChirpsByHashtagParams{Tag: tag,
+		ViewerID: viewer, MaxChirps: maxChirps(limit)})
+	if err != nil {
+		return nil, err
+	}
+	jsonChirps, err := cfg.chirpsConv(ctx, viewer, chirps)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.filterChirps(ctx, viewer, jsonChirps)
+}
+
+func (cfg *apiConfig) handleTrending(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	window := req.URL.Query().Get("window")
+	if window == "" {
+		window = defaultTrendingWindow
+	}
+	known := false
+	for i := range trendingWindows {
+		known = known || trendingWindows[i].name == window
+	}
+	if !known {
+		handleError(writer, req, http.StatusBadRequest, "unknown window "+window)
+		return
+	}
+	limit := defaultTrendingLimit
+	if limitStr := req.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxTrendingLimit {
+			handleError(writer, req, http.StatusBadRequest, "invalid limit")
+			return
+		}
+	}
+	rows, err := cfg.dbQueries.GetTrending(req.Context(), database.GetTrendingParams{TimeWindow: window, Limit: int32(limit)})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	tags := make([]trendingTag, len(rows))
+	for i := range rows {
+		tags[i] = trendingTag{Tag: rows[i].Tag, Score: rows[i].Score, Uses: rows[i].Uses}
+	}
+	handleJsonWrite(writer, http.StatusOK, "trending", tags)
+}
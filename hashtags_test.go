@@ -0,0 +1,120 @@
+package main
+
+import (
+	"chirpy/internal/database"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+// TestHashtagChirps checks that a hashtag is looked up in the folded form it is
+// stored in, however the path spells it, and that its chirps are listed oldest
+// first.
+func TestHashtagChirps(t *testing.T) {
+	author := uuid.New()
+	now := time.Now().UTC()
+	chirp := func(body string, age time.Duration) database.Chirp {
+		return database.Chirp{ID: uuid.New(), CreatedAt: now.Add(-age), UpdatedAt: now.Add(-age), Body: body,
+			UserID: author, Kind: chirpKindChirp}
+	}
+	newer, older := chirp("more #GoLang", time.Minute), chirp("#golang!", time.Hour)
+	for _, tag := range []string{"golang", "GoLang", "%23GoLang", "%EF%BC%A7%EF%BD%8FLang"} {
+		cfg, mock := mockConfig(t)
+		mock.ExpectQuery("name: GetNewestChirpsByHashtag ").WithArgs("golang", uuid.NullUUID{}, sql.NullInt32{}).
+			WillReturnRows(chirpRows(newer, older))
+		expectChirpDetails(mock, database.GetUserHandlesRow{ID: author, Handle: "alice"})
+
+		path := "/api/v2/hashtags/" + tag + "/chirps"
+		recorder := httptest.NewRecorder()
+		cfg.routes().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		var got []chirpResp
+		if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil || recorder.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d, %q: %v", path, recorder.Code, recorder.Body, err)
+		}
+		if len(got) != 2 || got[0].Body != older.Body || got[1].Body != newer.Body {
+			t.Errorf("GET %s = %+v, want %q then %q", path, got, older.Body, newer.Body)
+		}
+	}
+
+	cfg, _ := mockConfig(t)
+	for _, tag := range []string{"%23", "2024", "go-lang"} {
+		path := "/api/v2/hashtags/" + tag + "/chirps"
+		recorder := httptest.NewRecorder()
+		cfg.routes().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", path, recorder.Code)
+		}
+	}
+}
+
+// TestTrending checks that trending hashtags come out in the order of their
+// decayed scores, highest first, for the window and limit asked for.
+func TestTrending(t *testing.T) {
+	tests := []struct {
+		query  string
+		window string
+		limit  int32
+		want   int
+	}{
+		{"", defaultTrendingWindow, defaultTrendingLimit, http.StatusOK},
+		{"?window=1h&limit=2", "1h", 2, http.StatusOK},
+		{"?window=7d&limit=100", "7d", maxTrendingLimit, http.StatusOK},
+		{"?window=30d", "", 0, http.StatusBadRequest},
+		{"?limit=0", "", 0, http.StatusBadRequest},
+		{"?limit=101", "", 0, http.StatusBadRequest},
+		{"?limit=ten", "", 0, http.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		cfg, mock := mockConfig(t)
+		if tc.want == http.StatusOK {
+			mock.ExpectQuery(`name: GetTrending .*\s+ORDER BY score DESC, tag ASC\s+LIMIT \$2`).
+				WithArgs(tc.window, tc.limit).WillReturnRows(sqlmock.NewRows([]string{"tag", "score", "uses"}).
+				AddRow("golang", 2.5, 3).
+				AddRow("rust", 1.75, 9).
+				AddRow("zig", 1.75, 2))
+		}
+
+		recorder := httptest.NewRecorder()
+		cfg.routes().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v2/trending"+tc.query, nil))
+		if recorder.Code != tc.want {
+			t.Errorf("GET /api/v2/trending%s = %d, want %d: %s", tc.query, recorder.Code, tc.want, recorder.Body)
+			continue
+		}
+		if tc.want != http.StatusOK {
+			continue
+		}
+		var got []trendingTag
+		if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+			t.Fatalf("GET /api/v2/trending%s = %q: %v", tc.query, recorder.Body, err)
+		}
+		if len(got) != 3 || got[0].Tag != "golang" || got[1].Tag != "rust" || got[2].Tag != "zig" ||
+			got[1].Score != 1.75 || got[1].Uses != 9 {
+			t.Errorf("GET /api/v2/trending%s = %+v, want golang, rust then zig", tc.query, got)
+		}
+	}
+}
+
+// TestRefreshTrending checks that each window is recounted from the uses within
+// its length, each weighted by its age over the window's decay.
+func TestRefreshTrending(t *testing.T) {
+	for _, window := range trendingWindows {
+		cfg, mock := mockConfig(t)
+		mock.ExpectBegin()
+		mock.ExpectExec("name: ClearTrending ").WithArgs(window.name).WillReturnResult(sqlmock.NewResult(0, 5))
+		mock.ExpectExec(`name: RefreshTrending .*EXP\(-EXTRACT\(EPOCH FROM NOW\(\) - created_at\)::float8 / \$2::float8\)`+
+			`.*WHERE created_at > NOW\(\) - make_interval\(secs => \$3::float8\)`).
+			WithArgs(window.name, window.decay.Seconds(), window.length.Seconds()).
+			WillReturnResult(sqlmock.NewResult(0, 5))
+		mock.ExpectCommit()
+		if err := cfg.refreshTrending(context.Background(), window); err != nil {
+			t.Errorf("refreshTrending(%s) returned error %v", window.name, err)
+		}
+	}
+}
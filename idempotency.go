@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"chirpy/internal/database"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+const idempotentReplayHeader = "Idempotent-Replayed"
+const maxIdempotencyKey = 255
+
+// idempotencyTTL is how long a response is kept for retries.
+const idempotencyTTL = 24 * time.Hour
+
+// idempotencyLease is how long a request holds its key while it runs. A retry
+// after that takes the key over, as the first attempt must have died.
+const idempotencyLease = time.Minute
+const idempotencyCleanupInterval = time.Hour
+
+// signupKeySpace is the namespace sign-up keys are scoped in, by email.
+var signupKeySpace = uuid.MustParse("3efd3d43-529c-4f21-80e1-c3be76c0eaa4")
+
+// idempotencyScope says whose keys a request's Idempotency-Key is among, given the
+// request and its body. A request it returns false for is passed through for the
+// handler to refuse.
+type idempotencyScope func(req *http.Request, body []byte) (uuid.UUID, bool)
+
+// callerKeys scopes keys to the logged-in caller.
+func (cfg *apiConfig) callerKeys(req *http.Request, _ []byte) (uuid.UUID, bool) {
+	id, err := cfg.validateUser(req.Header)
+	return id, err == nil
+}
+
+// signupKeys scopes keys to the email being signed up, so that clients picking
+// the same key for different sign-ups never see each other's requests.
+func signupKeys(_ *http.Request, body []byte) (uuid.UUID, bool) {
+	var msg struct {
+		Email string `json:"email"`
+	}
+	if json.Unmarshal(body, &msg) != nil || msg.Email == "" {
+		return uuid.Nil, false
+	}
+	return uuid.NewSHA1(signupKeySpace, []byte(strings.ToLower(msg.Email))), true
+}
+
+// idempotent lets a client retry a POST safely by sending an Idempotency-Key.
+// The first response to a key is kept for a day and sent again for the same
+// request; the same key with a different request gets 422, and a retry while
+// the first attempt is still running gets 409. The key's row is the lock, so
+// duplicates are caught across servers. Faults on our side are not kept, so
+// they can be retried. Keys are only unique within their scope.
+//
+// Responses are kept as they were sent, so it must not wrap anything that
+// answers with credentials.
+func (cfg *apiConfig) idempotent(scope idempotencyScope, next func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(writer http.ResponseWriter, req *http.Request) {
+		key := req.Header.Get(idempotencyKeyHeader)
+		if key == "" {
+			next(writer, req)
+			return
+		}
+		writer.Header()["Content-Type"] = []string{jsonContent}
+		if len(key) > maxIdempotencyKey {
+			handleError(writer, req, http.StatusBadRequest,
+				fmt.Sprintf("%s must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKey))
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(writer, req.Body, maxRequestBody))
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			handleError(writer, req, http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("body must be at most %d bytes", tooBig.Limit))
+			return
+		} else if err != nil {
+			handleError(writer, req, http.StatusBadRequest, err.Error())
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		owner, ok := scope(req, body)
+		if !ok {
+			next(writer, req)
+			return
+		}
+		hash := requestHash(req, body)
+		_, err = cfg.dbQueries.ClaimIdempotencyKey(req.Context(), database.ClaimIdempotencyKeyParams{UserID: owner,
+			Key: key, RequestHash: hash, LeaseSeconds: idempotencyLease.Seconds(), TtlSeconds: idempotencyTTL.Seconds()})
+		if errors.Is(err, sql.ErrNoRows) {
+			cfg.replayIdempotent(writer, req, owner, key, hash)
+			return
+		} else if err != nil {
+			handleFault(writer, req, http.StatusInternalServerError, err)
+			return
+		}
+		recorder := &recordingWriter{ResponseWriter: writer, status: http.StatusOK}
+		next(recorder, req)
+		// The client may be gone, but its retry still needs what it missed.
+		ctx := context.WithoutCancel(req.Context())
+		if recorder.status >= http.StatusInternalServerError {
+			err = cfg.dbQueries.ReleaseIdempotencyKey(ctx, database.ReleaseIdempotencyKeyParams{UserID: owner, Key: key})
+		} else {
+			err = cfg.dbQueries.SaveIdempotentResponse(ctx, database.SaveIdempotentResponseParams{
+				ResponseStatus:      sql.NullInt32{Int32: int32(recorder.status), Valid: true},
+				ResponseContentType: sql.NullString{String: writer.Header().Get("Content-Type"), Valid: true},
+				ResponseBody:        sql.NullString{String: recorder.body.String(), Valid: true},
+				UserID:              owner, Key: key})
+		}
+		if err != nil {
+			logFault(req, err)
+		}
+	}
+}
+
+// replayIdempotent answers a request whose key was already claimed.
+func (cfg *apiConfig) replayIdempotent(writer http.ResponseWriter, req *http.Request, owner uuid.UUID, key, hash string) {
+	kept, err := cfg.dbQueries.GetIdempotencyKey(req.Context(), database.GetIdempotencyKeyParams{UserID: owner, Key: key})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	if kept.RequestHash != hash {
+		handleProblem(writer, req, http.StatusUnprocessableEntity, codeIdempotencyKeyReused,
+			idempotencyKeyHeader+" was already used for a different request")
+		return
+	}
+	if !kept.ResponseStatus.Valid {
+		writer.Header().Set("Retry-After", "1")
+		handleProblem(writer, req, http.StatusConflict, codeRequestInProgress,
+			"A request with this "+idempotencyKeyHeader+" is still in progress")
+		return
+	}
+	writer.Header()["Content-Type"] = []string{kept.ResponseContentType.String}
+	writer.Header().Set(idempotentReplayHeader, "true")
+	writer.WriteHeader(int(kept.ResponseStatus.Int32))
+	writer.Write([]byte(kept.ResponseBody.String))
+}
+
+// requestHash tells retries of a request from other requests under the same key.
+// It goes by the route rather than the path, so a retry may switch between the
+// unversioned and versioned prefixes.
+func requestHash(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", req.Method, routePattern(req))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// recordingWriter keeps a copy of the response it passes on.
+type recordingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (writer *recordingWriter) WriteHeader(status int) {
+	if !writer.wroteHeader {
+		writer.status, writer.wroteHeader = status, true
+	}
+	writer.ResponseWriter.WriteHeader(status)
+}
+
+func (writer *recordingWriter) Write(data []byte) (int, error) {
+	writer.wroteHeader = true
+	writer.body.Write(data)
+	return writer.ResponseWriter.Write(data)
+}
+
+// runIdempotencyCleanup deletes expired idempotency keys each interval until ctx is done.
+func (cfg *apiConfig) runIdempotencyCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := cfg.dbQueries.DeleteExpiredIdempotencyKeys(ctx, idempotencyTTL.Seconds()); err != nil {
+			fmt.Printf("idempotency cleanup: %v\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
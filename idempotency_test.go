@@ -0,0 +1,158 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestIdempotentWithoutStore(t *testing.T) {
+	routes := (&apiConfig{}).routes()
+	tests := []struct {
+		name       string
+		path       string
+		key        string
+		wantStatus int
+	}{
+		{"key too long", "/api/v2/users", strings.Repeat("k", maxIdempotencyKey+1), http.StatusBadRequest},
+		{"not logged in is left to the handler", "/api/v2/chirps", "retry-1", http.StatusUnauthorized},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(`{"body":"hi"}`))
+		req.Header.Set("Content-Type", jsonContent)
+		req.Header.Set(idempotencyKeyHeader, tc.key)
+		recorder := httptest.NewRecorder()
+		routes.ServeHTTP(recorder, req)
+		if recorder.Code != tc.wantStatus {
+			t.Errorf("%s: status %d, want %d: %s", tc.name, recorder.Code, tc.wantStatus, recorder.Body)
+		}
+	}
+}
+
+func TestSignupKeys(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/users", nil)
+	alice, ok := signupKeys(req, []byte(`{"email":"alice@example.com","password":"pw","handle":"alice"}`))
+	if !ok || alice == uuid.Nil {
+		t.Fatalf("signupKeys() = %v, %v", alice, ok)
+	}
+	if again, _ := signupKeys(req, []byte(`{"email":"Alice@Example.com","password":"other"}`)); again != alice {
+		t.Errorf("the same email in another case is scoped to %v, want %v", again, alice)
+	}
+	if bob, _ := signupKeys(req, []byte(`{"email":"bob@example.com","password":"pw"}`)); bob == alice {
+		t.Error("two emails share a scope")
+	}
+	for _, body := range []string{`{"password":"pw"}`, `not json`} {
+		if _, ok := signupKeys(req, []byte(body)); ok {
+			t.Errorf("signupKeys(%s) scoped a request with no email", body)
+		}
+	}
+}
+
+func TestIdempotencyScopes(t *testing.T) {
+	cfg, mock := mockConfig(t)
+	routes := cfg.routes()
+	signup := `{"email":"alice@example.com","password":"hunter22","handle":"alice"}`
+	owner, _ := signupKeys(nil, []byte(signup))
+	mock.ExpectQuery("name: ClaimIdempotencyKey ").
+		WithArgs(owner, "retry-1", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnError(errors.New("connection reset"))
+	// Logging in is never kept, since its response holds fresh tokens.
+	mock.ExpectQuery("name: GetUserByEmail ").WithArgs("alice@example.com").WillReturnError(sql.ErrNoRows)
+	tests := []struct {
+		path string
+		body string
+		want int
+	}{
+		{"/api/v2/users", signup, http.StatusInternalServerError},
+		{"/api/v2/login", `{"email":"alice@example.com","password":"hunter22"}`, http.StatusUnauthorized},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", jsonContent)
+		req.Header.Set(idempotencyKeyHeader, "retry-1")
+		recorder := httptest.NewRecorder()
+		routes.ServeHTTP(recorder, req)
+		if recorder.Code != tc.want {
+			t.Errorf("POST %s = %d, want %d: %s", tc.path, recorder.Code, tc.want, recorder.Body)
+		}
+	}
+}
+
+func TestRequestHash(t *testing.T) {
+	hash := func(method, path, body string) string {
+		return requestHash(httptest.NewRequest(method, path, nil), []byte(body))
+	}
+	first := hash(http.MethodPost, "/api/chirps", `{"body":"hi"}`)
+	if again := hash(http.MethodPost, "/api/chirps", `{"body":"hi"}`); again != first {
+		t.Errorf("a retry hashed to %s, want %s", again, first)
+	}
+	for _, other := range []string{
+		hash(http.MethodPost, "/api/chirps", `{"body":"bye"}`),
+		hash(http.MethodPost, "/api/users", `{"body":"hi"}`),
+		hash(http.MethodPut, "/api/chirps", `{"body":"hi"}`),
+	} {
+		if other == first {
+			t.Errorf("a different request hashed the same as the first")
+		}
+	}
+}
+
+// hashArg matches any request hash, keeping it.
+type hashArg struct{ hash *string }
+
+func (arg hashArg) Match(value driver.Value) bool {
+	*arg.hash, _ = value.(string)
+	return true
+}
+
+// TestRequestHashAcrossVersions checks that a retry may switch between /api and
+// a versioned prefix and still count as the same request.
+func TestRequestHashAcrossVersions(t *testing.T) {
+	cfg, mock := mockConfig(t)
+	routes := cfg.routes()
+	user := uuid.New()
+	paths := []string{"/api/chirps", "/api/v1/chirps", "/api/v2/chirps"}
+	hashes := make([]string, len(paths))
+	for i := range paths {
+		// Failing the claim stops the request before the handler runs.
+		mock.ExpectQuery("name: ClaimIdempotencyKey ").
+			WithArgs(user, "retry-1", hashArg{&hashes[i]}, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnError(errors.New("connection reset"))
+	}
+	for _, path := range paths {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"body":"hi"}`))
+		req.Header.Set("Content-Type", jsonContent)
+		req.Header.Set("Authorization", authHeader(t, user))
+		req.Header.Set(idempotencyKeyHeader, "retry-1")
+		routes.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if hashes[0] == "" || hashes[1] != hashes[0] || hashes[2] != hashes[0] {
+		t.Errorf("POST %v hashed to %v, want the same hash", paths, hashes)
+	}
+}
+
+func TestRecordingWriter(t *testing.T) {
+	for _, status := range []int{0, http.StatusCreated} {
+		inner := httptest.NewRecorder()
+		writer := &recordingWriter{ResponseWriter: inner, status: http.StatusOK}
+		if status != 0 {
+			writer.WriteHeader(status)
+		}
+		writer.Write([]byte("hi"))
+		writer.WriteHeader(http.StatusTeapot)
+		want := status
+		if want == 0 {
+			want = http.StatusOK
+		}
+		if writer.status != want || writer.body.String() != "hi" || inner.Body.String() != "hi" {
+			t.Errorf("recorded %d %q, passed on %q, want %d \"hi\"", writer.status, writer.body.String(), inner.Body, want)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package activitypub
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testKey(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+	privatePEM, publicPEM, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() returned error %v", err)
+	}
+	key, err := ParsePrivateKey(privatePEM)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey() returned error %v", err)
+	}
+	return key, publicPEM
+}
+
+// inbox verifies every request it gets against publicPEM and reports the result.
+func inbox(t *testing.T, publicPEM string) (*httptest.Server, <-chan error) {
+	t.Helper()
+	results := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		_, err := Verify(req, body, func(ctx context.Context, keyID string) (*rsa.PublicKey, error) {
+			if keyID != "https://chirpy.test/ap/users/alice#main-key" {
+				return nil, errors.New("unknown key")
+			}
+			return ParsePublicKey(publicPEM)
+		})
+		results <- err
+		if err != nil {
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writer.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+	return server, results
+}
+
+func TestDeliverIsVerified(t *testing.T) {
+	key, publicPEM := testKey(t)
+	server, results := inbox(t, publicPEM)
+	signer := &Signer{KeyID: "https://chirpy.test/ap/users/alice#main-key", Key: key}
+	activity := []byte(`{"type":"Follow"}`)
+	if err := Deliver(context.Background(), server.Client(), signer, server.URL+"/inbox", activity); err != nil {
+		t.Errorf("Deliver() returned error %v", err)
+	}
+	if err := <-results; err != nil {
+		t.Errorf("Verify() returned error %v", err)
+	}
+	other, _ := testKey(t)
+	signer.Key = other
+	if err := Deliver(context.Background(), server.Client(), signer, server.URL+"/inbox", activity); err == nil {
+		t.Error("Deliver() signed with the wrong key succeeded")
+	}
+	if err := <-results; !errors.Is(err, ErrBadSignature) {
+		t.Errorf("Verify() with the wrong key = %v, want %v", err, ErrBadSignature)
+	}
+}
+
+func TestVerifyRejectsTampering(t *testing.T) {
+	key, publicPEM := testKey(t)
+	fetch := func(context.Context, string) (*rsa.PublicKey, error) { return ParsePublicKey(publicPEM) }
+	signed := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "https://chirpy.test/ap/users/bob/inbox", strings.NewReader(body))
+		if err := Sign(req, []byte(body), "https://chirpy.test/ap/users/alice#main-key", key); err != nil {
+			t.Fatalf("Sign() returned error %v", err)
+		}
+		return req
+	}
+	req := signed(`{"type":"Like"}`)
+	if _, err := Verify(req, []byte(`{"type":"Delete"}`), fetch); !errors.Is(err, ErrBadDigest) {
+		t.Errorf("Verify() of a changed body = %v, want %v", err, ErrBadDigest)
+	}
+	req = signed(`{"type":"Like"}`)
+	req.URL.Path = "/ap/users/carol/inbox"
+	if _, err := Verify(req, []byte(`{"type":"Like"}`), fetch); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("Verify() of a request sent elsewhere = %v, want %v", err, ErrBadSignature)
+	}
+	req = signed(`{"type":"Like"}`)
+	req.Header.Set("Date", time.Now().Add(-2*MaxClockSkew).UTC().Format(http.TimeFormat))
+	if _, err := Verify(req, []byte(`{"type":"Like"}`), fetch); !errors.Is(err, ErrStaleDate) {
+		t.Errorf("Verify() of an old request = %v, want %v", err, ErrStaleDate)
+	}
+	req = httptest.NewRequest(http.MethodPost, "https://chirpy.test/ap/users/bob/inbox", nil)
+	if _, err := Verify(req, nil, fetch); !errors.Is(err, ErrNoSignature) {
+		t.Errorf("Verify() of an unsigned request = %v, want %v", err, ErrNoSignature)
+	}
+}
+
+func TestResolveAndFetchActor(t *testing.T) {
+	_, publicPEM := testKey(t)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		actorID := server.URL + "/ap/users/alice"
+		switch req.URL.Path {
+		case "/.well-known/webfinger":
+			if req.URL.Query().Get("resource") != "acct:alice@"+req.Host {
+				http.NotFound(writer, req)
+				return
+			}
+			json.NewEncoder(writer).Encode(WebFinger{Subject: req.URL.Query().Get("resource"),
+				Links: []WebFingerLink{{Rel: "self", Type: ContentType, Href: actorID}}})
+		case "/ap/users/alice":
+			json.NewEncoder(writer).Encode(Actor{ID: actorID, Type: "Person", PreferredUsername: "alice",
+				Inbox: actorID + "/inbox", PublicKey: PublicKey{ID: actorID + "#main-key", Owner: actorID, PublicKeyPem: publicPEM}})
+		default:
+			http.NotFound(writer, req)
+		}
+	}))
+	defer server.Close()
+	domain := strings.TrimPrefix(server.URL, "http://")
+	id, err := Resolve(context.Background(), server.Client(), "@alice@"+domain)
+	if err != nil || id != server.URL+"/ap/users/alice" {
+		t.Fatalf("Resolve() = %q, %v", id, err)
+	}
+	actor, err := FetchActor(context.Background(), server.Client(), nil, id)
+	if err != nil || actor.PreferredUsername != "alice" {
+		t.Errorf("FetchActor() = %+v, %v", actor, err)
+	}
+	if _, err = Resolve(context.Background(), server.Client(), "bob@"+domain); err == nil {
+		t.Error("Resolve() of an unknown account succeeded")
+	}
+}
+
+func TestRequireHTTPS(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/elsewhere" {
+			http.Redirect(writer, req, "http://example.com/actor", http.StatusFound)
+			return
+		}
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: RequireHTTPS(server.Client().Transport)}
+
+	local := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	resp, err := client.Get(local)
+	if err != nil {
+		t.Fatalf("GET %s returned error %v", local, err)
+	}
+	resp.Body.Close()
+	if _, err = client.Get("http://example.com/actor"); !errors.Is(err, ErrInsecure) {
+		t.Errorf("GET over http = %v, want ErrInsecure", err)
+	}
+	if _, err = client.Get(local + "/elsewhere"); !errors.Is(err, ErrInsecure) {
+		t.Errorf("redirect to http = %v, want ErrInsecure", err)
+	}
+}
+
+func TestActivityObject(t *testing.T) {
+	tests := []struct {
+		object   string
+		wantID   string
+		wantType string
+	}{
+		{`"https://remote.test/users/bob"`, "https://remote.test/users/bob", ""},
+		{`{"id":"https://remote.test/notes/1","type":"Note"}`, "https://remote.test/notes/1", "Note"},
+	}
+	for _, tc := range tests {
+		activity := Activity{Object: json.RawMessage(tc.object)}
+		if activity.ObjectID() != tc.wantID || activity.ObjectType() != tc.wantType {
+			t.Errorf("object %s: got %q, %q", tc.object, activity.ObjectID(), activity.ObjectType())
+		}
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{`<p>hello <a href="https://remote.test/@bob">@<span>bob</span></a></p>`, "hello @bob"},
+		{"<p>one</p><p>two<br/>three</p>", "one\ntwo\nthree"},
+		{"<p>fish &amp; chips &lt;3</p>", "fish & chips <3"},
+	}
+	for _, tc := range tests {
+		if got := PlainText(tc.content); got != tc.want {
+			t.Errorf("PlainText(%q) = %q, want %q", tc.content, got, tc.want)
+		}
+	}
+	if got := PlainText(HTML("a < b\nc")); got != "a < b\nc" {
+		t.Errorf("PlainText(HTML()) = %q", got)
+	}
+}
@@ -0,0 +1,159 @@
+package activitypub
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxDocument caps how much of a remote document we read.
+const maxDocument = 1 << 20
+
+// Signer signs outgoing requests as one local actor.
+type Signer struct {
+	KeyID string
+	Key   *rsa.PrivateKey
+}
+
+// Scheme is the scheme used to reach domain. Other servers are only spoken to
+// over https, except on localhost, so that two instances on one machine can
+// federate with each other.
+func Scheme(domain string) string {
+	host := domain
+	if h, _, err := net.SplitHostPort(domain); err == nil {
+		host = h
+	}
+	if host == "localhost" || host == "127.0.0.1" || host == "::1" {
+		return "http"
+	}
+	return "https"
+}
+
+// ErrInsecure is returned for a request that would reach another server over
+// plain http.
+var ErrInsecure = errors.New("other servers are only spoken to over https")
+
+type httpsOnly struct {
+	next http.RoundTripper
+}
+
+// RequireHTTPS wraps next so that it refuses any request, redirects included,
+// whose scheme is not the one Scheme picks for its host.
+func RequireHTTPS(next http.RoundTripper) http.RoundTripper {
+	return httpsOnly{next: next}
+}
+
+func (transport httpsOnly) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "https" && req.URL.Scheme != Scheme(req.URL.Host) {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL, ErrInsecure)
+	}
+	return transport.next.RoundTrip(req)
+}
+
+// SplitAccount splits "user@example.com", with or without a leading "@" or
+// "acct:", into the user and the domain.
+func SplitAccount(account string) (string, string, error) {
+	account = strings.TrimPrefix(strings.TrimPrefix(account, "acct:"), "@")
+	user, domain, found := strings.Cut(account, "@")
+	if !found || user == "" || domain == "" || strings.ContainsAny(domain, "/?#@") {
+		return "", "", fmt.Errorf("account must look like user@example.com")
+	}
+	return user, domain, nil
+}
+
+func send(ctx context.Context, client *http.Client, signer *Signer, method, target string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", ContentType+", "+LDContentType)
+	req.Header.Set("User-Agent", "chirpy")
+	if body != nil {
+		req.Header.Set("Content-Type", ContentType)
+	}
+	if signer != nil {
+		if err = Sign(req, body, signer.KeyID, signer.Key); err != nil {
+			return nil, err
+		}
+	}
+	return client.Do(req)
+}
+
+// getJSON fetches a document into out, signing the request if signer is set so
+// that servers which require signed fetches answer it.
+func getJSON(ctx context.Context, client *http.Client, signer *Signer, target string, out any) error {
+	resp, err := send(ctx, client, signer, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", target, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxDocument)).Decode(out)
+}
+
+// Resolve looks account up with WebFinger and returns its actor id.
+func Resolve(ctx context.Context, client *http.Client, account string) (string, error) {
+	user, domain, err := SplitAccount(account)
+	if err != nil {
+		return "", err
+	}
+	target := fmt.Sprintf("%s://%s/.well-known/webfinger?resource=%s", Scheme(domain), domain,
+		url.QueryEscape("acct:"+user+"@"+domain))
+	var finger WebFinger
+	if err = getJSON(ctx, client, nil, target, &finger); err != nil {
+		return "", err
+	}
+	for _, link := range finger.Links {
+		if link.Rel == "self" && (link.Type == ContentType || strings.HasPrefix(link.Type, "application/ld+json")) {
+			return link.Href, nil
+		}
+	}
+	return "", fmt.Errorf("%s has no ActivityPub actor", account)
+}
+
+// FetchActor fetches an actor document and checks that it is the actor asked for
+// and that its key belongs to it.
+func FetchActor(ctx context.Context, client *http.Client, signer *Signer, id string) (Actor, error) {
+	var actor Actor
+	if err := getJSON(ctx, client, signer, id, &actor); err != nil {
+		return Actor{}, err
+	}
+	if actor.ID != id {
+		return Actor{}, fmt.Errorf("fetched %s but got actor %s", id, actor.ID)
+	}
+	if actor.Inbox == "" || actor.PublicKey.Owner != actor.ID || actor.PublicKey.PublicKeyPem == "" {
+		return Actor{}, fmt.Errorf("actor %s has no inbox or key", id)
+	}
+	return actor, nil
+}
+
+// Deliver posts a signed activity to an inbox.
+func Deliver(ctx context.Context, client *http.Client, signer *Signer, inbox string, activity []byte) error {
+	resp, err := send(ctx, client, signer, http.MethodPost, inbox, activity)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxDocument))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("POST %s: %s", inbox, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package activitypub
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MaxClockSkew is how far a signed request's Date may be from our clock.
+const MaxClockSkew = time.Hour
+
+var ErrNoSignature = errors.New("request is not signed")
+var ErrBadSignature = errors.New("signature does not match")
+var ErrBadDigest = errors.New("digest does not match the body")
+var ErrStaleDate = errors.New("signed date is too far from now")
+
+var signatureParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// KeyFetcher returns the public key a signature's keyId names, fetching the
+// remote actor that owns it if it is not known yet.
+type KeyFetcher func(ctx context.Context, keyID string) (*rsa.PublicKey, error)
+
+// Digest is the Digest header value for body.
+func Digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Sign adds Date, Digest and Signature headers to req the way Mastodon expects:
+// draft-cavage HTTP Signatures with rsa-sha256 over the request target, host and
+// date, and over the digest of the body when there is one.
+func Sign(req *http.Request, body []byte, keyID string, key *rsa.PrivateKey) error {
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	headers := []string{"(request-target)", "host", "date"}
+	if body != nil {
+		req.Header.Set("Digest", Digest(body))
+		headers = append(headers, "digest")
+	}
+	signing, err := signingString(req, headers)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256([]byte(signing))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Signature", fmt.Sprintf(`keyId="%s",algorithm="rsa-sha256",headers="%s",signature="%s"`,
+		keyID, strings.Join(headers, " "), base64.StdEncoding.EncodeToString(signature)))
+	return nil
+}
+
+// Verify checks the signature on an incoming request and returns the keyId that
+// signed it. Requests with a body must sign its digest, and every request must
+// sign its target and date.
+func Verify(req *http.Request, body []byte, fetch KeyFetcher) (string, error) {
+	header := req.Header.Get("Signature")
+	if header == "" {
+		return "", ErrNoSignature
+	}
+	params := map[string]string{}
+	for _, match := range signatureParam.FindAllStringSubmatch(header, -1) {
+		params[match[1]] = match[2]
+	}
+	keyID := params["keyId"]
+	if keyID == "" || params["signature"] == "" {
+		return "", fmt.Errorf("signature is missing keyId or signature")
+	}
+	if algorithm := params["algorithm"]; algorithm != "" && algorithm != "rsa-sha256" && algorithm != "hs2019" {
+		return "", fmt.Errorf("unsupported signature algorithm %q", algorithm)
+	}
+	headers := strings.Fields(strings.ToLower(params["headers"]))
+	required := []string{"(request-target)", "date"}
+	if len(body) > 0 {
+		required = append(required, "digest")
+	}
+	for _, name := range required {
+		if !slices.Contains(headers, name) {
+			return "", fmt.Errorf("signature does not cover %s", name)
+		}
+	}
+	date, err := http.ParseTime(req.Header.Get("Date"))
+	if err != nil {
+		return "", fmt.Errorf("invalid Date header")
+	}
+	if skew := time.Since(date); skew > MaxClockSkew || skew < -MaxClockSkew {
+		return "", ErrStaleDate
+	}
+	if len(body) > 0 && req.Header.Get("Digest") != Digest(body) {
+		return "", ErrBadDigest
+	}
+	signature, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil {
+		return "", ErrBadSignature
+	}
+	signing, err := signingString(req, headers)
+	if err != nil {
+		return "", err
+	}
+	key, err := fetch(req.Context(), keyID)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256([]byte(signing))
+	if err = rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature); err != nil {
+		return "", ErrBadSignature
+	}
+	return keyID, nil
+}
+
+func signingString(req *http.Request, headers []string) (string, error) {
+	lines := make([]string, len(headers))
+	for i, name := range headers {
+		switch name {
+		case "(request-target)":
+			lines[i] = name + ": " + strings.ToLower(req.Method) + " " + req.URL.RequestURI()
+		case "host":
+			host := req.Host
+			if host == "" {
+				host = req.URL.Host
+			}
+			lines[i] = name + ": " + host
+		default:
+			values := req.Header.Values(name)
+			if len(values) == 0 {
+				return "", fmt.Errorf("signed header %s is missing", name)
+			}
+			lines[i] = name + ": " + strings.Join(values, ", ")
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
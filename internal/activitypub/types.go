@@ -0,0 +1,152 @@
+// Package activitypub holds the ActivityPub and WebFinger documents Chirpy
+// exchanges with other servers, and the HTTP Signatures that authenticate them.
+package activitypub
+
+import (
+	"encoding/json"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const ContentType = "application/activity+json"
+const LDContentType = `application/ld+json; profile="https://www.w3.org/ns/activitystreams"`
+const JRDContentType = "application/jrd+json"
+const Public = "https://www.w3.org/ns/activitystreams#Public"
+
+// Context is the @context of every top-level document we serve or send.
+var Context = []string{"https://www.w3.org/ns/activitystreams", "https://w3id.org/security/v1"}
+
+type Actor struct {
+	Context           any        `json:"@context,omitempty"`
+	ID                string     `json:"id"`
+	Type              string     `json:"type"`
+	PreferredUsername string     `json:"preferredUsername"`
+	Name              string     `json:"name,omitempty"`
+	Summary           string     `json:"summary,omitempty"`
+	URL               string     `json:"url,omitempty"`
+	Inbox             string     `json:"inbox"`
+	Outbox            string     `json:"outbox,omitempty"`
+	Followers         string     `json:"followers,omitempty"`
+	Following         string     `json:"following,omitempty"`
+	Endpoints         *Endpoints `json:"endpoints,omitempty"`
+	Icon              *Image     `json:"icon,omitempty"`
+	PublicKey         PublicKey  `json:"publicKey"`
+}
+
+type Endpoints struct {
+	SharedInbox string `json:"sharedInbox,omitempty"`
+}
+
+type Image struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type PublicKey struct {
+	ID           string `json:"id"`
+	Owner        string `json:"owner"`
+	PublicKeyPem string `json:"publicKeyPem"`
+}
+
+type Note struct {
+	Context      any        `json:"@context,omitempty"`
+	ID           string     `json:"id"`
+	Type         string     `json:"type"`
+	AttributedTo string     `json:"attributedTo"`
+	Content      string     `json:"content"`
+	InReplyTo    string     `json:"inReplyTo,omitempty"`
+	Published    time.Time  `json:"published"`
+	Updated      *time.Time `json:"updated,omitempty"`
+	URL          string     `json:"url,omitempty"`
+	To           []string   `json:"to,omitempty"`
+	Cc           []string   `json:"cc,omitempty"`
+}
+
+// Tombstone replaces a deleted object.
+type Tombstone struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// Activity is any activity. Its object is kept raw because servers send some
+// objects embedded and others as a bare id.
+type Activity struct {
+	Context   any             `json:"@context,omitempty"`
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Actor     string          `json:"actor"`
+	Object    json.RawMessage `json:"object"`
+	Published *time.Time      `json:"published,omitempty"`
+	To        []string        `json:"to,omitempty"`
+	Cc        []string        `json:"cc,omitempty"`
+}
+
+// object is enough of any embedded object to tell what it is.
+type object struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// ObjectID returns the id of the activity's object, whether it was embedded or not.
+func (activity Activity) ObjectID() string {
+	var id string
+	if err := json.Unmarshal(activity.Object, &id); err == nil {
+		return id
+	}
+	var embedded object
+	json.Unmarshal(activity.Object, &embedded)
+	return embedded.ID
+}
+
+// ObjectType returns the type of an embedded object, or "" for a bare id.
+func (activity Activity) ObjectType() string {
+	var embedded object
+	json.Unmarshal(activity.Object, &embedded)
+	return embedded.Type
+}
+
+type OrderedCollection struct {
+	Context    any    `json:"@context,omitempty"`
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	TotalItems int64  `json:"totalItems"`
+	First      string `json:"first,omitempty"`
+}
+
+type OrderedCollectionPage struct {
+	Context      any    `json:"@context,omitempty"`
+	ID           string `json:"id"`
+	Type         string `json:"type"`
+	PartOf       string `json:"partOf"`
+	Next         string `json:"next,omitempty"`
+	OrderedItems []any  `json:"orderedItems"`
+}
+
+type WebFinger struct {
+	Subject string          `json:"subject"`
+	Aliases []string        `json:"aliases,omitempty"`
+	Links   []WebFingerLink `json:"links"`
+}
+
+type WebFingerLink struct {
+	Rel  string `json:"rel"`
+	Type string `json:"type,omitempty"`
+	Href string `json:"href"`
+}
+
+var htmlBreak = regexp.MustCompile(`(?i)<br\s*/?>|</p>\s*<p[^>]*>`)
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// HTML renders a chirp's plain text as Note content.
+func HTML(text string) string {
+	return "<p>" + strings.ReplaceAll(html.EscapeString(text), "\n", "<br>") + "</p>"
+}
+
+// PlainText turns remote Note content into the plain text Chirpy stores, so that
+// markup from other servers never reaches our clients.
+func PlainText(content string) string {
+	text := htmlBreak.ReplaceAllString(content, "\n")
+	return strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(text, "")))
+}
@@ -41,12 +41,16 @@ func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (str
 	return tokenstr, nil
 }
 
-func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
+func parseJWT(tokenString, tokenSecret string) (*jwt.Token, error) {
 	byteSecret, err := base64.StdEncoding.DecodeString(tokenSecret)
 	if err != nil {
-		return uuid.UUID{}, err
+		return nil, err
 	}
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (any, error) { return byteSecret, nil })
+	return jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (any, error) { return byteSecret, nil })
+}
+
+func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
+	token, err := parseJWT(tokenString, tokenSecret)
 	if err != nil {
 		return uuid.UUID{}, err
 	}
@@ -57,6 +61,21 @@ func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
 	return uuid.Parse(uuidstr)
 }
 
+// JWTExpiry validates a token the same way as ValidateJWT and returns when it expires.
+func JWTExpiry(tokenString, tokenSecret string) (time.Time, error) {
+	token, err := parseJWT(tokenString, tokenSecret)
+	if err != nil {
+		return time.Time{}, err
+	}
+	expire, err := token.Claims.GetExpirationTime()
+	if err != nil {
+		return time.Time{}, err
+	} else if expire == nil {
+		return time.Time{}, fmt.Errorf("token has no expiry")
+	}
+	return expire.Time, nil
+}
+
 func GetBearerToken(headers http.Header) (string, error) {
 	headerTok := strings.SplitN(headers.Get("Authorization"), " ", 2)
 	if len(headerTok) < 2 || len(headerTok[1]) == 0 {
@@ -65,6 +84,15 @@ func GetBearerToken(headers http.Header) (string, error) {
 	return headerTok[1], nil
 }
 
+// GetAPIKey reads a key sent as "Authorization: ApiKey <key>".
+func GetAPIKey(headers http.Header) (string, error) {
+	scheme, key, found := strings.Cut(headers.Get("Authorization"), " ")
+	if !found || scheme != "ApiKey" || len(key) == 0 {
+		return "", fmt.Errorf("valid API key not found in header")
+	}
+	return key, nil
+}
+
 func MakeRefreshToken() string {
 	bytes := make([]byte, 32)
 	rand.Read(bytes)
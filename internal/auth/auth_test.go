@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"net/http"
 	"testing"
 	"time"
 
@@ -55,3 +56,41 @@ func TestJWTTimeout(t *testing.T) {
 	}
 
 }
+
+func TestJWTExpiry(t *testing.T) {
+	tokenstr, err := MakeJWT(uuid.New(), secret, time.Hour)
+	if err != nil {
+		t.Errorf("MakeJWT() returned error %v", err)
+		return
+	}
+	expire, err := JWTExpiry(tokenstr, secret)
+	if err != nil {
+		t.Errorf("JWTExpiry() returned error %v", err)
+		return
+	}
+	if until := time.Until(expire); until < 59*time.Minute || until > time.Hour {
+		t.Errorf("JWTExpiry() = %v, want about an hour from now", expire)
+	}
+	if _, err = JWTExpiry(tokenstr, "c2VrcmV0"); err == nil {
+		t.Errorf("JWTExpiry() accepted a token signed with another secret")
+	}
+}
+
+func TestGetAPIKey(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+		ok     bool
+	}{
+		{"ApiKey f271c81ff7084ee5b99a5091b42d486e", "f271c81ff7084ee5b99a5091b42d486e", true},
+		{"Bearer f271c81ff7084ee5b99a5091b42d486e", "", false},
+		{"ApiKey ", "", false},
+		{"", "", false},
+	}
+	for _, tc := range tests {
+		got, err := GetAPIKey(http.Header{"Authorization": []string{tc.header}})
+		if got != tc.want || (err == nil) != tc.ok {
+			t.Errorf("GetAPIKey(%q) = %q, %v", tc.header, got, err)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package broker fans out newly created chirps, direct messages and
+// notifications to everyone streaming them.
+package broker
+
+import (
+	"chirpy/internal/database"
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Topic is a stream of events that subscribers pick from, so that a chirp
+// stream is not filled with other people's messages and notifications.
+type Topic string
+
+// Chirps carries every new chirp.
+const Chirps Topic = "chirps"
+
+// User carries the messages and notifications for one user.
+func User(id uuid.UUID) Topic {
+	return Topic("user:" + id.String())
+}
+
+// Event is something that has just been committed. Exactly one of Chirp,
+// Message and Notification is set.
+type Event struct {
+	Chirp        *database.Chirp        `json:"chirp,omitempty"`
+	Message      *database.Message      `json:"message,omitempty"`
+	Notification *database.Notification `json:"notification,omitempty"`
+	// Recipients are the participants a Message is for.
+	Recipients []uuid.UUID `json:"recipients,omitempty"`
+}
+
+// Topics are the topics event is published on.
+func (event Event) Topics() []Topic {
+	switch {
+	case event.Chirp != nil:
+		return []Topic{Chirps}
+	case event.Notification != nil:
+		return []Topic{User(event.Notification.UserID)}
+	}
+	topics := make([]Topic, len(event.Recipients))
+	for i, id := range event.Recipients {
+		topics[i] = User(id)
+	}
+	return topics
+}
+
+// Broker delivers every published event to the current subscribers of its topics.
+type Broker interface {
+	// Publish announces an event once the write behind it has been committed.
+	Publish(ctx context.Context, event Event) error
+	// Subscribe starts receiving events on any of topics on a channel that
+	// holds up to buffer of them. A subscriber that lets its buffer fill is
+	// dropped and its channel closed rather than holding up everyone else; it
+	// can resume from the last event it saw. Calling cancel also closes the
+	// channel.
+	Subscribe(buffer int, topics ...Topic) (events <-chan Event, cancel func())
+}
+
+// Memory is a Broker for a single server process.
+type Memory struct {
+	mu     sync.Mutex
+	subs   map[Topic]map[chan Event]struct{}
+	topics map[chan Event][]Topic
+}
+
+func NewMemory() *Memory {
+	return &Memory{subs: map[Topic]map[chan Event]struct{}{}, topics: map[chan Event][]Topic{}}
+}
+
+func (b *Memory) Publish(_ context.Context, event Event) error {
+	b.deliver(event)
+	return nil
+}
+
+func (b *Memory) deliver(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sent := map[chan Event]bool{}
+	for _, topic := range event.Topics() {
+		for ch := range b.subs[topic] {
+			if sent[ch] {
+				continue
+			}
+			sent[ch] = true
+			select {
+			case ch <- event:
+			default:
+				b.drop(ch)
+			}
+		}
+	}
+}
+
+// listened reports whether anyone is subscribed to any of topics.
+func (b *Memory) listened(topics []Topic) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, topic := range topics {
+		if len(b.subs[topic]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// drop unsubscribes ch and closes it. b.mu must be held.
+func (b *Memory) drop(ch chan Event) {
+	for _, topic := range b.topics[ch] {
+		delete(b.subs[topic], ch)
+		if len(b.subs[topic]) == 0 {
+			delete(b.subs, topic)
+		}
+	}
+	delete(b.topics, ch)
+	close(ch)
+}
+
+func (b *Memory) Subscribe(buffer int, topics ...Topic) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+	b.mu.Lock()
+	b.topics[ch] = topics
+	for _, topic := range topics {
+		if b.subs[topic] == nil {
+			b.subs[topic] = map[chan Event]struct{}{}
+		}
+		b.subs[topic][ch] = struct{}{}
+	}
+	b.mu.Unlock()
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.topics[ch]; ok {
+			b.drop(ch)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package broker
+
+import (
+	"chirpy/internal/database"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestMemoryFanOut(t *testing.T) {
+	b := NewMemory()
+	first, cancelFirst := b.Subscribe(1, Chirps)
+	defer cancelFirst()
+	second, cancelSecond := b.Subscribe(1, Chirps)
+	defer cancelSecond()
+	chirp := database.Chirp{ID: uuid.New(), Body: "hello"}
+	b.Publish(context.Background(), Event{Chirp: &chirp})
+	for _, ch := range []<-chan Event{first, second} {
+		if got := <-ch; got.Chirp == nil || got.Chirp.ID != chirp.ID {
+			t.Errorf("received %+v, want chirp %v", got, chirp.ID)
+		}
+	}
+}
+
+func TestMemoryDropsSlowSubscriber(t *testing.T) {
+	b := NewMemory()
+	slow, cancelSlow := b.Subscribe(1, Chirps)
+	defer cancelSlow()
+	fast, cancelFast := b.Subscribe(2, Chirps)
+	defer cancelFast()
+	b.Publish(context.Background(), Event{Chirp: &database.Chirp{ID: uuid.New()}})
+	b.Publish(context.Background(), Event{Chirp: &database.Chirp{ID: uuid.New()}})
+	if _, ok := <-slow; !ok {
+		t.Fatal("slow subscriber lost the chirp that fit its buffer")
+	}
+	if _, ok := <-slow; ok {
+		t.Error("slow subscriber was not dropped when its buffer overflowed")
+	}
+	for i := 0; i < 2; i++ {
+		if _, ok := <-fast; !ok {
+			t.Fatal("fast subscriber was dropped")
+		}
+	}
+}
+
+func TestMemoryCancel(t *testing.T) {
+	b := NewMemory()
+	ch, cancel := b.Subscribe(1, Chirps)
+	cancel()
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Error("channel still open after cancel")
+	}
+	b.Publish(context.Background(), Event{Chirp: &database.Chirp{ID: uuid.New()}})
+}
+
+func TestMemoryTopics(t *testing.T) {
+	b := NewMemory()
+	user := uuid.New()
+	chirps, cancelChirps := b.Subscribe(1, Chirps)
+	defer cancelChirps()
+	mine, cancelMine := b.Subscribe(2, Chirps, User(user))
+	defer cancelMine()
+	message := database.Message{ID: uuid.New(), Body: "psst"}
+	b.Publish(context.Background(), Event{Message: &message, Recipients: []uuid.UUID{uuid.New(), user}})
+	b.Publish(context.Background(), Event{Notification: &database.Notification{ID: uuid.New(), UserID: uuid.New()}})
+	b.Publish(context.Background(), Event{Chirp: &database.Chirp{ID: uuid.New()}})
+	if got := <-chirps; got.Chirp == nil {
+		t.Errorf("chirp subscriber received %+v, want only the chirp", got)
+	}
+	if got := <-mine; got.Message == nil || got.Message.ID != message.ID {
+		t.Errorf("recipient received %+v first, want message %v", got, message.ID)
+	}
+	if got := <-mine; got.Chirp == nil {
+		t.Errorf("recipient received %+v, want the chirp and not someone else's notification", got)
+	}
+}
+
+func TestNoticeLeavesOutMessageBody(t *testing.T) {
+	message := database.Message{ID: uuid.New(), Body: "psst"}
+	payload, err := encodeNotice(Event{Message: &message, Recipients: []uuid.UUID{uuid.New()}})
+	if err != nil {
+		t.Fatalf("encodeNotice() returned error %v", err)
+	}
+	if strings.Contains(string(payload), "psst") || !strings.Contains(string(payload), message.ID.String()) {
+		t.Errorf("notice = %s, want the message ID and not its body", payload)
+	}
+}
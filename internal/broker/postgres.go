@@ -0,0 +1,102 @@
+package broker
+
+import (
+	"chirpy/internal/database"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// channel must match the one PublishEvent notifies.
+const channel = "chirpy_events"
+
+const minReconnect = 10 * time.Second
+const maxReconnect = time.Minute
+
+// Postgres is a Broker shared by every server process using the same database.
+// Events are published with NOTIFY and each process LISTENs for them, then fans
+// them out to its own subscribers.
+type Postgres struct {
+	local    *Memory
+	queries  *database.Queries
+	listener *pq.Listener
+}
+
+// notice is an Event as NOTIFY carries it to every process. A direct message
+// goes by ID so that its body is not broadcast, and is loaded again by the
+// processes with someone to deliver it to.
+type notice struct {
+	Chirp        *database.Chirp        `json:"chirp,omitempty"`
+	MessageID    *uuid.UUID             `json:"message_id,omitempty"`
+	Notification *database.Notification `json:"notification,omitempty"`
+	Recipients   []uuid.UUID            `json:"recipients,omitempty"`
+}
+
+// NewPostgres starts listening on a dedicated connection to dbURL. queries is
+// used to publish, and to load messages.
+func NewPostgres(queries *database.Queries, dbURL string) (*Postgres, error) {
+	listener := pq.NewListener(dbURL, minReconnect, maxReconnect, nil)
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	b := &Postgres{local: NewMemory(), queries: queries, listener: listener}
+	go b.run()
+	return b, nil
+}
+
+func (b *Postgres) run() {
+	for n := range b.listener.Notify {
+		// A nil notification means the connection was re-established, and
+		// anything sent in between is lost. Subscribers catch up by resuming.
+		if n == nil {
+			continue
+		}
+		var note notice
+		if err := json.Unmarshal([]byte(n.Extra), &note); err != nil {
+			continue
+		}
+		event := Event{Chirp: note.Chirp, Notification: note.Notification, Recipients: note.Recipients}
+		if note.MessageID != nil {
+			if !b.local.listened(event.Topics()) {
+				continue
+			}
+			message, err := b.queries.GetMessage(context.Background(), *note.MessageID)
+			if err != nil {
+				continue
+			}
+			event.Message = &message
+		}
+		b.local.deliver(event)
+	}
+}
+
+func encodeNotice(event Event) ([]byte, error) {
+	note := notice{Chirp: event.Chirp, Notification: event.Notification, Recipients: event.Recipients}
+	if event.Message != nil {
+		note.MessageID = &event.Message.ID
+	}
+	return json.Marshal(note)
+}
+
+// Publish notifies every listening process, this one included. Postgres caps
+// payloads at 8000 bytes, far above any notice's.
+func (b *Postgres) Publish(ctx context.Context, event Event) error {
+	payload, err := encodeNotice(event)
+	if err != nil {
+		return err
+	}
+	return b.queries.PublishEvent(ctx, string(payload))
+}
+
+func (b *Postgres) Subscribe(buffer int, topics ...Topic) (<-chan Event, func()) {
+	return b.local.Subscribe(buffer, topics...)
+}
+
+// Close stops listening. Subscribers stay open but receive nothing more.
+func (b *Postgres) Close() error {
+	return b.listener.Close()
+}
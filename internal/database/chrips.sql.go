@@ -7,8 +7,11 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createChirp = `-- name: CreateChirp :one
@@ -16,7 +19,7 @@ INSERT INTO chirps (id, created_at, updated_at, body, user_id)
 VALUES (
     gen_random_uuid(), NOW(), NOW(), $1, $2
 )
-RETURNING id, created_at, updated_at, body, user_id
+RETURNING id, created_at, updated_at, body, user_id, kind, ref_chirp_id
 `
 
 type CreateChirpParams struct {
@@ -33,6 +36,43 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.Kind,
+		&i.RefChirpID,
+	)
+	return i, err
+}
+
+const createRefChirp = `-- name: CreateRefChirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, kind, ref_chirp_id)
+VALUES (
+    gen_random_uuid(), NOW(), NOW(), $1, $2, $3, $4
+)
+RETURNING id, created_at, updated_at, body, user_id, kind, ref_chirp_id
+`
+
+type CreateRefChirpParams struct {
+	Body       string
+	UserID     uuid.UUID
+	Kind       string
+	RefChirpID uuid.NullUUID
+}
+
+func (q *Queries) CreateRefChirp(ctx context.Context, arg CreateRefChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, createRefChirp,
+		arg.Body,
+		arg.UserID,
+		arg.Kind,
+		arg.RefChirpID,
+	)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.Kind,
+		&i.RefChirpID,
 	)
 	return i, err
 }
@@ -60,7 +100,7 @@ func (q *Queries) DeleteChirp(ctx context.Context, arg DeleteChirpParams) (Delet
 }
 
 const getChirp = `-- name: GetChirp :one
-SELECT id, created_at, updated_at, body, user_id FROM chirps WHERE id = $1
+SELECT id, created_at, updated_at, body, user_id, kind, ref_chirp_id FROM chirps WHERE id = $1
 `
 
 func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
@@ -72,16 +112,37 @@ func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.Kind,
+		&i.RefChirpID,
 	)
 	return i, err
 }
 
-const getChirps = `-- name: GetChirps :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps ORDER BY created_at ASC
+const getChirpsAfter = `-- name: GetChirpsAfter :many
+SELECT id, created_at, updated_at, body, user_id, kind, ref_chirp_id FROM chirps
+WHERE (created_at, id) > ($1::timestamp, $2::uuid) AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = $3 AND blocks.blocked_id = chirps.user_id)
+        OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $3)
+)
+ORDER BY created_at ASC, id ASC
+LIMIT $4
 `
 
-func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirps)
+type GetChirpsAfterParams struct {
+	AfterTime time.Time
+	AfterID   uuid.UUID
+	ViewerID  uuid.NullUUID
+	PageSize  int32
+}
+
+func (q *Queries) GetChirpsAfter(ctx context.Context, arg GetChirpsAfterParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsAfter,
+		arg.AfterTime,
+		arg.AfterID,
+		arg.ViewerID,
+		arg.PageSize,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -95,6 +156,8 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.Kind,
+			&i.RefChirpID,
 		); err != nil {
 			return nil, err
 		}
@@ -108,3 +171,274 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 	}
 	return items, nil
 }
+
+const getChirpsByAuthors = `-- name: GetChirpsByAuthors :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.kind, chirps.ref_chirp_id FROM unnest($1::uuid[]) AS authors(id)
+CROSS JOIN LATERAL (
+    SELECT id, created_at, updated_at, body, user_id, kind, ref_chirp_id FROM chirps
+    WHERE chirps.user_id = authors.id
+        AND (chirps.created_at, chirps.id) < ($2::timestamp, $3::uuid)
+        AND NOT EXISTS (
+            SELECT 1 FROM blocks
+            WHERE (blocks.blocker_id = $4 AND blocks.blocked_id = chirps.user_id)
+                OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $4)
+        )
+    ORDER BY chirps.created_at DESC, chirps.id DESC
+    LIMIT $5
+) AS chirps
+`
+
+type GetChirpsByAuthorsParams struct {
+	UserIds    []uuid.UUID
+	BeforeTime time.Time
+	BeforeID   uuid.UUID
+	ViewerID   uuid.NullUUID
+	PageSize   int32
+}
+
+func (q *Queries) GetChirpsByAuthors(ctx context.Context, arg GetChirpsByAuthorsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByAuthors,
+		pq.Array(arg.UserIds),
+		arg.BeforeTime,
+		arg.BeforeID,
+		arg.ViewerID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.Kind,
+			&i.RefChirpID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsByIDs = `-- name: GetChirpsByIDs :many
+SELECT id, created_at, updated_at, body, user_id, kind, ref_chirp_id FROM chirps WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) GetChirpsByIDs(ctx context.Context, ids []uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.Kind,
+			&i.RefChirpID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNewestChirps = `-- name: GetNewestChirps :many
+SELECT id, created_at, updated_at, body, user_id, kind, ref_chirp_id FROM chirps
+WHERE NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = $1 AND blocks.blocked_id = chirps.user_id)
+        OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $1)
+) AND ($2::uuid IS NULL OR chirps.user_id = $2)
+    AND (NOT $3::boolean OR chirps.kind <> 'rechirp')
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type GetNewestChirpsParams struct {
+	ViewerID      uuid.NullUUID
+	AuthorID      uuid.NullUUID
+	OriginalsOnly bool
+	MaxChirps     sql.NullInt32
+}
+
+// Newest first, so a listing that only shows the latest few reads no more than
+// that; a NULL max_chirps reads them all.
+func (q *Queries) GetNewestChirps(ctx context.Context, arg GetNewestChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getNewestChirps,
+		arg.ViewerID,
+		arg.AuthorID,
+		arg.OriginalsOnly,
+		arg.MaxChirps,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.Kind,
+			&i.RefChirpID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReplies = `-- name: GetReplies :many
+SELECT id, created_at, updated_at, body, user_id, kind, ref_chirp_id FROM chirps
+WHERE ref_chirp_id = $1::uuid AND kind = 'reply' AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = $2 AND blocks.blocked_id = chirps.user_id)
+        OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $2)
+)
+ORDER BY created_at ASC
+`
+
+type GetRepliesParams struct {
+	ChirpID  uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+func (q *Queries) GetReplies(ctx context.Context, arg GetRepliesParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getReplies, arg.ChirpID, arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.Kind,
+			&i.RefChirpID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRepliesTo = `-- name: GetRepliesTo :many
+SELECT id, created_at, updated_at, body, user_id, kind, ref_chirp_id FROM chirps
+WHERE ref_chirp_id = ANY($1::uuid[]) AND kind = 'reply' AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = $2 AND blocks.blocked_id = chirps.user_id)
+        OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $2)
+)
+ORDER BY created_at ASC, id ASC
+`
+
+type GetRepliesToParams struct {
+	ChirpIds []uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+func (q *Queries) GetRepliesTo(ctx context.Context, arg GetRepliesToParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getRepliesTo, pq.Array(arg.ChirpIds), arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.Kind,
+			&i.RefChirpID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateChirpBody = `-- name: UpdateChirpBody :one
+UPDATE chirps SET body = $1, updated_at = NOW() WHERE id = $2 AND user_id = $3
+RETURNING id, created_at, updated_at, body, user_id, kind, ref_chirp_id
+`
+
+type UpdateChirpBodyParams struct {
+	Body   string
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) UpdateChirpBody(ctx context.Context, arg UpdateChirpBodyParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateChirpBody, arg.Body, arg.ID, arg.UserID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.Kind,
+		&i.RefChirpID,
+	)
+	return i, err
+}
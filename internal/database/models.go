@@ -11,12 +11,131 @@ import (
 	"github.com/google/uuid"
 )
 
+type ActorKey struct {
+	UserID        uuid.UUID
+	PublicKeyPem  string
+	PrivateKeyPem string
+	CreatedAt     time.Time
+}
+
+type Block struct {
+	BlockerID uuid.UUID
+	BlockedID uuid.UUID
+	CreatedAt time.Time
+}
+
 type Chirp struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Body       string
+	UserID     uuid.UUID
+	Kind       string
+	RefChirpID uuid.NullUUID
+}
+
+type ChirpHashtag struct {
+	ChirpID   uuid.UUID
+	Tag       string
+	CreatedAt time.Time
+}
+
+type ChirpLike struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpMention struct {
+	ChirpID     uuid.UUID
+	UserID      uuid.UUID
+	StartOffset int32
+	EndOffset   int32
+}
+
+type Conversation struct {
 	ID        uuid.UUID
+	IsGroup   bool
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	Body      string
+}
+
+type ConversationParticipant struct {
+	ConversationID uuid.UUID
+	UserID         uuid.UUID
+	JoinedAt       time.Time
+	LastReadAt     sql.NullTime
+}
+
+type FederationDelivery struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	Inbox         string
+	Payload       string
+	Status        string
+	Attempts      int32
+	NextAttemptAt time.Time
+	LastError     sql.NullString
+	CreatedAt     time.Time
+}
+
+type Follow struct {
+	UserID     uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  time.Time
+}
+
+type IdempotencyKey struct {
+	UserID              uuid.UUID
+	Key                 string
+	RequestHash         string
+	ResponseStatus      sql.NullInt32
+	ResponseContentType sql.NullString
+	ResponseBody        sql.NullString
+	LockedUntil         time.Time
+	CreatedAt           time.Time
+}
+
+type Message struct {
+	ID             uuid.UUID
+	ConversationID uuid.UUID
+	SenderID       uuid.NullUUID
+	Body           string
+	CreatedAt      time.Time
+}
+
+type Mute struct {
+	MuterID   uuid.UUID
+	MutedID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type MutedWord struct {
+	ID        uuid.UUID
 	UserID    uuid.UUID
+	Phrase    string
+	WholeWord bool
+	Action    string
+	ExpiresAt sql.NullTime
+	CreatedAt time.Time
+}
+
+type Notification struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	ActorID   uuid.UUID
+	Type      string
+	ChirpID   uuid.NullUUID
+	GroupKey  uuid.UUID
+	CreatedAt time.Time
+	ReadAt    sql.NullTime
+}
+
+type PolkaEvent struct {
+	ID         string
+	Event      string
+	UserID     uuid.UUID
+	ReceivedAt time.Time
 }
 
 type RefreshToken struct {
@@ -28,10 +147,87 @@ type RefreshToken struct {
 	RevokedAt sql.NullTime
 }
 
+type RemoteActor struct {
+	ID           uuid.UUID
+	Uri          string
+	Handle       string
+	Domain       string
+	DisplayName  string
+	Inbox        string
+	SharedInbox  sql.NullString
+	KeyID        string
+	PublicKeyPem string
+	FetchedAt    time.Time
+}
+
+type RemoteFollower struct {
+	UserID    uuid.UUID
+	ActorID   uuid.UUID
+	FollowUri string
+	CreatedAt time.Time
+}
+
+type RemoteFollowing struct {
+	UserID    uuid.UUID
+	ActorID   uuid.UUID
+	FollowUri string
+	Accepted  bool
+	CreatedAt time.Time
+}
+
+type RemotePost struct {
+	ID          uuid.UUID
+	Uri         string
+	ActorID     uuid.UUID
+	Body        string
+	Url         string
+	InReplyTo   sql.NullString
+	PublishedAt time.Time
+	CreatedAt   time.Time
+}
+
+type TrendingHashtag struct {
+	TimeWindow string
+	Tag        string
+	Score      float64
+	Uses       int64
+	UpdatedAt  time.Time
+}
+
 type User struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Email           string
+	HashedPassword  string
+	Handle          string
+	HandleChangedAt sql.NullTime
+	DisplayName     string
+	Bio             string
+	AvatarUrl       string
+	IsChirpyRed     bool
+}
+
+type Webhook struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Url        string
+	EventTypes []string
+	Secret     string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type WebhookDelivery struct {
 	ID             uuid.UUID
+	WebhookID      uuid.UUID
+	EventType      string
+	Payload        string
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	LastAttemptAt  sql.NullTime
+	ResponseStatus sql.NullInt32
+	LastError      sql.NullString
 	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	Email          string
-	HashedPassword string
 }
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+)
+
+// The timeline benchmark needs a migrated Postgres database, named by this
+// variable. The first run seeds it with benchUsers users who each follow
+// benchFollows others, and benchChirps chirps spread over their authors.
+const benchDBEnv = "CHIRPY_BENCH_DB_URL"
+const benchUsers = 10000
+const benchFollows = 100
+const benchChirps = 1000000
+
+const seedBenchUsers = `
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, handle)
+SELECT gen_random_uuid(), NOW(), NOW(), 'bench-' || n || '@example.com', 'unset', 'bench_' || n
+FROM generate_series(0, $1 - 1) AS n
+ON CONFLICT DO NOTHING`
+
+const seedBenchFollows = `
+WITH bench AS (
+    SELECT id, substring(email FROM 'bench-(\d+)@')::int AS n FROM users WHERE email LIKE 'bench-%@example.com'
+)
+INSERT INTO follows (user_id, followee_id, created_at)
+SELECT a.id, b.id, NOW()
+FROM bench a CROSS JOIN generate_series(1, $2) AS k
+JOIN bench b ON b.n = (a.n + k * 97) % $1
+ON CONFLICT DO NOTHING`
+
+const seedBenchChirps = `
+WITH bench AS (
+    SELECT id, substring(email FROM 'bench-(\d+)@')::int AS n FROM users WHERE email LIKE 'bench-%@example.com'
+)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+SELECT gen_random_uuid(), NOW() - s * INTERVAL '30 seconds', NOW() - s * INTERVAL '30 seconds', 'bench chirp ' || s, bench.id
+FROM generate_series(1, $2) AS s
+JOIN bench ON bench.n = s % $1`
+
+func benchDB(b *testing.B) *sql.DB {
+	dbURL := os.Getenv(benchDBEnv)
+	if dbURL == "" {
+		b.Skipf("%s not set", benchDBEnv)
+	}
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var seeded int
+	if err = db.QueryRow(`SELECT COUNT(*) FROM users WHERE email LIKE 'bench-%@example.com'`).Scan(&seeded); err != nil {
+		b.Fatal(err)
+	}
+	if seeded < benchUsers {
+		b.Logf("seeding %d users and %d chirps", benchUsers, benchChirps)
+		if _, err = db.Exec(seedBenchUsers, benchUsers); err != nil {
+			b.Fatal(err)
+		}
+		if _, err = db.Exec(seedBenchFollows, benchUsers, benchFollows); err != nil {
+			b.Fatal(err)
+		}
+		if _, err = db.Exec(seedBenchChirps, benchUsers, benchChirps); err != nil {
+			b.Fatal(err)
+		}
+		if _, err = db.Exec(`ANALYZE`); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return db
+}
+
+func BenchmarkTimeline(b *testing.B) {
+	db := benchDB(b)
+	defer db.Close()
+	ctx := context.Background()
+	var userID uuid.UUID
+	if err := db.QueryRow(`SELECT id FROM users WHERE email = 'bench-0@example.com'`).Scan(&userID); err != nil {
+		b.Fatal(err)
+	}
+	q := New(db)
+	first := GetTimelineParams{UserID: userID, BeforeTime: time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC),
+		BeforeID: uuid.Max, PageSize: 20}
+
+	b.Run("first page", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := q.GetTimeline(ctx, first); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("deep page", func(b *testing.B) {
+		deep := first
+		for range 50 {
+			page, err := q.GetTimeline(ctx, deep)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if len(page) == 0 {
+				break
+			}
+			deep.BeforeTime, deep.BeforeID = page[len(page)-1].CreatedAt, page[len(page)-1].ID
+		}
+		for b.Loop() {
+			if _, err := q.GetTimeline(ctx, deep); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
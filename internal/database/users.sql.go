@@ -7,45 +7,52 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password)
-VALUES (
-    gen_random_uuid(), NOW(), NOW(), $1, $2
-)
-RETURNING id, created_at, updated_at, email
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, handle)
+SELECT id, NOW(), NOW(), $1, $2, COALESCE(NULLIF($3::text, ''), 'user_' || substr(replace(id::text, '-', ''), 1, 12))
+FROM (SELECT gen_random_uuid() AS id) AS new_user
+RETURNING id, created_at, updated_at, email, handle, is_chirpy_red
 `
 
 type CreateUserParams struct {
 	Email          string
 	HashedPassword string
+	Handle         string
 }
 
 type CreateUserRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Email     string
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Email       string
+	Handle      string
+	IsChirpyRed bool
 }
 
+// An empty handle gets the default migration 008 gave existing users.
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error) {
-	row := q.db.QueryRowContext(ctx, createUser, arg.Email, arg.HashedPassword)
+	row := q.db.QueryRowContext(ctx, createUser, arg.Email, arg.HashedPassword, arg.Handle)
 	var i CreateUserRow
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Email,
+		&i.Handle,
+		&i.IsChirpyRed,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email, hashed_password FROM users WHERE email = $1
+SELECT id, created_at, updated_at, email, hashed_password, handle, handle_changed_at, display_name, bio, avatar_url, is_chirpy_red FROM users WHERE email = $1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -57,10 +64,172 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.UpdatedAt,
 		&i.Email,
 		&i.HashedPassword,
+		&i.Handle,
+		&i.HandleChangedAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.IsChirpyRed,
+	)
+	return i, err
+}
+
+const getUserByHandle = `-- name: GetUserByHandle :one
+SELECT id, created_at, updated_at, email, hashed_password, handle, handle_changed_at, display_name, bio, avatar_url, is_chirpy_red FROM users WHERE lower(handle) = lower($1)
+`
+
+func (q *Queries) GetUserByHandle(ctx context.Context, handle string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByHandle, handle)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.Handle,
+		&i.HandleChangedAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.IsChirpyRed,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, created_at, updated_at, email, hashed_password, handle, handle_changed_at, display_name, bio, avatar_url, is_chirpy_red FROM users WHERE id = $1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.Handle,
+		&i.HandleChangedAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.IsChirpyRed,
 	)
 	return i, err
 }
 
+const getUserHandles = `-- name: GetUserHandles :many
+SELECT id, handle FROM users WHERE id = ANY($1::uuid[])
+`
+
+type GetUserHandlesRow struct {
+	ID     uuid.UUID
+	Handle string
+}
+
+func (q *Queries) GetUserHandles(ctx context.Context, ids []uuid.UUID) ([]GetUserHandlesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserHandles, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserHandlesRow
+	for rows.Next() {
+		var i GetUserHandlesRow
+		if err := rows.Scan(&i.ID, &i.Handle); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserProfile = `-- name: GetUserProfile :one
+SELECT users.id, users.created_at, users.handle, users.display_name, users.bio, users.avatar_url,
+    (SELECT COUNT(*) FROM chirps WHERE chirps.user_id = users.id) AS chirp_count
+FROM users WHERE users.id = $1
+`
+
+type GetUserProfileRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	Handle      string
+	DisplayName string
+	Bio         string
+	AvatarUrl   string
+	ChirpCount  int64
+}
+
+func (q *Queries) GetUserProfile(ctx context.Context, id uuid.UUID) (GetUserProfileRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserProfile, id)
+	var i GetUserProfileRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.ChirpCount,
+	)
+	return i, err
+}
+
+const getUserProfiles = `-- name: GetUserProfiles :many
+SELECT users.id, users.created_at, users.handle, users.display_name, users.bio, users.avatar_url,
+    (SELECT COUNT(*) FROM chirps WHERE chirps.user_id = users.id) AS chirp_count
+FROM users WHERE users.id = ANY($1::uuid[])
+`
+
+type GetUserProfilesRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	Handle      string
+	DisplayName string
+	Bio         string
+	AvatarUrl   string
+	ChirpCount  int64
+}
+
+func (q *Queries) GetUserProfiles(ctx context.Context, ids []uuid.UUID) ([]GetUserProfilesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserProfiles, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserProfilesRow
+	for rows.Next() {
+		var i GetUserProfilesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.Handle,
+			&i.DisplayName,
+			&i.Bio,
+			&i.AvatarUrl,
+			&i.ChirpCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const resetUsers = `-- name: ResetUsers :exec
 DELETE FROM users
 `
@@ -70,9 +239,26 @@ func (q *Queries) ResetUsers(ctx context.Context) error {
 	return err
 }
 
+const setChirpyRed = `-- name: SetChirpyRed :execrows
+UPDATE users SET is_chirpy_red = $1, updated_at = NOW() WHERE id = $2
+`
+
+type SetChirpyRedParams struct {
+	IsChirpyRed bool
+	ID          uuid.UUID
+}
+
+func (q *Queries) SetChirpyRed(ctx context.Context, arg SetChirpyRedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setChirpyRed, arg.IsChirpyRed, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users SET email = $1, updated_at = NOW(), hashed_password = $2 WHERE id = $3
-RETURNING id, created_at, updated_at, email
+RETURNING id, created_at, updated_at, email, handle, is_chirpy_red
 `
 
 type UpdateUserParams struct {
@@ -82,10 +268,12 @@ type UpdateUserParams struct {
 }
 
 type UpdateUserRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Email     string
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Email       string
+	Handle      string
+	IsChirpyRed bool
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateUserRow, error) {
@@ -96,6 +284,64 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateU
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Email,
+		&i.Handle,
+		&i.IsChirpyRed,
 	)
 	return i, err
 }
+
+const updateUserHandle = `-- name: UpdateUserHandle :one
+UPDATE users SET handle = $1, handle_changed_at = NOW(), updated_at = NOW() WHERE id = $2
+RETURNING id, created_at, updated_at, email, handle, is_chirpy_red
+`
+
+type UpdateUserHandleParams struct {
+	Handle string
+	ID     uuid.UUID
+}
+
+type UpdateUserHandleRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Email       string
+	Handle      string
+	IsChirpyRed bool
+}
+
+func (q *Queries) UpdateUserHandle(ctx context.Context, arg UpdateUserHandleParams) (UpdateUserHandleRow, error) {
+	row := q.db.QueryRowContext(ctx, updateUserHandle, arg.Handle, arg.ID)
+	var i UpdateUserHandleRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.Handle,
+		&i.IsChirpyRed,
+	)
+	return i, err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :exec
+UPDATE users SET display_name = COALESCE($1, display_name), bio = COALESCE($2, bio),
+    avatar_url = COALESCE($3, avatar_url), updated_at = NOW()
+WHERE id = $4
+`
+
+type UpdateUserProfileParams struct {
+	DisplayName sql.NullString
+	Bio         sql.NullString
+	AvatarUrl   sql.NullString
+	ID          uuid.UUID
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserProfile,
+		arg.DisplayName,
+		arg.Bio,
+		arg.AvatarUrl,
+		arg.ID,
+	)
+	return err
+}
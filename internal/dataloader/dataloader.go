@@ -0,0 +1,89 @@
+// Package dataloader batches lookups by key. Loads only queue their key; the
+// first time any queued result is needed, every queued key is fetched with a
+// single call, so resolving a field on each item of a list costs one query
+// rather than one per item.
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNotFound is the error for a key the batch function returned nothing for.
+var ErrNotFound = errors.New("not found")
+
+// BatchFunc fetches the values for keys. Keys it leaves out of the map are
+// not found.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+type result[V any] struct {
+	value V
+	err   error
+}
+
+// Loader batches and caches the lookups of one request. It must not outlive
+// the request, since it never forgets a result.
+type Loader[K comparable, V any] struct {
+	batch   BatchFunc[K, V]
+	mu      sync.Mutex
+	pending []K
+	queued  map[K]bool
+	results map[K]result[V]
+	batches int
+}
+
+func New[K comparable, V any](batch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{batch: batch, queued: map[K]bool{}, results: map[K]result[V]{}}
+}
+
+// Load queues key and returns a thunk that waits for its value. Calling the
+// thunk fetches everything queued so far that has not been fetched yet.
+func (loader *Loader[K, V]) Load(ctx context.Context, key K) func() (V, error) {
+	loader.mu.Lock()
+	if _, done := loader.results[key]; !done && !loader.queued[key] {
+		loader.queued[key] = true
+		loader.pending = append(loader.pending, key)
+	}
+	loader.mu.Unlock()
+	return func() (V, error) {
+		loader.mu.Lock()
+		defer loader.mu.Unlock()
+		if _, done := loader.results[key]; !done {
+			loader.dispatch(ctx)
+		}
+		found := loader.results[key]
+		return found.value, found.err
+	}
+}
+
+// Prime caches a value that was fetched some other way.
+func (loader *Loader[K, V]) Prime(key K, value V) {
+	loader.mu.Lock()
+	defer loader.mu.Unlock()
+	loader.results[key] = result[V]{value: value}
+}
+
+// Batches reports how many times the batch function has been called.
+func (loader *Loader[K, V]) Batches() int {
+	loader.mu.Lock()
+	defer loader.mu.Unlock()
+	return loader.batches
+}
+
+// dispatch fetches every pending key. The caller holds mu.
+func (loader *Loader[K, V]) dispatch(ctx context.Context) {
+	keys := loader.pending
+	loader.pending, loader.queued = nil, map[K]bool{}
+	loader.batches++
+	values, err := loader.batch(ctx, keys)
+	for _, key := range keys {
+		if err != nil {
+			loader.results[key] = result[V]{err: err}
+		} else if value, ok := values[key]; ok {
+			loader.results[key] = result[V]{value: value}
+		} else {
+			loader.results[key] = result[V]{err: ErrNotFound}
+		}
+	}
+}
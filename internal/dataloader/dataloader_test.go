@@ -0,0 +1,79 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestLoadBatchesQueuedKeys(t *testing.T) {
+	var calls [][]int
+	loader := New(func(ctx context.Context, keys []int) (map[int]string, error) {
+		calls = append(calls, keys)
+		values := map[int]string{}
+		for _, key := range keys {
+			if key != 3 {
+				values[key] = string(rune('a' + key))
+			}
+		}
+		return values, nil
+	})
+	ctx := context.Background()
+	first, second, again, missing := loader.Load(ctx, 0), loader.Load(ctx, 1), loader.Load(ctx, 0), loader.Load(ctx, 3)
+	if got, err := second(); got != "b" || err != nil {
+		t.Errorf("second() = %q, %v", got, err)
+	}
+	if got, err := first(); got != "a" || err != nil {
+		t.Errorf("first() = %q, %v", got, err)
+	}
+	if got, err := again(); got != "a" || err != nil {
+		t.Errorf("again() = %q, %v", got, err)
+	}
+	if _, err := missing(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing() error = %v, want ErrNotFound", err)
+	}
+	if len(calls) != 1 || !slices.Equal(calls[0], []int{0, 1, 3}) {
+		t.Fatalf("batches = %v, want one of [0 1 3]", calls)
+	}
+
+	// Cached keys are not fetched again; new ones are batched afresh.
+	cached, fresh := loader.Load(ctx, 1), loader.Load(ctx, 2)
+	if got, _ := cached(); got != "b" {
+		t.Errorf("cached() = %q", got)
+	}
+	if got, _ := fresh(); got != "c" {
+		t.Errorf("fresh() = %q", got)
+	}
+	if loader.Batches() != 2 || !slices.Equal(calls[1], []int{2}) {
+		t.Errorf("batches = %v", calls)
+	}
+}
+
+func TestLoadSharesBatchError(t *testing.T) {
+	boom := errors.New("boom")
+	loader := New(func(ctx context.Context, keys []string) (map[string]int, error) {
+		return nil, boom
+	})
+	a, b := loader.Load(context.Background(), "a"), loader.Load(context.Background(), "b")
+	if _, err := a(); err != boom {
+		t.Errorf("a() error = %v", err)
+	}
+	if _, err := b(); err != boom {
+		t.Errorf("b() error = %v", err)
+	}
+	if loader.Batches() != 1 {
+		t.Errorf("Batches() = %d, want 1", loader.Batches())
+	}
+}
+
+func TestPrime(t *testing.T) {
+	loader := New(func(ctx context.Context, keys []int) (map[int]int, error) {
+		t.Fatalf("fetched %v despite priming", keys)
+		return nil, nil
+	})
+	loader.Prime(7, 49)
+	if got, err := loader.Load(context.Background(), 7)(); got != 49 || err != nil {
+		t.Errorf("Load(7)() = %d, %v", got, err)
+	}
+}
@@ -0,0 +1,38 @@
+package entities
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const MinHandleLen = 3
+
+// reservedHandles may not be claimed by users, whatever their case, so they
+// cannot pass themselves off as the service or collide with a route.
+var reservedHandles = []string{"admin", "administrator", "api", "app", "chirpy", "help", "login",
+	"logout", "me", "mod", "moderator", "official", "root", "security", "settings", "signup", "staff", "support",
+	"system", "user", "users"}
+
+// ValidateHandle checks that handle is between MinHandleLen and MaxHandleLen
+// characters of ASCII letters, digits and underscores, contains at least one
+// letter and is not reserved.
+func ValidateHandle(handle string) error {
+	if len(handle) < MinHandleLen || len(handle) > MaxHandleLen {
+		return fmt.Errorf("handle must be between %d and %d characters", MinHandleLen, MaxHandleLen)
+	}
+	hasLetter := false
+	for _, r := range handle {
+		if !IsHandleRune(r) {
+			return fmt.Errorf("handle may only contain letters, digits and underscores")
+		}
+		hasLetter = hasLetter || (r != '_' && (r < '0' || r > '9'))
+	}
+	if !hasLetter {
+		return fmt.Errorf("handle must contain a letter")
+	}
+	if slices.Contains(reservedHandles, strings.ToLower(handle)) {
+		return fmt.Errorf("handle %q is reserved", handle)
+	}
+	return nil
+}
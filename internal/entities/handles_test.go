@@ -0,0 +1,19 @@
+package entities
+
+import "testing"
+
+func TestValidateHandle(t *testing.T) {
+	valid := []string{"bob", "Alice_99", "x_y", "abcdefghijabcdefghijabcdefghij"}
+	for _, handle := range valid {
+		if err := ValidateHandle(handle); err != nil {
+			t.Errorf("ValidateHandle(%q) returned error %v", handle, err)
+		}
+	}
+	invalid := []string{"", "ab", "abcdefghijabcdefghijabcdefghijk", "has space", "dash-ed", "émile", "12345", "___",
+		"Admin", "SUPPORT"}
+	for _, handle := range invalid {
+		if err := ValidateHandle(handle); err == nil {
+			t.Errorf("ValidateHandle(%q) should have returned an error", handle)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package entities
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const MaxHashtagLen = 100
+
+// isTagRune reports whether r may appear in the body of a hashtag.
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func isTagMark(r rune) bool {
+	return r == '#' || r == '＃'
+}
+
+// NormalizeHashtag folds a hashtag to the form it is stored and looked up in:
+// NFKC-normalized, lower case and without the leading '#'. It returns an empty
+// string if tag is not a valid hashtag.
+func NormalizeHashtag(tag string) string {
+	if r, size := utf8.DecodeRuneInString(tag); isTagMark(r) {
+		tag = tag[size:]
+	}
+	tag = strings.ToLower(norm.NFKC.String(tag))
+	if tag == "" || utf8.RuneCountInString(tag) > MaxHashtagLen {
+		return ""
+	}
+	hasLetter := false
+	for _, r := range tag {
+		if !isTagRune(r) {
+			return ""
+		}
+		hasLetter = hasLetter || unicode.IsLetter(r)
+	}
+	if !hasLetter {
+		return ""
+	}
+	return tag
+}
+
+// Hashtags returns the distinct normalized hashtags in body, in order of first
+// appearance. A hashtag starts with '#' at the start of the text or after a
+// character that could not be part of a word, and must contain a letter.
+func Hashtags(body string) []string {
+	var tags []string
+	seen := map[string]bool{}
+	prev := ' '
+	for i := 0; i < len(body); {
+		r, size := utf8.DecodeRuneInString(body[i:])
+		if !isTagMark(r) || isTagRune(prev) || isTagMark(prev) {
+			prev = r
+			i += size
+			continue
+		}
+		prev = r
+		end := i + size
+		for end < len(body) {
+			next, nextSize := utf8.DecodeRuneInString(body[end:])
+			if !isTagRune(next) {
+				break
+			}
+			prev = next
+			end += nextSize
+		}
+		if tag := NormalizeHashtag(body[i:end]); tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+		i = end
+	}
+	return tags
+}
@@ -0,0 +1,36 @@
+package entities
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHashtags(t *testing.T) {
+	cases := []struct {
+		body string
+		want []string
+	}{
+		{"no tags here", nil},
+		{"#Go is fun", []string{"go"}},
+		{"learning #golang and #GoLang again", []string{"golang"}},
+		{"email me at me#home or #1", nil},
+		{"tags: #café, #日本語! #über_alles", []string{"café", "日本語", "über_alles"}},
+		{"fullwidth ＃ＧＯ works", []string{"go"}},
+		{"##double #a#b", []string{"a"}},
+	}
+	for _, c := range cases {
+		got := Hashtags(c.body)
+		if !slices.Equal(got, c.want) {
+			t.Errorf("Hashtags(%q) = %q, want %q", c.body, got, c.want)
+		}
+	}
+}
+
+func TestNormalizeHashtag(t *testing.T) {
+	if got := NormalizeHashtag("#Café"); got != "café" {
+		t.Errorf("NormalizeHashtag() did not compose accents, got %q", got)
+	}
+	if got := NormalizeHashtag("#123"); got != "" {
+		t.Errorf("NormalizeHashtag() accepted a tag without letters: %q", got)
+	}
+}
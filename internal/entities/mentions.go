@@ -0,0 +1,75 @@
+package entities
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const MaxHandleLen = 30
+
+// Mention is an "@handle" found in a chirp body. Start and End are offsets in
+// Unicode code points, End exclusive, and span the '@' as well as the handle.
+type Mention struct {
+	Handle string
+	Start  int
+	End    int
+}
+
+// IsHandleRune reports whether r may appear in a user handle.
+func IsHandleRune(r rune) bool {
+	return r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')
+}
+
+// Mentions returns every "@handle" in body. An '@' only starts a mention at the
+// start of the text or after a character that could not be part of a word, so
+// email addresses like "josé@example.com" are not mistaken for mentions. A handle followed by a letter
+// or mark that handles cannot hold, like the ë in "@zoë", is not a mention
+// either, rather than a mention of someone else.
+func Mentions(body string) []Mention {
+	var mentions []Mention
+	prev := ' '
+	pos := 0
+	for i := 0; i < len(body); {
+		r, size := utf8.DecodeRuneInString(body[i:])
+		if r != '@' || isTagRune(prev) || prev == '@' {
+			prev = r
+			pos++
+			i += size
+			continue
+		}
+		start, end := pos, i+size
+		prev = r
+		pos++
+		for end < len(body) {
+			next, nextSize := utf8.DecodeRuneInString(body[end:])
+			if !IsHandleRune(next) {
+				break
+			}
+			prev = next
+			pos++
+			end += nextSize
+		}
+		next, _ := utf8.DecodeRuneInString(body[end:])
+		partial := end < len(body) && (unicode.IsLetter(next) || unicode.IsMark(next))
+		if handle := body[i+size : end]; !partial && len(handle) > 0 && len(handle) <= MaxHandleLen {
+			mentions = append(mentions, Mention{Handle: handle, Start: start, End: pos})
+		}
+		i = end
+	}
+	return mentions
+}
+
+// MentionedHandles returns the distinct lower-cased handles in mentions.
+func MentionedHandles(mentions []Mention) []string {
+	var handles []string
+	seen := map[string]bool{}
+	for _, m := range mentions {
+		handle := strings.ToLower(m.Handle)
+		if !seen[handle] {
+			seen[handle] = true
+			handles = append(handles, handle)
+		}
+	}
+	return handles
+}
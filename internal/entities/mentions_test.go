@@ -0,0 +1,38 @@
+package entities
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMentions(t *testing.T) {
+	cases := []struct {
+		body string
+		want []Mention
+	}{
+		{"nobody here", nil},
+		{"@alice hi", []Mention{{Handle: "alice", Start: 0, End: 6}}},
+		{"hey @Bob_1, and @carol!", []Mention{{Handle: "Bob_1", Start: 4, End: 10}, {Handle: "carol", Start: 16, End: 22}}},
+		{"mail me@example.com or @@dave", nil},
+		{"mail josé@example.com or 名前@example.com", nil},
+		{"cafe\u0301@example.com, ٣@bob", nil},
+		{"(@alice) «@bob»", []Mention{{Handle: "alice", Start: 1, End: 7}, {Handle: "bob", Start: 10, End: 14}}},
+		{"héllo @zoë", nil},
+		{"héllo @zoe\u0301", nil},
+		{"@bob→ café @ann", []Mention{{Handle: "bob", Start: 0, End: 4}, {Handle: "ann", Start: 11, End: 15}}},
+		{"@ alone", nil},
+	}
+	for _, c := range cases {
+		got := Mentions(c.body)
+		if !slices.Equal(got, c.want) {
+			t.Errorf("Mentions(%q) = %v, want %v", c.body, got, c.want)
+		}
+	}
+}
+
+func TestMentionedHandles(t *testing.T) {
+	got := MentionedHandles(Mentions("@Ann @ann @bea"))
+	if want := []string{"ann", "bea"}; !slices.Equal(got, want) {
+		t.Errorf("MentionedHandles() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,176 @@
+// Package feeds renders lists of chirps as Atom and RSS documents for feed readers,
+// and computes the entity tags those readers poll with.
+package feeds
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const AtomContentType = "application/atom+xml; charset=utf-8"
+const RSSContentType = "application/rss+xml; charset=utf-8"
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+const titleLength = 80
+
+// Feed is a feed independent of its format. Entries are newest first.
+type Feed struct {
+	ID      string
+	Title   string
+	Link    string
+	Self    string
+	Updated time.Time
+	Entries []Entry
+}
+
+// Entry is one chirp. ID must never change for the same chirp, so that readers
+// recognise entries they have already shown.
+type Entry struct {
+	ID        string
+	Link      string
+	Author    string
+	Content   string
+	Published time.Time
+	Updated   time.Time
+}
+
+// Title is the entry's title: the start of its first line. Atom requires one and
+// chirps do not have one of their own.
+func (entry Entry) Title() string {
+	title, _, _ := strings.Cut(strings.TrimSpace(entry.Content), "\n")
+	if utf8.RuneCountInString(title) > titleLength {
+		title = string([]rune(title)[:titleLength-1]) + "…"
+	}
+	if entry.Author != "" {
+		title = "@" + entry.Author + ": " + title
+	}
+	return title
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Text string `xml:",chardata"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Link      atomLink    `xml:"link"`
+	Author    *atomAuthor `xml:"author,omitempty"`
+	Published string      `xml:"published"`
+	Updated   string      `xml:"updated"`
+	Content   atomText    `xml:"content"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	XMLNS   string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// Atom renders feed as an Atom 1.0 document.
+func Atom(feed Feed) ([]byte, error) {
+	doc := atomFeed{XMLNS: atomNamespace, ID: feed.ID, Title: feed.Title, Updated: feed.Updated.UTC().Format(time.RFC3339),
+		Links:   []atomLink{{Rel: "alternate", Href: feed.Link}, {Rel: "self", Type: AtomContentType, Href: feed.Self}},
+		Entries: make([]atomEntry, len(feed.Entries))}
+	for i, entry := range feed.Entries {
+		doc.Entries[i] = atomEntry{ID: entry.ID, Title: entry.Title(), Link: atomLink{Rel: "alternate", Href: entry.Link},
+			Published: entry.Published.UTC().Format(time.RFC3339), Updated: entry.Updated.UTC().Format(time.RFC3339),
+			Content: atomText{Type: "text", Text: entry.Content}}
+		if entry.Author != "" {
+			doc.Entries[i].Author = &atomAuthor{Name: entry.Author}
+		}
+	}
+	return marshal(doc)
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Text        string `xml:",chardata"`
+}
+
+type rssItem struct {
+	GUID        rssGUID `xml:"guid"`
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	Author      string  `xml:"dc:creator,omitempty"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Self          atomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	DC      string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// RSS renders feed as an RSS 2.0 document. RSS has no updated date for items, so
+// edits only show up in lastBuildDate and the item text.
+func RSS(feed Feed) ([]byte, error) {
+	doc := rssFeed{Version: "2.0", Atom: atomNamespace, DC: "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{Title: feed.Title, Link: feed.Link, Description: feed.Title,
+			Self:          atomLink{Rel: "self", Type: RSSContentType, Href: feed.Self},
+			LastBuildDate: feed.Updated.UTC().Format(time.RFC1123Z), Items: make([]rssItem, len(feed.Entries))}}
+	for i, entry := range feed.Entries {
+		doc.Channel.Items[i] = rssItem{GUID: rssGUID{Text: entry.ID}, Title: entry.Title(), Link: entry.Link,
+			Description: entry.Content, Author: entry.Author, PubDate: entry.Published.UTC().Format(time.RFC1123Z)}
+	}
+	return marshal(doc)
+}
+
+func marshal(doc any) ([]byte, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// ETag is a strong entity tag for a rendered feed.
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// Matches reports whether an If-None-Match header names etag. Tags are compared
+// weakly, as RFC 9110 asks for If-None-Match.
+func Matches(ifNoneMatch, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
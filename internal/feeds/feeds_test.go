@@ -0,0 +1,103 @@
+package feeds
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+var published = time.Date(2024, time.May, 4, 12, 0, 0, 0, time.UTC)
+
+var testFeed = Feed{ID: "urn:uuid:ba0f3b30-2d6a-4bd5-9ed4-7d0ea5bc1fbb", Title: "Chirps by @alice",
+	Link: "https://chirpy.test/users/alice", Self: "https://chirpy.test/users/alice/feed.atom",
+	Updated: published.Add(time.Hour), Entries: []Entry{
+		{ID: "urn:uuid:1f6a1a3e-8b0e-4a4f-9d2b-2a9f7f6f7c11", Link: "https://chirpy.test/chirps/1", Author: "alice",
+			Content: "fish & <chips>\nsecond line", Published: published, Updated: published.Add(time.Hour)},
+	}}
+
+func TestAtom(t *testing.T) {
+	body, err := Atom(testFeed)
+	if err != nil {
+		t.Fatalf("Atom() returned error %v", err)
+	}
+	var doc struct {
+		ID      string `xml:"id"`
+		Updated string `xml:"updated"`
+		Entries []struct {
+			ID      string `xml:"id"`
+			Title   string `xml:"title"`
+			Updated string `xml:"updated"`
+			Content string `xml:"content"`
+		} `xml:"entry"`
+	}
+	if err = xml.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("Atom() is not valid XML: %v\n%s", err, body)
+	}
+	if doc.ID != testFeed.ID || doc.Updated != "2024-05-04T13:00:00Z" || len(doc.Entries) != 1 {
+		t.Fatalf("Atom() = %+v", doc)
+	}
+	entry := doc.Entries[0]
+	if entry.ID != testFeed.Entries[0].ID || entry.Updated != "2024-05-04T13:00:00Z" {
+		t.Errorf("entry id = %q, updated = %q", entry.ID, entry.Updated)
+	}
+	if entry.Title != "@alice: fish & <chips>" || entry.Content != testFeed.Entries[0].Content {
+		t.Errorf("entry title = %q, content = %q", entry.Title, entry.Content)
+	}
+}
+
+func TestRSS(t *testing.T) {
+	body, err := RSS(testFeed)
+	if err != nil {
+		t.Fatalf("RSS() returned error %v", err)
+	}
+	var doc struct {
+		Version string `xml:"version,attr"`
+		Items   []struct {
+			GUID    string `xml:"guid"`
+			PubDate string `xml:"pubDate"`
+		} `xml:"channel>item"`
+	}
+	if err = xml.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("RSS() is not valid XML: %v\n%s", err, body)
+	}
+	if doc.Version != "2.0" || len(doc.Items) != 1 {
+		t.Fatalf("RSS() = %+v", doc)
+	}
+	if doc.Items[0].GUID != testFeed.Entries[0].ID || doc.Items[0].PubDate != "Sat, 04 May 2024 12:00:00 +0000" {
+		t.Errorf("item = %+v", doc.Items[0])
+	}
+	if !strings.Contains(string(body), `isPermaLink="false"`) {
+		t.Error("RSS() guid does not say it is not a permalink")
+	}
+}
+
+func TestTitle(t *testing.T) {
+	long := Entry{Content: strings.Repeat("é", 100)}
+	if got := long.Title(); len([]rune(got)) != titleLength || !strings.HasSuffix(got, "…") {
+		t.Errorf("Title() of a long chirp = %q", got)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	etag := ETag([]byte("feed"))
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{etag, true},
+		{"W/" + etag, true},
+		{`"other", ` + etag, true},
+		{"*", true},
+		{`"other"`, false},
+		{"", false},
+	}
+	for _, tc := range tests {
+		if got := Matches(tc.header, etag); got != tc.want {
+			t.Errorf("Matches(%q) = %v, want %v", tc.header, got, tc.want)
+		}
+	}
+	if ETag([]byte("feed")) != etag || ETag([]byte("feed2")) == etag {
+		t.Error("ETag() is not a function of the body")
+	}
+}
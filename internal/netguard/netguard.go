@@ -0,0 +1,95 @@
+// Package netguard keeps requests whose destination someone else picks, such as
+// webhook URLs and federated actor IDs, from reaching this host or the private
+// network it sits on. Addresses are checked once the name is resolved, at dial
+// time, so a name that resolves differently on a second lookup gains nothing.
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+var ErrForbidden = errors.New("address is not publicly routable")
+
+// reserved are ranges that pass the netip predicates but are still not reachable
+// on the public internet: "this network", carrier-grade NAT and benchmarking.
+var reserved = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// Guard decides which addresses may be dialed. The zero value allows public
+// unicast addresses only; AllowLoopback additionally lets requests reach this
+// host, for development setups where every peer runs on localhost.
+type Guard struct {
+	AllowLoopback bool
+}
+
+// Allowed reports whether addr may be dialed.
+func (guard Guard) Allowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() {
+		return guard.AllowLoopback
+	}
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range reserved {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Control is a net.Dialer Control function that refuses connections to
+// addresses the guard does not allow.
+func (guard Guard) Control(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("dialing %s: %w", address, err)
+	}
+	if !guard.Allowed(addrPort.Addr()) {
+		return fmt.Errorf("dialing %s: %w", address, ErrForbidden)
+	}
+	return nil
+}
+
+// Check resolves host and fails with ErrForbidden if any address it has is not
+// allowed. It lets a caller turn a destination away when it is first given,
+// rather than only when it is dialed.
+func (guard Guard) Check(ctx context.Context, host string) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !guard.Allowed(addr) {
+			return ErrForbidden
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("cannot resolve %s", host)
+	}
+	for _, addr := range addrs {
+		if !guard.Allowed(addr) {
+			return ErrForbidden
+		}
+	}
+	return nil
+}
+
+// Transport returns an http.Transport whose connections go through the guard.
+// It never uses a proxy, since the guard would then only see the proxy's address.
+func (guard Guard) Transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second,
+		Control: guard.Control}).DialContext
+	return transport
+}
@@ -0,0 +1,80 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestAllowed(t *testing.T) {
+	cases := []struct {
+		addr     string
+		public   bool
+		loopback bool
+	}{
+		{"93.184.215.14", true, true},
+		{"2606:4700::1111", true, true},
+		{"127.0.0.1", false, true},
+		{"::1", false, true},
+		{"::ffff:127.0.0.1", false, true},
+		{"10.1.2.3", false, false},
+		{"172.16.0.1", false, false},
+		{"192.168.1.1", false, false},
+		{"::ffff:192.168.1.1", false, false},
+		{"fd00::1", false, false},
+		{"169.254.169.254", false, false},
+		{"fe80::1", false, false},
+		{"0.0.0.0", false, false},
+		{"::", false, false},
+		{"0.1.2.3", false, false},
+		{"100.64.0.1", false, false},
+		{"224.0.0.1", false, false},
+		{"255.255.255.255", false, false},
+	}
+	for _, c := range cases {
+		addr := netip.MustParseAddr(c.addr)
+		if got := (Guard{}).Allowed(addr); got != c.public {
+			t.Errorf("Allowed(%s) = %v, want %v", c.addr, got, c.public)
+		}
+		if got := (Guard{AllowLoopback: true}).Allowed(addr); got != c.loopback {
+			t.Errorf("Allowed(%s) with loopback = %v, want %v", c.addr, got, c.loopback)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	if err := (Guard{}).Check(ctx, "localhost"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Check(localhost) = %v, want ErrForbidden", err)
+	}
+	if err := (Guard{}).Check(ctx, "10.0.0.1"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Check(10.0.0.1) = %v, want ErrForbidden", err)
+	}
+	if err := (Guard{AllowLoopback: true}).Check(ctx, "127.0.0.1"); err != nil {
+		t.Errorf("Check(127.0.0.1) with loopback = %v", err)
+	}
+}
+
+func TestTransportRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: Guard{}.Transport()}
+	if _, err := client.Get(server.URL); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("Get through guard = %v, want ErrForbidden", err)
+	}
+	client = &http.Client{Transport: Guard{AllowLoopback: true}.Transport()}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get with loopback allowed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+}
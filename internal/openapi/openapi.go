@@ -0,0 +1,315 @@
+// Package openapi builds OpenAPI 3.1 documents. Schemas are derived from the
+// Go types a server marshals, so they describe the JSON it actually sends.
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const Version = "3.1.0"
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps lower-case HTTP methods to the operations on one path.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty"`
+	Summary     string               `json:"summary"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Security    []Requirement        `json:"security,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Requirement names the security schemes that together authorize a request.
+// An empty Requirement makes authentication optional.
+type Requirement map[string][]string
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+// Response is either a reference to a shared response or a response of its own.
+type Response struct {
+	Ref         string               `json:"$ref,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	Responses       map[string]*Response      `json:"responses,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+}
+
+// Schema is the subset of JSON Schema 2020-12 the documents use. Type is a
+// string, or a list of them for nullable values.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Maximum              *int               `json:"maximum,omitempty"`
+}
+
+// New returns an empty document.
+func New(info Info) *Document {
+	return &Document{OpenAPI: Version, Info: info, Paths: map[string]PathItem{},
+		Components: Components{Schemas: map[string]*Schema{}, Responses: map[string]*Response{},
+			SecuritySchemes: map[string]SecurityScheme{}}}
+}
+
+var wildcard = regexp.MustCompile(`\{([^}.$]+)(\.\.\.)?\}`)
+
+// Route converts a ServeMux pattern to the method and path that describe it. A
+// pattern without a method is described as GET, a prefix pattern ending in a
+// slash as a trailing {path} parameter, and {$} is dropped.
+func Route(pattern string) (string, string) {
+	method, path, found := strings.Cut(pattern, " ")
+	if !found {
+		method, path = "GET", pattern
+	}
+	path = strings.TrimSpace(path)
+	path = strings.TrimSuffix(path, "{$}")
+	if path == "" {
+		path = "/"
+	}
+	if path != "/" && strings.HasSuffix(path, "/") {
+		path += "{path}"
+	}
+	return strings.ToLower(method), wildcard.ReplaceAllString(path, "{$1}")
+}
+
+// Add describes the route matched by a ServeMux pattern. Path parameters the
+// operation does not declare are added as required strings.
+func (doc *Document) Add(pattern string, op *Operation) {
+	method, path := Route(pattern)
+	declared := map[string]bool{}
+	for _, param := range op.Parameters {
+		if param.In == "path" {
+			declared[param.Name] = true
+		}
+	}
+	for _, match := range wildcard.FindAllStringSubmatch(path, -1) {
+		if !declared[match[1]] {
+			op.Parameters = append(op.Parameters, Parameter{Name: match[1], In: "path", Required: true,
+				Schema: &Schema{Type: "string"}})
+		}
+	}
+	for i := range op.Parameters {
+		if op.Parameters[i].In == "path" {
+			op.Parameters[i].Required = true
+		}
+	}
+	if doc.Paths[path] == nil {
+		doc.Paths[path] = PathItem{}
+	}
+	doc.Paths[path][method] = op
+}
+
+// Operation returns what the document says about a ServeMux pattern, or nil.
+func (doc *Document) Operation(pattern string) *Operation {
+	method, path := Route(pattern)
+	return doc.Paths[path][method]
+}
+
+// Ref refers to a schema under components.
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// Nullable allows null where schema is expected.
+func Nullable(schema *Schema) *Schema {
+	if schema.Ref != "" {
+		return &Schema{OneOf: []*Schema{schema, {Type: "null"}}}
+	}
+	nullable := *schema
+	if name, ok := schema.Type.(string); ok {
+		nullable.Type = []string{name, "null"}
+	}
+	return &nullable
+}
+
+// Schemas derives schemas from Go types the way encoding/json marshals them.
+// Types given a name with Define are referred to, rather than inlined, wherever
+// they appear.
+type Schemas struct {
+	doc   *Document
+	names map[reflect.Type]string
+}
+
+func (doc *Document) Schemas() *Schemas {
+	return &Schemas{doc: doc, names: map[reflect.Type]string{}}
+}
+
+// Define adds the schema of v's type to the document's components and returns
+// a reference to it.
+func (schemas *Schemas) Define(name string, v any) *Schema {
+	t := reflect.TypeOf(v)
+	schemas.names[t] = name
+	schemas.doc.Components.Schemas[name] = schemas.build(t)
+	return Ref(name)
+}
+
+// Body is Define for request bodies. Decoding leaves out whatever the client
+// does not send, so only the fields named are required.
+func (schemas *Schemas) Body(name string, v any, required ...string) *Schema {
+	ref := schemas.Define(name, v)
+	schemas.doc.Components.Schemas[name].Required = required
+	return ref
+}
+
+// Of returns the schema of v's type.
+func (schemas *Schemas) Of(v any) *Schema {
+	return schemas.schema(reflect.TypeOf(v))
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+var uuidType = reflect.TypeOf(uuid.UUID{})
+var rawType = reflect.TypeOf(json.RawMessage{})
+var textMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+func (schemas *Schemas) schema(t reflect.Type) *Schema {
+	if name, ok := schemas.names[t]; ok {
+		return Ref(name)
+	}
+	return schemas.build(t)
+}
+
+func (schemas *Schemas) build(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	case t == rawType:
+		return &Schema{}
+	case t.Kind() != reflect.Pointer && t.Implements(textMarshaler):
+		return &Schema{Type: "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Pointer:
+		return Nullable(schemas.schema(t.Elem()))
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: schemas.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemas.schema(t.Elem())}
+	case reflect.Struct:
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		schemas.fields(schema, t)
+		return schema
+	}
+	// Interfaces can hold anything.
+	return &Schema{}
+}
+
+// fields adds the properties of struct type t to schema, flattening embedded
+// structs as encoding/json does. Fields without omitempty are required.
+func (schemas *Schemas) fields(schema *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			schemas.fields(schema, field.Type)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		omitempty := strings.Contains(","+options+",", ",omitempty,")
+		fieldType := field.Type
+		if omitempty && fieldType.Kind() == reflect.Pointer {
+			// encoding/json leaves nil pointers out instead of writing null.
+			fieldType = fieldType.Elem()
+		}
+		schema.Properties[name] = schemas.schema(fieldType)
+		if !omitempty {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRoute(t *testing.T) {
+	tests := []struct {
+		pattern, method, path string
+	}{
+		{"GET /api/chirps/{id}", "get", "/api/chirps/{id}"},
+		{"DELETE /api/webhooks/{id}", "delete", "/api/webhooks/{id}"},
+		{"GET /{$}", "get", "/"},
+		{"/app/", "get", "/app/{path}"},
+		{"GET /files/{name...}", "get", "/files/{name}"},
+	}
+	for _, tc := range tests {
+		method, path := Route(tc.pattern)
+		if method != tc.method || path != tc.path {
+			t.Errorf("Route(%q) = %s %s, want %s %s", tc.pattern, method, path, tc.method, tc.path)
+		}
+	}
+}
+
+type header struct {
+	ID      uuid.UUID `json:"id"`
+	Created time.Time `json:"created_at"`
+}
+
+type item struct {
+	header
+	Name    string          `json:"name"`
+	Note    *string         `json:"note"`
+	Parent  *item           `json:"parent,omitempty"`
+	Tags    []string        `json:"tags,omitempty"`
+	Count   int32           `json:"count"`
+	Extra   json.RawMessage `json:"extra"`
+	Skipped string          `json:"-"`
+	hidden  string
+}
+
+func TestSchemas(t *testing.T) {
+	doc := New(Info{Title: "test", Version: "1"})
+	ref := doc.Schemas().Define("Item", item{})
+	if ref.Ref != "#/components/schemas/Item" {
+		t.Errorf("Define() = %+v", ref)
+	}
+	got, err := json.Marshal(doc.Components.Schemas["Item"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"object","properties":{"count":{"type":"integer","format":"int32"},` +
+		`"created_at":{"type":"string","format":"date-time"},"extra":{},"id":{"type":"string","format":"uuid"},` +
+		`"name":{"type":"string"},"note":{"type":["string","null"]},"parent":{"$ref":"#/components/schemas/Item"},` +
+		`"tags":{"type":"array","items":{"type":"string"}}},"required":["id","created_at","name","note","count","extra"]}`
+	if string(got) != want {
+		t.Errorf("schema =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAddFillsPathParameters(t *testing.T) {
+	doc := New(Info{Title: "test", Version: "1"})
+	op := &Operation{Summary: "test", Parameters: []Parameter{{Name: "id", In: "path",
+		Schema: &Schema{Type: "string", Format: "uuid"}}}}
+	doc.Add("POST /things/{id}/parts/{part}", op)
+	if doc.Operation("POST /things/{id}/parts/{part}") != op {
+		t.Fatal("operation was not added under its path")
+	}
+	if len(op.Parameters) != 2 || op.Parameters[1].Name != "part" || !op.Parameters[0].Required {
+		t.Errorf("parameters = %+v", op.Parameters)
+	}
+}
@@ -0,0 +1,210 @@
+// Package querylimit bounds how much work a GraphQL query may ask for, so a
+// single request cannot nest or fan out far enough to exhaust the server.
+// Queries are measured after validation and before they run.
+package querylimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Limits caps the depth and complexity of a query. Every field selected costs
+// one, multiplied by the size of each list it is inside. A list is as long as
+// the limit argument of its field, or of the field right above it for lists
+// wrapped in a page object; a list without one is assumed to be ListSize long.
+type Limits struct {
+	MaxDepth      int
+	MaxComplexity int
+	ListSize      int
+}
+
+// Cost is what an operation asks for.
+type Cost struct {
+	Depth      int
+	Complexity int
+}
+
+// Check measures the named operation in doc and reports an error if it is too
+// deep or too complex. The document must already have passed validation.
+func (limits Limits) Check(schema *graphql.Schema, doc *ast.Document, operationName string, variables map[string]any) (Cost, error) {
+	cost, err := limits.Measure(schema, doc, operationName, variables)
+	if err != nil {
+		return cost, err
+	}
+	if cost.Depth > limits.MaxDepth {
+		return cost, fmt.Errorf("query is nested %d deep, more than the limit of %d", cost.Depth, limits.MaxDepth)
+	}
+	if cost.Complexity > limits.MaxComplexity {
+		return cost, fmt.Errorf("query has a complexity of %d, more than the limit of %d", cost.Complexity,
+			limits.MaxComplexity)
+	}
+	return cost, nil
+}
+
+// Measure works out the depth and complexity of the named operation in doc.
+func (limits Limits) Measure(schema *graphql.Schema, doc *ast.Document, operationName string, variables map[string]any) (Cost, error) {
+	op, err := Operation(doc, operationName)
+	if err != nil {
+		return Cost{}, err
+	}
+	walk := walker{limits: limits, schema: schema, fragments: map[string]*ast.FragmentDefinition{},
+		variables: map[string]any{}}
+	for _, def := range doc.Definitions {
+		if fragment, ok := def.(*ast.FragmentDefinition); ok {
+			walk.fragments[fragment.Name.Value] = fragment
+		}
+	}
+	for _, def := range op.VariableDefinitions {
+		if def.DefaultValue != nil {
+			walk.variables[def.Variable.Name.Value] = def.DefaultValue.GetValue()
+		}
+	}
+	for name, value := range variables {
+		walk.variables[name] = value
+	}
+	var root *graphql.Object
+	switch op.Operation {
+	case ast.OperationTypeQuery:
+		root = schema.QueryType()
+	case ast.OperationTypeMutation:
+		root = schema.MutationType()
+	case ast.OperationTypeSubscription:
+		root = schema.SubscriptionType()
+	}
+	return walk.selections(op.SelectionSet, root, 0), nil
+}
+
+// Operation finds the named operation in doc, or its only one if name is empty.
+func Operation(doc *ast.Document, name string) (*ast.OperationDefinition, error) {
+	var op *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		if def, ok := def.(*ast.OperationDefinition); ok {
+			if name == "" && op != nil {
+				return nil, fmt.Errorf("an operation name is required when the query has several")
+			}
+			if name == "" || (def.Name != nil && def.Name.Value == name) {
+				op = def
+			}
+		}
+	}
+	if op == nil {
+		return nil, fmt.Errorf("unknown operation %q", name)
+	}
+	return op, nil
+}
+
+type walker struct {
+	limits    Limits
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]any
+}
+
+// selections measures a selection set on parent. size is the list length set
+// by the field that selected it, or zero.
+func (walk walker) selections(set *ast.SelectionSet, parent graphql.Type, size int) Cost {
+	total := Cost{}
+	if set == nil {
+		return total
+	}
+	add := func(cost Cost) {
+		total.Complexity += cost.Complexity
+		total.Depth = max(total.Depth, cost.Depth)
+	}
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			add(walk.field(selection, parent, size))
+		case *ast.InlineFragment:
+			add(walk.selections(selection.SelectionSet, walk.condition(selection.TypeCondition, parent), size))
+		case *ast.FragmentSpread:
+			if fragment := walk.fragments[selection.Name.Value]; fragment != nil {
+				add(walk.selections(fragment.SelectionSet, walk.condition(fragment.TypeCondition, parent), size))
+			}
+		}
+	}
+	return total
+}
+
+func (walk walker) field(field *ast.Field, parent graphql.Type, size int) Cost {
+	if strings.HasPrefix(field.Name.Value, "__") {
+		// Introspection is bounded by the schema, not by the data.
+		return Cost{}
+	}
+	var fieldType graphql.Type
+	childSize := 0
+	if def := fieldsOf(parent)[field.Name.Value]; def != nil {
+		fieldType = def.Type
+		for _, arg := range def.Args {
+			if arg.Name() == "limit" {
+				childSize, _ = arg.DefaultValue.(int)
+			}
+		}
+	}
+	for _, arg := range field.Arguments {
+		if arg.Name.Value == "limit" {
+			childSize = walk.intValue(arg.Value)
+		}
+	}
+	items := 1
+	if _, isList := graphql.GetNullable(fieldType).(*graphql.List); isList {
+		switch {
+		case childSize > 0:
+			items = childSize
+		case size > 0:
+			items = size
+		default:
+			items = walk.limits.ListSize
+		}
+		childSize = 0
+	}
+	var named graphql.Type
+	if fieldType != nil {
+		named, _ = graphql.GetNamed(fieldType).(graphql.Type)
+	}
+	children := walk.selections(field.SelectionSet, named, childSize)
+	return Cost{Depth: children.Depth + 1, Complexity: items * (1 + children.Complexity)}
+}
+
+// condition is the type a fragment's fields are looked up on.
+func (walk walker) condition(condition *ast.Named, parent graphql.Type) graphql.Type {
+	if condition == nil {
+		return parent
+	}
+	return walk.schema.Type(condition.Name.Value)
+}
+
+func (walk walker) intValue(value ast.Value) int {
+	var raw any = value.GetValue()
+	if variable, ok := value.(*ast.Variable); ok {
+		raw = walk.variables[variable.Name.Value]
+	}
+	switch raw := raw.(type) {
+	case string:
+		n, _ := strconv.Atoi(raw)
+		return n
+	case int:
+		return raw
+	case float64:
+		return int(raw)
+	case json.Number:
+		n, _ := raw.Int64()
+		return int(n)
+	}
+	return 0
+}
+
+func fieldsOf(parent graphql.Type) graphql.FieldDefinitionMap {
+	switch parent := parent.(type) {
+	case *graphql.Object:
+		return parent.Fields()
+	case *graphql.Interface:
+		return parent.Fields()
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package querylimit
+
+import (
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+func testSchema(t *testing.T) *graphql.Schema {
+	t.Helper()
+	post := graphql.NewObject(graphql.ObjectConfig{Name: "Post", Fields: graphql.Fields{
+		"title": &graphql.Field{Type: graphql.String},
+	}})
+	var person *graphql.Object
+	page := graphql.NewObject(graphql.ObjectConfig{Name: "PostPage", Fields: graphql.Fields{
+		"posts":      &graphql.Field{Type: graphql.NewList(post)},
+		"nextCursor": &graphql.Field{Type: graphql.String},
+	}})
+	person = graphql.NewObject(graphql.ObjectConfig{Name: "Person", Fields: graphql.FieldsThunk(func() graphql.Fields {
+		return graphql.Fields{
+			"name":    &graphql.Field{Type: graphql.String},
+			"friends": &graphql.Field{Type: graphql.NewList(person)},
+			"posts": &graphql.Field{Type: page, Args: graphql.FieldConfigArgument{
+				"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 20},
+			}},
+		}
+	})})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query", Fields: graphql.Fields{"me": &graphql.Field{Type: person}},
+	})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &schema
+}
+
+func TestMeasure(t *testing.T) {
+	schema := testSchema(t)
+	limits := Limits{MaxDepth: 4, MaxComplexity: 100, ListSize: 10}
+	tests := []struct {
+		query      string
+		variables  map[string]any
+		depth      int
+		complexity int
+	}{
+		{`{ me { name } }`, nil, 2, 2},
+		// The page's limit sizes the list inside it: 1 + 1 + 5*(1+1).
+		{`{ me { posts(limit: 5) { posts { title } } } }`, nil, 4, 12},
+		{`query($n: Int) { me { posts(limit: $n) { posts { title } } } }`, map[string]any{"n": float64(2)}, 4, 6},
+		{`query($n: Int = 3) { me { posts(limit: $n) { posts { title } } } }`, nil, 4, 8},
+		// Without an argument the schema's default applies.
+		{`{ me { posts { posts { title } } } }`, nil, 4, 42},
+		// Lists without a limit are ListSize long, and fragments count like the fields they hold.
+		{`{ me { ...F } } fragment F on Person { friends { name } }`, nil, 3, 21},
+		{`{ me { ... on Person { name } __typename } __schema { types { name } } }`, nil, 2, 2},
+	}
+	for _, tc := range tests {
+		doc, err := parser.Parse(parser.ParseParams{Source: tc.query})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cost, err := limits.Measure(schema, doc, "", tc.variables)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cost.Depth != tc.depth || cost.Complexity != tc.complexity {
+			t.Errorf("Measure(%s) = %+v, want depth %d and complexity %d", tc.query, cost, tc.depth, tc.complexity)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	schema := testSchema(t)
+	limits := Limits{MaxDepth: 3, MaxComplexity: 50, ListSize: 10}
+	tests := []struct {
+		query string
+		ok    bool
+	}{
+		{`{ me { friends { name } } }`, true},
+		{`{ me { friends { friends { name } } } }`, false},
+		{`{ me { posts(limit: 100) { nextCursor } } }`, true},
+		{`{ me { posts(limit: 100) { posts { title } } } }`, false},
+		{`{ me { friends { name } posts { nextCursor } } a: me { friends { name } } b: me { friends { name } } }`, false},
+	}
+	for _, tc := range tests {
+		doc, err := parser.Parse(parser.ParseParams{Source: tc.query})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = limits.Check(schema, doc, "", nil)
+		if (err == nil) != tc.ok {
+			t.Errorf("Check(%s) = %v, want ok %v", tc.query, err, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Package validate checks decoded request bodies against rules written in
+// their struct tags, so a handler can say what it accepts next to the fields
+// it accepts it in:
+//
+//	Email string `json:"email" validate:"required,email"`
+//
+// The rules are:
+//
+//	required  the field is not its zero value
+//	email     a string holding a bare email address, like a@example.com
+//	maxlen=N  a string of at most N characters, counted in runes, or a slice of at most N elements
+//	minlen=N  the same, at least N
+//
+// Rules other than required pass on zero values and nil pointers, so optional
+// fields are only checked when they are sent.
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError is a rule that a field broke. Field is the field's JSON name.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (err FieldError) Error() string {
+	return err.Message
+}
+
+// Struct checks every field of the struct v points at, or of v itself, and
+// returns an error for each rule broken, in field order. It panics on a rule
+// it does not know, which is a mistake in the tag rather than in the input.
+func Struct(v any) []FieldError {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	errs := []FieldError{}
+	fields(value, &errs)
+	return errs
+}
+
+// fields checks the fields of struct value, flattening embedded structs as
+// encoding/json does.
+func fields(value reflect.Value, errs *[]FieldError) {
+	t := value.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			fields(value.Field(i), errs)
+			continue
+		}
+		rules := field.Tag.Get("validate")
+		if rules == "" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		for _, rule := range strings.Split(rules, ",") {
+			if msg := check(value.Field(i), rule); msg != "" {
+				ruleName, _, _ := strings.Cut(rule, "=")
+				*errs = append(*errs, FieldError{Field: name, Rule: ruleName, Message: name + " " + msg})
+				// Later rules on the same field would only repeat what is wrong with it.
+				break
+			}
+		}
+	}
+}
+
+// check applies one rule to a field, returning what is wrong with it or "" if nothing is.
+func check(field reflect.Value, rule string) string {
+	name, arg, _ := strings.Cut(rule, "=")
+	if name == "required" {
+		if field.IsZero() {
+			return "is required"
+		}
+		return ""
+	}
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return ""
+		}
+		field = field.Elem()
+	}
+	if field.IsZero() {
+		return ""
+	}
+	switch name {
+	case "email":
+		addr, err := mail.ParseAddress(field.String())
+		if err != nil || addr.Name != "" || addr.Address != field.String() {
+			return "must be an email address"
+		}
+	case "maxlen":
+		if limit := ruleArg(rule, arg); length(field) > limit {
+			return fmt.Sprintf("must be at most %d %s", limit, unit(field))
+		}
+	case "minlen":
+		if limit := ruleArg(rule, arg); length(field) < limit {
+			return fmt.Sprintf("must be at least %d %s", limit, unit(field))
+		}
+	default:
+		panic("validate: unknown rule " + strconv.Quote(rule))
+	}
+	return ""
+}
+
+func ruleArg(rule, arg string) int {
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		panic("validate: rule " + strconv.Quote(rule) + " needs a number")
+	}
+	return n
+}
+
+func length(field reflect.Value) int {
+	if field.Kind() == reflect.String {
+		return utf8.RuneCountInString(field.String())
+	}
+	return field.Len()
+}
+
+func unit(field reflect.Value) string {
+	if field.Kind() == reflect.String {
+		return "characters"
+	}
+	return "items"
+}
@@ -0,0 +1,64 @@
+package validate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type inner struct {
+	Note string `json:"note" validate:"maxlen=3"`
+}
+
+type form struct {
+	inner
+	Email  string   `json:"email" validate:"required,email"`
+	Name   string   `json:"name,omitempty" validate:"maxlen=5"`
+	Bio    *string  `json:"bio" validate:"minlen=2,maxlen=4"`
+	Tags   []string `json:"tags" validate:"required,maxlen=2"`
+	Hidden string   `json:"-"`
+	Plain  string
+}
+
+func TestStruct(t *testing.T) {
+	long, short := "too long", "x"
+	tests := []struct {
+		name string
+		form form
+		want []FieldError
+	}{
+		{"valid", form{Email: "a@example.com", Name: "héllo", Tags: []string{"go"}}, []FieldError{}},
+		{"every field", form{inner: inner{Note: "long"}, Email: "Alice <a@example.com>", Name: "toolong", Bio: &long},
+			[]FieldError{
+				{"note", "maxlen", "note must be at most 3 characters"},
+				{"email", "email", "email must be an email address"},
+				{"name", "maxlen", "name must be at most 5 characters"},
+				{"bio", "maxlen", "bio must be at most 4 characters"},
+				{"tags", "required", "tags is required"},
+			}},
+		{"first broken rule only", form{Email: "a@example.com", Bio: &short, Tags: []string{"a", "b", "c"}},
+			[]FieldError{
+				{"bio", "minlen", "bio must be at least 2 characters"},
+				{"tags", "maxlen", "tags must be at most 2 items"},
+			}},
+		{"missing email", form{Tags: []string{"go"}}, []FieldError{{"email", "required", "email is required"}}},
+		{"not an address", form{Email: "nope", Tags: []string{"go"}},
+			[]FieldError{{"email", "email", "email must be an email address"}}},
+	}
+	for _, tc := range tests {
+		if got := Struct(&tc.form); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: Struct() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestUnknownRule(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "shiny") {
+			t.Errorf("recover() = %v, want a panic naming the rule", r)
+		}
+	}()
+	Struct(struct {
+		Name string `validate:"shiny"`
+	}{Name: "x"})
+}
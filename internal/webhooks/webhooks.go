@@ -0,0 +1,117 @@
+// Package webhooks signs and sends webhook deliveries. Queueing them and deciding
+// when to retry is left to the caller, which keeps them in the database.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const EventHeader = "Chirpy-Event"
+const DeliveryHeader = "Chirpy-Delivery"
+const TimestampHeader = "Chirpy-Timestamp"
+const SignatureHeader = "Chirpy-Signature"
+
+const signaturePrefix = "sha256="
+const backoffBase = time.Minute
+const backoffMax = 12 * time.Hour
+const drainLimit = 64 << 10
+
+var ErrBadSignature = errors.New("webhook signature does not match")
+var ErrStaleTimestamp = errors.New("webhook timestamp is too old or too far ahead")
+
+// Delivery is one attempt at sending an event to a subscriber.
+type Delivery struct {
+	ID      uuid.UUID
+	Event   string
+	URL     string
+	Secret  string
+	Payload []byte
+}
+
+// StatusError is returned when the receiver answers with anything but a 2xx.
+type StatusError struct {
+	StatusCode int
+}
+
+func (err *StatusError) Error() string {
+	return fmt.Sprintf("receiver answered %d %s", err.StatusCode, http.StatusText(err.StatusCode))
+}
+
+// Sign returns the signature header value for body sent at timestamp: an
+// HMAC-SHA256, keyed with the subscription's secret, over "<unix seconds>.<body>".
+// Covering the timestamp lets receivers turn away old deliveries replayed at them.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp.Unix())
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a delivery the way a receiver should: the signature must match
+// and the timestamp must be within tolerance of now.
+func Verify(secret string, head http.Header, body []byte, now time.Time, tolerance time.Duration) error {
+	seconds, err := strconv.ParseInt(head.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s header", TimestampHeader)
+	}
+	timestamp := time.Unix(seconds, 0)
+	if timestamp.Before(now.Add(-tolerance)) || timestamp.After(now.Add(tolerance)) {
+		return ErrStaleTimestamp
+	}
+	got := head.Get(SignatureHeader)
+	if !strings.HasPrefix(got, signaturePrefix) || !hmac.Equal([]byte(got), []byte(Sign(secret, timestamp, body))) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// Backoff is how long to wait before retrying a delivery that has failed attempts
+// times: a minute after the first failure, doubling after each one up to 12 hours.
+func Backoff(attempts int) time.Duration {
+	wait := backoffBase
+	for i := 1; i < attempts && wait < backoffMax; i++ {
+		wait *= 2
+	}
+	return min(wait, backoffMax)
+}
+
+// Send posts the delivery, signed as of now. It returns the status code the
+// receiver answered with, or 0 if it never answered, and an error unless that
+// was a 2xx.
+func Send(ctx context.Context, client *http.Client, delivery Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "chirpy-webhooks")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID.String())
+	now := time.Now()
+	req.Header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, now, delivery.Payload))
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Reading some of the body lets the client reuse the connection.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, drainLimit))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, &StatusError{StatusCode: resp.StatusCode}
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,115 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const testSecret = "whsec-test"
+
+type received struct {
+	head http.Header
+	body []byte
+}
+
+// receiver records every request it gets and answers with status.
+func receiver(t *testing.T, status int) (*httptest.Server, <-chan received) {
+	t.Helper()
+	got := make(chan received, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		got <- received{head: req.Header.Clone(), body: body}
+		writer.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, got
+}
+
+func TestSendSignsDelivery(t *testing.T) {
+	server, got := receiver(t, http.StatusNoContent)
+	delivery := Delivery{ID: uuid.New(), Event: "chirp.created", URL: server.URL, Secret: testSecret,
+		Payload: []byte(`{"type":"chirp.created"}`)}
+	status, err := Send(context.Background(), server.Client(), delivery)
+	if err != nil || status != http.StatusNoContent {
+		t.Fatalf("Send() = %d, %v, want 204 and no error", status, err)
+	}
+	req := <-got
+	if string(req.body) != string(delivery.Payload) {
+		t.Errorf("receiver got body %q, want %q", req.body, delivery.Payload)
+	}
+	if req.head.Get(EventHeader) != delivery.Event || req.head.Get(DeliveryHeader) != delivery.ID.String() {
+		t.Errorf("receiver got headers %v", req.head)
+	}
+	if err = Verify(testSecret, req.head, req.body, time.Now(), time.Minute); err != nil {
+		t.Errorf("Verify() returned error %v", err)
+	}
+}
+
+func TestSendReportsFailures(t *testing.T) {
+	server, _ := receiver(t, http.StatusServiceUnavailable)
+	delivery := Delivery{ID: uuid.New(), Event: "user.created", URL: server.URL, Secret: testSecret, Payload: []byte(`{}`)}
+	status, err := Send(context.Background(), server.Client(), delivery)
+	var statusErr *StatusError
+	if status != http.StatusServiceUnavailable || !errors.As(err, &statusErr) {
+		t.Errorf("Send() to a failing receiver = %d, %v, want 503 and a StatusError", status, err)
+	}
+	server.Close()
+	if status, err = Send(context.Background(), server.Client(), delivery); status != 0 || err == nil {
+		t.Errorf("Send() to a closed receiver = %d, %v, want 0 and an error", status, err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	body := []byte(`{"type":"chirp.deleted"}`)
+	signed := func(secret string, at time.Time) http.Header {
+		head := http.Header{}
+		head.Set(TimestampHeader, strconv.FormatInt(at.Unix(), 10))
+		head.Set(SignatureHeader, Sign(secret, at, body))
+		return head
+	}
+	tests := []struct {
+		name string
+		head http.Header
+		body []byte
+		want error
+	}{
+		{"valid", signed(testSecret, now), body, nil},
+		{"wrong secret", signed("other", now), body, ErrBadSignature},
+		{"tampered body", signed(testSecret, now), []byte(`{"type":"chirp.created"}`), ErrBadSignature},
+		{"too old", signed(testSecret, now.Add(-time.Hour)), body, ErrStaleTimestamp},
+		{"from the future", signed(testSecret, now.Add(time.Hour)), body, ErrStaleTimestamp},
+	}
+	for _, tc := range tests {
+		if err := Verify(testSecret, tc.head, tc.body, now, 5*time.Minute); !errors.Is(err, tc.want) {
+			t.Errorf("%s: Verify() = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{5, 16 * time.Minute},
+		{10, 512 * time.Minute},
+		{11, 12 * time.Hour},
+		{100, 12 * time.Hour},
+	}
+	for _, tc := range tests {
+		if got := Backoff(tc.attempts); got != tc.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tc.attempts, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package wordfilter
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rule is a word or phrase a user does not want to see. Matching ignores case.
+// A whole-word rule only matches where the phrase is not joined to letters or
+// digits on either side, so "cat" matches "my cat!" but not "concatenate".
+type Rule struct {
+	Phrase    string
+	WholeWord bool
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_'
+}
+
+// Matches reports whether text contains rule's phrase.
+func (rule Rule) Matches(text string) bool {
+	phrase := strings.ToLower(strings.TrimSpace(rule.Phrase))
+	if phrase == "" {
+		return false
+	}
+	text = strings.ToLower(text)
+	if !rule.WholeWord {
+		return strings.Contains(text, phrase)
+	}
+	for start := 0; start < len(text); {
+		i := strings.Index(text[start:], phrase)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(phrase)
+		before, _ := utf8.DecodeLastRuneInString(text[:i])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (i == 0 || !isWordRune(before)) && (end == len(text) || !isWordRune(after)) {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		start = i + size
+	}
+	return false
+}
+
+// Match returns the first rule in rules that matches any of texts.
+func Match(rules []Rule, texts ...string) (Rule, bool) {
+	for _, rule := range rules {
+		for _, text := range texts {
+			if rule.Matches(text) {
+				return rule, true
+			}
+		}
+	}
+	return Rule{}, false
+}
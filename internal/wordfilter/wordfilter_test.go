@@ -0,0 +1,35 @@
+package wordfilter
+
+import "testing"
+
+func TestMatches(t *testing.T) {
+	cases := []struct {
+		rule Rule
+		text string
+		want bool
+	}{
+		{Rule{Phrase: "cat"}, "Concatenate", true},
+		{Rule{Phrase: "cat", WholeWord: true}, "Concatenate", false},
+		{Rule{Phrase: "cat", WholeWord: true}, "my CAT!", true},
+		{Rule{Phrase: "cat", WholeWord: true}, "cats and a cat", true},
+		{Rule{Phrase: "spoiler alert", WholeWord: true}, "Big SPOILER ALERT: it ends", true},
+		{Rule{Phrase: "été", WholeWord: true}, "l'été dernier", true},
+		{Rule{Phrase: "été", WholeWord: true}, "étéé", false},
+		{Rule{Phrase: "  "}, "anything", false},
+	}
+	for _, c := range cases {
+		if got := c.rule.Matches(c.text); got != c.want {
+			t.Errorf("%+v.Matches(%q) = %v, want %v", c.rule, c.text, got, c.want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	rules := []Rule{{Phrase: "foo", WholeWord: true}, {Phrase: "bar"}}
+	if rule, ok := Match(rules, "nothing", "a crowbar"); !ok || rule.Phrase != "bar" {
+		t.Errorf("Match() = %+v, %v, want the bar rule", rule, ok)
+	}
+	if _, ok := Match(rules, "food"); ok {
+		t.Errorf("Match() matched a whole-word rule inside a word")
+	}
+}
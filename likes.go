@@ -0,0 +1,125 @@
+package main
+
+import (
+	"chirpy/internal/broker"
+	"chirpy/internal/database"
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handleLike(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	id, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	chirpID, err := cfg.refTarget(req, id)
+	if errors.Is(err, errBlocked) {
+		handleProblem(writer, req, http.StatusForbidden, codeBlocked, err.Error())
+		return
+	} else if err != nil {
+		handleError(writer, req, http.StatusNotFound, "Chirp not found")
+		return
+	}
+	chirp, err := cfg.dbQueries.GetChirp(req.Context(), chirpID)
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, "Chirp not found")
+		return
+	}
+	if err = cfg.likeChirp(req.Context(), chirp, id); err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// likeChirp records that user likes chirp and notifies its author the first time.
+func (cfg *apiConfig) likeChirp(ctx context.Context, chirp database.Chirp, user uuid.UUID) error {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	rows, err := qtx.LikeChirp(ctx, database.LikeChirpParams{UserID: user, ChirpID: chirp.ID})
+	if err != nil {
+		return err
+	}
+	var notification *database.Notification
+	if rows > 0 {
+		notification, err = notify(ctx, qtx, notifyLike, chirp.UserID, user, uuid.NullUUID{UUID: chirp.ID, Valid: true})
+		if err != nil {
+			return err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	if notification != nil {
+		cfg.publish(ctx, broker.Event{Notification: notification})
+	}
+	return nil
+}
+
+func (cfg *apiConfig) handleUnlike(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	id, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	chirpID, err := parseID(req)
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, err.Error())
+		return
+	}
+	chirp, err := cfg.unlikeTarget(req.Context(), chirpID)
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, "Chirp not found")
+		return
+	}
+	if err = cfg.unlikeChirp(req.Context(), chirp, id); err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// unlikeTarget finds the chirp whose like to undo. Likes of a rechirp went to the
+// chirp it amplifies, so they are undone there too.
+func (cfg *apiConfig) unlikeTarget(ctx context.Context, id uuid.UUID) (database.Chirp, error) {
+	chirp, err := cfg.dbQueries.GetChirp(ctx, id)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if chirp.Kind == chirpKindRechirp && chirp.RefChirpID.Valid {
+		return cfg.dbQueries.GetChirp(ctx, chirp.RefChirpID.UUID)
+	}
+	return chirp, nil
+}
+
+// unlikeChirp takes back user's like of chirp and the notification it caused.
+func (cfg *apiConfig) unlikeChirp(ctx context.Context, chirp database.Chirp, user uuid.UUID) error {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	rows, err := qtx.UnlikeChirp(ctx, database.UnlikeChirpParams{UserID: user, ChirpID: chirp.ID})
+	if err != nil {
+		return err
+	}
+	if rows > 0 {
+		err = qtx.DeleteNotification(ctx, database.DeleteNotificationParams{UserID: chirp.UserID, ActorID: user,
+			Type: notifyLike, ChirpID: uuid.NullUUID{UUID: chirp.ID, Valid: true}})
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
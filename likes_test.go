@@ -0,0 +1,94 @@
+package main
+
+import (
+	"chirpy/internal/broker"
+	"chirpy/internal/database"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+// TestLikeNotifies checks that a like notifies the author of the chirp it ends up
+// on the first time only, and never the liker themselves.
+func TestLikeNotifies(t *testing.T) {
+	author, liker := uuid.New(), uuid.New()
+	now := time.Now().UTC()
+	chirp := database.Chirp{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Body: "hi", UserID: author,
+		Kind: chirpKindChirp}
+	rechirp := database.Chirp{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, UserID: uuid.New(),
+		Kind: chirpKindRechirp, RefChirpID: uuid.NullUUID{UUID: chirp.ID, Valid: true}}
+	tests := []struct {
+		name   string
+		liker  uuid.UUID
+		liked  database.Chirp
+		rows   int64
+		notify bool
+	}{
+		{"first like", liker, chirp, 1, true},
+		{"like again", liker, chirp, 0, false},
+		{"own chirp", author, chirp, 1, false},
+		{"like of a rechirp", liker, rechirp, 1, true},
+	}
+	for _, tc := range tests {
+		cfg, mock := mockConfig(t)
+		events, cancel := cfg.broker.Subscribe(4, broker.User(author))
+		mock.ExpectQuery("name: GetChirp ").WithArgs(tc.liked.ID).WillReturnRows(chirpRows(tc.liked))
+		if tc.liked.Kind == chirpKindRechirp {
+			mock.ExpectQuery("name: GetChirp ").WithArgs(chirp.ID).WillReturnRows(chirpRows(chirp))
+		}
+		mock.ExpectQuery("name: IsBlocked ").WithArgs(tc.liker, author).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery("name: GetChirp ").WithArgs(chirp.ID).WillReturnRows(chirpRows(chirp))
+		mock.ExpectBegin()
+		mock.ExpectExec("name: LikeChirp ").WithArgs(tc.liker, chirp.ID).WillReturnResult(sqlmock.NewResult(0, tc.rows))
+		if tc.notify {
+			expectNotification(mock, notifyLike, author, tc.liker, uuid.NullUUID{UUID: chirp.ID, Valid: true})
+		}
+		mock.ExpectCommit()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/chirps/"+tc.liked.ID.String()+"/like", nil)
+		req.Header.Set("Authorization", authHeader(t, tc.liker))
+		recorder := httptest.NewRecorder()
+		cfg.routes().ServeHTTP(recorder, req)
+		if recorder.Code != http.StatusNoContent {
+			t.Errorf("%s: POST like = %d, want 204: %s", tc.name, recorder.Code, recorder.Body)
+		}
+		if got := published(events); (len(got) > 0) != tc.notify {
+			t.Errorf("%s: published %+v, want a notification: %v", tc.name, got, tc.notify)
+		}
+		cancel()
+	}
+}
+
+// TestUnlikeTakesBackNotification checks that undoing a like removes the
+// notification it caused, and that undoing no like changes nothing.
+func TestUnlikeTakesBackNotification(t *testing.T) {
+	author, liker := uuid.New(), uuid.New()
+	now := time.Now().UTC()
+	chirp := database.Chirp{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Body: "hi", UserID: author,
+		Kind: chirpKindChirp}
+	for _, rows := range []int64{1, 0} {
+		cfg, mock := mockConfig(t)
+		mock.ExpectQuery("name: GetChirp ").WithArgs(chirp.ID).WillReturnRows(chirpRows(chirp))
+		mock.ExpectBegin()
+		mock.ExpectExec("name: UnlikeChirp ").WithArgs(liker, chirp.ID).WillReturnResult(sqlmock.NewResult(0, rows))
+		if rows > 0 {
+			mock.ExpectExec("name: DeleteNotification ").
+				WithArgs(author, liker, notifyLike, uuid.NullUUID{UUID: chirp.ID, Valid: true}).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		mock.ExpectCommit()
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/v2/chirps/"+chirp.ID.String()+"/like", nil)
+		req.Header.Set("Authorization", authHeader(t, liker))
+		recorder := httptest.NewRecorder()
+		cfg.routes().ServeHTTP(recorder, req)
+		if recorder.Code != http.StatusNoContent {
+			t.Errorf("DELETE like with %d likes = %d, want 204: %s", rows, recorder.Code, recorder.Body)
+		}
+	}
+}
@@ -1,10 +1,15 @@
 package main
 
 import (
+	"chirpy/internal/activitypub"
 	"chirpy/internal/auth"
+	"chirpy/internal/broker"
 	"chirpy/internal/database"
+	"chirpy/internal/entities"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -12,17 +17,25 @@ import (
 	"strings"
 	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type apiConfig struct {
 	fileserverHits atomic.Int32
+	db             *sql.DB
 	dbQueries      *database.Queries
 	platform       string
 	sekrit         string
+	polkaKey       string
+	publicURL      string
+	broker         broker.Broker
+	feeds          feedCache
+	keyFetches     keyFetches
+	v1Deprecation  apiDeprecation
 }
 
 func (cfg *apiConfig) middlewareMetricsInc(next func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
@@ -55,6 +68,12 @@ type chirpMsg struct {
 	UserId uuid.UUID `json:"user_id"`
 }
 
+// chirpBody is what clients send to post or edit a chirp. Its length limit
+// depends on who is posting, so handlers check it themselves.
+type chirpBody struct {
+	Body string `json:"body"`
+}
+
 type createHeader struct {
 	Id        uuid.UUID `json:"id"`
 	CreatedAt time.Time `json:"created_at"`
@@ -68,26 +87,57 @@ type chirpErr struct {
 type chirpResp struct {
 	createHeader
 	chirpMsg
+	AuthorHandle string        `json:"author_handle"`
+	Kind         string        `json:"kind"`
+	RefChirp     any           `json:"ref_chirp,omitempty"`
+	Entities     []chirpEntity `json:"entities"`
+	Likes        int64         `json:"likes"`
+	Filtered     bool          `json:"filtered,omitempty"`
+}
+
+// chirpTombstone stands in for a rechirped, quoted or replied-to chirp that has since been deleted.
+type chirpTombstone struct {
+	Id   uuid.UUID `json:"id"`
+	Kind string    `json:"kind"`
 }
 
 type addUser struct {
-	Password string `json:"password"`
-	Email    string `json:"email"`
+	Password string `json:"password" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
+	Handle   string `json:"handle" validate:"required"`
+}
+
+// addUserV1 is addUser as v1 clients send it. They predate handles, so theirs
+// is optional and CreateUser gives them a default one if it is left out.
+type addUserV1 struct {
+	Password string `json:"password" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
+	Handle   string `json:"handle"`
 }
 
 type addedUser struct {
 	createHeader
-	Email string `json:"email"`
+	Email       string `json:"email"`
+	Handle      string `json:"handle"`
+	IsChirpyRed bool   `json:"is_chirpy_red"`
 }
 
 type loginUser struct {
-	Password string `json:"password"`
-	Email    string `json:"email"`
+	Password string `json:"password" validate:"required"`
+	Email    string `json:"email" validate:"required"`
+}
+
+type updateUser struct {
+	Password string `json:"password" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
+	Handle   string `json:"handle,omitempty"`
 }
 
 type loggedinUser struct {
 	createHeader
 	Email        string `json:"email"`
+	Handle       string `json:"handle"`
+	IsChirpyRed  bool   `json:"is_chirpy_red"`
 	Token        string `json:"token"`
 	RefreshToken string `json:"refresh_token"`
 }
@@ -99,26 +149,47 @@ type refreshedToken struct {
 const dbEnv = "DB_URL"
 const platformEnv = "PLATFORM"
 const sekritEnv = "SECRET"
+const brokerEnv = "BROKER"
+const polkaEnv = "POLKA_KEY"
+const portEnv = "PORT"
+const publicURLEnv = "PUBLIC_URL"
+const defaultPort = "8080"
+const postgresBroker = "postgres"
 const devPlatform = "dev"
 const lengthLimit = 140
+const redLengthLimit = 280
 const jsonContent = "application/json"
 const textContent = "text/plain; charset=utf-8"
-const marshalErrorTemplate = "{\"error\":\"Marshal error \"%s\" when trying to respond to \"%s\"}"
 const maxExpireTime = time.Hour
+const chirpKindChirp = "chirp"
+const chirpKindRechirp = "rechirp"
+const chirpKindQuote = "quote"
+const chirpKindReply = "reply"
+const chirpKindTombstone = "tombstone"
+const pqUniqueViolation = "23505"
+const handleIndex = "users_handle_lower_idx"
+const rechirpIndex = "chirps_rechirp_once_idx"
+const handleCooldown = 30 * 24 * time.Hour
 
 var dirtyWords = []string{"kerfuffle", "sharbert", "fornax"}
 
 func handleJsonWrite(writer http.ResponseWriter, code int, msg string, jsonStruct any) {
 	resp, err := json.Marshal(jsonStruct)
 	if err != nil {
+		fmt.Printf("marshal response to %q: %v\n", msg, err)
 		writer.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprintf(writer, marshalErrorTemplate, err, msg)
 	} else {
 		writer.WriteHeader(code)
 		writer.Write(resp)
 	}
 }
 
+// isUniqueViolation reports whether err is Postgres rejecting a duplicate in the named unique index.
+func isUniqueViolation(err error, index string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation && pqErr.Constraint == index
+}
+
 func clean(dirty string) string {
 	words := strings.Split(dirty, " ")
 	for i, word := range words {
@@ -131,8 +202,191 @@ func clean(dirty string) string {
 
 func chirpConv(dbChirp database.Chirp) chirpResp {
 	return chirpResp{createHeader: createHeader{Id: dbChirp.ID, CreatedAt: dbChirp.CreatedAt, UpdatedAt: dbChirp.UpdatedAt},
-		chirpMsg: chirpMsg{Body: dbChirp.Body, UserId: dbChirp.UserID}}
+		chirpMsg: chirpMsg{Body: dbChirp.Body, UserId: dbChirp.UserID}, Kind: dbChirp.Kind, Entities: []chirpEntity{}}
+}
+
+// chirpsConv converts chirps and embeds the chirps they rechirp, quote or reply to,
+// loading every referenced chirp, all entities and all like counts in one query
+// each. References to deleted chirps, or to chirps by someone with a block between
+// them and viewer, become tombstones.
+func (cfg *apiConfig) chirpsConv(ctx context.Context, viewer uuid.NullUUID, dbChirps []database.Chirp) ([]chirpResp, error) {
+	var refIDs []uuid.UUID
+	chirpIDs := make([]uuid.UUID, 0, len(dbChirps))
+	for i := range dbChirps {
+		chirpIDs = append(chirpIDs, dbChirps[i].ID)
+		if dbChirps[i].RefChirpID.Valid {
+			refIDs = append(refIDs, dbChirps[i].RefChirpID.UUID)
+		}
+	}
+	refs := make(map[uuid.UUID]database.Chirp, len(refIDs))
+	if len(refIDs) > 0 {
+		found, err := cfg.dbQueries.GetChirpsByIDs(ctx, refIDs)
+		if err != nil {
+			return nil, err
+		}
+		refAuthors := make([]uuid.UUID, len(found))
+		for i := range found {
+			refAuthors[i] = found[i].UserID
+		}
+		blocked, err := cfg.blockedAmong(ctx, viewer, refAuthors)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range found {
+			if !blocked[ref.UserID] {
+				refs[ref.ID] = ref
+				chirpIDs = append(chirpIDs, ref.ID)
+			}
+		}
+	}
+	chirpEntities, err := cfg.chirpEntities(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	likeCounts, err := cfg.dbQueries.GetLikeCounts(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	likes := make(map[uuid.UUID]int64, len(likeCounts))
+	for _, count := range likeCounts {
+		likes[count.ChirpID] = count.Likes
+	}
+	authorIDs := make([]uuid.UUID, 0, len(dbChirps)+len(refs))
+	for i := range dbChirps {
+		authorIDs = append(authorIDs, dbChirps[i].UserID)
+	}
+	for _, ref := range refs {
+		authorIDs = append(authorIDs, ref.UserID)
+	}
+	authors, err := cfg.dbQueries.GetUserHandles(ctx, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+	handles := make(map[uuid.UUID]string, len(authors))
+	for _, author := range authors {
+		handles[author.ID] = author.Handle
+	}
+	jsonChirps := make([]chirpResp, len(dbChirps))
+	for i := range dbChirps {
+		jsonChirps[i] = chirpConv(dbChirps[i])
+		jsonChirps[i].AuthorHandle = handles[dbChirps[i].UserID]
+		jsonChirps[i].Likes = likes[dbChirps[i].ID]
+		if found, ok := chirpEntities[dbChirps[i].ID]; ok {
+			jsonChirps[i].Entities = found
+		}
+		if !dbChirps[i].RefChirpID.Valid {
+			continue
+		}
+		if ref, ok := refs[dbChirps[i].RefChirpID.UUID]; ok {
+			refChirp := chirpConv(ref)
+			refChirp.AuthorHandle = handles[ref.UserID]
+			refChirp.Likes = likes[ref.ID]
+			if found, ok := chirpEntities[ref.ID]; ok {
+				refChirp.Entities = found
+			}
+			jsonChirps[i].RefChirp = &refChirp
+		} else {
+			jsonChirps[i].RefChirp = chirpTombstone{Id: dbChirps[i].RefChirpID.UUID, Kind: chirpKindTombstone}
+		}
+	}
+	return jsonChirps, nil
+}
+
+func (cfg *apiConfig) chirpRespond(writer http.ResponseWriter, req *http.Request, code int, msg string, dbChirp database.Chirp) {
+	jsonChirps, err := cfg.chirpsConv(req.Context(), cfg.optionalUser(req.Header), []database.Chirp{dbChirp})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	handleJsonWrite(writer, code, msg, jsonChirps[0])
+}
+
+// storeChirp creates a chirp, indexes its hashtags, resolves its mentions and
+// notifies the people it mentions or responds to, all in a single transaction,
+// then publishes it to live streams.
+func (cfg *apiConfig) storeChirp(ctx context.Context, params database.CreateRefChirpParams) (database.Chirp, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	chirp, err := qtx.CreateRefChirp(ctx, params)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if tags := entities.Hashtags(chirp.Body); len(tags) > 0 {
+		if err = qtx.AddChirpHashtags(ctx, database.AddChirpHashtagsParams{ChirpID: chirp.ID, Tags: tags}); err != nil {
+			return database.Chirp{}, err
+		}
+	}
+	mentioned, err := addMentions(ctx, qtx, chirp)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	events, err := notifyChirp(ctx, qtx, chirp, mentioned)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if err = enqueueWebhooks(ctx, qtx, webhookChirpCreated, chirp.UserID, chirpConv(chirp)); err != nil {
+		return database.Chirp{}, err
+	}
+	if err = cfg.enqueueFederation(ctx, qtx, "Create", chirp); err != nil {
+		return database.Chirp{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return database.Chirp{}, err
+	}
+	cfg.publish(ctx, append(events, broker.Event{Chirp: &chirp})...)
+	return chirp, nil
+}
+
+// publish announces events to live streams once the transaction that wrote them
+// has committed. The writes stand either way, so a failed publish only costs live
+// streams; clients that resume will pick the data up from the database.
+func (cfg *apiConfig) publish(ctx context.Context, events ...broker.Event) {
+	for _, event := range events {
+		if err := cfg.broker.Publish(ctx, event); err != nil {
+			fmt.Printf("publish event: %v\n", err)
+		}
+	}
+}
+
+// refTarget finds the chirp named in the request path that a new rechirp, quote or
+// reply by author should point at, as resolveRef does.
+func (cfg *apiConfig) refTarget(req *http.Request, author uuid.UUID) (uuid.UUID, error) {
+	id, err := parseID(req)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	return cfg.resolveRef(req.Context(), author, id)
+}
+
+// resolveRef finds the chirp that a new rechirp, quote, reply or like by author
+// of chirp id should point at. Rechirps are followed back to the chirp they amplify.
+// It returns errBlocked if there is a block between author and that chirp's author.
+func (cfg *apiConfig) resolveRef(ctx context.Context, author, id uuid.UUID) (uuid.UUID, error) {
+	ref, err := cfg.dbQueries.GetChirp(ctx, id)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	if ref.Kind == chirpKindRechirp {
+		if !ref.RefChirpID.Valid {
+			return uuid.UUID{}, fmt.Errorf("rechirped chirp has been deleted")
+		}
+		if ref, err = cfg.dbQueries.GetChirp(ctx, ref.RefChirpID.UUID); err != nil {
+			return uuid.UUID{}, err
+		}
+	}
+	blocked, err := cfg.dbQueries.IsBlocked(ctx, database.IsBlockedParams{UserA: author, UserB: ref.UserID})
+	if err != nil {
+		return uuid.UUID{}, err
+	} else if blocked {
+		return uuid.UUID{}, errBlocked
+	}
+	return ref.ID, nil
 }
+
 func (cfg *apiConfig) validateUser(head http.Header) (uuid.UUID, error) {
 	token, err := auth.GetBearerToken(head)
 	if err != nil {
@@ -147,59 +401,169 @@ func (cfg *apiConfig) validateUser(head http.Header) (uuid.UUID, error) {
 
 func (cfg *apiConfig) handleMakeChirp(writer http.ResponseWriter, req *http.Request) {
 	writer.Header()["Content-Type"] = []string{jsonContent}
-	decoder := json.NewDecoder(req.Body)
-	msg := chirpMsg{}
-	if err := decoder.Decode(&msg); err != nil {
-		handleJsonWrite(writer, http.StatusBadRequest, msg.Body, chirpErr{Error: err.Error()})
-	} else if len(msg.Body) > lengthLimit {
-		handleJsonWrite(writer, http.StatusBadRequest, msg.Body, chirpErr{Error: "Chirp is too long"})
+	msg := chirpBody{}
+	if !decodeRequest(writer, req, &msg) {
+		return
+	} else if utf8.RuneCountInString(msg.Body) > redLengthLimit {
+		handleProblem(writer, req, http.StatusBadRequest, codeChirpTooLong, "Chirp is too long")
 	} else {
 		id, err := cfg.validateUser(req.Header)
 		if err != nil {
-			handleJsonWrite(writer, http.StatusUnauthorized, msg.Body, chirpErr{Error: err.Error()})
+			handleError(writer, req, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if tooLong, err := cfg.chirpTooLong(req.Context(), id, msg.Body); err != nil {
+			handleFault(writer, req, http.StatusInternalServerError, err)
+			return
+		} else if tooLong {
+			handleProblem(writer, req, http.StatusBadRequest, codeChirpTooLong, "Chirp is too long")
 			return
 		}
-		chirp, err := cfg.dbQueries.CreateChirp(req.Context(), database.CreateChirpParams{Body: clean(msg.Body), UserID: id})
+		chirp, err := cfg.storeChirp(req.Context(), database.CreateRefChirpParams{Body: clean(msg.Body), UserID: id, Kind: chirpKindChirp})
 		if err != nil {
-			handleJsonWrite(writer, http.StatusBadRequest, msg.Body, chirpErr{Error: err.Error()})
+			handleFault(writer, req, versionedStatus(req, http.StatusBadRequest, http.StatusInternalServerError), err)
+			return
 		}
-		handleJsonWrite(writer, http.StatusCreated, msg.Body, chirpConv(chirp))
+		cfg.chirpRespond(writer, req, http.StatusCreated, msg.Body, chirp)
+	}
+}
+
+func (cfg *apiConfig) handleRechirp(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	id, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	refID, err := cfg.refTarget(req, id)
+	if errors.Is(err, errBlocked) {
+		handleProblem(writer, req, http.StatusForbidden, codeBlocked, err.Error())
+		return
+	} else if err != nil {
+		handleError(writer, req, http.StatusNotFound, "Chirp not found")
+		return
+	}
+	chirp, err := cfg.storeChirp(req.Context(), database.CreateRefChirpParams{UserID: id, Kind: chirpKindRechirp,
+		RefChirpID: uuid.NullUUID{UUID: refID, Valid: true}})
+	if isUniqueViolation(err, rechirpIndex) {
+		handleProblem(writer, req, http.StatusConflict, codeAlreadyRechirped, "Chirp has already been rechirped")
+		return
+	} else if err != nil {
+		handleFault(writer, req, versionedStatus(req, http.StatusBadRequest, http.StatusInternalServerError), err)
+		return
+	}
+	cfg.chirpRespond(writer, req, http.StatusCreated, "rechirp", chirp)
+}
+
+func (cfg *apiConfig) handleQuoteChirp(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	msg := chirpBody{}
+	if !decodeRequest(writer, req, &msg) {
+		return
+	} else if utf8.RuneCountInString(msg.Body) > redLengthLimit {
+		handleProblem(writer, req, http.StatusBadRequest, codeChirpTooLong, "Chirp is too long")
+		return
+	}
+	id, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if tooLong, err := cfg.chirpTooLong(req.Context(), id, msg.Body); err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	} else if tooLong {
+		handleProblem(writer, req, http.StatusBadRequest, codeChirpTooLong, "Chirp is too long")
+		return
 	}
+	refID, err := cfg.refTarget(req, id)
+	if errors.Is(err, errBlocked) {
+		handleProblem(writer, req, http.StatusForbidden, codeBlocked, err.Error())
+		return
+	} else if err != nil {
+		handleError(writer, req, http.StatusNotFound, "Chirp not found")
+		return
+	}
+	chirp, err := cfg.storeChirp(req.Context(), database.CreateRefChirpParams{Body: clean(msg.Body), UserID: id,
+		Kind: chirpKindQuote, RefChirpID: uuid.NullUUID{UUID: refID, Valid: true}})
+	if err != nil {
+		handleFault(writer, req, versionedStatus(req, http.StatusBadRequest, http.StatusInternalServerError), err)
+		return
+	}
+	cfg.chirpRespond(writer, req, http.StatusCreated, msg.Body, chirp)
 }
 
 func createUserConv(dbUser database.CreateUserRow) addedUser {
-	return addedUser{createHeader: createHeader{Id: dbUser.ID, CreatedAt: dbUser.CreatedAt, UpdatedAt: dbUser.UpdatedAt}, Email: dbUser.Email}
+	return addedUser{createHeader: createHeader{Id: dbUser.ID, CreatedAt: dbUser.CreatedAt, UpdatedAt: dbUser.UpdatedAt}, Email: dbUser.Email,
+		Handle: dbUser.Handle, IsChirpyRed: dbUser.IsChirpyRed}
 }
 
 func updateUserConv(dbUser database.UpdateUserRow) addedUser {
-	return addedUser{createHeader: createHeader{Id: dbUser.ID, CreatedAt: dbUser.CreatedAt, UpdatedAt: dbUser.UpdatedAt}, Email: dbUser.Email}
+	return addedUser{createHeader: createHeader{Id: dbUser.ID, CreatedAt: dbUser.CreatedAt, UpdatedAt: dbUser.UpdatedAt}, Email: dbUser.Email,
+		Handle: dbUser.Handle, IsChirpyRed: dbUser.IsChirpyRed}
 }
 
 func loginConv(dbUser database.User) loggedinUser {
-	return loggedinUser{createHeader: createHeader{Id: dbUser.ID, CreatedAt: dbUser.CreatedAt, UpdatedAt: dbUser.UpdatedAt}, Email: dbUser.Email}
+	return loggedinUser{createHeader: createHeader{Id: dbUser.ID, CreatedAt: dbUser.CreatedAt, UpdatedAt: dbUser.UpdatedAt}, Email: dbUser.Email,
+		Handle: dbUser.Handle, IsChirpyRed: dbUser.IsChirpyRed}
 }
 
 func (cfg *apiConfig) handleCreateUser(writer http.ResponseWriter, req *http.Request) {
 	writer.Header()["Content-Type"] = []string{jsonContent}
-	decoder := json.NewDecoder(req.Body)
 	msg := addUser{}
-	if err := decoder.Decode(&msg); err != nil {
-		handleJsonWrite(writer, http.StatusBadRequest, "Createuser", chirpErr{Error: err.Error()})
+	var dst any = &msg
+	if requestVersion(req) == apiV1 {
+		dst = (*addUserV1)(&msg)
+	}
+	if !decodeRequest(writer, req, dst) {
 		return
 	}
+	if msg.Handle != "" {
+		if err := entities.ValidateHandle(msg.Handle); err != nil {
+			handleError(writer, req, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 	hashed, err := auth.HashPassword(msg.Password)
 	if err != nil {
-		handleJsonWrite(writer, http.StatusInternalServerError, "hash password", chirpErr{Error: err.Error()})
+		handleFault(writer, req, http.StatusInternalServerError, err)
 		return
 	}
-	user, err := cfg.dbQueries.CreateUser(req.Context(), database.CreateUserParams{Email: msg.Email, HashedPassword: hashed})
-	if err != nil {
-		handleJsonWrite(writer, http.StatusBadRequest, msg.Email, chirpErr{Error: err.Error()})
+	user, err := cfg.createUser(req.Context(), database.CreateUserParams{Email: msg.Email, HashedPassword: hashed,
+		Handle: msg.Handle})
+	if isUniqueViolation(err, handleIndex) {
+		handleProblem(writer, req, http.StatusConflict, codeHandleTaken, "Handle is already taken")
+		return
+	} else if err != nil {
+		handleFault(writer, req, versionedStatus(req, http.StatusBadRequest, http.StatusInternalServerError), err)
 		return
 	}
 	handleJsonWrite(writer, http.StatusCreated, msg.Email, createUserConv(user))
 }
 
+// createUser creates a user and tells webhooks about them.
+func (cfg *apiConfig) createUser(ctx context.Context, params database.CreateUserParams) (database.CreateUserRow, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return database.CreateUserRow{}, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	user, err := qtx.CreateUser(ctx, params)
+	if err != nil {
+		return database.CreateUserRow{}, err
+	}
+	err = enqueueWebhooks(ctx, qtx, webhookUserCreated, user.ID, webhookUser{Id: user.ID, Handle: user.Handle,
+		CreatedAt: user.CreatedAt})
+	if err != nil {
+		return database.CreateUserRow{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return database.CreateUserRow{}, err
+	}
+	return user, nil
+}
+
 func (cfg *apiConfig) handleReset(writer http.ResponseWriter, req *http.Request) {
 	writer.Header()["Content-Type"] = []string{textContent}
 	if cfg.platform != devPlatform {
@@ -219,18 +583,47 @@ func (cfg *apiConfig) handleReset(writer http.ResponseWriter, req *http.Request)
 
 func (cfg *apiConfig) handleGetChirps(writer http.ResponseWriter, req *http.Request) {
 	writer.Header()["Content-Type"] = []string{jsonContent}
-	chirps, err := cfg.dbQueries.GetChirps(req.Context())
+	var author uuid.NullUUID
+	if authorStr := req.URL.Query().Get("author_id"); authorStr != "" {
+		id, err := uuid.Parse(authorStr)
+		if err != nil {
+			handleError(writer, req, http.StatusBadRequest, "invalid author_id")
+			return
+		}
+		author = uuid.NullUUID{UUID: id, Valid: true}
+	}
+	jsonChirps, err := cfg.loadChirps(req.Context(), cfg.optionalUser(req.Header), author, 0, false)
 	if err != nil {
-		handleJsonWrite(writer, http.StatusBadRequest, "GetChirps", chirpErr{Error: err.Error()})
+		handleFault(writer, req, versionedStatus(req, http.StatusBadRequest, http.StatusInternalServerError), err)
 		return
 	}
-	jsonChirps := make([]chirpResp, len(chirps))
-	for i := range chirps {
-		jsonChirps[i] = chirpConv(chirps[i])
-	}
+	// The API lists chirps oldest first.
+	slices.Reverse(jsonChirps)
 	handleJsonWrite(writer, http.StatusOK, "GetChirps", jsonChirps)
 }
 
+// maxChirps is the max_chirps argument for at most limit chirps, or all of them
+// if limit is 0.
+func maxChirps(limit int) sql.NullInt32 {
+	return sql.NullInt32{Int32: int32(limit), Valid: limit > 0}
+}
+
+// loadChirps loads the newest limit chirps viewer may see, newest first, or only
+// those by author if it is set, converted and filtered for viewer. A limit of 0
+// loads them all, and originals leaves rechirps out.
+func (cfg *apiConfig) loadChirps(ctx context.Context, viewer, author uuid.NullUUID, limit int, originals bool) ([]chirpResp, error) {
+	chirps, err := cfg.dbQueries.GetNewestChirps(ctx, database.GetNewestChirpsParams{ViewerID: viewer, AuthorID: author,
+		OriginalsOnly: originals, MaxChirps: maxChirps(limit)})
+	if err != nil {
+		return nil, err
+	}
+	jsonChirps, err := cfg.chirpsConv(ctx, viewer, chirps)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.filterChirps(ctx, viewer, jsonChirps)
+}
+
 func parseID(req *http.Request) (uuid.UUID, error) {
 	idstr := req.PathValue("id")
 	if len(idstr) == 0 {
@@ -243,44 +636,75 @@ func (cfg *apiConfig) handleGetChirp(writer http.ResponseWriter, req *http.Reque
 	writer.Header()["Content-Type"] = []string{jsonContent}
 	id, err := parseID(req)
 	if err != nil {
-		handleJsonWrite(writer, http.StatusNotFound, "GetChirp", chirpErr{Error: err.Error()})
+		handleError(writer, req, http.StatusNotFound, err.Error())
 		return
 	}
-	chirp, err := cfg.dbQueries.GetChirp(req.Context(), id)
-	if err != nil {
-		handleJsonWrite(writer, http.StatusNotFound, "GetChirp", chirpErr{Error: err.Error()})
+	chirp, err := cfg.loadChirp(req.Context(), cfg.optionalUser(req.Header), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		handleError(writer, req, http.StatusNotFound, "Chirp not found")
+		return
+	} else if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
 		return
 	}
-	handleJsonWrite(writer, http.StatusOK, "GetChirps", chirpConv(chirp))
+	handleJsonWrite(writer, http.StatusOK, "GetChirp", chirp)
+}
+
+// loadChirp loads one chirp as viewer sees it. A chirp by someone with a block
+// between them and viewer is sql.ErrNoRows, exactly like a missing one, so the
+// block is not revealed.
+func (cfg *apiConfig) loadChirp(ctx context.Context, viewer uuid.NullUUID, id uuid.UUID) (chirpResp, error) {
+	chirp, err := cfg.dbQueries.GetChirp(ctx, id)
+	if err != nil {
+		return chirpResp{}, err
+	}
+	if viewer.Valid {
+		blocked, err := cfg.dbQueries.IsBlocked(ctx, database.IsBlockedParams{UserA: viewer.UUID, UserB: chirp.UserID})
+		if err != nil {
+			return chirpResp{}, err
+		} else if blocked {
+			return chirpResp{}, sql.ErrNoRows
+		}
+	}
+	jsonChirps, err := cfg.chirpsConv(ctx, viewer, []database.Chirp{chirp})
+	if err != nil {
+		return chirpResp{}, err
+	}
+	return jsonChirps[0], nil
 }
 
 func (cfg *apiConfig) handleLogin(writer http.ResponseWriter, req *http.Request) {
 	writer.Header()["Content-Type"] = []string{jsonContent}
-	decoder := json.NewDecoder(req.Body)
 	msg := loginUser{}
-	if err := decoder.Decode(&msg); err != nil {
-		handleJsonWrite(writer, http.StatusBadRequest, "login", chirpErr{Error: err.Error()})
+	if !decodeRequest(writer, req, &msg) {
 		return
 	}
 	user, err := cfg.dbQueries.GetUserByEmail(req.Context(), msg.Email)
-	if err != nil {
-		handleJsonWrite(writer, http.StatusBadRequest, "login", chirpErr{Error: err.Error()})
+	if requestVersion(req) >= apiV2 && errors.Is(err, sql.ErrNoRows) {
+		// An unknown email is as wrong as a wrong password, and must not look different.
+		handleError(writer, req, http.StatusUnauthorized, "Incorrect email or password")
+		return
+	} else if errors.Is(err, sql.ErrNoRows) {
+		handleError(writer, req, http.StatusBadRequest, "Incorrect email or password")
+		return
+	} else if err != nil {
+		handleFault(writer, req, versionedStatus(req, http.StatusBadRequest, http.StatusInternalServerError), err)
 		return
 	}
 	if err = auth.CheckPasswordHash(msg.Password, user.HashedPassword); err != nil {
-		handleJsonWrite(writer, http.StatusUnauthorized, "login", chirpErr{Error: "Incorrect email or password"})
+		handleError(writer, req, http.StatusUnauthorized, "Incorrect email or password")
 		return
 	}
 	newUser := loginConv(user)
 	newUser.Token, err = auth.MakeJWT(user.ID, cfg.sekrit, time.Hour)
 	if err != nil {
-		handleJsonWrite(writer, http.StatusBadRequest, "login", chirpErr{Error: err.Error()})
+		handleFault(writer, req, versionedStatus(req, http.StatusBadRequest, http.StatusInternalServerError), err)
 		return
 	}
 	refreshParams := database.AddRefreshTokenParams{Token: auth.MakeRefreshToken(), Email: msg.Email}
 	newUser.RefreshToken, err = cfg.dbQueries.AddRefreshToken(req.Context(), refreshParams)
 	if err != nil {
-		handleJsonWrite(writer, http.StatusInternalServerError, "login", chirpErr{Error: err.Error()})
+		handleFault(writer, req, http.StatusInternalServerError, err)
 		return
 	}
 	handleJsonWrite(writer, http.StatusOK, msg.Email, newUser)
@@ -289,22 +713,22 @@ func (cfg *apiConfig) handleLogin(writer http.ResponseWriter, req *http.Request)
 func (cfg *apiConfig) handleRefresh(writer http.ResponseWriter, req *http.Request) {
 	token, err := auth.GetBearerToken(req.Header)
 	if err != nil {
-		writer.WriteHeader(http.StatusUnauthorized)
+		statusError(writer, req, http.StatusUnauthorized, err.Error())
 		return
 	}
 	id, err := cfg.dbQueries.GetUserByToken(req.Context(), token)
 	if err != nil {
-		writer.WriteHeader(http.StatusUnauthorized)
+		statusError(writer, req, http.StatusUnauthorized, "Unknown refresh token")
 		return
 	}
 	if id.RevokedAt.Valid || id.ExpiresAt.Compare(time.Now()) < 0 {
-		writer.WriteHeader(http.StatusUnauthorized)
+		statusError(writer, req, http.StatusUnauthorized, "Refresh token has expired or been revoked")
 		return
 	}
 	var tokenMsg refreshedToken
 	tokenMsg.Token, err = auth.MakeJWT(id.UserID, cfg.sekrit, time.Hour)
 	if err != nil {
-		writer.WriteHeader(http.StatusUnauthorized)
+		statusFault(writer, req, versionedStatus(req, http.StatusUnauthorized, http.StatusInternalServerError), err)
 		return
 	}
 	writer.Header()["Content-Type"] = []string{jsonContent}
@@ -314,12 +738,12 @@ func (cfg *apiConfig) handleRefresh(writer http.ResponseWriter, req *http.Reques
 func (cfg *apiConfig) handleRevoke(writer http.ResponseWriter, req *http.Request) {
 	token, err := auth.GetBearerToken(req.Header)
 	if err != nil {
-		writer.WriteHeader(http.StatusUnauthorized)
+		statusError(writer, req, http.StatusUnauthorized, err.Error())
 		return
 	}
 	err = cfg.dbQueries.RevokeToken(req.Context(), token)
 	if err != nil {
-		handleJsonWrite(writer, http.StatusInternalServerError, "revoke", chirpErr{Error: err.Error()})
+		handleFault(writer, req, http.StatusInternalServerError, err)
 		return
 	}
 	writer.WriteHeader(http.StatusNoContent)
@@ -327,82 +751,234 @@ func (cfg *apiConfig) handleRevoke(writer http.ResponseWriter, req *http.Request
 
 func (cfg *apiConfig) handleUserPut(writer http.ResponseWriter, req *http.Request) {
 	writer.Header()["Content-Type"] = []string{jsonContent}
-	decoder := json.NewDecoder(req.Body)
-	msg := loginUser{}
-	if err := decoder.Decode(&msg); err != nil {
-		handleJsonWrite(writer, http.StatusBadRequest, "update", chirpErr{Error: err.Error()})
+	msg := updateUser{}
+	if !decodeRequest(writer, req, &msg) {
 		return
 	}
+	if msg.Handle != "" {
+		if err := entities.ValidateHandle(msg.Handle); err != nil {
+			handleError(writer, req, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 	hashed, err := auth.HashPassword(msg.Password)
 	if err != nil {
-		handleJsonWrite(writer, http.StatusInternalServerError, "update", chirpErr{Error: err.Error()})
+		handleFault(writer, req, http.StatusInternalServerError, err)
 		return
 	}
 	id, err := cfg.validateUser(req.Header)
 	if err != nil {
-		handleJsonWrite(writer, http.StatusUnauthorized, msg.Email, chirpErr{Error: err.Error()})
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
 		return
 	}
-	user, err := cfg.dbQueries.UpdateUser(req.Context(), database.UpdateUserParams{Email: msg.Email, HashedPassword: hashed, ID: id})
-	if err != nil {
-		handleJsonWrite(writer, http.StatusUnauthorized, msg.Email, chirpErr{Error: err.Error()})
+	user, err := cfg.updateUser(req.Context(), database.UpdateUserParams{Email: msg.Email, HashedPassword: hashed, ID: id},
+		msg.Handle)
+	var cooldown handleCooldownError
+	if errors.Is(err, sql.ErrNoRows) {
+		handleError(writer, req, http.StatusUnauthorized, "User not found")
+		return
+	} else if errors.As(err, &cooldown) {
+		handleProblem(writer, req, http.StatusTooManyRequests, codeHandleCooldown, err.Error())
+		return
+	} else if isUniqueViolation(err, handleIndex) {
+		handleProblem(writer, req, http.StatusConflict, codeHandleTaken, "Handle is already taken")
+		return
+	} else if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
 		return
 	}
 	handleJsonWrite(writer, http.StatusOK, msg.Email, updateUserConv(user))
 }
 
+// handleCooldownError refuses a handle change made within handleCooldown of the last one.
+type handleCooldownError struct {
+	next time.Time
+}
+
+func (err handleCooldownError) Error() string {
+	return "Handle cannot be changed again until " + err.next.Format(time.RFC3339)
+}
+
+// updateUser changes a user's email and password, and their handle too unless
+// handle is empty or unchanged. It returns sql.ErrNoRows if the user is gone
+// and a handleCooldownError if the handle was changed too recently.
+func (cfg *apiConfig) updateUser(ctx context.Context, params database.UpdateUserParams, handle string) (database.UpdateUserRow, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return database.UpdateUserRow{}, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	user, err := qtx.UpdateUser(ctx, params)
+	if err != nil {
+		return database.UpdateUserRow{}, err
+	}
+	if handle != "" && handle != user.Handle {
+		current, err := qtx.GetUserByID(ctx, params.ID)
+		if err != nil {
+			return database.UpdateUserRow{}, err
+		}
+		if current.HandleChangedAt.Valid && time.Since(current.HandleChangedAt.Time) < handleCooldown {
+			return database.UpdateUserRow{}, handleCooldownError{next: current.HandleChangedAt.Time.Add(handleCooldown)}
+		}
+		changed, err := qtx.UpdateUserHandle(ctx, database.UpdateUserHandleParams{Handle: handle, ID: params.ID})
+		if err != nil {
+			return database.UpdateUserRow{}, err
+		}
+		user.Handle, user.UpdatedAt = changed.Handle, changed.UpdatedAt
+	}
+	if err = tx.Commit(); err != nil {
+		return database.UpdateUserRow{}, err
+	}
+	return user, nil
+}
+
 func (cfg *apiConfig) handleDeleteChirp(writer http.ResponseWriter, req *http.Request) {
 	var args database.DeleteChirpParams
 	var err error
 	args.UserID, err = cfg.validateUser(req.Header)
 	if err != nil {
-		writer.WriteHeader(http.StatusUnauthorized)
+		statusError(writer, req, http.StatusUnauthorized, err.Error())
 		return
 	}
 	args.ID, err = parseID(req)
 	if err != nil {
-		writer.WriteHeader(http.StatusNotFound)
+		statusError(writer, req, http.StatusNotFound, "Chirp not found")
 		return
 	}
-	_, err = cfg.dbQueries.GetChirp(req.Context(), args.ID)
+	chirp, err := cfg.dbQueries.GetChirp(req.Context(), args.ID)
 	if err != nil {
-		writer.WriteHeader(http.StatusNotFound)
+		statusError(writer, req, http.StatusNotFound, "Chirp not found")
 		return
 	}
-	_, err = cfg.dbQueries.DeleteChirp(req.Context(), args)
-	if err != nil {
-		writer.WriteHeader(http.StatusForbidden)
+	if err = cfg.deleteChirp(req.Context(), chirp, args.UserID); errors.Is(err, errNotAuthor) {
+		statusError(writer, req, http.StatusForbidden, "Chirp belongs to someone else")
+		return
+	} else if err != nil {
+		statusFault(writer, req, http.StatusInternalServerError, err)
 		return
 	}
 	writer.WriteHeader(http.StatusNoContent)
 }
 
+var errNotAuthor = errors.New("chirp belongs to someone else")
+
+// deleteChirp deletes chirp if user wrote it, returning errNotAuthor if they did
+// not, and tells webhooks and followers on other servers that it is gone.
+func (cfg *apiConfig) deleteChirp(ctx context.Context, chirp database.Chirp, user uuid.UUID) error {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	if _, err = qtx.DeleteChirp(ctx, database.DeleteChirpParams{ID: chirp.ID, UserID: user}); err != nil {
+		return errNotAuthor
+	}
+	if err = enqueueWebhooks(ctx, qtx, webhookChirpDeleted, chirp.UserID, chirpConv(chirp)); err != nil {
+		return err
+	}
+	if err = cfg.enqueueFederation(ctx, qtx, "Delete", chirp); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// routeMux is a ServeMux that remembers the patterns registered on it, so tests
+// can check each one is described in the OpenAPI document.
+type routeMux struct {
+	*http.ServeMux
+	patterns []string
+}
+
+func (mux *routeMux) Handle(pattern string, handler http.Handler) {
+	mux.patterns = append(mux.patterns, pattern)
+	mux.ServeMux.Handle(pattern, handler)
+}
+
+func (mux *routeMux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	mux.patterns = append(mux.patterns, pattern)
+	mux.ServeMux.HandleFunc(pattern, handler)
+}
+
+func (mux *routeMux) ServeHTTP(writer http.ResponseWriter, req *http.Request) {
+	withRequestID(mux.ServeMux).ServeHTTP(writer, req)
+}
+
+func (cfg *apiConfig) routes() *routeMux {
+	serverMux := &routeMux{ServeMux: http.NewServeMux()}
+	serverMux.Handle("/app/", http.StripPrefix("/app", cfg.middlewareHandlerMetricsInc(http.FileServer(http.Dir(".")))))
+	serverMux.HandleFunc("GET /api/healthz", handleHealthz)
+	serverMux.HandleFunc("GET /api/openapi.json", cfg.middlewareMetricsInc(handleOpenAPI))
+	serverMux.HandleFunc("GET /admin/metrics", cfg.handleMetrics)
+	serverMux.HandleFunc("POST /admin/reset", cfg.handleReset)
+	for _, version := range apiVersions {
+		cfg.apiRoutes(serverMux, version)
+	}
+
+	serverMux.HandleFunc("GET /.well-known/webfinger", cfg.handleWebFinger)
+	serverMux.HandleFunc("GET /ap/users/{id}", cfg.handleActor)
+	serverMux.HandleFunc("POST /ap/users/{id}/inbox", cfg.handleInbox)
+	serverMux.HandleFunc("GET /ap/users/{id}/outbox", cfg.handleOutbox)
+	serverMux.HandleFunc("GET /ap/users/{id}/{collection}", cfg.handleFollowCollection)
+	serverMux.HandleFunc("GET /ap/chirps/{id}", cfg.handleNote)
+	serverMux.HandleFunc("GET /users/{id}/feed.atom", cfg.middlewareMetricsInc(cfg.handleUserFeed))
+	serverMux.HandleFunc("GET /users/{id}/feed.rss", cfg.middlewareMetricsInc(cfg.handleUserFeed))
+	serverMux.HandleFunc("GET /hashtags/{tag}/feed.atom", cfg.middlewareMetricsInc(cfg.handleHashtagFeed))
+	serverMux.HandleFunc("GET /hashtags/{tag}/feed.rss", cfg.middlewareMetricsInc(cfg.handleHashtagFeed))
+	serverMux.HandleFunc("GET /{$}", cfg.middlewareMetricsInc(cfg.handleTimelinePage))
+	serverMux.HandleFunc("GET /chirps/{id}", cfg.middlewareMetricsInc(cfg.handleChirpPage))
+	serverMux.HandleFunc("GET /users/{id}", cfg.middlewareMetricsInc(cfg.handleProfilePage))
+	serverMux.HandleFunc("GET /oembed", cfg.middlewareMetricsInc(cfg.handleOEmbed))
+	serverMux.HandleFunc("GET /embed/chirps/{id}", cfg.middlewareMetricsInc(cfg.handleEmbedPage))
+	serverMux.HandleFunc("GET /graphql", cfg.middlewareMetricsInc(cfg.handleGraphQL))
+	serverMux.HandleFunc("POST /graphql", cfg.middlewareMetricsInc(cfg.handleGraphQL))
+	return serverMux
+}
+
 func main() {
 	godotenv.Load()
 	dbURL := os.Getenv(dbEnv)
 	sekritStr := os.Getenv(sekritEnv)
+	port := os.Getenv(portEnv)
+	if port == "" {
+		port = defaultPort
+	}
+	// Other servers know our users by this URL, so it must be the one they reach us at.
+	publicURL := strings.TrimRight(os.Getenv(publicURLEnv), "/")
+	if publicURL == "" {
+		publicURL = "http://localhost:" + port
+	}
+	v1Deprecation, err := parseDeprecation(apiV1DeprecatedEnv, apiV1SunsetEnv)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 	db, err := sql.Open("postgres", dbURL)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
-	apiConf := &apiConfig{dbQueries: database.New(db), platform: os.Getenv(platformEnv), sekrit: sekritStr}
-	serverMux := http.NewServeMux()
-	serverMux.Handle("/app/", http.StripPrefix("/app", apiConf.middlewareHandlerMetricsInc(http.FileServer(http.Dir(".")))))
-	serverMux.HandleFunc("GET /api/healthz", handleHealthz)
-	serverMux.HandleFunc("GET /admin/metrics", apiConf.handleMetrics)
-	serverMux.HandleFunc("POST /admin/reset", apiConf.handleReset)
-	serverMux.HandleFunc("POST /api/chirps", apiConf.middlewareMetricsInc(apiConf.handleMakeChirp))
-	serverMux.HandleFunc("POST /api/users", apiConf.middlewareMetricsInc(apiConf.handleCreateUser))
-	serverMux.HandleFunc("GET /api/chirps", apiConf.middlewareMetricsInc(apiConf.handleGetChirps))
-	serverMux.HandleFunc("GET /api/chirps/{id}", apiConf.middlewareMetricsInc(apiConf.handleGetChirp))
-	serverMux.HandleFunc("POST /api/login", apiConf.middlewareMetricsInc(apiConf.handleLogin))
-	serverMux.HandleFunc("POST /api/refresh", apiConf.middlewareMetricsInc(apiConf.handleRefresh))
-	serverMux.HandleFunc("POST /api/revoke", apiConf.middlewareMetricsInc(apiConf.handleRevoke))
-	serverMux.HandleFunc("PUT /api/users", apiConf.middlewareMetricsInc(apiConf.handleUserPut))
-	serverMux.HandleFunc("DELETE /api/chirps/{id}", apiConf.middlewareMetricsInc(apiConf.handleDeleteChirp))
-
-	server := http.Server{Handler: serverMux, Addr: ":8080"}
+	apiConf := &apiConfig{db: db, dbQueries: database.New(db), platform: os.Getenv(platformEnv), sekrit: sekritStr,
+		polkaKey: os.Getenv(polkaEnv), publicURL: publicURL, broker: broker.NewMemory(), v1Deprecation: v1Deprecation}
+	if os.Getenv(brokerEnv) == postgresBroker {
+		if apiConf.broker, err = broker.NewPostgres(apiConf.dbQueries, dbURL); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	// Only an instance that is itself on localhost federates with peers there.
+	federationClient = newFederationClient(activitypub.Scheme(apiConf.domain()) == "http")
+	go apiConf.runTrending(context.Background(), trendingInterval)
+	go apiConf.runWebhooks(context.Background(), webhookInterval)
+	go apiConf.runFederation(context.Background(), federationInterval)
+	go apiConf.runIdempotencyCleanup(context.Background(), idempotencyCleanupInterval)
+	grpcPort := os.Getenv(grpcPortEnv)
+	if grpcPort == "" {
+		grpcPort = defaultGRPCPort
+	}
+	go apiConf.serveGRPC(grpcPort)
+	server := http.Server{Handler: apiConf.routes(), Addr: ":" + port}
 	err = server.ListenAndServe()
 	fmt.Println(err)
 }
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"chirpy/internal/database"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+// TestQuoteOfDeletedChirp checks that a quote outlives the chirp it quoted, which
+// is shown as a tombstone.
+func TestQuoteOfDeletedChirp(t *testing.T) {
+	cfg, mock := mockConfig(t)
+	now := time.Now().UTC()
+	quote := database.Chirp{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Body: "still true", UserID: uuid.New(),
+		Kind: chirpKindQuote, RefChirpID: uuid.NullUUID{UUID: uuid.New(), Valid: true}}
+	mock.ExpectQuery("name: GetChirp ").WithArgs(quote.ID).WillReturnRows(chirpRows(quote))
+	mock.ExpectQuery("name: GetChirpsByIDs ").WillReturnRows(chirpRows())
+	expectChirpDetails(mock, database.GetUserHandlesRow{ID: quote.UserID, Handle: "alice"})
+
+	recorder := httptest.NewRecorder()
+	cfg.routes().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v2/chirps/"+quote.ID.String(), nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET quote = %d: %s", recorder.Code, recorder.Body)
+	}
+	var got struct {
+		Body         string         `json:"body"`
+		AuthorHandle string         `json:"author_handle"`
+		RefChirp     chirpTombstone `json:"ref_chirp"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+		t.Fatalf("GET quote body %q: %v", recorder.Body, err)
+	}
+	want := chirpTombstone{Id: quote.RefChirpID.UUID, Kind: chirpKindTombstone}
+	if got.Body != quote.Body || got.AuthorHandle != "alice" || got.RefChirp != want {
+		t.Errorf("GET quote = %+v, want the quote with ref_chirp %+v", got, want)
+	}
+}
+
+// TestHandleCooldown checks that a handle can only be changed once every
+// handleCooldown, while the email and password can be changed any time.
+func TestHandleCooldown(t *testing.T) {
+	userColumns := []string{"id", "created_at", "updated_at", "email", "handle", "is_chirpy_red"}
+	tests := []struct {
+		name      string
+		changedAt sql.NullTime
+		want      int
+	}{
+		{"never changed", sql.NullTime{}, http.StatusOK},
+		{"changed long ago", sql.NullTime{Time: time.Now().Add(-handleCooldown - time.Hour), Valid: true}, http.StatusOK},
+		{"changed recently", sql.NullTime{Time: time.Now().Add(-time.Hour), Valid: true}, http.StatusTooManyRequests},
+	}
+	for _, tc := range tests {
+		cfg, mock := mockConfig(t)
+		id := uuid.New()
+		now := time.Now().UTC()
+		mock.ExpectBegin()
+		mock.ExpectQuery("name: UpdateUser ").WillReturnRows(sqlmock.NewRows(userColumns).
+			AddRow(id, now, now, "alice@example.com", "alice", false))
+		mock.ExpectQuery("name: GetUserByID ").WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"id", "created_at",
+			"updated_at", "email", "hashed_password", "handle", "handle_changed_at", "display_name", "bio", "avatar_url",
+			"is_chirpy_red"}).AddRow(id, now, now, "alice@example.com", "", "alice", tc.changedAt, "", "", "", false))
+		if tc.want == http.StatusOK {
+			mock.ExpectQuery("name: UpdateUserHandle ").WithArgs("alice2", id).WillReturnRows(
+				sqlmock.NewRows(userColumns).AddRow(id, now, now, "alice@example.com", "alice2", false))
+			mock.ExpectCommit()
+		} else {
+			mock.ExpectRollback()
+		}
+
+		req := httptest.NewRequest(http.MethodPut, "/api/v2/users",
+			strings.NewReader(`{"email":"alice@example.com","password":"hunter22","handle":"alice2"}`))
+		req.Header.Set("Content-Type", jsonContent)
+		req.Header.Set("Authorization", authHeader(t, id))
+		recorder := httptest.NewRecorder()
+		cfg.routes().ServeHTTP(recorder, req)
+		if recorder.Code != tc.want {
+			t.Errorf("%s: PUT /api/v2/users = %d, want %d: %s", tc.name, recorder.Code, tc.want, recorder.Body)
+			continue
+		}
+		var got struct {
+			Handle string `json:"handle"`
+			Code   string `json:"code"`
+		}
+		json.Unmarshal(recorder.Body.Bytes(), &got)
+		if tc.want == http.StatusOK && got.Handle != "alice2" {
+			t.Errorf("%s: handle = %q, want alice2", tc.name, got.Handle)
+		} else if tc.want != http.StatusOK && got.Code != codeHandleCooldown {
+			t.Errorf("%s: code = %q, want %q", tc.name, got.Code, codeHandleCooldown)
+		}
+	}
+}
+
+// TestSignupHandle checks that v1 clients may still sign up without a handle and
+// get a default one, while v2 clients must choose theirs.
+func TestSignupHandle(t *testing.T) {
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/api/users", http.StatusCreated},
+		{"/api/v1/users", http.StatusCreated},
+		{"/api/v2/users", http.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		cfg, mock := mockConfig(t)
+		if tc.want == http.StatusCreated {
+			id := uuid.New()
+			now := time.Now().UTC()
+			mock.ExpectBegin()
+			mock.ExpectQuery("name: CreateUser ").WithArgs("alice@example.com", sqlmock.AnyArg(), "").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at", "email", "handle",
+					"is_chirpy_red"}).AddRow(id, now, now, "alice@example.com", "user_"+strings.ReplaceAll(id.String(), "-", "")[:12], false))
+			mock.ExpectExec("name: EnqueueWebhookDeliveries ").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectCommit()
+		}
+		req := httptest.NewRequest(http.MethodPost, tc.path,
+			strings.NewReader(`{"email":"alice@example.com","password":"hunter22"}`))
+		req.Header.Set("Content-Type", jsonContent)
+		recorder := httptest.NewRecorder()
+		cfg.routes().ServeHTTP(recorder, req)
+		if recorder.Code != tc.want {
+			t.Errorf("POST %s = %d, want %d: %s", tc.path, recorder.Code, tc.want, recorder.Body)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"chirpy/internal/database"
+	"chirpy/internal/entities"
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type chirpEntity struct {
+	Type   string    `json:"type"`
+	Start  int       `json:"start"`
+	End    int       `json:"end"`
+	UserId uuid.UUID `json:"user_id"`
+	Handle string    `json:"handle"`
+}
+
+const entityMention = "mention"
+
+// addMentions resolves the @handles in a new chirp's body and stores the ones that
+// name a user, returning who was mentioned. Handles that match nobody, or a user
+// with a block between them and the author, are left as plain text.
+func addMentions(ctx context.Context, qtx *database.Queries, chirp database.Chirp) ([]uuid.UUID, error) {
+	mentions := entities.Mentions(chirp.Body)
+	if len(mentions) == 0 {
+		return nil, nil
+	}
+	users, err := qtx.GetUsersByHandles(ctx, entities.MentionedHandles(mentions))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, len(users))
+	for i := range users {
+		ids[i] = users[i].ID
+	}
+	blocked, err := qtx.GetBlockedAmong(ctx, database.GetBlockedAmongParams{ViewerID: chirp.UserID, UserIds: ids})
+	if err != nil {
+		return nil, err
+	}
+	byHandle := make(map[string]uuid.UUID, len(users))
+	for _, user := range users {
+		if !slices.Contains(blocked, user.ID) {
+			byHandle[strings.ToLower(user.Handle)] = user.ID
+		}
+	}
+	params := database.AddChirpMentionsParams{ChirpID: chirp.ID}
+	var mentioned []uuid.UUID
+	for _, m := range mentions {
+		if id, ok := byHandle[strings.ToLower(m.Handle)]; ok {
+			params.UserIds = append(params.UserIds, id)
+			params.StartOffsets = append(params.StartOffsets, int32(m.Start))
+			params.EndOffsets = append(params.EndOffsets, int32(m.End))
+			if !slices.Contains(mentioned, id) {
+				mentioned = append(mentioned, id)
+			}
+		}
+	}
+	if len(params.UserIds) == 0 {
+		return nil, nil
+	}
+	return mentioned, qtx.AddChirpMentions(ctx, params)
+}
+
+// chirpEntities loads the stored entities of every chirp in ids, keyed by chirp ID.
+func (cfg *apiConfig) chirpEntities(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]chirpEntity, error) {
+	rows, err := cfg.dbQueries.GetMentionsForChirps(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[uuid.UUID][]chirpEntity)
+	for _, row := range rows {
+		found[row.ChirpID] = append(found[row.ChirpID], chirpEntity{Type: entityMention, Start: int(row.StartOffset),
+			End: int(row.EndOffset), UserId: row.UserID, Handle: row.Handle})
+	}
+	return found, nil
+}
+
+func (cfg *apiConfig) handleUserMentions(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	id, err := parseID(req)
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, err.Error())
+		return
+	}
+	viewer := cfg.optionalUser(req.Header)
+	chirps, err := cfg.dbQueries.GetChirpsMentioningUser(req.Context(), database.GetChirpsMentioningUserParams{UserID: id,
+		ViewerID: viewer})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	jsonChirps, err := cfg.chirpsConv(req.Context(), viewer, chirps)
+	if err == nil {
+		jsonChirps, err = cfg.filterChirps(req.Context(), viewer, jsonChirps)
+	}
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	handleJsonWrite(writer, http.StatusOK, "mentions", jsonChirps)
+}
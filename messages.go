@@ -0,0 +1,398 @@
+package main
+
+import (
+	"chirpy/internal/broker"
+	"chirpy/internal/database"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type startConversation struct {
+	ParticipantIds []uuid.UUID `json:"participant_ids"`
+	Body           string      `json:"body" validate:"maxlen=140"`
+}
+
+type messageMsg struct {
+	Body string `json:"body" validate:"required,maxlen=140"`
+}
+
+type messageResp struct {
+	Id             uuid.UUID  `json:"id"`
+	ConversationId uuid.UUID  `json:"conversation_id"`
+	SenderId       *uuid.UUID `json:"sender_id"`
+	Body           string     `json:"body"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type participantResp struct {
+	Id         uuid.UUID  `json:"id"`
+	Handle     string     `json:"handle"`
+	LastReadAt *time.Time `json:"last_read_at"`
+}
+
+type conversationResp struct {
+	Id            uuid.UUID         `json:"id"`
+	IsGroup       bool              `json:"is_group"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	Participants  []participantResp `json:"participants"`
+	LatestMessage *messageResp      `json:"latest_message"`
+	UnreadCount   int64             `json:"unread_count"`
+}
+
+type conversationPage struct {
+	Conversations []conversationResp `json:"conversations"`
+	NextCursor    string             `json:"next_cursor,omitempty"`
+}
+
+type messagePage struct {
+	Messages   []messageResp `json:"messages"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// maxConversationSize counts the user starting a conversation.
+const maxConversationSize = 10
+
+// errNoRecipients is returned when every other participant has left or blocked
+// the sender, leaving nobody to deliver a message to.
+var errNoRecipients = errors.New("conversation has no one left to message")
+
+func messageConv(dbMessage database.Message) messageResp {
+	resp := messageResp{Id: dbMessage.ID, ConversationId: dbMessage.ConversationID, Body: dbMessage.Body,
+		CreatedAt: dbMessage.CreatedAt}
+	if dbMessage.SenderID.Valid {
+		resp.SenderId = &dbMessage.SenderID.UUID
+	}
+	return resp
+}
+
+// conversationParticipants loads the participants of every conversation in ids,
+// keyed by conversation ID.
+func (cfg *apiConfig) conversationParticipants(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]participantResp, error) {
+	rows, err := cfg.dbQueries.GetConversationParticipants(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	participants := make(map[uuid.UUID][]participantResp, len(ids))
+	for _, row := range rows {
+		participant := participantResp{Id: row.ID, Handle: row.Handle}
+		if row.LastReadAt.Valid {
+			participant.LastReadAt = &row.LastReadAt.Time
+		}
+		participants[row.ConversationID] = append(participants[row.ConversationID], participant)
+	}
+	return participants, nil
+}
+
+// conversationMember authenticates the caller and loads the conversation named in
+// the path, writing the error response itself if the caller is not one of its
+// participants. Outsiders get a 404 so they cannot probe for conversations.
+func (cfg *apiConfig) conversationMember(writer http.ResponseWriter, req *http.Request) (uuid.UUID, uuid.UUID, []participantResp, bool) {
+	self, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return uuid.UUID{}, uuid.UUID{}, nil, false
+	}
+	id, err := parseID(req)
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, err.Error())
+		return uuid.UUID{}, uuid.UUID{}, nil, false
+	}
+	participants, err := cfg.conversationParticipants(req.Context(), []uuid.UUID{id})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return uuid.UUID{}, uuid.UUID{}, nil, false
+	}
+	isMember := slices.ContainsFunc(participants[id], func(p participantResp) bool { return p.Id == self })
+	if !isMember {
+		handleError(writer, req, http.StatusNotFound, "Conversation not found")
+		return uuid.UUID{}, uuid.UUID{}, nil, false
+	}
+	return self, id, participants[id], true
+}
+
+// sendMessage stores a message from sender, bumps the conversation to the top of
+// everyone's list and counts the sender as having read up to their own message.
+func sendMessage(ctx context.Context, qtx *database.Queries, conversation, sender uuid.UUID, body string) (database.Message, error) {
+	message, err := qtx.CreateMessage(ctx, database.CreateMessageParams{ConversationID: conversation,
+		SenderID: uuid.NullUUID{UUID: sender, Valid: true}, Body: clean(body)})
+	if err != nil {
+		return database.Message{}, err
+	}
+	if err = qtx.TouchConversation(ctx, conversation); err != nil {
+		return database.Message{}, err
+	}
+	_, err = qtx.MarkConversationRead(ctx, database.MarkConversationReadParams{ConversationID: conversation, UserID: sender})
+	return message, err
+}
+
+func (cfg *apiConfig) handleStartConversation(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	msg := startConversation{}
+	if !decodeRequest(writer, req, &msg) {
+		return
+	}
+	self, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	var others []uuid.UUID
+	for _, id := range msg.ParticipantIds {
+		if id != self && !slices.Contains(others, id) {
+			others = append(others, id)
+		}
+	}
+	if len(others) == 0 || len(others) >= maxConversationSize {
+		handleError(writer, req, http.StatusBadRequest,
+			fmt.Sprintf("Conversations need between 2 and %d participants", maxConversationSize))
+		return
+	}
+	found, err := cfg.dbQueries.GetUserHandles(req.Context(), others)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	} else if len(found) != len(others) {
+		handleError(writer, req, http.StatusNotFound, "User not found")
+		return
+	}
+	blocked, err := cfg.blockedAmong(req.Context(), uuid.NullUUID{UUID: self, Valid: true}, others)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	} else if len(blocked) > 0 {
+		handleProblem(writer, req, http.StatusForbidden, codeBlocked, errBlocked.Error())
+		return
+	}
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	code := http.StatusCreated
+	var id uuid.UUID
+	if len(others) == 1 {
+		// A pair of users shares one direct conversation, so starting another
+		// just picks the existing one back up. The lock makes a concurrent
+		// start wait for this one and then find what it created.
+		err = qtx.LockDirectConversation(req.Context(), database.LockDirectConversationParams{UserA: self, UserB: others[0]})
+		if err == nil {
+			id, err = qtx.FindDirectConversation(req.Context(), database.FindDirectConversationParams{UserA: self,
+				UserB: others[0]})
+		}
+		if err == nil {
+			code = http.StatusOK
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			handleFault(writer, req, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	if code == http.StatusCreated {
+		conversation, err := qtx.CreateConversation(req.Context(), len(others) > 1)
+		if err != nil {
+			handleFault(writer, req, http.StatusInternalServerError, err)
+			return
+		}
+		id = conversation.ID
+		err = qtx.AddConversationParticipants(req.Context(), database.AddConversationParticipantsParams{ConversationID: id,
+			UserIds: append(slices.Clone(others), self)})
+		if err != nil {
+			handleFault(writer, req, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	var events []broker.Event
+	if msg.Body != "" {
+		message, err := sendMessage(req.Context(), qtx, id, self, msg.Body)
+		if err != nil {
+			handleFault(writer, req, http.StatusInternalServerError, err)
+			return
+		}
+		events = append(events, broker.Event{Message: &message, Recipients: append(slices.Clone(others), self)})
+	}
+	if err = tx.Commit(); err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	cfg.publish(req.Context(), events...)
+	cfg.conversationRespond(writer, req, code, self, id)
+}
+
+// conversationRespond writes the caller's view of a single conversation, as it
+// would appear in their conversation list.
+func (cfg *apiConfig) conversationRespond(writer http.ResponseWriter, req *http.Request, code int, self, id uuid.UUID) {
+	rows, err := cfg.dbQueries.GetConversations(req.Context(), database.GetConversationsParams{UserID: self,
+		ConversationID: uuid.NullUUID{UUID: id, Valid: true}, BeforeTime: firstPage.time, BeforeID: firstPage.id, PageSize: 1})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	} else if len(rows) == 0 {
+		handleError(writer, req, http.StatusNotFound, "Conversation not found")
+		return
+	}
+	conversations, err := cfg.conversationsConv(req.Context(), rows)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	handleJsonWrite(writer, code, "conversation", conversations[0])
+}
+
+func (cfg *apiConfig) conversationsConv(ctx context.Context, rows []database.GetConversationsRow) ([]conversationResp, error) {
+	ids := make([]uuid.UUID, len(rows))
+	for i := range rows {
+		ids[i] = rows[i].ID
+	}
+	participants, err := cfg.conversationParticipants(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	conversations := make([]conversationResp, len(rows))
+	for i, row := range rows {
+		conversations[i] = conversationResp{Id: row.ID, IsGroup: row.IsGroup, CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt, Participants: participants[row.ID], UnreadCount: row.Unread}
+		if row.MessageID.Valid {
+			latest := messageConv(database.Message{ID: row.MessageID.UUID, ConversationID: row.ID, SenderID: row.SenderID,
+				Body: row.Body.String, CreatedAt: row.MessageAt.Time})
+			conversations[i].LatestMessage = &latest
+		}
+	}
+	return conversations, nil
+}
+
+func (cfg *apiConfig) handleGetConversations(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	self, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	cur, limit, err := parsePage(req)
+	if err != nil {
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	rows, err := cfg.dbQueries.GetConversations(req.Context(), database.GetConversationsParams{UserID: self,
+		BeforeTime: cur.time, BeforeID: cur.id, PageSize: limit})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	conversations, err := cfg.conversationsConv(req.Context(), rows)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	page := conversationPage{Conversations: conversations}
+	if len(rows) > 0 {
+		last := rows[len(rows)-1]
+		page.NextCursor = nextCursor(len(rows), limit, last.UpdatedAt, last.ID)
+	}
+	handleJsonWrite(writer, http.StatusOK, "conversations", page)
+}
+
+// handleGetMessages pages through a conversation's history, newest first. Messages
+// from anyone with a block between them and the caller are left out.
+func (cfg *apiConfig) handleGetMessages(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	self, id, _, ok := cfg.conversationMember(writer, req)
+	if !ok {
+		return
+	}
+	cur, limit, err := parsePage(req)
+	if err != nil {
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	rows, err := cfg.dbQueries.GetMessages(req.Context(), database.GetMessagesParams{ConversationID: id,
+		BeforeTime: cur.time, BeforeID: cur.id, ViewerID: self, PageSize: limit})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	page := messagePage{Messages: make([]messageResp, len(rows))}
+	for i := range rows {
+		page.Messages[i] = messageConv(rows[i])
+	}
+	if len(rows) > 0 {
+		last := rows[len(rows)-1]
+		page.NextCursor = nextCursor(len(rows), limit, last.CreatedAt, last.ID)
+	}
+	handleJsonWrite(writer, http.StatusOK, "messages", page)
+}
+
+// handleSendMessage posts to a conversation. In a group, blocked participants
+// simply never see the message; a direct conversation with a blocked user, or one
+// whose other participant has deleted their account, cannot be written to.
+func (cfg *apiConfig) handleSendMessage(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	msg := messageMsg{}
+	if !decodeRequest(writer, req, &msg) {
+		return
+	}
+	self, id, participants, ok := cfg.conversationMember(writer, req)
+	if !ok {
+		return
+	}
+	var others []uuid.UUID
+	for _, p := range participants {
+		if p.Id != self {
+			others = append(others, p.Id)
+		}
+	}
+	blocked, err := cfg.blockedAmong(req.Context(), uuid.NullUUID{UUID: self, Valid: true}, others)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	} else if len(blocked) == len(others) {
+		handleError(writer, req, http.StatusForbidden, errNoRecipients.Error())
+		return
+	}
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+	message, err := sendMessage(req.Context(), cfg.dbQueries.WithTx(tx), id, self, msg.Body)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	if err = tx.Commit(); err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	recipients := make([]uuid.UUID, len(participants))
+	for i, p := range participants {
+		recipients[i] = p.Id
+	}
+	cfg.publish(req.Context(), broker.Event{Message: &message, Recipients: recipients})
+	handleJsonWrite(writer, http.StatusCreated, "message", messageConv(message))
+}
+
+// handleReadConversation records that the caller has read everything in a
+// conversation so far. Other participants see it as their last_read_at.
+func (cfg *apiConfig) handleReadConversation(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	self, id, _, ok := cfg.conversationMember(writer, req)
+	if !ok {
+		return
+	}
+	_, err := cfg.dbQueries.MarkConversationRead(req.Context(), database.MarkConversationReadParams{ConversationID: id,
+		UserID: self})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+// TestConversationVisibility checks that only participants can read or write a
+// conversation, and that outsiders cannot tell it from one that does not exist.
+func TestConversationVisibility(t *testing.T) {
+	conversation, alice, bob := uuid.New(), uuid.New(), uuid.New()
+	path := "/api/v2/conversations/" + conversation.String() + "/messages"
+	tests := []struct {
+		name   string
+		method string
+		caller uuid.UUID
+		want   int
+	}{
+		{"participant reads", http.MethodGet, alice, http.StatusOK},
+		{"outsider reads", http.MethodGet, uuid.New(), http.StatusNotFound},
+		{"outsider writes", http.MethodPost, uuid.New(), http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		cfg, mock := mockConfig(t)
+		mock.ExpectQuery("name: GetConversationParticipants ").WillReturnRows(sqlmock.NewRows(
+			[]string{"conversation_id", "id", "handle", "last_read_at"}).
+			AddRow(conversation, alice, "alice", nil).
+			AddRow(conversation, bob, "bob", nil))
+		if tc.want == http.StatusOK {
+			// Messages from anyone the caller has a block with are left out by the query.
+			mock.ExpectQuery("name: GetMessages ").
+				WithArgs(conversation, sqlmock.AnyArg(), sqlmock.AnyArg(), tc.caller, sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "conversation_id", "sender_id", "body", "created_at"}).
+					AddRow(uuid.New(), conversation, bob, "hi alice", time.Now().UTC()))
+		}
+		req := httptest.NewRequest(tc.method, path, strings.NewReader(`{"body":"hello?"}`))
+		req.Header.Set("Content-Type", jsonContent)
+		req.Header.Set("Authorization", authHeader(t, tc.caller))
+		recorder := httptest.NewRecorder()
+		cfg.routes().ServeHTTP(recorder, req)
+		if recorder.Code != tc.want {
+			t.Errorf("%s: %s %s = %d, want %d: %s", tc.name, tc.method, path, recorder.Code, tc.want, recorder.Body)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"chirpy/internal/database"
+	"chirpy/internal/wordfilter"
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+type mutedWordMsg struct {
+	Phrase    string     `json:"phrase"`
+	WholeWord bool       `json:"whole_word"`
+	Action    string     `json:"action"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type mutedWordResp struct {
+	Id        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	mutedWordMsg
+}
+
+const muteActionHide = "hide"
+const muteActionCollapse = "collapse"
+const mutedPhraseLimit = 100
+
+func mutedWordConv(dbWord database.MutedWord) mutedWordResp {
+	resp := mutedWordResp{Id: dbWord.ID, CreatedAt: dbWord.CreatedAt, mutedWordMsg: mutedWordMsg{Phrase: dbWord.Phrase,
+		WholeWord: dbWord.WholeWord, Action: dbWord.Action}}
+	if dbWord.ExpiresAt.Valid {
+		resp.ExpiresAt = &dbWord.ExpiresAt.Time
+	}
+	return resp
+}
+
+func (msg *mutedWordMsg) validate() error {
+	msg.Phrase = strings.TrimSpace(msg.Phrase)
+	if msg.Phrase == "" || utf8.RuneCountInString(msg.Phrase) > mutedPhraseLimit {
+		return fmt.Errorf("phrase must be between 1 and %d characters", mutedPhraseLimit)
+	}
+	if msg.Action == "" {
+		msg.Action = muteActionCollapse
+	} else if msg.Action != muteActionHide && msg.Action != muteActionCollapse {
+		return fmt.Errorf("action must be %q or %q", muteActionHide, muteActionCollapse)
+	}
+	if msg.ExpiresAt != nil && !msg.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("expires_at must be in the future")
+	}
+	return nil
+}
+
+// filterChirps applies viewer's muted words to a listing: chirps matching a "hide"
+// rule are dropped and those matching a "collapse" rule are flagged as filtered.
+// Quoted and rechirped text counts as part of the chirp.
+func (cfg *apiConfig) filterChirps(ctx context.Context, viewer uuid.NullUUID, chirps []chirpResp) ([]chirpResp, error) {
+	if !viewer.Valid {
+		return chirps, nil
+	}
+	words, err := cfg.dbQueries.GetMutedWords(ctx, viewer.UUID)
+	if err != nil || len(words) == 0 {
+		return chirps, err
+	}
+	hide := []wordfilter.Rule{}
+	collapse := []wordfilter.Rule{}
+	for _, word := range words {
+		rule := wordfilter.Rule{Phrase: word.Phrase, WholeWord: word.WholeWord}
+		if word.Action == muteActionHide {
+			hide = append(hide, rule)
+		} else {
+			collapse = append(collapse, rule)
+		}
+	}
+	filtered := make([]chirpResp, 0, len(chirps))
+	for _, chirp := range chirps {
+		texts := []string{chirp.Body}
+		if ref, ok := chirp.RefChirp.(*chirpResp); ok {
+			texts = append(texts, ref.Body)
+		}
+		if _, ok := wordfilter.Match(hide, texts...); ok {
+			continue
+		}
+		_, chirp.Filtered = wordfilter.Match(collapse, texts...)
+		filtered = append(filtered, chirp)
+	}
+	return filtered, nil
+}
+
+func (cfg *apiConfig) handleListMutedWords(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	id, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	words, err := cfg.dbQueries.GetMutedWords(req.Context(), id)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	jsonWords := make([]mutedWordResp, len(words))
+	for i := range words {
+		jsonWords[i] = mutedWordConv(words[i])
+	}
+	handleJsonWrite(writer, http.StatusOK, "muted words", jsonWords)
+}
+
+func (cfg *apiConfig) handleAddMutedWord(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	msg := mutedWordMsg{}
+	if !decodeRequest(writer, req, &msg) {
+		return
+	}
+	id, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	params := database.CreateMutedWordParams{UserID: id, Phrase: msg.Phrase, WholeWord: msg.WholeWord, Action: msg.Action}
+	if msg.ExpiresAt != nil {
+		params.ExpiresAt = sql.NullTime{Time: *msg.ExpiresAt, Valid: true}
+	}
+	word, err := cfg.dbQueries.CreateMutedWord(req.Context(), params)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	handleJsonWrite(writer, http.StatusCreated, msg.Phrase, mutedWordConv(word))
+}
+
+func (cfg *apiConfig) handleDeleteMutedWord(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	userID, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	id, err := parseID(req)
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, err.Error())
+		return
+	}
+	deleted, err := cfg.dbQueries.DeleteMutedWord(req.Context(), database.DeleteMutedWordParams{ID: id, UserID: userID})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	} else if deleted == 0 {
+		handleError(writer, req, http.StatusNotFound, "Muted word not found")
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"chirpy/internal/database"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+// TestMutedWordsInListings checks that a listing drops the chirps a viewer hides
+// and flags the ones they collapse, and that nobody else's listing is touched.
+func TestMutedWordsInListings(t *testing.T) {
+	now := time.Now().UTC()
+	author := uuid.New()
+	chirp := func(body string, age time.Duration) database.Chirp {
+		return database.Chirp{ID: uuid.New(), CreatedAt: now.Add(-age), UpdatedAt: now.Add(-age), Body: body,
+			UserID: author, Kind: chirpKindChirp}
+	}
+	// Newest first, as the query returns them.
+	chirps := []database.Chirp{chirp("Crypto news", time.Minute), chirp("big SPOILER ahead", 2*time.Minute),
+		chirp("hello", 3*time.Minute)}
+	tests := []struct {
+		name   string
+		viewer uuid.NullUUID
+		want   map[string]bool
+	}{
+		{"muting viewer", uuid.NullUUID{UUID: uuid.New(), Valid: true}, map[string]bool{"hello": false, "Crypto news": true}},
+		{"anonymous", uuid.NullUUID{}, map[string]bool{"hello": false, "big SPOILER ahead": false, "Crypto news": false}},
+	}
+	for _, tc := range tests {
+		cfg, mock := mockConfig(t)
+		mock.ExpectQuery("name: GetNewestChirps ").WillReturnRows(chirpRows(chirps...))
+		expectChirpDetails(mock, database.GetUserHandlesRow{ID: author, Handle: "alice"})
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/chirps", nil)
+		if tc.viewer.Valid {
+			mock.ExpectQuery("name: GetMutedWords ").WithArgs(tc.viewer.UUID).WillReturnRows(sqlmock.NewRows(
+				[]string{"id", "user_id", "phrase", "whole_word", "action", "expires_at", "created_at"}).
+				AddRow(uuid.New(), tc.viewer.UUID, "spoiler", true, muteActionHide, nil, now).
+				AddRow(uuid.New(), tc.viewer.UUID, "crypto", true, muteActionCollapse, nil, now))
+			req.Header.Set("Authorization", authHeader(t, tc.viewer.UUID))
+		}
+		recorder := httptest.NewRecorder()
+		cfg.routes().ServeHTTP(recorder, req)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("%s: GET /api/v2/chirps = %d: %s", tc.name, recorder.Code, recorder.Body)
+		}
+		var got []chirpResp
+		if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+			t.Fatalf("%s: GET /api/v2/chirps body %q: %v", tc.name, recorder.Body, err)
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: got %d chirps, want %d", tc.name, len(got), len(tc.want))
+		}
+		for i, chirp := range got {
+			if filtered, ok := tc.want[chirp.Body]; !ok || filtered != chirp.Filtered {
+				t.Errorf("%s: chirp %q filtered = %v, want it listed: %v with filtered %v", tc.name, chirp.Body,
+					chirp.Filtered, ok, filtered)
+			}
+			if i > 0 && chirp.CreatedAt.Before(got[i-1].CreatedAt) {
+				t.Errorf("%s: chirps are not oldest first", tc.name)
+			}
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"chirpy/internal/broker"
+	"chirpy/internal/database"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type notificationActor struct {
+	Id     uuid.UUID `json:"id"`
+	Handle string    `json:"handle"`
+}
+
+type notificationResp struct {
+	Id         uuid.UUID           `json:"id"`
+	Type       string              `json:"type"`
+	ChirpId    *uuid.UUID          `json:"chirp_id,omitempty"`
+	ActorCount int64               `json:"actor_count"`
+	Actors     []notificationActor `json:"actors"`
+	Summary    string              `json:"summary"`
+	LatestAt   time.Time           `json:"latest_at"`
+	Read       bool                `json:"read"`
+}
+
+type notificationPage struct {
+	Notifications []notificationResp `json:"notifications"`
+	UnreadCount   int64              `json:"unread_count"`
+	NextCursor    string             `json:"next_cursor,omitempty"`
+}
+
+const notifyLike = "like"
+const notifyReply = "reply"
+const notifyMention = "mention"
+const notifyFollow = "follow"
+const notifyRechirp = "rechirp"
+const notifyQuote = "quote"
+
+var notificationVerbs = map[string]string{
+	notifyLike:    "liked your chirp",
+	notifyReply:   "replied to your chirp",
+	notifyMention: "mentioned you",
+	notifyFollow:  "followed you",
+	notifyRechirp: "rechirped your chirp",
+	notifyQuote:   "quoted your chirp",
+}
+
+// followGroupWindow is how long a group of new followers stays open. Followers
+// are grouped by the window they arrived in, so each day's get their own entry.
+const followGroupWindow = 24 * time.Hour
+
+// notificationGroup picks the key that similar notifications are grouped under.
+// Likes and rechirps of one chirp collapse together, as do new followers within
+// a followGroupWindow; replies, mentions and quotes each carry their own chirp
+// and stand alone.
+func notificationGroup(kind string, recipient uuid.UUID, chirp uuid.NullUUID, at time.Time) uuid.UUID {
+	switch kind {
+	case notifyLike, notifyRechirp:
+		return uuid.NewSHA1(chirp.UUID, []byte(kind))
+	case notifyFollow:
+		window := at.UTC().Truncate(followGroupWindow)
+		return uuid.NewSHA1(recipient, []byte(fmt.Sprintf("%s/%d", kind, window.Unix())))
+	default:
+		return uuid.New()
+	}
+}
+
+// notify records that actor did something to recipient. It must be called with
+// the transaction of the write that triggered it, and the notification published
+// once that commits. Nobody is notified of their own actions, so the notification
+// may be nil.
+func notify(ctx context.Context, qtx *database.Queries, kind string, recipient, actor uuid.UUID, chirp uuid.NullUUID) (*database.Notification, error) {
+	if recipient == actor {
+		return nil, nil
+	}
+	notification, err := qtx.CreateNotification(ctx, database.CreateNotificationParams{UserID: recipient, ActorID: actor,
+		Type: kind, ChirpID: chirp, GroupKey: notificationGroup(kind, recipient, chirp, time.Now())})
+	if err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// notifyChirp notifies the author of the chirp a new rechirp, quote or reply refers
+// to, and everyone the new chirp mentions. Someone mentioned in a reply to their own
+// chirp only hears about the reply. It returns the events to publish on commit.
+func notifyChirp(ctx context.Context, qtx *database.Queries, chirp database.Chirp, mentioned []uuid.UUID) ([]broker.Event, error) {
+	newChirp := uuid.NullUUID{UUID: chirp.ID, Valid: true}
+	var events []broker.Event
+	var refAuthor uuid.UUID
+	if chirp.RefChirpID.Valid {
+		ref, err := qtx.GetChirp(ctx, chirp.RefChirpID.UUID)
+		if err != nil {
+			return nil, err
+		}
+		refAuthor = ref.UserID
+		var notification *database.Notification
+		switch chirp.Kind {
+		case chirpKindRechirp:
+			notification, err = notify(ctx, qtx, notifyRechirp, ref.UserID, chirp.UserID, chirp.RefChirpID)
+		case chirpKindQuote:
+			notification, err = notify(ctx, qtx, notifyQuote, ref.UserID, chirp.UserID, newChirp)
+		case chirpKindReply:
+			notification, err = notify(ctx, qtx, notifyReply, ref.UserID, chirp.UserID, newChirp)
+		}
+		if err != nil {
+			return nil, err
+		} else if notification != nil {
+			events = append(events, broker.Event{Notification: notification})
+		}
+	}
+	mentions, err := notifyMentions(ctx, qtx, chirp, mentioned, refAuthor)
+	if err != nil {
+		return nil, err
+	}
+	return append(events, mentions...), nil
+}
+
+// notifyMentions tells each user in mentioned, other than except, that chirp mentions them.
+func notifyMentions(ctx context.Context, qtx *database.Queries, chirp database.Chirp, mentioned []uuid.UUID, except uuid.UUID) ([]broker.Event, error) {
+	var events []broker.Event
+	for _, id := range mentioned {
+		if id == except {
+			continue
+		}
+		notification, err := notify(ctx, qtx, notifyMention, id, chirp.UserID, uuid.NullUUID{UUID: chirp.ID, Valid: true})
+		if err != nil {
+			return nil, err
+		} else if notification != nil {
+			events = append(events, broker.Event{Notification: notification})
+		}
+	}
+	return events, nil
+}
+
+// notificationSummary describes a group in words, such as "alice and 4 others liked
+// your chirp".
+func notificationSummary(kind string, actors []notificationActor, count int64) string {
+	name := "someone"
+	if len(actors) > 0 {
+		name = actors[0].Handle
+	}
+	switch {
+	case count <= 1:
+		return fmt.Sprintf("%s %s", name, notificationVerbs[kind])
+	case count == 2:
+		return fmt.Sprintf("%s and 1 other %s", name, notificationVerbs[kind])
+	default:
+		return fmt.Sprintf("%s and %d others %s", name, count-1, notificationVerbs[kind])
+	}
+}
+
+func (cfg *apiConfig) handleNotifications(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	id, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	cur, limit, err := parsePage(req)
+	if err != nil {
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	groups, err := cfg.dbQueries.GetNotificationGroups(req.Context(), database.GetNotificationGroupsParams{UserID: id,
+		BeforeTime: cur.time, BeforeID: cur.id, PageSize: limit})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	page := notificationPage{Notifications: make([]notificationResp, len(groups))}
+	if page.UnreadCount, err = cfg.dbQueries.CountUnreadNotifications(req.Context(), id); err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	var actorIDs []uuid.UUID
+	for _, group := range groups {
+		actorIDs = append(actorIDs, group.RecentActorIds...)
+	}
+	actors, err := cfg.dbQueries.GetUserHandles(req.Context(), actorIDs)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	handles := make(map[uuid.UUID]string, len(actors))
+	for _, actor := range actors {
+		handles[actor.ID] = actor.Handle
+	}
+	for i, group := range groups {
+		resp := notificationResp{Id: group.GroupKey, Type: group.Type, ActorCount: group.ActorCount,
+			Actors: make([]notificationActor, len(group.RecentActorIds)), LatestAt: group.LatestAt, Read: group.Unread == 0}
+		if group.ChirpID.Valid {
+			resp.ChirpId = &group.ChirpID.UUID
+		}
+		for j, actor := range group.RecentActorIds {
+			resp.Actors[j] = notificationActor{Id: actor, Handle: handles[actor]}
+		}
+		resp.Summary = notificationSummary(group.Type, resp.Actors, group.ActorCount)
+		page.Notifications[i] = resp
+	}
+	if len(groups) > 0 {
+		last := groups[len(groups)-1]
+		page.NextCursor = nextCursor(len(groups), limit, last.FirstAt, last.GroupKey)
+	}
+	handleJsonWrite(writer, http.StatusOK, "notifications", page)
+}
+
+func (cfg *apiConfig) handleReadNotification(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	id, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	groupKey, err := parseID(req)
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, err.Error())
+		return
+	}
+	rows, err := cfg.dbQueries.MarkNotificationGroupRead(req.Context(), database.MarkNotificationGroupReadParams{UserID: id,
+		GroupKey: groupKey})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	} else if rows == 0 {
+		handleError(writer, req, http.StatusNotFound, "Notification not found")
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleReadAllNotifications(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	id, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if err = cfg.dbQueries.MarkAllNotificationsRead(req.Context(), id); err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
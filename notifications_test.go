@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var notificationGroupColumns = []string{"group_key", "type", "chirp_id", "actor_count", "recent_actor_ids", "first_at",
+	"latest_at", "unread"}
+
+// TestNotificationGroup checks which notifications share a group: likes of one
+// chirp whenever they come, follows only within a followGroupWindow.
+func TestNotificationGroup(t *testing.T) {
+	recipient := uuid.New()
+	chirp := uuid.NullUUID{UUID: uuid.New(), Valid: true}
+	day := time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		kind      string
+		chirps    [2]uuid.NullUUID
+		times     [2]time.Time
+		wantGroup bool
+	}{
+		{"likes a month apart", notifyLike, [2]uuid.NullUUID{chirp, chirp}, [2]time.Time{day, day.AddDate(0, 1, 0)}, true},
+		{"likes of other chirps", notifyLike, [2]uuid.NullUUID{chirp, {UUID: uuid.New(), Valid: true}},
+			[2]time.Time{day, day}, false},
+		{"follows the same day", notifyFollow, [2]uuid.NullUUID{}, [2]time.Time{day, day.Add(10 * time.Hour)}, true},
+		{"follows a day apart", notifyFollow, [2]uuid.NullUUID{}, [2]time.Time{day, day.Add(followGroupWindow)}, false},
+		{"mentions", notifyMention, [2]uuid.NullUUID{chirp, chirp}, [2]time.Time{day, day}, false},
+	}
+	for _, tc := range tests {
+		first := notificationGroup(tc.kind, recipient, tc.chirps[0], tc.times[0])
+		second := notificationGroup(tc.kind, recipient, tc.chirps[1], tc.times[1])
+		if got := first == second; got != tc.wantGroup {
+			t.Errorf("%s: grouped = %v, want %v", tc.name, got, tc.wantGroup)
+		}
+	}
+}
+
+// TestNotificationPaging checks that the next page starts after the last group's
+// first notification, which stays put when the group grows between requests.
+func TestNotificationPaging(t *testing.T) {
+	cfg, mock := mockConfig(t)
+	routes := cfg.routes()
+	user, actor := uuid.New(), uuid.New()
+	chirp := uuid.New()
+	first := time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)
+	likes := notificationGroup(notifyLike, user, uuid.NullUUID{UUID: chirp, Valid: true}, first)
+	mock.ExpectQuery("name: GetNotificationGroups ").WithArgs(user, firstPage.time, firstPage.id, 1).
+		WillReturnRows(sqlmock.NewRows(notificationGroupColumns).
+			AddRow(likes, notifyLike, chirp, 2, pq.Array([]uuid.UUID{actor, uuid.New()}), first,
+				first.Add(time.Hour), 1))
+	mock.ExpectQuery("name: CountUnreadNotifications ").WithArgs(user).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("name: GetUserHandles ").WillReturnRows(sqlmock.NewRows([]string{"id", "handle"}).
+		AddRow(actor, "alice"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/notifications?limit=1", nil)
+	req.Header.Set("Authorization", authHeader(t, user))
+	recorder := httptest.NewRecorder()
+	routes.ServeHTTP(recorder, req)
+	var page notificationPage
+	if err := json.Unmarshal(recorder.Body.Bytes(), &page); err != nil || recorder.Code != http.StatusOK {
+		t.Fatalf("GET /api/v2/notifications = %d, %q: %v", recorder.Code, recorder.Body, err)
+	}
+	if len(page.Notifications) != 1 || page.Notifications[0].Summary != "alice and 1 other liked your chirp" ||
+		page.Notifications[0].Read || page.UnreadCount != 1 {
+		t.Errorf("GET /api/v2/notifications = %+v, want one unread group of two likes", page)
+	}
+
+	// However the group has grown since, the next page starts after its first like.
+	mock.ExpectQuery("name: GetNotificationGroups ").WithArgs(user, first, likes, 1).
+		WillReturnRows(sqlmock.NewRows(notificationGroupColumns))
+	mock.ExpectQuery("name: CountUnreadNotifications ").WithArgs(user).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("name: GetUserHandles ").WillReturnRows(sqlmock.NewRows([]string{"id", "handle"}))
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/notifications?limit=1&cursor="+page.NextCursor, nil)
+	req.Header.Set("Authorization", authHeader(t, user))
+	recorder = httptest.NewRecorder()
+	routes.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusOK {
+		t.Errorf("GET next page = %d: %s", recorder.Code, recorder.Body)
+	}
+}
+
+// TestReadNotifications checks that a group can be marked read by its key, which
+// is only found among the caller's own notifications, and that all can be at once.
+func TestReadNotifications(t *testing.T) {
+	cfg, mock := mockConfig(t)
+	routes := cfg.routes()
+	user := uuid.New()
+	group, other := uuid.New(), uuid.New()
+	mock.ExpectExec("name: MarkNotificationGroupRead ").WithArgs(user, group).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("name: MarkNotificationGroupRead ").WithArgs(user, other).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("name: MarkAllNotificationsRead ").WithArgs(user).WillReturnResult(sqlmock.NewResult(0, 3))
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/api/v2/notifications/" + group.String() + "/read", http.StatusNoContent},
+		{"/api/v2/notifications/" + other.String() + "/read", http.StatusNotFound},
+		{"/api/v2/notifications/read", http.StatusNoContent},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodPost, tc.path, nil)
+		req.Header.Set("Authorization", authHeader(t, user))
+		recorder := httptest.NewRecorder()
+		routes.ServeHTTP(recorder, req)
+		if recorder.Code != tc.want {
+			t.Errorf("POST %s = %d, want %d: %s", tc.path, recorder.Code, tc.want, recorder.Body)
+		}
+	}
+}
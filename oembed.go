@@ -0,0 +1,160 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// oembedResp is a rich oEmbed response (https://oembed.com/#section2.3).
+type oembedResp struct {
+	Version      string `json:"version"`
+	Type         string `json:"type"`
+	ProviderName string `json:"provider_name"`
+	ProviderUrl  string `json:"provider_url"`
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name"`
+	AuthorUrl    string `json:"author_url"`
+	Html         string `json:"html"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	CacheAge     int    `json:"cache_age"`
+}
+
+type embedView struct {
+	Meta  pageMeta
+	Theme string
+	Chirp *pageChirp
+}
+
+const oembedContent = "application/json+oembed"
+const embedWidth = 550
+const embedHeight = 250
+const embedCacheAge = 3600
+const themeLight = "light"
+const themeDark = "dark"
+
+// embedTheme reads the theme parameter, falling back to light for anything unknown.
+func embedTheme(req *http.Request) string {
+	if req.URL.Query().Get("theme") == themeDark {
+		return themeDark
+	}
+	return themeLight
+}
+
+func (cfg *apiConfig) embedURL(id uuid.UUID, theme string) string {
+	return cfg.publicURL + "/embed" + chirpPath(id) + "?theme=" + theme
+}
+
+// oembedURL is the oEmbed endpoint for a page, as its discovery link gives it.
+func (cfg *apiConfig) oembedURL(page string) string {
+	return cfg.publicURL + "/oembed?format=json&url=" + url.QueryEscape(page)
+}
+
+// embeddedChirp returns the id of the chirp a URL on this instance points at,
+// either its page or its embed.
+func (cfg *apiConfig) embeddedChirp(target string) (uuid.UUID, error) {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return uuid.UUID{}, sql.ErrNoRows
+	}
+	public, err := url.Parse(cfg.publicURL)
+	if err != nil || !strings.EqualFold(parsed.Host, public.Host) {
+		return uuid.UUID{}, sql.ErrNoRows
+	}
+	idStr, found := strings.CutPrefix(strings.TrimPrefix(parsed.Path, "/embed"), "/chirps/")
+	if !found {
+		return uuid.UUID{}, sql.ErrNoRows
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return uuid.UUID{}, sql.ErrNoRows
+	}
+	return id, nil
+}
+
+// dimension reads an optional positive maxwidth or maxheight parameter and
+// returns the smaller of it and def.
+func dimension(req *http.Request, name string, def int) (int, error) {
+	str := req.URL.Query().Get(name)
+	if str == "" {
+		return def, nil
+	}
+	limit, err := strconv.Atoi(str)
+	if err != nil || limit < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer", name)
+	}
+	return min(limit, def), nil
+}
+
+// handleOEmbed describes how to embed a chirp. Chirps that are deleted or hidden
+// from anonymous visitors are 404, as oEmbed asks for URLs with nothing to embed.
+func (cfg *apiConfig) handleOEmbed(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	if format := req.URL.Query().Get("format"); format != "" && format != "json" {
+		handleError(writer, req, http.StatusNotImplemented, "Only the json format is supported")
+		return
+	}
+	width, err := dimension(req, "maxwidth", embedWidth)
+	if err != nil {
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	height, err := dimension(req, "maxheight", embedHeight)
+	if err != nil {
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	id, err := cfg.embeddedChirp(req.URL.Query().Get("url"))
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, "Not a chirp URL")
+		return
+	}
+	chirp, err := cfg.loadChirp(req.Context(), uuid.NullUUID{}, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		handleError(writer, req, http.StatusNotFound, "Chirp not found")
+		return
+	} else if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	title := "@" + chirp.AuthorHandle + " on Chirpy"
+	html := fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" style="border: 0; max-width: 100%%;" title="%s" loading="lazy"></iframe>`,
+		template.HTMLEscapeString(cfg.embedURL(chirp.Id, embedTheme(req))), width, height, template.HTMLEscapeString(title))
+	writer.Header()["Content-Type"] = []string{oembedContent}
+	handleJsonWrite(writer, http.StatusOK, "oembed", oembedResp{Version: "1.0", Type: "rich", ProviderName: "Chirpy",
+		ProviderUrl: cfg.publicURL + "/", Title: title, AuthorName: "@" + chirp.AuthorHandle,
+		AuthorUrl: cfg.publicURL + profilePath(chirp.UserId), Html: html, Width: width, Height: height,
+		CacheAge: embedCacheAge})
+}
+
+// handleEmbedPage renders a chirp for an iframe on another site. Once the chirp is
+// gone, embeds already out there show a tombstone instead.
+func (cfg *apiConfig) handleEmbedPage(writer http.ResponseWriter, req *http.Request) {
+	view := embedView{Theme: embedTheme(req), Meta: pageMeta{Title: "Chirpy"}}
+	id, err := parseID(req)
+	if err != nil {
+		cfg.pageError(writer, req, sql.ErrNoRows, "chirp")
+		return
+	}
+	view.Meta.URL = cfg.publicURL + chirpPath(id)
+	chirp, err := cfg.loadChirp(req.Context(), uuid.NullUUID{}, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		renderPage(writer, http.StatusNotFound, "embed", view)
+		return
+	} else if err != nil {
+		cfg.pageError(writer, req, err, "chirp")
+		return
+	}
+	page := pageChirpConv(chirp)
+	view.Chirp = &page
+	view.Meta.Title = "@" + chirp.AuthorHandle + " on Chirpy"
+	renderPage(writer, http.StatusOK, "embed", view)
+}
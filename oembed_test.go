@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestEmbeddedChirp(t *testing.T) {
+	cfg := &apiConfig{publicURL: "https://chirpy.test"}
+	id := uuid.New()
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"https://chirpy.test/chirps/" + id.String(), true},
+		{"https://CHIRPY.test/chirps/" + id.String() + "?ref=partner", true},
+		{"https://chirpy.test/embed/chirps/" + id.String(), true},
+		{"https://elsewhere.test/chirps/" + id.String(), false},
+		{"https://chirpy.test/users/" + id.String(), false},
+		{"https://chirpy.test/chirps/not-a-uuid", false},
+		{"::", false},
+	}
+	for _, tc := range tests {
+		got, err := cfg.embeddedChirp(tc.url)
+		if tc.ok && (err != nil || got != id) {
+			t.Errorf("embeddedChirp(%q) = %v, %v", tc.url, got, err)
+		} else if !tc.ok && err == nil {
+			t.Errorf("embeddedChirp(%q) succeeded", tc.url)
+		}
+	}
+}
+
+// TestOEmbedRejects only sends requests that fail before the chirp is loaded.
+func TestOEmbedRejects(t *testing.T) {
+	cfg := &apiConfig{publicURL: "https://chirpy.test"}
+	page := "https://chirpy.test/chirps/" + uuid.NewString()
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"url=" + url.QueryEscape(page) + "&format=xml", http.StatusNotImplemented},
+		{"url=" + url.QueryEscape(page) + "&maxwidth=wide", http.StatusBadRequest},
+		{"url=" + url.QueryEscape(page) + "&maxheight=0", http.StatusBadRequest},
+		{"url=" + url.QueryEscape("https://elsewhere.test/chirps/1"), http.StatusNotFound},
+		{"", http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		recorder := httptest.NewRecorder()
+		cfg.handleOEmbed(recorder, httptest.NewRequest(http.MethodGet, "/oembed?"+tc.query, nil))
+		if recorder.Code != tc.want {
+			t.Errorf("/oembed?%s = %d, want %d", tc.query, recorder.Code, tc.want)
+		}
+	}
+}
+
+func TestEmbedTombstone(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	renderPage(recorder, http.StatusNotFound, "embed", embedView{Theme: themeDark,
+		Meta: pageMeta{Title: "Chirpy", URL: "https://chirpy.test/chirps/" + uuid.NewString()}})
+	page := recorder.Body.String()
+	if recorder.Code != http.StatusNotFound || !strings.Contains(page, "This chirp is no longer available.") {
+		t.Errorf("tombstone embed = %d:\n%s", recorder.Code, page)
+	}
+	if !strings.Contains(page, `<body class="dark">`) {
+		t.Errorf("tombstone embed ignores the theme:\n%s", page)
+	}
+}
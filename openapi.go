@@ -0,0 +1,779 @@
+package main
+
+import (
+	"chirpy/internal/activitypub"
+	"chirpy/internal/openapi"
+	"chirpy/internal/webhooks"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// apiSpec builds the OpenAPI document. Schemas come from the structs the
+// handlers marshal, so they change along with them.
+type apiSpec struct {
+	doc     *openapi.Document
+	schemas *openapi.Schemas
+}
+
+const staticContent = "*/*"
+
+var bearer = []openapi.Requirement{{"bearerAuth": {}}}
+var optionalBearer = []openapi.Requirement{{"bearerAuth": {}}, {}}
+
+// openapiJSON is the document served at /api/openapi.json, built on first use.
+var openapiJSON = sync.OnceValues(func() ([]byte, error) {
+	return json.Marshal(newAPISpec().doc)
+})
+
+func handleOpenAPI(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	body, err := openapiJSON()
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(body)
+}
+
+func content(contentType string, schema *openapi.Schema) map[string]openapi.MediaType {
+	return map[string]openapi.MediaType{contentType: {Schema: schema}}
+}
+
+func jsonBody(schema *openapi.Schema) *openapi.RequestBody {
+	return &openapi.RequestBody{Required: true, Content: content(jsonContent, schema)}
+}
+
+func jsonOK(description string, schema *openapi.Schema) *openapi.Response {
+	return &openapi.Response{Description: description, Content: content(jsonContent, schema)}
+}
+
+func empty(description string) *openapi.Response {
+	return &openapi.Response{Description: description}
+}
+
+func pathParam(name, description, format string) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "path", Description: description, Required: true,
+		Schema: &openapi.Schema{Type: "string", Format: format}}
+}
+
+func queryParam(name, description string, schema *openapi.Schema) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+func idParam(what string) openapi.Parameter {
+	return pathParam("id", "ID of the "+what+".", "uuid")
+}
+
+func bounded(low, high int) *openapi.Schema {
+	return &openapi.Schema{Type: "integer", Minimum: &low, Maximum: &high}
+}
+
+func limited(schema *openapi.Schema, low, high int) *openapi.Schema {
+	schema.MinLength, schema.MaxLength = &low, &high
+	return schema
+}
+
+func enum(values ...string) *openapi.Schema {
+	return &openapi.Schema{Type: "string", Enum: values}
+}
+
+// idempotencyParam lets a client retry a POST without doing it twice.
+var idempotencyParam = openapi.Parameter{Name: idempotencyKeyHeader, In: "header",
+	Description: "A unique key for the request, like a UUID. A retry with the same key within a day gets the " +
+		"first response again, marked " + idempotentReplayHeader + ": true, instead of being run twice.",
+	Schema: limited(&openapi.Schema{Type: "string"}, 1, maxIdempotencyKey)}
+
+var pageParams = []openapi.Parameter{
+	queryParam("cursor", "next_cursor from the previous page.", &openapi.Schema{Type: "string"}),
+	queryParam("limit", "Page size.", bounded(1, maxPageSize)),
+}
+
+// responses answers success with ok and each error code with the shared response
+// for it, adding that to the components the first time it is used.
+func (spec apiSpec) responses(code int, ok *openapi.Response, errs ...int) map[string]*openapi.Response {
+	out := map[string]*openapi.Response{strconv.Itoa(code): ok}
+	for _, errCode := range errs {
+		name := strings.ReplaceAll(http.StatusText(errCode), " ", "")
+		if spec.doc.Components.Responses[name] == nil {
+			spec.doc.Components.Responses[name] = jsonOK(http.StatusText(errCode)+".", openapi.Ref("Error"))
+		}
+		out[strconv.Itoa(errCode)] = &openapi.Response{Ref: "#/components/responses/" + name}
+	}
+	return out
+}
+
+func newAPISpec() apiSpec {
+	doc := openapi.New(openapi.Info{Title: "Chirpy", Version: "1.0.0",
+		Description: "Short posts, the people who write them and everything around them."})
+	spec := apiSpec{doc: doc, schemas: doc.Schemas()}
+	doc.Tags = []openapi.Tag{
+		{Name: "chirps", Description: "Posting and reading chirps."},
+		{Name: "users", Description: "Accounts, profiles and the relationships between them."},
+		{Name: "auth", Description: "Logging in and managing tokens. None of these take an " + idempotencyKeyHeader +
+			": logging in and refreshing answer with tokens, which are never kept for a retry, and revoking a " +
+			"token twice does no more than revoking it once. Retry them as they are."},
+		{Name: "messages", Description: "Private conversations."},
+		{Name: "notifications", Description: "What happened to the caller's chirps and account."},
+		{Name: "webhooks", Description: "Outgoing webhooks, and the Polka webhook coming in."},
+		{Name: "federation", Description: "ActivityPub, and following people on other servers."},
+		{Name: "pages", Description: "HTML pages, feeds and embeds."},
+		{Name: "graphql", Description: "Users, chirps and the relationships between them in one query."},
+		{Name: "admin", Description: "Operating the server."},
+	}
+	doc.Components.SecuritySchemes = map[string]openapi.SecurityScheme{
+		"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT",
+			Description: "Access token from POST /api/login or POST /api/refresh. It expires after an hour."},
+		"refreshToken": {Type: "http", Scheme: "bearer",
+			Description: "Refresh token from POST /api/login. It lasts 60 days unless revoked."},
+		"polkaKey": {Type: "apiKey", In: "header", Name: "Authorization",
+			Description: "Polka's API key, sent as \"ApiKey <key>\"."},
+		"polkaSignature": {Type: "apiKey", In: "header", Name: polkaSignatureHeader,
+			Description: "Hex HMAC-SHA256 of the body keyed with Polka's API key, optionally prefixed \"sha256=\"."},
+		"httpSignature": {Type: "apiKey", In: "header", Name: "Signature",
+			Description: "HTTP Signature (draft-cavage-http-signatures-12) by the sending actor's key, covering " +
+				"(request-target), host, date and digest."},
+	}
+	spec.defineSchemas()
+	spec.chirpRoutes()
+	spec.userRoutes()
+	spec.messageRoutes()
+	spec.webhookRoutes()
+	spec.federationRoutes()
+	spec.pageRoutes()
+	spec.graphqlRoutes()
+	spec.versionRoutes()
+	return spec
+}
+
+// unversioned are the paths under /api that are not part of a version.
+var unversioned = map[string]bool{"/api/healthz": true, "/api/openapi.json": true}
+
+// deprecationHeaders are sent by v1 once it is deprecated.
+var deprecationHeaders = map[string]openapi.Header{
+	"Deprecation": {Description: "When this version was deprecated, as @<unix time> (RFC 9745).",
+		Schema: &openapi.Schema{Type: "string"}},
+	"Sunset": {Description: "When this version will stop working, as an HTTP date (RFC 8594).",
+		Schema: &openapi.Schema{Type: "string"}},
+	"Link": {Description: "The same resource in the successor version, with rel=\"successor-version\".",
+		Schema: &openapi.Schema{Type: "string"}},
+}
+
+// versionRoutes describes each route under /api as every version serves it.
+// The routes are written down once, as v1 behaves; v2 answers every error,
+// including those v1 sends without a body, with a Problem.
+func (spec apiSpec) versionRoutes() {
+	paths := map[string]openapi.PathItem{}
+	for path, item := range spec.doc.Paths {
+		if strings.HasPrefix(path, "/api/") && !unversioned[path] {
+			paths[path] = item
+		}
+	}
+	for path, item := range paths {
+		for method, op := range item {
+			for _, surface := range apiVersions {
+				versioned := spec.versionOperation(op, surface.version)
+				if surface.prefix != "/api" {
+					versioned.OperationID += "V" + strconv.Itoa(int(surface.version))
+				}
+				spec.doc.Add(strings.ToUpper(method)+" "+surface.prefix+strings.TrimPrefix(path, "/api"), versioned)
+			}
+		}
+	}
+}
+
+// versionOperation copies op as the given version serves it.
+func (spec apiSpec) versionOperation(op *openapi.Operation, version apiVersion) *openapi.Operation {
+	versioned := *op
+	versioned.Parameters = slices.Clone(op.Parameters)
+	versioned.Responses = map[string]*openapi.Response{}
+	for code, response := range op.Responses {
+		status, _ := strconv.Atoi(code)
+		switch {
+		case version == apiV1 && status < 400 && response.Ref == "":
+			withHeaders := *response
+			withHeaders.Headers = maps.Clone(response.Headers)
+			if withHeaders.Headers == nil {
+				withHeaders.Headers = map[string]openapi.Header{}
+			}
+			maps.Copy(withHeaders.Headers, deprecationHeaders)
+			response = &withHeaders
+		case version >= apiV2 && status >= 400 && response.Ref != "":
+			response = spec.problemResponse(status)
+		case version >= apiV2 && status >= 400:
+			response = problemOK(response.Description)
+		}
+		versioned.Responses[code] = response
+	}
+	if version >= apiV2 && op.RequestBody != nil {
+		// Bodies are decoded strictly from v2 on; see decodeRequest.
+		for _, status := range []int{http.StatusBadRequest, http.StatusRequestEntityTooLarge,
+			http.StatusUnsupportedMediaType} {
+			versioned.Responses[strconv.Itoa(status)] = spec.problemResponse(status)
+		}
+	}
+	return &versioned
+}
+
+func problemOK(description string) *openapi.Response {
+	return &openapi.Response{Description: description, Content: content(problemContent, openapi.Ref("Problem"))}
+}
+
+// problemResponse is the shared response for an error code as v2 sends it,
+// adding that to the components the first time it is used.
+func (spec apiSpec) problemResponse(code int) *openapi.Response {
+	name := strings.ReplaceAll(http.StatusText(code), " ", "") + "Problem"
+	if spec.doc.Components.Responses[name] == nil {
+		spec.doc.Components.Responses[name] = problemOK(http.StatusText(code) + ".")
+	}
+	return &openapi.Response{Ref: "#/components/responses/" + name}
+}
+
+func (spec apiSpec) defineSchemas() {
+	s := spec.schemas
+	schemas := spec.doc.Components.Schemas
+	s.Define("Error", chirpErr{})
+	schemas["Error"].Description = "An error from v1."
+	s.Define("Problem", problem{})
+	schemas["Problem"].Description = "An error from v2 on, as a problem document (RFC 9457)."
+	schemas["Problem"].Properties["type"].Format = "uri"
+	schemas["Problem"].Properties["instance"].Format = "uri"
+	schemas["Problem"].Properties["instance"].Description = "The request's ID, which is also sent in X-Request-Id."
+	schemas["Problem"].Properties["code"].Description = "What went wrong, for programs. Most errors are named " +
+		"after their status, like not_found; some have a code of their own, like " + codeChirpTooLong + " or " +
+		codeHandleTaken + "."
+	s.Define("ChirpEntity", chirpEntity{})
+	s.Define("ChirpTombstone", chirpTombstone{})
+	s.Define("Chirp", chirpResp{})
+	schemas["Chirp"].Properties["kind"] = enum(chirpKindChirp, chirpKindRechirp, chirpKindQuote, chirpKindReply)
+	schemas["Chirp"].Properties["ref_chirp"] = &openapi.Schema{Description: "The chirp this one rechirps, quotes " +
+		"or replies to. Present for every kind but chirp.", OneOf: []*openapi.Schema{openapi.Ref("Chirp"),
+		openapi.Ref("ChirpTombstone")}}
+	schemas["Chirp"].Properties["filtered"].Description = "Set when one of the caller's muted words collapses the chirp."
+	schemas["ChirpTombstone"].Properties["kind"] = enum(chirpKindTombstone)
+	schemas["ChirpEntity"].Properties["start"].Description = "Offset in code points, inclusive."
+	schemas["ChirpEntity"].Properties["end"].Description = "Offset in code points, exclusive."
+	s.Body("ChirpBody", chirpBody{}, "body")
+	schemas["ChirpBody"].Properties["body"] = limited(&openapi.Schema{Type: "string",
+		Description: fmt.Sprintf("At most %d characters, or %d for Chirpy Red members.", lengthLimit, redLengthLimit)},
+		0, redLengthLimit)
+	s.Define("ChirpPage", chirpPage{})
+	s.Define("TrendingTag", trendingTag{})
+
+	s.Body("NewUser", addUser{}, "email", "password")
+	s.Define("User", addedUser{})
+	s.Body("Login", loginUser{}, "email", "password")
+	s.Define("LoggedInUser", loggedinUser{})
+	s.Body("UserUpdate", updateUser{}, "email", "password")
+	schemas["NewUser"].Properties["email"].Format = "email"
+	schemas["NewUser"].Properties["handle"].Description = "Required from v2. v1 signups without one get user_ " +
+		"followed by the first 12 hex digits of their id."
+	schemas["UserUpdate"].Properties["email"].Format = "email"
+	schemas["UserUpdate"].Properties["handle"].Description = "Handles can be changed once every 30 days."
+	s.Define("Token", refreshedToken{})
+	s.Define("Profile", userProfile{})
+	s.Body("ProfilePatch", profilePatch{})
+	schemas["ProfilePatch"].Description = "Fields left out or null are left as they are."
+	schemas["ProfilePatch"].Properties["display_name"].MaxLength = ptr(displayNameLimit)
+	schemas["ProfilePatch"].Properties["bio"].MaxLength = ptr(bioLimit)
+	schemas["ProfilePatch"].Properties["avatar_url"].MaxLength = ptr(avatarUrlLimit)
+	s.Define("FollowUser", followUser{})
+	s.Define("FollowPage", followPage{})
+	s.Define("ListedUser", listedUser{})
+	s.Define("ListedUserPage", listedUserPage{})
+	s.Body("MutedWordBody", mutedWordMsg{}, "phrase")
+	schemas["MutedWordBody"].Properties["phrase"] = limited(&openapi.Schema{Type: "string"}, 1, mutedPhraseLimit)
+	schemas["MutedWordBody"].Properties["action"] = enum(muteActionHide, muteActionCollapse)
+	schemas["MutedWordBody"].Properties["action"].Description = "Defaults to " + muteActionCollapse + "."
+	s.Define("MutedWord", mutedWordResp{})
+	schemas["MutedWord"].Properties["action"] = enum(muteActionHide, muteActionCollapse)
+
+	s.Body("StartConversation", startConversation{}, "participant_ids", "body")
+	s.Body("MessageBody", messageMsg{}, "body")
+	limited(schemas["StartConversation"].Properties["body"], 0, lengthLimit)
+	limited(schemas["MessageBody"].Properties["body"], 1, lengthLimit)
+	s.Define("Message", messageResp{})
+	s.Define("Participant", participantResp{})
+	s.Define("Conversation", conversationResp{})
+	s.Define("ConversationPage", conversationPage{})
+	s.Define("MessagePage", messagePage{})
+	s.Define("NotificationActor", notificationActor{})
+	s.Define("Notification", notificationResp{})
+	s.Define("NotificationPage", notificationPage{})
+
+	s.Body("WebhookBody", webhookMsg{}, "url", "event_types")
+	schemas["WebhookBody"].Properties["event_types"].Items = enum(webhookEventTypes...)
+	schemas["WebhookBody"].Properties["secret"].Description = fmt.Sprintf("At least %d characters. One is "+
+		"generated if left out.", webhookSecretMin)
+	s.Define("Webhook", webhookResp{})
+	schemas["Webhook"].Properties["secret"].Description = "Only returned when the webhook is created."
+	s.Define("WebhookDelivery", deliveryResp{})
+	schemas["WebhookDelivery"].Properties["payload"] = openapi.Ref("WebhookEvent")
+	s.Define("DeliveryPage", deliveryPage{})
+	s.Define("WebhookUser", webhookUser{})
+	s.Define("WebhookEvent", webhookEvent{})
+	schemas["WebhookEvent"].Properties["type"] = enum(webhookEventTypes...)
+	schemas["WebhookEvent"].Properties["data"] = &openapi.Schema{Description: "A Chirp for chirp events, a " +
+		"WebhookUser for user events.", OneOf: []*openapi.Schema{openapi.Ref("Chirp"), openapi.Ref("WebhookUser")}}
+	s.Define("PolkaEvent", polkaEvent{})
+
+	s.Body("RemoteFollowBody", remoteFollowMsg{}, "account")
+	schemas["RemoteFollowBody"].Properties["account"].Description = "The account to follow, as user@example.com."
+	s.Define("RemoteActor", remoteActorResp{})
+	s.Define("RemoteFollow", remoteFollowResp{})
+	s.Define("RemotePost", remotePostResp{})
+	s.Define("RemotePostPage", remotePostPage{})
+	s.Define("Actor", activitypub.Actor{})
+	s.Define("Note", activitypub.Note{})
+	s.Define("Activity", activitypub.Activity{})
+	s.Define("OrderedCollection", activitypub.OrderedCollection{})
+	s.Define("OrderedCollectionPage", activitypub.OrderedCollectionPage{})
+	s.Define("WebFinger", activitypub.WebFinger{})
+	s.Define("OEmbed", oembedResp{})
+
+	s.Body("GraphQLRequest", graphqlBody{}, "query")
+	s.Define("GraphQLLocation", graphqlLocation{})
+	s.Define("GraphQLError", graphqlError{})
+	schemas["GraphQLError"].Properties["extensions"].Description = "Holds the error's code, one of the problem " +
+		"codes the REST API uses. Faults on our side are all internal_server_error."
+	s.Define("GraphQLResponse", graphqlResponse{})
+	schemas["GraphQLResponse"].Properties["data"] = &openapi.Schema{Type: "object",
+		Description: "The result, shaped like the query. Left out when the query was refused."}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func (spec apiSpec) chirpRoutes() {
+	doc := spec.doc
+	chirp := openapi.Ref("Chirp")
+	chirps := &openapi.Schema{Type: "array", Items: chirp}
+	doc.Add("POST /api/chirps", &openapi.Operation{OperationID: "createChirp", Summary: "Post a chirp",
+		Tags: []string{"chirps"}, Security: bearer, Parameters: []openapi.Parameter{idempotencyParam},
+		RequestBody: jsonBody(openapi.Ref("ChirpBody")),
+		Responses:   spec.responses(http.StatusCreated, jsonOK("The new chirp.", chirp), 400, 401, 409, 422, 500)})
+	doc.Add("GET /api/chirps", &openapi.Operation{OperationID: "listChirps", Summary: "List chirps, oldest first",
+		Description: "Chirps involving users the caller has blocked, or been blocked by, are left out.",
+		Tags:        []string{"chirps"}, Security: optionalBearer,
+		Parameters: []openapi.Parameter{queryParam("author_id", "Only chirps by this user.",
+			&openapi.Schema{Type: "string", Format: "uuid"})},
+		Responses: spec.responses(http.StatusOK, jsonOK("The chirps.", chirps), 400, 500)})
+	doc.Add("GET /api/chirps/{id}", &openapi.Operation{OperationID: "getChirp", Summary: "Get a chirp",
+		Tags: []string{"chirps"}, Security: optionalBearer, Parameters: []openapi.Parameter{idParam("chirp")},
+		Responses: spec.responses(http.StatusOK, jsonOK("The chirp.", chirp), 404, 500)})
+	doc.Add("PUT /api/chirps/{id}", &openapi.Operation{OperationID: "editChirp", Summary: "Edit a chirp",
+		Description: "Only Chirpy Red members can edit, and only their own chirps.",
+		Tags:        []string{"chirps"}, Security: bearer, Parameters: []openapi.Parameter{idParam("chirp")},
+		RequestBody: jsonBody(openapi.Ref("ChirpBody")),
+		Responses:   spec.responses(http.StatusOK, jsonOK("The edited chirp.", chirp), 400, 401, 403, 404, 500)})
+	doc.Add("DELETE /api/chirps/{id}", &openapi.Operation{OperationID: "deleteChirp", Summary: "Delete a chirp",
+		Tags: []string{"chirps"}, Security: bearer, Parameters: []openapi.Parameter{idParam("chirp")},
+		Responses: map[string]*openapi.Response{
+			"204": empty("Deleted."),
+			"401": empty("Missing or invalid token."),
+			"403": empty("The chirp is someone else's."),
+			"404": empty("No such chirp."),
+			"500": empty("Internal Server Error."),
+		}})
+	doc.Add("POST /api/chirps/{id}/rechirp", &openapi.Operation{OperationID: "rechirp", Summary: "Rechirp a chirp",
+		Tags: []string{"chirps"}, Security: bearer, Parameters: []openapi.Parameter{idParam("chirp")},
+		Responses: spec.responses(http.StatusCreated, jsonOK("The rechirp.", chirp), 400, 401, 403, 404, 409)})
+	doc.Add("POST /api/chirps/{id}/quote", &openapi.Operation{OperationID: "quoteChirp", Summary: "Quote a chirp",
+		Tags: []string{"chirps"}, Security: bearer, Parameters: []openapi.Parameter{idParam("chirp")},
+		RequestBody: jsonBody(openapi.Ref("ChirpBody")),
+		Responses:   spec.responses(http.StatusCreated, jsonOK("The quote.", chirp), 400, 401, 403, 404, 500)})
+	doc.Add("POST /api/chirps/{id}/replies", &openapi.Operation{OperationID: "replyToChirp",
+		Summary: "Reply to a chirp", Tags: []string{"chirps"}, Security: bearer,
+		Parameters: []openapi.Parameter{idParam("chirp")}, RequestBody: jsonBody(openapi.Ref("ChirpBody")),
+		Responses: spec.responses(http.StatusCreated, jsonOK("The reply.", chirp), 400, 401, 403, 404, 500)})
+	doc.Add("GET /api/chirps/{id}/replies", &openapi.Operation{OperationID: "listReplies",
+		Summary: "List the replies to a chirp", Tags: []string{"chirps"}, Security: optionalBearer,
+		Parameters: []openapi.Parameter{idParam("chirp")},
+		Responses:  spec.responses(http.StatusOK, jsonOK("The replies, oldest first.", chirps), 404, 500)})
+	doc.Add("POST /api/chirps/{id}/like", &openapi.Operation{OperationID: "likeChirp", Summary: "Like a chirp",
+		Tags: []string{"chirps"}, Security: bearer, Parameters: []openapi.Parameter{idParam("chirp")},
+		Responses: spec.responses(http.StatusNoContent, empty("Liked."), 401, 403, 404, 500)})
+	doc.Add("DELETE /api/chirps/{id}/like", &openapi.Operation{OperationID: "unlikeChirp", Summary: "Unlike a chirp",
+		Tags: []string{"chirps"}, Security: bearer, Parameters: []openapi.Parameter{idParam("chirp")},
+		Responses: spec.responses(http.StatusNoContent, empty("No longer liked."), 401, 404, 500)})
+	doc.Add("GET /api/hashtags/{tag}/chirps", &openapi.Operation{OperationID: "listHashtagChirps",
+		Summary: "List chirps with a hashtag", Tags: []string{"chirps"}, Security: optionalBearer,
+		Parameters: []openapi.Parameter{pathParam("tag", "The hashtag, with or without its #.", "")},
+		Responses:  spec.responses(http.StatusOK, jsonOK("The chirps, oldest first.", chirps), 404, 500)})
+	windows := make([]string, len(trendingWindows))
+	for i := range trendingWindows {
+		windows[i] = trendingWindows[i].name
+	}
+	doc.Add("GET /api/trending", &openapi.Operation{OperationID: "listTrending", Summary: "List trending hashtags",
+		Tags: []string{"chirps"}, Parameters: []openapi.Parameter{
+			queryParam("window", "Defaults to "+defaultTrendingWindow+".", enum(windows...)),
+			queryParam("limit", "How many hashtags to return.", bounded(1, maxTrendingLimit)),
+		},
+		Responses: spec.responses(http.StatusOK, jsonOK("The hashtags, most trending first.",
+			&openapi.Schema{Type: "array", Items: openapi.Ref("TrendingTag")}), 400, 500)})
+	doc.Add("GET /api/timeline", &openapi.Operation{OperationID: "getTimeline",
+		Summary: "Chirps by the caller and the people they follow, newest first", Tags: []string{"chirps"},
+		Security: bearer, Parameters: pageParams,
+		Responses: spec.responses(http.StatusOK, jsonOK("A page of chirps.", openapi.Ref("ChirpPage")), 400, 401, 500)})
+	doc.Add("GET /api/stream/chirps", &openapi.Operation{OperationID: "streamChirps", Summary: "Stream new chirps",
+		Description: "Server-sent events. Each chirp event's data is a Chirp and its id a cursor: reconnecting " +
+			"with it as Last-Event-ID replays what was missed.",
+		Tags: []string{"chirps"}, Security: optionalBearer, Parameters: []openapi.Parameter{
+			queryParam("author_id", "Only chirps by this user.", &openapi.Schema{Type: "string", Format: "uuid"}),
+			queryParam("hashtag", "Only chirps with this hashtag.", &openapi.Schema{Type: "string"}),
+			queryParam("timeline", "Only chirps on the caller's timeline. Needs a token.", enum("true")),
+			{Name: "Last-Event-ID", In: "header", Description: "Resume after this event.",
+				Schema: &openapi.Schema{Type: "string"}},
+		},
+		Responses: spec.responses(http.StatusOK, &openapi.Response{Description: "The event stream.",
+			Content: content(eventStreamContent, &openapi.Schema{Type: "string"})}, 400, 401)})
+	doc.Add("GET /api/ws", &openapi.Operation{OperationID: "openWebSocket", Summary: "Open a WebSocket",
+		Description: "Send {\"type\": \"subscribe\", \"channel\": ...} to follow the timeline, notifications, " +
+			"chirp:{id} or conversation:{id}. Events carry the same JSON as the REST API.",
+		Tags: []string{"chirps"}, Security: bearer,
+		Responses: spec.responses(http.StatusSwitchingProtocols, empty("Upgraded to a WebSocket."), 401)})
+}
+
+func (spec apiSpec) userRoutes() {
+	doc := spec.doc
+	profile := openapi.Ref("Profile")
+	doc.Add("POST /api/users", &openapi.Operation{OperationID: "createUser", Summary: "Sign up",
+		Tags: []string{"users"}, Parameters: []openapi.Parameter{idempotencyParam},
+		RequestBody: jsonBody(openapi.Ref("NewUser")),
+		Responses: spec.responses(http.StatusCreated, jsonOK("The new user.", openapi.Ref("User")),
+			400, 409, 422, 500)})
+	doc.Add("PUT /api/users", &openapi.Operation{OperationID: "updateUser",
+		Summary: "Change the caller's email, password or handle", Tags: []string{"users"}, Security: bearer,
+		Description: "This takes no " + idempotencyKeyHeader + ": it sets the account to what the request says, " +
+			"so a retry changes nothing more, and the handle cooldown only counts an actual change of handle.",
+		RequestBody: jsonBody(openapi.Ref("UserUpdate")),
+		Responses: spec.responses(http.StatusOK, jsonOK("The updated user.", openapi.Ref("User")),
+			400, 401, 409, 429, 500)})
+	doc.Add("POST /api/login", &openapi.Operation{OperationID: "login", Summary: "Log in",
+		Tags: []string{"auth"}, RequestBody: jsonBody(openapi.Ref("Login")),
+		Responses: spec.responses(http.StatusOK, jsonOK("The user, with an access and a refresh token.",
+			openapi.Ref("LoggedInUser")), 400, 401, 500)})
+	doc.Add("POST /api/refresh", &openapi.Operation{OperationID: "refresh", Summary: "Get a new access token",
+		Tags: []string{"auth"}, Security: []openapi.Requirement{{"refreshToken": {}}},
+		Responses: map[string]*openapi.Response{
+			"200": jsonOK("A new access token.", openapi.Ref("Token")),
+			"401": empty("The refresh token is missing, unknown, expired or revoked."),
+		}})
+	revoked := spec.responses(http.StatusNoContent, empty("Revoked."), 500)
+	revoked["401"] = empty("No refresh token was given.")
+	doc.Add("POST /api/revoke", &openapi.Operation{OperationID: "revoke", Summary: "Revoke a refresh token",
+		Tags: []string{"auth"}, Security: []openapi.Requirement{{"refreshToken": {}}}, Responses: revoked})
+	doc.Add("GET /api/users/{id}", &openapi.Operation{OperationID: "getUser", Summary: "Get a profile",
+		Tags: []string{"users"}, Parameters: []openapi.Parameter{idParam("user")},
+		Responses: spec.responses(http.StatusOK, jsonOK("The profile.", profile), 404)})
+	doc.Add("PATCH /api/users/me", &openapi.Operation{OperationID: "updateProfile",
+		Summary: "Change the caller's profile", Tags: []string{"users"}, Security: bearer,
+		RequestBody: jsonBody(openapi.Ref("ProfilePatch")),
+		Responses:   spec.responses(http.StatusOK, jsonOK("The updated profile.", profile), 400, 401, 500)})
+	doc.Add("GET /api/users/by-handle/{handle}", &openapi.Operation{OperationID: "getUserByHandle",
+		Summary: "Find a profile by handle", Tags: []string{"users"},
+		Parameters: []openapi.Parameter{pathParam("handle", "The handle, in any case.", "")},
+		Responses:  spec.responses(http.StatusOK, jsonOK("The profile.", profile), 404)})
+	doc.Add("GET /api/users/{id}/mentions", &openapi.Operation{OperationID: "listMentions",
+		Summary: "List chirps mentioning a user, oldest first", Tags: []string{"users"}, Security: optionalBearer,
+		Parameters: []openapi.Parameter{idParam("user")},
+		Responses: spec.responses(http.StatusOK, jsonOK("The chirps.", &openapi.Schema{Type: "array",
+			Items: openapi.Ref("Chirp")}), 404, 500)})
+	for _, list := range []struct{ path, operationID, summary string }{
+		{"followers", "listFollowers", "List who follows a user"},
+		{"following", "listFollowing", "List who a user follows"},
+	} {
+		doc.Add("GET /api/users/{id}/"+list.path, &openapi.Operation{OperationID: list.operationID,
+			Summary: list.summary + ", newest first", Tags: []string{"users"},
+			Parameters: append([]openapi.Parameter{idParam("user")}, pageParams...),
+			Responses:  spec.responses(http.StatusOK, jsonOK("A page of users.", openapi.Ref("FollowPage")), 400, 404, 500)})
+	}
+	relations := []struct {
+		path, verb, past string
+		errs             []int
+	}{
+		{"follow", "Follow", "Followed", []int{400, 401, 403, 404, 500}},
+		{"block", "Block", "Blocked", []int{400, 401, 404, 500}},
+		{"mute", "Mute", "Muted", []int{400, 401, 404, 500}},
+	}
+	for _, relation := range relations {
+		doc.Add("POST /api/users/{id}/"+relation.path, &openapi.Operation{OperationID: relation.path + "User",
+			Summary: relation.verb + " a user", Tags: []string{"users"}, Security: bearer,
+			Parameters: []openapi.Parameter{idParam("user")},
+			Responses:  spec.responses(http.StatusNoContent, empty(relation.past+"."), relation.errs...)})
+		doc.Add("DELETE /api/users/{id}/"+relation.path, &openapi.Operation{OperationID: "un" + relation.path + "User",
+			Summary: "Un" + relation.path + " a user", Tags: []string{"users"}, Security: bearer,
+			Parameters: []openapi.Parameter{idParam("user")},
+			Responses: spec.responses(http.StatusNoContent, empty("No longer "+strings.ToLower(relation.past)+"."),
+				400, 401, 404, 500)})
+	}
+	for _, listing := range []string{"blocks", "mutes"} {
+		doc.Add("GET /api/"+listing, &openapi.Operation{OperationID: "list" + strings.ToUpper(listing[:1]) + listing[1:],
+			Summary: "List the users the caller " + strings.TrimSuffix(listing, "s") + "s", Tags: []string{"users"},
+			Security: bearer, Parameters: pageParams,
+			Responses: spec.responses(http.StatusOK, jsonOK("A page of users.", openapi.Ref("ListedUserPage")),
+				400, 401, 500)})
+	}
+	mutedWord := openapi.Ref("MutedWord")
+	doc.Add("GET /api/muted-words", &openapi.Operation{OperationID: "listMutedWords",
+		Summary: "List the caller's muted words", Tags: []string{"users"}, Security: bearer,
+		Responses: spec.responses(http.StatusOK, jsonOK("The muted words.",
+			&openapi.Schema{Type: "array", Items: mutedWord}), 401, 500)})
+	doc.Add("POST /api/muted-words", &openapi.Operation{OperationID: "addMutedWord", Summary: "Mute a word",
+		Tags: []string{"users"}, Security: bearer, RequestBody: jsonBody(openapi.Ref("MutedWordBody")),
+		Responses: spec.responses(http.StatusCreated, jsonOK("The muted word.", mutedWord), 400, 401, 500)})
+	doc.Add("DELETE /api/muted-words/{id}", &openapi.Operation{OperationID: "deleteMutedWord",
+		Summary: "Unmute a word", Tags: []string{"users"}, Security: bearer,
+		Parameters: []openapi.Parameter{idParam("muted word")},
+		Responses:  spec.responses(http.StatusNoContent, empty("No longer muted."), 401, 404, 500)})
+	doc.Add("GET /api/notifications", &openapi.Operation{OperationID: "listNotifications",
+		Summary: "List the caller's notifications, newest first", Tags: []string{"notifications"},
+		Description: "Groups are ordered by when their first notification arrived, so a group that grows " +
+			"keeps its place between pages.",
+		Security: bearer, Parameters: pageParams,
+		Responses: spec.responses(http.StatusOK, jsonOK("A page of notifications.", openapi.Ref("NotificationPage")),
+			400, 401, 500)})
+	doc.Add("POST /api/notifications/read", &openapi.Operation{OperationID: "readAllNotifications",
+		Summary: "Mark every notification read", Tags: []string{"notifications"}, Security: bearer,
+		Responses: spec.responses(http.StatusNoContent, empty("Marked read."), 401, 500)})
+	doc.Add("POST /api/notifications/{id}/read", &openapi.Operation{OperationID: "readNotification",
+		Summary: "Mark a notification read", Tags: []string{"notifications"}, Security: bearer,
+		Parameters: []openapi.Parameter{idParam("notification")},
+		Responses:  spec.responses(http.StatusNoContent, empty("Marked read."), 401, 404, 500)})
+}
+
+func (spec apiSpec) messageRoutes() {
+	doc := spec.doc
+	conversation := openapi.Ref("Conversation")
+	doc.Add("POST /api/conversations", &openapi.Operation{OperationID: "startConversation",
+		Summary: "Start a conversation", Tags: []string{"messages"}, Security: bearer,
+		Description: "Starting a conversation with one other user picks up the existing one, if there is one.",
+		RequestBody: jsonBody(openapi.Ref("StartConversation")),
+		Responses: spec.responses(http.StatusCreated, jsonOK("The new conversation.", conversation),
+			400, 401, 403, 404, 500)})
+	doc.Paths["/api/conversations"]["post"].Responses["200"] = jsonOK("The existing direct conversation.", conversation)
+	doc.Add("GET /api/conversations", &openapi.Operation{OperationID: "listConversations",
+		Summary: "List the caller's conversations, most recently active first", Tags: []string{"messages"},
+		Security: bearer, Parameters: pageParams,
+		Responses: spec.responses(http.StatusOK, jsonOK("A page of conversations.", openapi.Ref("ConversationPage")),
+			400, 401, 500)})
+	doc.Add("GET /api/conversations/{id}/messages", &openapi.Operation{OperationID: "listMessages",
+		Summary: "List a conversation's messages, newest first", Tags: []string{"messages"}, Security: bearer,
+		Parameters: append([]openapi.Parameter{idParam("conversation")}, pageParams...),
+		Responses: spec.responses(http.StatusOK, jsonOK("A page of messages.", openapi.Ref("MessagePage")),
+			400, 401, 404, 500)})
+	doc.Add("POST /api/conversations/{id}/messages", &openapi.Operation{OperationID: "sendMessage",
+		Summary: "Send a message", Tags: []string{"messages"}, Security: bearer,
+		Parameters: []openapi.Parameter{idParam("conversation")}, RequestBody: jsonBody(openapi.Ref("MessageBody")),
+		Responses: spec.responses(http.StatusCreated, jsonOK("The message.", openapi.Ref("Message")),
+			400, 401, 403, 404, 500)})
+	doc.Add("POST /api/conversations/{id}/read", &openapi.Operation{OperationID: "readConversation",
+		Summary: "Mark a conversation read", Tags: []string{"messages"}, Security: bearer,
+		Parameters: []openapi.Parameter{idParam("conversation")},
+		Responses:  spec.responses(http.StatusNoContent, empty("Marked read."), 401, 404, 500)})
+}
+
+func (spec apiSpec) webhookRoutes() {
+	doc := spec.doc
+	doc.Add("POST /api/polka/webhooks", &openapi.Operation{OperationID: "polkaWebhook",
+		Summary: "Receive a membership change from Polka",
+		Description: "user.upgraded grants Chirpy Red and user.downgraded takes it away. Other events, and " +
+			"events already seen, are acknowledged and ignored.",
+		Tags:        []string{"webhooks"},
+		Security:    []openapi.Requirement{{"polkaSignature": {}}, {"polkaKey": {}}},
+		RequestBody: jsonBody(openapi.Ref("PolkaEvent")),
+		Responses:   spec.responses(http.StatusNoContent, empty("Acknowledged."), 400, 401, 404, 500)})
+	webhook := openapi.Ref("Webhook")
+	doc.Add("GET /api/webhooks", &openapi.Operation{OperationID: "listWebhooks",
+		Summary: "List the caller's webhooks", Tags: []string{"webhooks"}, Security: bearer,
+		Responses: spec.responses(http.StatusOK, jsonOK("The webhooks.",
+			&openapi.Schema{Type: "array", Items: webhook}), 401, 500)})
+	doc.Add("POST /api/webhooks", &openapi.Operation{OperationID: "addWebhook", Summary: "Add a webhook",
+		Description: "Deliveries are signed with the secret: " + webhooks.SignatureHeader + " is its HMAC-SHA256 of " +
+			"\"<" + webhooks.TimestampHeader + ">.<body>\".",
+		Tags: []string{"webhooks"}, Security: bearer, RequestBody: jsonBody(openapi.Ref("WebhookBody")),
+		Responses: spec.responses(http.StatusCreated, jsonOK("The webhook, with its secret.", webhook),
+			400, 401, 500)})
+	doc.Add("DELETE /api/webhooks/{id}", &openapi.Operation{OperationID: "deleteWebhook",
+		Summary: "Delete a webhook", Tags: []string{"webhooks"}, Security: bearer,
+		Parameters: []openapi.Parameter{idParam("webhook")},
+		Responses:  spec.responses(http.StatusNoContent, empty("Deleted."), 401, 404, 500)})
+	doc.Add("GET /api/webhooks/{id}/deliveries", &openapi.Operation{OperationID: "listDeliveries",
+		Summary: "List a webhook's deliveries, newest first", Tags: []string{"webhooks"}, Security: bearer,
+		Parameters: append([]openapi.Parameter{idParam("webhook")}, pageParams...),
+		Responses: spec.responses(http.StatusOK, jsonOK("A page of deliveries.", openapi.Ref("DeliveryPage")),
+			400, 401, 404, 500)})
+	doc.Add("POST /api/webhooks/{id}/deliveries/{delivery_id}/redeliver", &openapi.Operation{
+		OperationID: "redeliver", Summary: "Send a delivery again", Tags: []string{"webhooks"}, Security: bearer,
+		Parameters: []openapi.Parameter{idParam("webhook"), pathParam("delivery_id", "ID of the delivery.", "uuid")},
+		Responses: spec.responses(http.StatusAccepted, jsonOK("The delivery, queued again.",
+			openapi.Ref("WebhookDelivery")), 401, 404, 500)})
+}
+
+func (spec apiSpec) federationRoutes() {
+	doc := spec.doc
+	activity := func(description, name string) *openapi.Response {
+		return &openapi.Response{Description: description, Content: content(activitypub.ContentType, openapi.Ref(name))}
+	}
+	doc.Add("GET /.well-known/webfinger", &openapi.Operation{OperationID: "webfinger",
+		Summary: "Find a local account's actor", Tags: []string{"federation"}, Parameters: []openapi.Parameter{
+			{Name: "resource", In: "query", Required: true, Description: "acct:handle@domain",
+				Schema: &openapi.Schema{Type: "string"}},
+		},
+		Responses: spec.responses(http.StatusOK, &openapi.Response{Description: "The account.",
+			Content: content(activitypub.JRDContentType, openapi.Ref("WebFinger"))}, 400, 404, 500)})
+	doc.Add("GET /ap/users/{id}", &openapi.Operation{OperationID: "getActor", Summary: "Get a user's actor",
+		Tags: []string{"federation"}, Parameters: []openapi.Parameter{idParam("user")},
+		Responses: spec.responses(http.StatusOK, activity("The actor.", "Actor"), 404, 500)})
+	doc.Add("POST /ap/users/{id}/inbox", &openapi.Operation{OperationID: "postInbox",
+		Summary: "Deliver an activity to a user",
+		Description: "Follow, Undo, Accept, Reject, Create, Update and Delete are acted on. The signer must be " +
+			"the activity's actor.",
+		Tags: []string{"federation"}, Security: []openapi.Requirement{{"httpSignature": {}}},
+		Parameters:  []openapi.Parameter{idParam("user")},
+		RequestBody: &openapi.RequestBody{Required: true, Content: content(activitypub.ContentType, openapi.Ref("Activity"))},
+		Responses:   spec.responses(http.StatusAccepted, empty("Accepted."), 400, 401, 403, 404, 413, 500)})
+	doc.Add("GET /ap/users/{id}/outbox", &openapi.Operation{OperationID: "getOutbox",
+		Summary: "Get a user's outbox",
+		Description: "Without page, the collection, whose first page is given; with it, a page of Create " +
+			"activities, newest first.",
+		Tags: []string{"federation"}, Parameters: append([]openapi.Parameter{idParam("user"),
+			queryParam("page", "Any value asks for a page.", &openapi.Schema{Type: "string"})}, pageParams...),
+		Responses: spec.responses(http.StatusOK, &openapi.Response{Description: "The outbox or one of its pages.",
+			Content: content(activitypub.ContentType, &openapi.Schema{OneOf: []*openapi.Schema{
+				openapi.Ref("OrderedCollection"), openapi.Ref("OrderedCollectionPage")}})}, 400, 404, 500)})
+	doc.Add("GET /ap/users/{id}/{collection}", &openapi.Operation{OperationID: "getFollowCollection",
+		Summary: "Count a user's followers or following", Tags: []string{"federation"},
+		Parameters: []openapi.Parameter{idParam("user"), {Name: "collection", In: "path", Required: true,
+			Schema: enum("followers", "following")}},
+		Responses: spec.responses(http.StatusOK, activity("The collection, without its items.",
+			"OrderedCollection"), 404, 500)})
+	doc.Add("GET /ap/chirps/{id}", &openapi.Operation{OperationID: "getNote", Summary: "Get a chirp as a Note",
+		Tags: []string{"federation"}, Parameters: []openapi.Parameter{idParam("chirp")},
+		Responses: spec.responses(http.StatusOK, activity("The note.", "Note"), 404, 500)})
+	remoteFollow := openapi.Ref("RemoteFollow")
+	doc.Add("GET /api/federation/follows", &openapi.Operation{OperationID: "listRemoteFollows",
+		Summary: "List the accounts on other servers the caller follows", Tags: []string{"federation"},
+		Security: bearer, Responses: spec.responses(http.StatusOK, jsonOK("The follows.",
+			&openapi.Schema{Type: "array", Items: remoteFollow}), 401, 500)})
+	doc.Add("POST /api/federation/follows", &openapi.Operation{OperationID: "followRemote",
+		Summary:     "Follow an account on another server",
+		Description: "The follow is pending until the other server accepts it.", Tags: []string{"federation"},
+		Security: bearer, RequestBody: jsonBody(openapi.Ref("RemoteFollowBody")),
+		Responses: spec.responses(http.StatusAccepted, jsonOK("The pending follow.", remoteFollow),
+			400, 401, 404, 500, 502)})
+	doc.Add("DELETE /api/federation/follows/{id}", &openapi.Operation{OperationID: "unfollowRemote",
+		Summary: "Unfollow an account on another server", Tags: []string{"federation"}, Security: bearer,
+		Parameters: []openapi.Parameter{idParam("remote actor")},
+		Responses:  spec.responses(http.StatusNoContent, empty("No longer followed."), 401, 404, 500)})
+	doc.Add("GET /api/federation/timeline", &openapi.Operation{OperationID: "getRemoteTimeline",
+		Summary: "Posts from followed accounts on other servers, newest first", Tags: []string{"federation"},
+		Security: bearer, Parameters: pageParams,
+		Responses: spec.responses(http.StatusOK, jsonOK("A page of posts.", openapi.Ref("RemotePostPage")),
+			400, 401, 500)})
+}
+
+func (spec apiSpec) pageRoutes() {
+	doc := spec.doc
+	text := func(description string) *openapi.Response {
+		return &openapi.Response{Description: description, Content: content("text/plain", &openapi.Schema{Type: "string"})}
+	}
+	html := func(description string) *openapi.Response {
+		return &openapi.Response{Description: description, Content: content("text/html", &openapi.Schema{Type: "string"})}
+	}
+	doc.Add("GET /api/healthz", &openapi.Operation{OperationID: "healthz", Summary: "Check the server is up",
+		Tags: []string{"admin"}, Responses: map[string]*openapi.Response{"200": text("OK.")}})
+	doc.Add("GET /api/openapi.json", &openapi.Operation{OperationID: "getOpenAPI", Summary: "This document",
+		Tags: []string{"admin"}, Responses: map[string]*openapi.Response{
+			"200": jsonOK("The OpenAPI document.", &openapi.Schema{Type: "object"})}})
+	doc.Add("GET /admin/metrics", &openapi.Operation{OperationID: "getMetrics", Summary: "Count visits",
+		Tags: []string{"admin"}, Responses: map[string]*openapi.Response{"200": text("An HTML page with the visit count.")}})
+	doc.Add("POST /admin/reset", &openapi.Operation{OperationID: "reset",
+		Summary: "Delete every user and reset the visit count", Description: "Only on the dev platform.",
+		Tags: []string{"admin"}, Responses: map[string]*openapi.Response{
+			"200": text("Reset."), "400": text("The reset failed."), "403": text("Not the dev platform.")}})
+	doc.Add("/app/", &openapi.Operation{OperationID: "getStatic", Summary: "Serve the web app's static files",
+		Tags: []string{"pages"}, Responses: map[string]*openapi.Response{
+			"200": {Description: "The file.", Content: content(staticContent, &openapi.Schema{})},
+			"404": text("No such file.")}})
+	doc.Add("GET /{$}", &openapi.Operation{OperationID: "timelinePage", Summary: "The latest chirps",
+		Tags: []string{"pages"}, Security: optionalBearer,
+		Responses: map[string]*openapi.Response{"200": html("The page.")}})
+	doc.Add("GET /chirps/{id}", &openapi.Operation{OperationID: "chirpPage", Summary: "A chirp's page",
+		Tags: []string{"pages"}, Security: optionalBearer, Parameters: []openapi.Parameter{idParam("chirp")},
+		Responses: map[string]*openapi.Response{"200": html("The page."), "404": html("The not found page.")}})
+	doc.Add("GET /users/{id}", &openapi.Operation{OperationID: "profilePage", Summary: "A user's profile page",
+		Tags: []string{"pages"}, Security: optionalBearer,
+		Parameters: []openapi.Parameter{pathParam("id", "ID or handle of the user.", "")},
+		Responses:  map[string]*openapi.Response{"200": html("The page."), "404": html("The not found page.")}})
+	feed := func(kind, contentType string) map[string]*openapi.Response {
+		responses := spec.responses(http.StatusOK, &openapi.Response{Description: "The " + kind + " feed of the " +
+			"newest chirps.", Headers: map[string]openapi.Header{"ETag": {Schema: &openapi.Schema{Type: "string"}}},
+			Content: content(contentType, &openapi.Schema{Type: "string"})}, 404, 500)
+		responses["304"] = empty("The feed has not changed.")
+		return responses
+	}
+	ifNoneMatch := openapi.Parameter{Name: "If-None-Match", In: "header", Schema: &openapi.Schema{Type: "string"}}
+	for _, format := range []struct{ ext, kind, contentType string }{
+		{"atom", "Atom", "application/atom+xml"},
+		{"rss", "RSS", "application/rss+xml"},
+	} {
+		doc.Add("GET /users/{id}/feed."+format.ext, &openapi.Operation{OperationID: "userFeed" + format.kind,
+			Summary: "A user's chirps as " + format.kind, Tags: []string{"pages"},
+			Parameters: []openapi.Parameter{idParam("user"), ifNoneMatch},
+			Responses:  feed(format.kind, format.contentType)})
+		doc.Add("GET /hashtags/{tag}/feed."+format.ext, &openapi.Operation{OperationID: "hashtagFeed" + format.kind,
+			Summary: "Chirps with a hashtag as " + format.kind, Tags: []string{"pages"},
+			Parameters: []openapi.Parameter{pathParam("tag", "The hashtag, without its #.", ""), ifNoneMatch},
+			Responses:  feed(format.kind, format.contentType)})
+	}
+	theme := queryParam("theme", "Defaults to "+themeLight+".", enum(themeLight, themeDark))
+	doc.Add("GET /oembed", &openapi.Operation{OperationID: "oembed", Summary: "Describe how to embed a chirp",
+		Tags: []string{"pages"}, Parameters: []openapi.Parameter{
+			{Name: "url", In: "query", Required: true, Description: "The chirp's page or embed URL.",
+				Schema: &openapi.Schema{Type: "string", Format: "uri"}},
+			queryParam("format", "Only json is supported.", enum("json")),
+			queryParam("maxwidth", "Widest the embed may be.", &openapi.Schema{Type: "integer", Minimum: ptr(1)}),
+			queryParam("maxheight", "Tallest the embed may be.", &openapi.Schema{Type: "integer", Minimum: ptr(1)}),
+			theme,
+		},
+		Responses: spec.responses(http.StatusOK, &openapi.Response{Description: "The oEmbed response.",
+			Content: content(oembedContent, openapi.Ref("OEmbed"))}, 400, 404, 500, 501)})
+	doc.Add("GET /embed/chirps/{id}", &openapi.Operation{OperationID: "embedPage",
+		Summary: "A chirp for an iframe", Tags: []string{"pages"},
+		Parameters: []openapi.Parameter{idParam("chirp"), theme},
+		Responses: map[string]*openapi.Response{"200": html("The embed."),
+			"404": html("A tombstone saying the chirp is gone.")}})
+}
+
+func (spec apiSpec) graphqlRoutes() {
+	doc := spec.doc
+	result := jsonOK("The result. Errors from resolving fields come with a 200 and are listed in errors.",
+		openapi.Ref("GraphQLResponse"))
+	refused := func(description string) *openapi.Response {
+		return jsonOK(description, openapi.Ref("GraphQLResponse"))
+	}
+	responses := map[string]*openapi.Response{"200": result,
+		"400": refused(fmt.Sprintf("The query does not parse or validate, or is nested more than %d deep or "+
+			"costs more than %d.", graphqlLimits.MaxDepth, graphqlLimits.MaxComplexity)),
+		"401": refused("The access token is invalid.")}
+	get := maps.Clone(responses)
+	get["405"] = refused("A mutation was sent with GET.")
+	doc.Add("GET /graphql", &openapi.Operation{OperationID: "graphqlQuery", Summary: "Run a GraphQL query",
+		Tags: []string{"graphql"}, Security: optionalBearer, Parameters: []openapi.Parameter{
+			{Name: "query", In: "query", Required: true, Schema: &openapi.Schema{Type: "string"}},
+			queryParam("operationName", "Which operation in the query to run.", &openapi.Schema{Type: "string"}),
+			queryParam("variables", "The operation's variables as a JSON object.", &openapi.Schema{Type: "string"}),
+		},
+		Responses: get})
+	doc.Add("POST /graphql", &openapi.Operation{OperationID: "graphqlRequest",
+		Summary: "Run a GraphQL query or mutation", Tags: []string{"graphql"}, Security: optionalBearer,
+		RequestBody: jsonBody(openapi.Ref("GraphQLRequest")), Responses: responses})
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"chirpy/internal/activitypub"
+	"chirpy/internal/openapi"
+	"chirpy/internal/validate"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+func TestOpenAPIDescribesRoutes(t *testing.T) {
+	doc := newAPISpec().doc
+	routed := map[*openapi.Operation]bool{}
+	for _, pattern := range (&apiConfig{}).routes().patterns {
+		op := doc.Operation(pattern)
+		if op == nil {
+			t.Errorf("%s is not in the OpenAPI document", pattern)
+		}
+		routed[op] = true
+	}
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			if !routed[op] {
+				t.Errorf("%s %s is described but not routed", method, path)
+			}
+		}
+	}
+}
+
+func TestOpenAPISamples(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	handleOpenAPI(recorder, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET /api/openapi.json = %d: %s", recorder.Code, recorder.Body)
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(recorder.Body.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.AssertFormat()
+	if err = compiler.AddResource("openapi.json", doc); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2024, time.May, 4, 12, 0, 0, 0, time.UTC)
+	header := createHeader{Id: uuid.New(), CreatedAt: now, UpdatedAt: now}
+	id := uuid.New()
+	chirp := chirpResp{createHeader: header, chirpMsg: chirpMsg{Body: "hi @bob", UserId: id}, AuthorHandle: "alice",
+		Kind: chirpKindChirp, Entities: []chirpEntity{{Type: entityMention, Start: 3, End: 7, UserId: id, Handle: "bob"}}}
+	quote := chirp
+	quote.Kind, quote.RefChirp, quote.Filtered = chirpKindQuote, &chirp, true
+	rechirp := chirp
+	rechirp.Kind, rechirp.RefChirp = chirpKindRechirp, chirpTombstone{Id: id, Kind: chirpKindTombstone}
+	message := messageResp{Id: id, ConversationId: id, SenderId: &id, Body: "hi", CreatedAt: now}
+	payload, _ := json.Marshal(webhookEvent{Id: id, Type: webhookChirpCreated, CreatedAt: now, Data: chirp})
+	status := int32(500)
+	name := "Alice"
+	actor := remoteActorResp{Id: id, Uri: "https://elsewhere.test/users/bob", Account: "bob@elsewhere.test"}
+	polka := polkaEvent{Id: "evt_1", Event: "user.upgraded"}
+	polka.Data.UserId = id
+	activity, _ := json.Marshal(activitypub.Note{ID: "https://elsewhere.test/notes/1", Type: "Note", Published: now})
+	samples := map[string][]any{
+		"Error": {chirpErr{Error: "Chirp not found"}},
+		"Problem": {problem{Type: problemTypePrefix + "not_found", Title: "Not Found", Status: 404,
+			Detail: "Chirp not found", Instance: id.URN(), Code: "not_found"}, problem{
+			Type: problemTypePrefix + codeValidationFailed, Title: "Bad Request", Status: 400,
+			Detail: "email is required", Code: codeValidationFailed,
+			Errors: []validate.FieldError{{Field: "email", Rule: "required", Message: "email is required"}}}},
+		"ChirpEntity":    {chirp.Entities[0]},
+		"ChirpTombstone": {chirpTombstone{Id: id, Kind: chirpKindTombstone}},
+		"Chirp":          {chirp, quote, rechirp},
+		"ChirpBody":      {chirpBody{Body: "hi"}},
+		"ChirpPage":      {chirpPage{Chirps: []chirpResp{chirp, quote}, NextCursor: "abc"}, chirpPage{Chirps: []chirpResp{}}},
+		"TrendingTag":    {trendingTag{Tag: "go", Score: 1.5, Uses: 3}},
+		"NewUser":        {addUser{Email: "a@example.com", Password: "pw", Handle: "alice"}},
+		"User":           {addedUser{createHeader: header, Email: "a@example.com", Handle: "alice"}},
+		"Login":          {loginUser{Email: "a@example.com", Password: "pw"}},
+		"LoggedInUser": {loggedinUser{createHeader: header, Email: "a@example.com", Handle: "alice", Token: "jwt",
+			RefreshToken: "refresh"}},
+		"UserUpdate":     {updateUser{Email: "a@example.com", Password: "pw"}},
+		"Token":          {refreshedToken{Token: "jwt"}},
+		"Profile":        {userProfile{Id: id, Handle: "alice", JoinedAt: now, ChirpCount: 2}},
+		"ProfilePatch":   {profilePatch{DisplayName: &name}, profilePatch{}},
+		"FollowUser":     {followUser{Id: id, Handle: "bob", FollowedAt: now}},
+		"FollowPage":     {followPage{Users: []followUser{{Id: id, Handle: "bob", FollowedAt: now}}}},
+		"ListedUser":     {listedUser{Id: id, Handle: "bob", Since: now}},
+		"ListedUserPage": {listedUserPage{Users: []listedUser{}, NextCursor: "abc"}},
+		"MutedWordBody":  {mutedWordMsg{Phrase: "spoilers", Action: muteActionHide, ExpiresAt: &now}},
+		"MutedWord": {mutedWordResp{Id: id, CreatedAt: now, mutedWordMsg: mutedWordMsg{Phrase: "spoilers",
+			Action: muteActionCollapse}}},
+		"StartConversation": {startConversation{ParticipantIds: []uuid.UUID{id}, Body: "hi"}},
+		"MessageBody":       {messageMsg{Body: "hi"}},
+		"Message":           {message, messageResp{Id: id, ConversationId: id, Body: "welcome", CreatedAt: now}},
+		"Participant":       {participantResp{Id: id, Handle: "bob"}, participantResp{Id: id, Handle: "bob", LastReadAt: &now}},
+		"Conversation": {conversationResp{Id: id, CreatedAt: now, UpdatedAt: now, Participants: []participantResp{},
+			LatestMessage: &message, UnreadCount: 1}},
+		"ConversationPage":  {conversationPage{Conversations: []conversationResp{}}},
+		"MessagePage":       {messagePage{Messages: []messageResp{message}}},
+		"NotificationActor": {notificationActor{Id: id, Handle: "bob"}},
+		"Notification": {notificationResp{Id: id, Type: "like", ChirpId: &id, ActorCount: 1,
+			Actors: []notificationActor{{Id: id, Handle: "bob"}}, Summary: "bob liked your chirp", LatestAt: now}},
+		"NotificationPage": {notificationPage{Notifications: []notificationResp{}, UnreadCount: 3}},
+		"WebhookBody":      {webhookMsg{Url: "https://example.com/hook", EventTypes: []string{webhookChirpCreated}}},
+		"Webhook": {webhookResp{Id: id, CreatedAt: now, webhookMsg: webhookMsg{Url: "https://example.com/hook",
+			EventTypes: []string{webhookUserCreated}, Secret: "0123456789abcdef"}}},
+		"WebhookDelivery": {deliveryResp{Id: id, EventType: webhookChirpCreated, Payload: payload, Status: "failed",
+			Attempts: 2, LastAttemptAt: &now, ResponseStatus: &status, LastError: "boom", CreatedAt: now}},
+		"DeliveryPage": {deliveryPage{Deliveries: []deliveryResp{}}},
+		"WebhookUser":  {webhookUser{Id: id, Handle: "alice", CreatedAt: now}},
+		"WebhookEvent": {webhookEvent{Id: id, Type: webhookUserCreated, CreatedAt: now,
+			Data: webhookUser{Id: id, Handle: "alice", CreatedAt: now}}},
+		"PolkaEvent":       {polka},
+		"RemoteFollowBody": {remoteFollowMsg{Account: "bob@elsewhere.test"}},
+		"RemoteActor":      {actor},
+		"RemoteFollow":     {remoteFollowResp{remoteActorResp: actor, CreatedAt: now}},
+		"RemotePost":       {remotePostResp{Id: id, Uri: actor.Uri + "/1", Body: "hello", PublishedAt: now, Author: actor}},
+		"RemotePostPage":   {remotePostPage{Posts: []remotePostResp{}}},
+		"Actor": {activitypub.Actor{Context: activitypub.Context, ID: actor.Uri, Type: "Person",
+			PreferredUsername: "bob", Inbox: actor.Uri + "/inbox", Endpoints: &activitypub.Endpoints{},
+			PublicKey: activitypub.PublicKey{ID: actor.Uri + "#main-key", Owner: actor.Uri}}},
+		"Note": {activitypub.Note{ID: actor.Uri + "/1", Type: "Note", Published: now, Updated: &now,
+			To: []string{activitypub.Public}}},
+		"Activity": {activitypub.Activity{ID: actor.Uri + "/1/activity", Type: "Create", Actor: actor.Uri,
+			Object: activity}},
+		"OrderedCollection": {activitypub.OrderedCollection{ID: actor.Uri + "/outbox", Type: "OrderedCollection"}},
+		"OrderedCollectionPage": {activitypub.OrderedCollectionPage{ID: actor.Uri + "/outbox?page=true",
+			Type: "OrderedCollectionPage", OrderedItems: []any{}}},
+		"WebFinger": {activitypub.WebFinger{Subject: "acct:bob@elsewhere.test",
+			Links: []activitypub.WebFingerLink{{Rel: "self", Href: actor.Uri}}}},
+		"OEmbed": {oembedResp{Version: "1.0", Type: "rich", Width: embedWidth, Height: embedHeight}},
+		"GraphQLRequest": {graphqlBody{Query: "query($id: ID!) { chirp(id: $id) { body } }",
+			Variables: map[string]any{"id": id.String()}}},
+		"GraphQLLocation": {graphqlLocation{Line: 1, Column: 3}},
+		"GraphQLError":    {graphqlError{Message: "boom", Path: []any{"chirp", 0}}},
+		"GraphQLResponse": {graphqlResponse{Data: map[string]any{"viewer": nil}},
+			graphqlResponse{Errors: []graphqlError{{Message: "boom", Locations: []graphqlLocation{{Line: 1, Column: 3}}}}}},
+	}
+	components := newAPISpec().doc.Components.Schemas
+	for name := range components {
+		if len(samples[name]) == 0 {
+			t.Errorf("no sample for schema %s", name)
+		}
+	}
+	for name, values := range samples {
+		if components[name] == nil {
+			t.Errorf("sample for unknown schema %s", name)
+			continue
+		}
+		schema, err := compiler.Compile("openapi.json#/components/schemas/" + name)
+		if err != nil {
+			t.Fatalf("compile %s: %v", name, err)
+		}
+		for _, value := range values {
+			body, err := json.Marshal(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = schema.Validate(instance); err != nil {
+				t.Errorf("%s does not validate %s: %v", name, body, err)
+			}
+		}
+	}
+	// A chirp missing what every chirp has must not validate.
+	schema := compiler.MustCompile("openapi.json#/components/schemas/Chirp")
+	if err = schema.Validate(map[string]any{"id": id.String(), "body": "hi"}); err == nil {
+		t.Error("Chirp schema accepts a chirp without its required fields")
+	}
+}
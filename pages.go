@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"chirpy/internal/activitypub"
+	"chirpy/internal/feeds"
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// pageMeta is what a page tells link previews and feed readers about itself.
+type pageMeta struct {
+	Title       string
+	Description string
+	URL         string
+	Type        string
+	Image       string
+	Alternates  []pageAlternate
+}
+
+type pageAlternate struct {
+	Type  string
+	Href  string
+	Title string
+}
+
+// pageSegment is a run of chirp text, linked if Href is set.
+type pageSegment struct {
+	Text string
+	Href string
+}
+
+// pageChirp is a chirp laid out for a page. Ref is the chirp it rechirps, quotes
+// or replies to; Deleted says that chirp is gone.
+type pageChirp struct {
+	chirpResp
+	Link       string
+	AuthorLink string
+	Segments   []pageSegment
+	Ref        *pageChirp
+	Deleted    bool
+}
+
+type chirpView struct {
+	Meta  pageMeta
+	Chirp pageChirp
+}
+
+type profileView struct {
+	Meta    pageMeta
+	Profile userProfile
+	Chirps  []pageChirp
+}
+
+type timelineView struct {
+	Meta   pageMeta
+	Chirps []pageChirp
+}
+
+type notFoundView struct {
+	Meta pageMeta
+}
+
+const htmlContent = "text/html; charset=utf-8"
+const pageChirps = 50
+const descriptionLength = 200
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// pageTemplates holds each page with the templates it uses. Every page is
+// rendered from its "layout" template.
+var pageTemplates = map[string]*template.Template{
+	"chirp":    parseTemplates("layout.html", "partials.html", "chirp.html"),
+	"profile":  parseTemplates("layout.html", "partials.html", "profile.html"),
+	"timeline": parseTemplates("layout.html", "partials.html", "timeline.html"),
+	"notfound": parseTemplates("layout.html", "notfound.html"),
+	"embed":    parseTemplates("embed.html", "partials.html"),
+}
+
+func parseTemplates(names ...string) *template.Template {
+	for i := range names {
+		names[i] = "templates/" + names[i]
+	}
+	return template.Must(template.ParseFS(templateFS, names...))
+}
+
+func chirpPath(id uuid.UUID) string {
+	return "/chirps/" + id.String()
+}
+
+func profilePath(id uuid.UUID) string {
+	return "/users/" + id.String()
+}
+
+// segments splits a chirp body around its mentions so they can link to profiles.
+// Entity offsets count code points, not bytes.
+func segments(body string, chirpEntities []chirpEntity) []pageSegment {
+	runes := []rune(body)
+	var out []pageSegment
+	pos := 0
+	for _, entity := range chirpEntities {
+		if entity.Start < pos || entity.End > len(runes) || entity.Start >= entity.End {
+			continue
+		}
+		if entity.Start > pos {
+			out = append(out, pageSegment{Text: string(runes[pos:entity.Start])})
+		}
+		out = append(out, pageSegment{Text: string(runes[entity.Start:entity.End]), Href: profilePath(entity.UserId)})
+		pos = entity.End
+	}
+	if pos < len(runes) {
+		out = append(out, pageSegment{Text: string(runes[pos:])})
+	}
+	return out
+}
+
+func pageChirpConv(chirp chirpResp) pageChirp {
+	page := pageChirp{chirpResp: chirp, Link: chirpPath(chirp.Id), AuthorLink: profilePath(chirp.UserId),
+		Segments: segments(chirp.Body, chirp.Entities)}
+	switch ref := chirp.RefChirp.(type) {
+	case *chirpResp:
+		refPage := pageChirpConv(*ref)
+		page.Ref = &refPage
+	case chirpTombstone:
+		page.Deleted = true
+	}
+	return page
+}
+
+// newestPageChirps lays out the first pageChirps of chirps, which are newest first.
+func newestPageChirps(chirps []chirpResp) []pageChirp {
+	pages := make([]pageChirp, 0, min(len(chirps), pageChirps))
+	for _, chirp := range chirps {
+		if len(pages) == pageChirps {
+			break
+		}
+		if !chirp.Filtered {
+			pages = append(pages, pageChirpConv(chirp))
+		}
+	}
+	return pages
+}
+
+// describe shortens text for a description meta tag.
+func describe(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) > descriptionLength {
+		text = string([]rune(text)[:descriptionLength-1]) + "…"
+	}
+	return text
+}
+
+func (cfg *apiConfig) defaultImage() string {
+	return cfg.publicURL + "/app/assets/logo.png"
+}
+
+// renderPage executes a page template into a buffer first, so a template error
+// becomes a 500 instead of half a page.
+func renderPage(writer http.ResponseWriter, code int, name string, view any) {
+	var buf bytes.Buffer
+	if err := pageTemplates[name].ExecuteTemplate(&buf, "layout", view); err != nil {
+		writer.Header()["Content-Type"] = []string{textContent}
+		writer.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(writer, "Error rendering page: %v\n", err)
+		return
+	}
+	writer.Header()["Content-Type"] = []string{htmlContent}
+	writer.WriteHeader(code)
+	writer.Write(buf.Bytes())
+}
+
+// pageError answers a page request that failed: missing things get the not
+// found page, anything else a plain 500.
+func (cfg *apiConfig) pageError(writer http.ResponseWriter, req *http.Request, err error, what string) {
+	if errors.Is(err, sql.ErrNoRows) {
+		renderPage(writer, http.StatusNotFound, "notfound", notFoundView{Meta: pageMeta{Title: "Not found · Chirpy",
+			Description: "That " + what + " does not exist, or is not visible to you.", URL: cfg.publicURL + req.URL.Path,
+			Type: "website", Image: cfg.defaultImage()}})
+		return
+	}
+	writer.Header()["Content-Type"] = []string{textContent}
+	writer.WriteHeader(http.StatusInternalServerError)
+	fmt.Fprintf(writer, "Error loading %s: %v\n", what, err)
+}
+
+func (cfg *apiConfig) handleChirpPage(writer http.ResponseWriter, req *http.Request) {
+	id, err := parseID(req)
+	if err != nil {
+		cfg.pageError(writer, req, sql.ErrNoRows, "chirp")
+		return
+	}
+	chirp, err := cfg.loadChirp(req.Context(), cfg.optionalUser(req.Header), id)
+	if err != nil {
+		cfg.pageError(writer, req, err, "chirp")
+		return
+	}
+	view := chirpView{Chirp: pageChirpConv(chirp), Meta: pageMeta{Title: "@" + chirp.AuthorHandle + " on Chirpy",
+		Description: describe(chirp.Body), URL: cfg.publicURL + chirpPath(chirp.Id), Type: "article",
+		Image: cfg.defaultImage()}}
+	view.Meta.Alternates = []pageAlternate{{Type: oembedContent, Href: cfg.oembedURL(view.Meta.URL), Title: view.Meta.Title}}
+	if chirp.Kind != chirpKindRechirp {
+		view.Meta.Alternates = append(view.Meta.Alternates, pageAlternate{Type: activitypub.ContentType,
+			Href: cfg.noteURL(chirp.Id)})
+	}
+	if chirp.Kind == chirpKindRechirp && view.Chirp.Ref != nil {
+		view.Meta.Description = describe(view.Chirp.Ref.Body)
+	}
+	renderPage(writer, http.StatusOK, "chirp", view)
+}
+
+// profileUser finds the user a profile page names, by id or by handle.
+func (cfg *apiConfig) profileUser(ctx context.Context, name string) (uuid.UUID, error) {
+	if id, err := uuid.Parse(name); err == nil {
+		return id, nil
+	}
+	user, err := cfg.dbQueries.GetUserByHandle(ctx, strings.TrimPrefix(name, "@"))
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	return user.ID, nil
+}
+
+func (cfg *apiConfig) handleProfilePage(writer http.ResponseWriter, req *http.Request) {
+	id, err := cfg.profileUser(req.Context(), req.PathValue("id"))
+	if err != nil {
+		cfg.pageError(writer, req, err, "user")
+		return
+	}
+	profile, err := cfg.loadProfile(req.Context(), id)
+	if err != nil {
+		cfg.pageError(writer, req, err, "user")
+		return
+	}
+	viewer := cfg.optionalUser(req.Header)
+	if viewer.Valid {
+		if blocked, err := cfg.blockedAmong(req.Context(), viewer, []uuid.UUID{id}); err != nil {
+			cfg.pageError(writer, req, err, "user")
+			return
+		} else if blocked[id] {
+			cfg.pageError(writer, req, sql.ErrNoRows, "user")
+			return
+		}
+	}
+	chirps, err := cfg.loadChirps(req.Context(), viewer, uuid.NullUUID{UUID: id, Valid: true}, pageChirps, false)
+	if err != nil {
+		cfg.pageError(writer, req, err, "user")
+		return
+	}
+	name := "@" + profile.Handle
+	if profile.DisplayName != "" {
+		name = profile.DisplayName + " (@" + profile.Handle + ")"
+	}
+	description := profile.Bio
+	if description == "" {
+		description = fmt.Sprintf("%d chirps by @%s", profile.ChirpCount, profile.Handle)
+	}
+	view := profileView{Profile: profile, Chirps: newestPageChirps(chirps), Meta: pageMeta{Title: name + " on Chirpy",
+		Description: describe(description), URL: cfg.publicURL + profilePath(id), Type: "profile",
+		Image: cfg.defaultImage(), Alternates: []pageAlternate{
+			{Type: feeds.AtomContentType, Href: profilePath(id) + "/feed.atom", Title: "Chirps by " + name + " (Atom)"},
+			{Type: feeds.RSSContentType, Href: profilePath(id) + "/feed.rss", Title: "Chirps by " + name + " (RSS)"},
+			{Type: activitypub.ContentType, Href: cfg.actorURL(id)},
+		}}}
+	if profile.AvatarUrl != "" {
+		view.Meta.Image = profile.AvatarUrl
+	}
+	renderPage(writer, http.StatusOK, "profile", view)
+}
+
+func (cfg *apiConfig) handleTimelinePage(writer http.ResponseWriter, req *http.Request) {
+	viewer := cfg.optionalUser(req.Header)
+	chirps, err := cfg.loadChirps(req.Context(), viewer, uuid.NullUUID{}, pageChirps, false)
+	if err != nil {
+		cfg.pageError(writer, req, err, "timeline")
+		return
+	}
+	renderPage(writer, http.StatusOK, "timeline", timelineView{Chirps: newestPageChirps(chirps), Meta: pageMeta{
+		Title: "Chirpy", Description: "The latest chirps on Chirpy.", URL: cfg.publicURL + "/", Type: "website",
+		Image: cfg.defaultImage()}})
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRenderChirpPage(t *testing.T) {
+	cfg := &apiConfig{publicURL: "https://chirpy.test"}
+	bob := uuid.New()
+	body := `hi @bob <script>alert("x")</script> & "quotes"`
+	chirp := chirpResp{createHeader: createHeader{Id: uuid.New(), CreatedAt: time.Date(2024, time.May, 4, 12, 0, 0, 0, time.UTC)},
+		chirpMsg: chirpMsg{Body: body, UserId: uuid.New()}, AuthorHandle: "alice", Kind: chirpKindQuote,
+		Entities: []chirpEntity{{Type: entityMention, Start: 3, End: 7, UserId: bob, Handle: "bob"}},
+		RefChirp: chirpTombstone{Id: uuid.New(), Kind: chirpKindTombstone}}
+	view := chirpView{Chirp: pageChirpConv(chirp), Meta: pageMeta{Title: "@alice on Chirpy", Description: describe(body),
+		URL: cfg.publicURL + chirpPath(chirp.Id), Type: "article", Image: cfg.defaultImage()}}
+	recorder := httptest.NewRecorder()
+	renderPage(recorder, http.StatusOK, "chirp", view)
+	page := recorder.Body.String()
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != htmlContent {
+		t.Fatalf("renderPage() = %d, %q:\n%s", recorder.Code, recorder.Header().Get("Content-Type"), page)
+	}
+	if strings.Contains(page, "<script>") {
+		t.Errorf("chirp body was not escaped:\n%s", page)
+	}
+	for _, want := range []string{
+		`<meta property="og:title" content="@alice on Chirpy">`,
+		`<meta property="og:url" content="https://chirpy.test/chirps/` + chirp.Id.String() + `">`,
+		`<meta name="twitter:card" content="summary">`,
+		`<meta property="og:description" content="hi @bob &lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; &amp; &#34;quotes&#34;">`,
+		`<a href="/users/` + bob.String() + `">@bob</a>`,
+		"This chirp has been deleted.",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page does not contain %s:\n%s", want, page)
+		}
+	}
+}
+
+func TestTimelinePageLoadsOnePage(t *testing.T) {
+	cfg, mock := mockConfig(t)
+	mock.ExpectQuery("name: GetNewestChirps ").
+		WithArgs(uuid.NullUUID{}, uuid.NullUUID{}, false, sql.NullInt32{Int32: pageChirps, Valid: true}).
+		WillReturnRows(chirpRows())
+	expectChirpDetails(mock)
+	recorder := httptest.NewRecorder()
+	cfg.handleTimelinePage(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("GET / = %d, want 200: %s", recorder.Code, recorder.Body)
+	}
+}
+
+func TestSegments(t *testing.T) {
+	bob := uuid.New()
+	got := segments("é @bob!", []chirpEntity{{Start: 2, End: 6, UserId: bob}})
+	want := []pageSegment{{Text: "é "}, {Text: "@bob", Href: profilePath(bob)}, {Text: "!"}}
+	if len(got) != len(want) {
+		t.Fatalf("segments() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("segment %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
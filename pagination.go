@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// pageCursor marks where the previous page of a newest-first keyset listing
+// stopped: the next page holds only rows strictly older than (time, id).
+type pageCursor struct {
+	time time.Time
+	id   uuid.UUID
+}
+
+const defaultPageSize = 20
+const maxPageSize = 100
+
+// firstPage sorts after every real row, so the first page needs no special query.
+var firstPage = pageCursor{time: time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC), id: uuid.Max}
+
+func (cur pageCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cur.time.Format(time.RFC3339Nano) + "|" + cur.id.String()))
+}
+
+func parseCursor(encoded string) (pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return pageCursor{}, fmt.Errorf("invalid cursor")
+	}
+	timeStr, idStr, found := strings.Cut(string(raw), "|")
+	if !found {
+		return pageCursor{}, fmt.Errorf("invalid cursor")
+	}
+	cur := pageCursor{}
+	if cur.time, err = time.Parse(time.RFC3339Nano, timeStr); err != nil {
+		return pageCursor{}, fmt.Errorf("invalid cursor")
+	}
+	if cur.id, err = uuid.Parse(idStr); err != nil {
+		return pageCursor{}, fmt.Errorf("invalid cursor")
+	}
+	return cur, nil
+}
+
+// parsePage reads the cursor and limit query parameters of a paginated listing.
+func parsePage(req *http.Request) (pageCursor, int32, error) {
+	limit := defaultPageSize
+	if limitStr := req.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitStr); err != nil {
+			limit = 0
+		}
+	}
+	return pageOf(req.URL.Query().Get("cursor"), limit)
+}
+
+// pageOf checks the cursor and page size of a listing. An empty cursor starts
+// at the first page.
+func pageOf(encoded string, limit int) (pageCursor, int32, error) {
+	cur := firstPage
+	if encoded != "" {
+		var err error
+		if cur, err = parseCursor(encoded); err != nil {
+			return pageCursor{}, 0, err
+		}
+	}
+	if limit < 1 || limit > maxPageSize {
+		return pageCursor{}, 0, fmt.Errorf("limit must be between 1 and %d", maxPageSize)
+	}
+	return cur, int32(limit), nil
+}
+
+// nextCursor returns the cursor for the page after one that ended at (last, id),
+// or an empty string if a short page shows there is nothing more.
+func nextCursor(count int, limit int32, last time.Time, id uuid.UUID) string {
+	if count < int(limit) {
+		return ""
+	}
+	return pageCursor{time: last, id: id}.String()
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"chirpy/internal/validate"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// problem is an error response from v2 on, as RFC 9457 (which replaced RFC
+// 7807) describes it. Code is the machine-readable reason; Type is the same
+// reason as a URI, and Instance names the request so it can be found in the
+// logs.
+type problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+	// Errors lists every rule a request body broke.
+	Errors []validate.FieldError `json:"errors,omitempty"`
+}
+
+const problemContent = "application/problem+json"
+const problemTypePrefix = "urn:chirpy:problem:"
+const requestIDHeader = "X-Request-Id"
+
+// faultDetail stands in for the details of a fault on our side, which are logged instead.
+const faultDetail = "Something went wrong on our side. Quote the request ID if you report it."
+
+// Codes for errors that clients need to tell apart from others with the same
+// status. Other errors have a code named after their status, like not_found.
+const (
+	codeChirpTooLong         = "chirp_too_long"
+	codeAlreadyRechirped     = "already_rechirped"
+	codeHandleTaken          = "handle_taken"
+	codeHandleCooldown       = "handle_cooldown"
+	codeBlocked              = "blocked"
+	codeNotAuthor            = "not_author"
+	codeChirpyRedRequired    = "chirpy_red_required"
+	codeValidationFailed     = "validation_failed"
+	codeIdempotencyKeyReused = "idempotency_key_reused"
+	codeRequestInProgress    = "request_in_progress"
+)
+
+// statusProblemCode is the code for an error without a more specific one.
+func statusProblemCode(status int) string {
+	return strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+}
+
+type requestIDKey struct{}
+
+// withRequestID gives each request an ID, sent back in X-Request-Id. A proxy
+// in front of us can pass its own as long as it is a UUID.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		id, err := uuid.Parse(req.Header.Get(requestIDHeader))
+		if err != nil {
+			id = uuid.New()
+		}
+		writer.Header().Set(requestIDHeader, id.String())
+		next.ServeHTTP(writer, req.WithContext(context.WithValue(req.Context(), requestIDKey{}, id)))
+	})
+}
+
+// requestID is the ID withRequestID gave req, if it came through it.
+func requestID(req *http.Request) (uuid.UUID, bool) {
+	id, ok := req.Context().Value(requestIDKey{}).(uuid.UUID)
+	return id, ok
+}
+
+// handleError answers with an error whose detail is meant for the client.
+func handleError(writer http.ResponseWriter, req *http.Request, status int, detail string) {
+	handleProblem(writer, req, status, statusProblemCode(status), detail)
+}
+
+// handleProblem answers with an error that has its own code.
+func handleProblem(writer http.ResponseWriter, req *http.Request, status int, code, detail string) {
+	writeProblem(writer, req, problem{Status: status, Detail: detail, Code: code})
+}
+
+// handleInvalid answers a request whose body broke validation rules, listing every one.
+func handleInvalid(writer http.ResponseWriter, req *http.Request, errs []validate.FieldError) {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Message
+	}
+	writeProblem(writer, req, problem{Status: http.StatusBadRequest, Detail: strings.Join(msgs, "; "),
+		Code: codeValidationFailed, Errors: errs})
+}
+
+// writeProblem fills in the rest of resp from its status and code and sends
+// it. v1 keeps the {"error": ...} body its clients parse, with only the detail.
+func writeProblem(writer http.ResponseWriter, req *http.Request, resp problem) {
+	if requestVersion(req) == apiV1 {
+		handleJsonWrite(writer, resp.Status, resp.Code, chirpErr{Error: resp.Detail})
+		return
+	}
+	resp.Type, resp.Title = problemTypePrefix+resp.Code, http.StatusText(resp.Status)
+	if id, ok := requestID(req); ok {
+		resp.Instance = id.URN()
+	}
+	writer.Header()["Content-Type"] = []string{problemContent}
+	handleJsonWrite(writer, resp.Status, resp.Code, resp)
+}
+
+// handleFault answers with an error on our side. err is logged under the
+// request ID and the client only learns that something went wrong.
+func handleFault(writer http.ResponseWriter, req *http.Request, status int, err error) {
+	logFault(req, err)
+	handleError(writer, req, status, faultDetail)
+}
+
+func logFault(req *http.Request, err error) {
+	id, _ := requestID(req)
+	fmt.Printf("request %s: %s %s: %v\n", id, req.Method, req.URL.Path, err)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestProblemResponses(t *testing.T) {
+	routes := (&apiConfig{}).routes()
+	proxyID := uuid.New()
+	tests := []struct {
+		path     string
+		id       string
+		problem  bool
+		wantID   string
+		wantCode string
+	}{
+		{"/api/chirps/nope", "", false, "", ""},
+		{"/api/v2/chirps/nope", "", true, "", "not_found"},
+		{"/api/v2/chirps/nope", proxyID.String(), true, proxyID.String(), "not_found"},
+		{"/api/v2/chirps/nope", "not-a-uuid", true, "", "not_found"},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.id != "" {
+			req.Header.Set(requestIDHeader, tc.id)
+		}
+		recorder := httptest.NewRecorder()
+		routes.ServeHTTP(recorder, req)
+		if recorder.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", tc.path, recorder.Code)
+		}
+		id, err := uuid.Parse(recorder.Header().Get(requestIDHeader))
+		if err != nil {
+			t.Errorf("GET %s: X-Request-Id = %q", tc.path, recorder.Header().Get(requestIDHeader))
+		} else if tc.wantID != "" && id.String() != tc.wantID {
+			t.Errorf("GET %s: X-Request-Id = %s, want the proxy's %s", tc.path, id, tc.wantID)
+		}
+		if !tc.problem {
+			var msg chirpErr
+			if err = json.Unmarshal(recorder.Body.Bytes(), &msg); err != nil || msg.Error == "" {
+				t.Errorf("GET %s body = %q, want a v1 error", tc.path, recorder.Body)
+			}
+			continue
+		}
+		if got := recorder.Header().Get("Content-Type"); got != problemContent {
+			t.Errorf("GET %s Content-Type = %q", tc.path, got)
+		}
+		var msg problem
+		if err = json.Unmarshal(recorder.Body.Bytes(), &msg); err != nil {
+			t.Errorf("GET %s body = %q: %v", tc.path, recorder.Body, err)
+			continue
+		}
+		want := problem{Type: problemTypePrefix + tc.wantCode, Title: "Not Found", Status: http.StatusNotFound,
+			Detail: msg.Detail, Instance: id.URN(), Code: tc.wantCode}
+		if !reflect.DeepEqual(msg, want) {
+			t.Errorf("GET %s body = %+v, want %+v", tc.path, msg, want)
+		}
+	}
+}
+
+func TestFaultsHideDetails(t *testing.T) {
+	for _, surface := range []apiSurface{{"/api", apiV1}, {"/api/v2", apiV2}} {
+		handler := withRequestID(http.HandlerFunc((&apiConfig{}).versioned(surface,
+			func(writer http.ResponseWriter, req *http.Request) {
+				handleFault(writer, req, http.StatusInternalServerError, errors.New(`pq: relation "chirps" does not exist`))
+			})))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, surface.prefix+"/chirps", nil))
+		if recorder.Code != http.StatusInternalServerError {
+			t.Errorf("%s: status %d, want 500", surface.prefix, recorder.Code)
+		}
+		if strings.Contains(recorder.Body.String(), "pq:") || !strings.Contains(recorder.Body.String(), faultDetail) {
+			t.Errorf("%s: body = %q, want only %q", surface.prefix, recorder.Body, faultDetail)
+		}
+	}
+}
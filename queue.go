@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// deliveryQueue is an outgoing queue kept in the database, such as webhook or
+// federation deliveries. Claims skip rows other workers hold, so several
+// instances can share a queue.
+type deliveryQueue[T any] struct {
+	name    string
+	batch   int
+	claim   func(ctx context.Context) ([]T, error)
+	attempt func(ctx context.Context, item T) error
+	id      func(item T) uuid.UUID
+}
+
+// work claims a batch of due items and attempts them all at once. It returns how
+// many it claimed.
+func (queue deliveryQueue[T]) work(ctx context.Context) (int, error) {
+	claimed, err := queue.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var wg sync.WaitGroup
+	for _, item := range claimed {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := queue.attempt(ctx, item); err != nil {
+				fmt.Printf("%s delivery %s: %v\n", queue.name, queue.id(item), err)
+			}
+		}()
+	}
+	wg.Wait()
+	return len(claimed), nil
+}
+
+// run works through the queue each interval until ctx is done, claiming batch
+// after batch for as long as they come back full.
+func (queue deliveryQueue[T]) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for {
+			claimed, err := queue.work(ctx)
+			if err != nil {
+				fmt.Printf("%s: %v\n", queue.name, err)
+			}
+			if claimed < queue.batch {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
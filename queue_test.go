@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestDeliveryQueueDrainsFullBatches(t *testing.T) {
+	batches := [][]uuid.UUID{{uuid.New(), uuid.New()}, {uuid.New(), uuid.New()}, {uuid.New()}}
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	attempted := map[uuid.UUID]bool{}
+	claims := 0
+	queue := deliveryQueue[uuid.UUID]{
+		name:  "test",
+		batch: 2,
+		claim: func(context.Context) ([]uuid.UUID, error) {
+			claims++
+			if claims > len(batches) {
+				cancel()
+				return nil, nil
+			}
+			return batches[claims-1], nil
+		},
+		attempt: func(_ context.Context, id uuid.UUID) error {
+			mu.Lock()
+			defer mu.Unlock()
+			attempted[id] = true
+			return nil
+		},
+		id: func(id uuid.UUID) uuid.UUID { return id },
+	}
+	done := make(chan struct{})
+	go func() {
+		queue.run(ctx, time.Millisecond)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("run() did not return once ctx was done")
+	}
+	// The short third batch ends the first pass; the next tick finds nothing.
+	if claims != 4 || len(attempted) != 5 {
+		t.Errorf("claims = %d, attempted = %d, want 4 and 5", claims, len(attempted))
+	}
+}
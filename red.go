@@ -0,0 +1,248 @@
+package main
+
+import (
+	"chirpy/internal/auth"
+	"chirpy/internal/database"
+	"chirpy/internal/entities"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// polkaEvent is what Polka, our payment provider, posts when a membership changes.
+type polkaEvent struct {
+	Id    string `json:"id"`
+	Event string `json:"event"`
+	Data  struct {
+		UserId uuid.UUID `json:"user_id"`
+	} `json:"data"`
+}
+
+const polkaSignatureHeader = "X-Polka-Signature"
+const polkaBodyLimit = 1 << 16
+
+// polkaMembership maps the Polka events we act on to the Chirpy Red status they set.
+// Anything else is acknowledged and ignored so Polka stops retrying it.
+var polkaMembership = map[string]bool{
+	"user.upgraded":   true,
+	"user.downgraded": false,
+}
+
+// chirpLimit is how long a chirp by user may be. Chirpy Red members get twice
+// the usual length.
+func (cfg *apiConfig) chirpLimit(ctx context.Context, user uuid.UUID) (int, error) {
+	dbUser, err := cfg.dbQueries.GetUserByID(ctx, user)
+	if err != nil {
+		return 0, err
+	}
+	if dbUser.IsChirpyRed {
+		return redLengthLimit, nil
+	}
+	return lengthLimit, nil
+}
+
+// chirpTooLong reports whether body is longer than user's chirpLimit, counting
+// characters rather than bytes.
+func (cfg *apiConfig) chirpTooLong(ctx context.Context, user uuid.UUID, body string) (bool, error) {
+	limit, err := cfg.chirpLimit(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	return utf8.RuneCountInString(body) > limit, nil
+}
+
+// editChirp replaces a chirp's body and indexes its hashtags and mentions afresh.
+// Only people the new body mentions for the first time are notified.
+func (cfg *apiConfig) editChirp(ctx context.Context, old database.Chirp, body string) (database.Chirp, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	chirp, err := qtx.UpdateChirpBody(ctx, database.UpdateChirpBodyParams{Body: body, ID: old.ID, UserID: old.UserID})
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if err = qtx.DeleteChirpHashtags(ctx, chirp.ID); err != nil {
+		return database.Chirp{}, err
+	}
+	if tags := entities.Hashtags(chirp.Body); len(tags) > 0 {
+		if err = qtx.AddChirpHashtags(ctx, database.AddChirpHashtagsParams{ChirpID: chirp.ID, Tags: tags}); err != nil {
+			return database.Chirp{}, err
+		}
+	}
+	previous, err := qtx.GetMentionsForChirps(ctx, []uuid.UUID{chirp.ID})
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if err = qtx.DeleteChirpMentions(ctx, chirp.ID); err != nil {
+		return database.Chirp{}, err
+	}
+	mentioned, err := addMentions(ctx, qtx, chirp)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	mentioned = slices.DeleteFunc(mentioned, func(id uuid.UUID) bool {
+		return slices.ContainsFunc(previous, func(m database.GetMentionsForChirpsRow) bool { return m.UserID == id })
+	})
+	// The chirp a reply or quote points at may have been deleted since, leaving
+	// no author to spare a second notification.
+	var refAuthor uuid.UUID
+	if chirp.RefChirpID.Valid {
+		ref, err := qtx.GetChirp(ctx, chirp.RefChirpID.UUID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return database.Chirp{}, err
+		}
+		refAuthor = ref.UserID
+	}
+	events, err := notifyMentions(ctx, qtx, chirp, mentioned, refAuthor)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if err = enqueueWebhooks(ctx, qtx, webhookChirpUpdated, chirp.UserID, chirpConv(chirp)); err != nil {
+		return database.Chirp{}, err
+	}
+	if err = cfg.enqueueFederation(ctx, qtx, "Update", chirp); err != nil {
+		return database.Chirp{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return database.Chirp{}, err
+	}
+	cfg.publish(ctx, events...)
+	return chirp, nil
+}
+
+// handleEditChirp lets Chirpy Red members rewrite their own chirps.
+func (cfg *apiConfig) handleEditChirp(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	msg := chirpBody{}
+	if !decodeRequest(writer, req, &msg) {
+		return
+	} else if utf8.RuneCountInString(msg.Body) > redLengthLimit {
+		handleProblem(writer, req, http.StatusBadRequest, codeChirpTooLong, "Chirp is too long")
+		return
+	}
+	id, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	user, err := cfg.dbQueries.GetUserByID(req.Context(), id)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	} else if !user.IsChirpyRed {
+		handleProblem(writer, req, http.StatusForbidden, codeChirpyRedRequired, "Editing chirps needs Chirpy Red")
+		return
+	}
+	chirpID, err := parseID(req)
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, err.Error())
+		return
+	}
+	old, err := cfg.dbQueries.GetChirp(req.Context(), chirpID)
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, "Chirp not found")
+		return
+	} else if old.UserID != id {
+		handleProblem(writer, req, http.StatusForbidden, codeNotAuthor, "Chirp belongs to someone else")
+		return
+	} else if old.Kind == chirpKindRechirp {
+		handleError(writer, req, http.StatusBadRequest, "Rechirps have no text to edit")
+		return
+	}
+	chirp, err := cfg.editChirp(req.Context(), old, clean(msg.Body))
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	cfg.chirpRespond(writer, req, http.StatusOK, msg.Body, chirp)
+}
+
+// polkaAuthenticated accepts either Polka's API key, or an HMAC-SHA256 of the body
+// keyed with it and sent hex encoded in X-Polka-Signature. Without a configured
+// key nothing is accepted.
+func (cfg *apiConfig) polkaAuthenticated(head http.Header, body []byte) bool {
+	if cfg.polkaKey == "" {
+		return false
+	}
+	if signature := head.Get(polkaSignatureHeader); signature != "" {
+		mac := hmac.New(sha256.New, []byte(cfg.polkaKey))
+		mac.Write(body)
+		want := hex.EncodeToString(mac.Sum(nil))
+		return hmac.Equal([]byte(strings.TrimPrefix(signature, "sha256=")), []byte(want))
+	}
+	key, err := auth.GetAPIKey(head)
+	return err == nil && subtle.ConstantTimeCompare([]byte(key), []byte(cfg.polkaKey)) == 1
+}
+
+// handlePolkaWebhook grants or takes away Chirpy Red. Polka retries until it gets a
+// 2xx, so each event ID is only acted on once.
+func (cfg *apiConfig) handlePolkaWebhook(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	body, err := io.ReadAll(http.MaxBytesReader(writer, req.Body, polkaBodyLimit))
+	if err != nil {
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !cfg.polkaAuthenticated(req.Header, body) {
+		handleError(writer, req, http.StatusUnauthorized, "Invalid API key or signature")
+		return
+	}
+	event := polkaEvent{}
+	if err = json.Unmarshal(body, &event); err != nil {
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	red, known := polkaMembership[event.Event]
+	if !known {
+		writer.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if event.Id == "" {
+		handleError(writer, req, http.StatusBadRequest, "event id is required")
+		return
+	}
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.dbQueries.WithTx(tx)
+	fresh, err := qtx.RecordPolkaEvent(req.Context(), database.RecordPolkaEventParams{ID: event.Id, Event: event.Event,
+		UserID: event.Data.UserId})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	} else if fresh == 0 {
+		writer.WriteHeader(http.StatusNoContent)
+		return
+	}
+	changed, err := qtx.SetChirpyRed(req.Context(), database.SetChirpyRedParams{IsChirpyRed: red, ID: event.Data.UserId})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	} else if changed == 0 {
+		handleError(writer, req, http.StatusNotFound, "User not found")
+		return
+	}
+	if err = tx.Commit(); err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
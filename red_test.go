@@ -0,0 +1,88 @@
+package main
+
+import (
+	"chirpy/internal/database"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+const testPolkaKey = "f271c81ff7084ee5b99a5091b42d486e"
+
+func polkaSign(key, body string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// TestPolkaWebhookAuth only sends events the handler ignores, so it never reaches
+// the database.
+func TestPolkaWebhookAuth(t *testing.T) {
+	const body = `{"id":"evt_1","event":"user.payment_failed","data":{"user_id":"3311741c-680c-4546-99f3-fc9efac2036c"}}`
+	tests := []struct {
+		name   string
+		key    string
+		header http.Header
+		want   int
+	}{
+		{"api key", testPolkaKey, http.Header{"Authorization": {"ApiKey " + testPolkaKey}}, http.StatusNoContent},
+		{"signature", testPolkaKey, http.Header{polkaSignatureHeader: {polkaSign(testPolkaKey, body)}}, http.StatusNoContent},
+		{"prefixed signature", testPolkaKey, http.Header{polkaSignatureHeader: {"sha256=" + polkaSign(testPolkaKey, body)}},
+			http.StatusNoContent},
+		{"no credentials", testPolkaKey, http.Header{}, http.StatusUnauthorized},
+		{"wrong api key", testPolkaKey, http.Header{"Authorization": {"ApiKey nope"}}, http.StatusUnauthorized},
+		{"bearer token", testPolkaKey, http.Header{"Authorization": {"Bearer " + testPolkaKey}}, http.StatusUnauthorized},
+		{"wrong signature", testPolkaKey, http.Header{polkaSignatureHeader: {polkaSign("other", body)}}, http.StatusUnauthorized},
+		{"no key configured", "", http.Header{"Authorization": {"ApiKey "}}, http.StatusUnauthorized},
+	}
+	for _, tc := range tests {
+		cfg := &apiConfig{polkaKey: tc.key}
+		req := httptest.NewRequest(http.MethodPost, "/api/polka/webhooks", strings.NewReader(body))
+		req.Header = tc.header
+		recorder := httptest.NewRecorder()
+		cfg.handlePolkaWebhook(recorder, req)
+		if recorder.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d", tc.name, recorder.Code, tc.want)
+		}
+	}
+}
+
+// TestEditQuoteOfDeletedChirp checks that a quote can still be edited once the
+// chirp it quoted is gone.
+func TestEditQuoteOfDeletedChirp(t *testing.T) {
+	cfg, mock := mockConfig(t)
+	now := time.Now().UTC()
+	quote := database.Chirp{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Body: "still true", UserID: uuid.New(),
+		Kind: chirpKindQuote, RefChirpID: uuid.NullUUID{UUID: uuid.New(), Valid: true}}
+	edited := quote
+	edited.Body = "still true, sadly"
+	mock.ExpectBegin()
+	mock.ExpectQuery("name: UpdateChirpBody ").WithArgs(edited.Body, quote.ID, quote.UserID).
+		WillReturnRows(chirpRows(edited))
+	mock.ExpectExec("name: DeleteChirpHashtags ").WithArgs(quote.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("name: GetMentionsForChirps ").WillReturnRows(
+		sqlmock.NewRows([]string{"chirp_id", "user_id", "start_offset", "end_offset", "handle"}))
+	mock.ExpectExec("name: DeleteChirpMentions ").WithArgs(quote.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("name: GetChirp ").WithArgs(quote.RefChirpID.UUID).WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec("name: EnqueueWebhookDeliveries ").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("name: EnqueueFollowerDeliveries ").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	got, err := cfg.editChirp(context.Background(), quote, edited.Body)
+	if err != nil {
+		t.Fatalf("editChirp() returned error %v", err)
+	}
+	if got.Body != edited.Body {
+		t.Errorf("editChirp() body = %q, want %q", got.Body, edited.Body)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"chirpy/internal/database"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handleReply(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	msg := chirpBody{}
+	if !decodeRequest(writer, req, &msg) {
+		return
+	} else if utf8.RuneCountInString(msg.Body) > redLengthLimit {
+		handleProblem(writer, req, http.StatusBadRequest, codeChirpTooLong, "Chirp is too long")
+		return
+	}
+	id, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if tooLong, err := cfg.chirpTooLong(req.Context(), id, msg.Body); err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	} else if tooLong {
+		handleProblem(writer, req, http.StatusBadRequest, codeChirpTooLong, "Chirp is too long")
+		return
+	}
+	refID, err := cfg.refTarget(req, id)
+	if errors.Is(err, errBlocked) {
+		handleProblem(writer, req, http.StatusForbidden, codeBlocked, err.Error())
+		return
+	} else if err != nil {
+		handleError(writer, req, http.StatusNotFound, "Chirp not found")
+		return
+	}
+	chirp, err := cfg.storeChirp(req.Context(), database.CreateRefChirpParams{Body: clean(msg.Body), UserID: id,
+		Kind: chirpKindReply, RefChirpID: uuid.NullUUID{UUID: refID, Valid: true}})
+	if err != nil {
+		handleFault(writer, req, versionedStatus(req, http.StatusBadRequest, http.StatusInternalServerError), err)
+		return
+	}
+	cfg.chirpRespond(writer, req, http.StatusCreated, msg.Body, chirp)
+}
+
+// handleGetReplies lists the direct replies to a chirp, oldest first.
+func (cfg *apiConfig) handleGetReplies(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	id, err := parseID(req)
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, err.Error())
+		return
+	}
+	replies, err := cfg.loadReplies(req.Context(), cfg.optionalUser(req.Header), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		handleError(writer, req, http.StatusNotFound, "Chirp not found")
+		return
+	} else if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	handleJsonWrite(writer, http.StatusOK, "replies", replies)
+}
+
+// loadReplies loads the direct replies to chirp id that viewer may see, oldest
+// first. Like loadChirp, it returns sql.ErrNoRows for a chirp by someone with a
+// block between them and viewer.
+func (cfg *apiConfig) loadReplies(ctx context.Context, viewer uuid.NullUUID, id uuid.UUID) ([]chirpResp, error) {
+	chirp, err := cfg.dbQueries.GetChirp(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if viewer.Valid {
+		blocked, err := cfg.dbQueries.IsBlocked(ctx, database.IsBlockedParams{UserA: viewer.UUID, UserB: chirp.UserID})
+		if err != nil {
+			return nil, err
+		} else if blocked {
+			return nil, sql.ErrNoRows
+		}
+	}
+	replies, err := cfg.dbQueries.GetReplies(ctx, database.GetRepliesParams{ChirpID: id, ViewerID: viewer})
+	if err != nil {
+		return nil, err
+	}
+	jsonChirps, err := cfg.chirpsConv(ctx, viewer, replies)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.filterChirps(ctx, viewer, jsonChirps)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"chirpy/internal/broker"
+	"chirpy/internal/database"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+// TestReplyNotifies checks that a reply notifies the author of the chirp it
+// answers and everyone it mentions, and that an author mentioned in a reply to
+// their own chirp hears about it once.
+func TestReplyNotifies(t *testing.T) {
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now().UTC()
+	original := database.Chirp{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Body: "hot take", UserID: alice,
+		Kind: chirpKindChirp}
+	tests := []struct {
+		name      string
+		body      string
+		mentioned database.GetUsersByHandlesRow
+		want      map[uuid.UUID]string
+	}{
+		{"mentioning someone else", "@carol agreed", database.GetUsersByHandlesRow{ID: carol, Handle: "carol"},
+			map[uuid.UUID]string{alice: notifyReply, carol: notifyMention}},
+		{"mentioning the author", "@alice agreed", database.GetUsersByHandlesRow{ID: alice, Handle: "alice"},
+			map[uuid.UUID]string{alice: notifyReply}},
+	}
+	for _, tc := range tests {
+		cfg, mock := mockConfig(t)
+		events, cancel := cfg.broker.Subscribe(4, broker.User(alice), broker.User(carol))
+		reply := database.Chirp{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Body: tc.body, UserID: bob,
+			Kind: chirpKindReply, RefChirpID: uuid.NullUUID{UUID: original.ID, Valid: true}}
+		mock.ExpectQuery("name: GetUserByID ").WithArgs(bob).WillReturnRows(userRows(database.User{ID: bob,
+			CreatedAt: now, UpdatedAt: now, Email: "bob@example.com", Handle: "bob"}))
+		mock.ExpectQuery("name: GetChirp ").WithArgs(original.ID).WillReturnRows(chirpRows(original))
+		mock.ExpectQuery("name: IsBlocked ").WithArgs(bob, alice).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectBegin()
+		mock.ExpectQuery("name: CreateRefChirp ").WithArgs(tc.body, bob, chirpKindReply, reply.RefChirpID).
+			WillReturnRows(chirpRows(reply))
+		mock.ExpectQuery("name: GetUsersByHandles ").WillReturnRows(sqlmock.NewRows([]string{"id", "handle"}).
+			AddRow(tc.mentioned.ID, tc.mentioned.Handle))
+		mock.ExpectQuery("name: GetBlockedAmong ").WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+		mock.ExpectExec("name: AddChirpMentions ").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("name: GetChirp ").WithArgs(original.ID).WillReturnRows(chirpRows(original))
+		newChirp := uuid.NullUUID{UUID: reply.ID, Valid: true}
+		expectNotification(mock, notifyReply, alice, bob, newChirp)
+		if tc.mentioned.ID != alice {
+			expectNotification(mock, notifyMention, tc.mentioned.ID, bob, newChirp)
+		}
+		mock.ExpectExec("name: EnqueueWebhookDeliveries ").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("name: EnqueueFollowerDeliveries ").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		mock.ExpectQuery("name: GetChirpsByIDs ").WillReturnRows(chirpRows(original))
+		mock.ExpectQuery("name: GetBlockedAmong ").WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+		expectChirpDetails(mock, database.GetUserHandlesRow{ID: bob, Handle: "bob"},
+			database.GetUserHandlesRow{ID: alice, Handle: "alice"})
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/chirps/"+original.ID.String()+"/replies",
+			strings.NewReader(`{"body":"`+tc.body+`"}`))
+		req.Header.Set("Content-Type", jsonContent)
+		req.Header.Set("Authorization", authHeader(t, bob))
+		recorder := httptest.NewRecorder()
+		cfg.routes().ServeHTTP(recorder, req)
+		if recorder.Code != http.StatusCreated {
+			t.Errorf("%s: POST reply = %d, want 201: %s", tc.name, recorder.Code, recorder.Body)
+		}
+		got := map[uuid.UUID]string{}
+		for _, event := range published(events) {
+			got[event.Notification.UserID] = event.Notification.Type
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: notified %v, want %v", tc.name, got, tc.want)
+		}
+		for user, kind := range tc.want {
+			if got[user] != kind {
+				t.Errorf("%s: notified %v, want %v", tc.name, got, tc.want)
+			}
+		}
+		cancel()
+	}
+}
+
+// TestGetReplies checks that replies are listed oldest first, and that the
+// replies to a missing chirp are not found.
+func TestGetReplies(t *testing.T) {
+	cfg, mock := mockConfig(t)
+	routes := cfg.routes()
+	now := time.Now().UTC()
+	original := database.Chirp{ID: uuid.New(), CreatedAt: now.Add(-time.Hour), UpdatedAt: now, Body: "hot take",
+		UserID: uuid.New(), Kind: chirpKindChirp}
+	reply := func(body string, at time.Time) database.Chirp {
+		return database.Chirp{ID: uuid.New(), CreatedAt: at, UpdatedAt: at, Body: body, UserID: uuid.New(),
+			Kind: chirpKindReply, RefChirpID: uuid.NullUUID{UUID: original.ID, Valid: true}}
+	}
+	first, second := reply("agreed", now.Add(-time.Minute)), reply("disagreed", now)
+	mock.ExpectQuery("name: GetChirp ").WithArgs(original.ID).WillReturnRows(chirpRows(original))
+	mock.ExpectQuery("name: GetReplies ").WithArgs(original.ID, uuid.NullUUID{}).
+		WillReturnRows(chirpRows(first, second))
+	mock.ExpectQuery("name: GetChirpsByIDs ").WillReturnRows(chirpRows(original))
+	expectChirpDetails(mock)
+	missing := uuid.New()
+	mock.ExpectQuery("name: GetChirp ").WithArgs(missing).WillReturnError(sql.ErrNoRows)
+
+	recorder := httptest.NewRecorder()
+	routes.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet,
+		"/api/v2/chirps/"+original.ID.String()+"/replies", nil))
+	var got []chirpResp
+	if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil || recorder.Code != http.StatusOK {
+		t.Fatalf("GET replies = %d, %q: %v", recorder.Code, recorder.Body, err)
+	}
+	if len(got) != 2 || got[0].Body != first.Body || got[1].Body != second.Body {
+		t.Errorf("GET replies = %+v, want %q then %q", got, first.Body, second.Body)
+	}
+	recorder = httptest.NewRecorder()
+	routes.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v2/chirps/"+missing.String()+"/replies", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("GET replies to a missing chirp = %d, want 404", recorder.Code)
+	}
+}
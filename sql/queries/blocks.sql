@@ -0,0 +1,52 @@
+-- name: BlockUser :exec
+INSERT INTO blocks (blocker_id, blocked_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: UnblockUser :exec
+DELETE FROM blocks WHERE blocker_id = $1 AND blocked_id = $2;
+
+-- name: DeleteFollowsBetween :exec
+DELETE FROM follows
+WHERE (user_id = sqlc.arg(user_a) AND followee_id = sqlc.arg(user_b))
+    OR (user_id = sqlc.arg(user_b) AND followee_id = sqlc.arg(user_a));
+
+-- name: IsBlocked :one
+SELECT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocker_id = sqlc.arg(user_a) AND blocked_id = sqlc.arg(user_b))
+        OR (blocker_id = sqlc.arg(user_b) AND blocked_id = sqlc.arg(user_a))
+);
+
+-- name: GetBlockedAmong :many
+SELECT blocked_id AS user_id FROM blocks
+WHERE blocker_id = sqlc.arg(viewer_id) AND blocked_id = ANY(sqlc.arg(user_ids)::uuid[])
+UNION
+SELECT blocker_id AS user_id FROM blocks
+WHERE blocked_id = sqlc.arg(viewer_id) AND blocker_id = ANY(sqlc.arg(user_ids)::uuid[]);
+
+-- name: GetBlocks :many
+SELECT users.id, users.handle, users.display_name, users.avatar_url, blocks.created_at
+FROM blocks
+JOIN users ON users.id = blocks.blocked_id
+WHERE blocks.blocker_id = sqlc.arg(user_id)
+    AND (blocks.created_at, blocks.blocked_id) < (sqlc.arg(before_time)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY blocks.created_at DESC, blocks.blocked_id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: MuteUser :exec
+INSERT INTO mutes (muter_id, muted_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: UnmuteUser :exec
+DELETE FROM mutes WHERE muter_id = $1 AND muted_id = $2;
+
+-- name: GetMutes :many
+SELECT users.id, users.handle, users.display_name, users.avatar_url, mutes.created_at
+FROM mutes
+JOIN users ON users.id = mutes.muted_id
+WHERE mutes.muter_id = sqlc.arg(user_id)
+    AND (mutes.created_at, mutes.muted_id) < (sqlc.arg(before_time)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY mutes.created_at DESC, mutes.muted_id DESC
+LIMIT sqlc.arg(page_size);
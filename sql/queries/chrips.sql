@@ -5,12 +5,79 @@ VALUES (
 )
 RETURNING *;
 
--- name: GetChirps :many
-SELECT * FROM chirps ORDER BY created_at ASC;
+-- name: CreateRefChirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, kind, ref_chirp_id)
+VALUES (
+    gen_random_uuid(), NOW(), NOW(), $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetNewestChirps :many
+-- Newest first, so a listing that only shows the latest few reads no more than
+-- that; a NULL max_chirps reads them all.
+SELECT * FROM chirps
+WHERE NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg(viewer_id) AND blocks.blocked_id = chirps.user_id)
+        OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg(viewer_id))
+) AND (sqlc.narg(author_id)::uuid IS NULL OR chirps.user_id = sqlc.narg(author_id))
+    AND (NOT sqlc.arg(originals_only)::boolean OR chirps.kind <> 'rechirp')
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.narg(max_chirps);
 
 -- name: GetChirp :one
 SELECT * FROM chirps WHERE id = $1;
 
+-- name: GetChirpsByIDs :many
+SELECT * FROM chirps WHERE id = ANY(sqlc.arg(ids)::uuid[]);
+
+-- name: UpdateChirpBody :one
+UPDATE chirps SET body = $1, updated_at = NOW() WHERE id = $2 AND user_id = $3
+RETURNING *;
+
 -- name: DeleteChirp :one
 DELETE FROM chirps WHERE id = $1 AND user_id = $2
 RETURNING id, user_id;
+
+-- name: GetReplies :many
+SELECT * FROM chirps
+WHERE ref_chirp_id = sqlc.arg(chirp_id)::uuid AND kind = 'reply' AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg(viewer_id) AND blocks.blocked_id = chirps.user_id)
+        OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg(viewer_id))
+)
+ORDER BY created_at ASC;
+
+-- name: GetChirpsAfter :many
+SELECT * FROM chirps
+WHERE (created_at, id) > (sqlc.arg(after_time)::timestamp, sqlc.arg(after_id)::uuid) AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg(viewer_id) AND blocks.blocked_id = chirps.user_id)
+        OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg(viewer_id))
+)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetRepliesTo :many
+SELECT * FROM chirps
+WHERE ref_chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[]) AND kind = 'reply' AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg(viewer_id) AND blocks.blocked_id = chirps.user_id)
+        OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg(viewer_id))
+)
+ORDER BY created_at ASC, id ASC;
+
+-- name: GetChirpsByAuthors :many
+SELECT chirps.* FROM unnest(sqlc.arg(user_ids)::uuid[]) AS authors(id)
+CROSS JOIN LATERAL (
+    SELECT * FROM chirps
+    WHERE chirps.user_id = authors.id
+        AND (chirps.created_at, chirps.id) < (sqlc.arg(before_time)::timestamp, sqlc.arg(before_id)::uuid)
+        AND NOT EXISTS (
+            SELECT 1 FROM blocks
+            WHERE (blocks.blocker_id = sqlc.narg(viewer_id) AND blocks.blocked_id = chirps.user_id)
+                OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg(viewer_id))
+        )
+    ORDER BY chirps.created_at DESC, chirps.id DESC
+    LIMIT sqlc.arg(page_size)
+) AS chirps;
@@ -0,0 +1,2 @@
+-- name: PublishEvent :exec
+SELECT pg_notify('chirpy_events', sqlc.arg(payload)::text);
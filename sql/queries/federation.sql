@@ -0,0 +1,131 @@
+-- name: CreateActorKey :exec
+INSERT INTO actor_keys (user_id, public_key_pem, private_key_pem, created_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (user_id) DO NOTHING;
+
+-- name: GetActorKey :one
+SELECT * FROM actor_keys WHERE user_id = $1;
+
+-- name: UpsertRemoteActor :one
+INSERT INTO remote_actors (id, uri, handle, domain, display_name, inbox, shared_inbox, key_id, public_key_pem, fetched_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, NOW())
+ON CONFLICT (uri) DO UPDATE SET handle = EXCLUDED.handle, domain = EXCLUDED.domain,
+    display_name = EXCLUDED.display_name, inbox = EXCLUDED.inbox, shared_inbox = EXCLUDED.shared_inbox,
+    key_id = EXCLUDED.key_id, public_key_pem = EXCLUDED.public_key_pem, fetched_at = NOW()
+RETURNING *;
+
+-- name: GetRemoteActor :one
+SELECT * FROM remote_actors WHERE id = $1;
+
+-- name: GetRemoteActorByKeyID :one
+SELECT * FROM remote_actors WHERE key_id = $1;
+
+-- name: DeleteRemoteActor :exec
+DELETE FROM remote_actors WHERE uri = $1;
+
+-- name: AddRemoteFollower :exec
+INSERT INTO remote_followers (user_id, actor_id, follow_uri, created_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (user_id, actor_id) DO UPDATE SET follow_uri = EXCLUDED.follow_uri;
+
+-- name: RemoveRemoteFollower :exec
+DELETE FROM remote_followers WHERE user_id = $1 AND actor_id = $2;
+
+-- name: CountActorFollows :one
+SELECT
+    (SELECT COUNT(*) FROM follows WHERE follows.followee_id = sqlc.arg(user_id))
+        + (SELECT COUNT(*) FROM remote_followers WHERE remote_followers.user_id = sqlc.arg(user_id)) AS followers,
+    (SELECT COUNT(*) FROM follows WHERE follows.user_id = sqlc.arg(user_id))
+        + (SELECT COUNT(*) FROM remote_following
+            WHERE remote_following.user_id = sqlc.arg(user_id) AND remote_following.accepted) AS following;
+
+-- name: FollowRemoteActor :exec
+INSERT INTO remote_following (user_id, actor_id, follow_uri, accepted, created_at)
+VALUES ($1, $2, $3, FALSE, NOW())
+ON CONFLICT (user_id, actor_id) DO UPDATE SET follow_uri = EXCLUDED.follow_uri, accepted = FALSE;
+
+-- name: AcceptRemoteFollow :exec
+UPDATE remote_following SET accepted = TRUE WHERE follow_uri = $1 AND actor_id = $2;
+
+-- name: RejectRemoteFollow :exec
+DELETE FROM remote_following WHERE follow_uri = $1 AND actor_id = $2;
+
+-- name: UnfollowRemoteActor :one
+DELETE FROM remote_following WHERE user_id = $1 AND actor_id = $2
+RETURNING follow_uri;
+
+-- name: GetRemoteFollowing :many
+SELECT remote_actors.id, remote_actors.uri, remote_actors.handle, remote_actors.domain, remote_actors.display_name,
+    remote_following.accepted, remote_following.created_at
+FROM remote_following
+JOIN remote_actors ON remote_actors.id = remote_following.actor_id
+WHERE remote_following.user_id = $1
+ORDER BY remote_following.created_at ASC;
+
+-- name: IsRemoteActorFollowed :one
+SELECT EXISTS (SELECT 1 FROM remote_following WHERE actor_id = $1 AND accepted);
+
+-- name: UpsertRemotePost :exec
+INSERT INTO remote_posts (id, uri, actor_id, body, url, in_reply_to, published_at, created_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, NOW())
+ON CONFLICT (uri) DO UPDATE SET body = EXCLUDED.body, url = EXCLUDED.url, in_reply_to = EXCLUDED.in_reply_to
+WHERE remote_posts.actor_id = EXCLUDED.actor_id;
+
+-- name: DeleteRemotePost :exec
+DELETE FROM remote_posts WHERE uri = $1 AND actor_id = $2;
+
+-- name: GetRemoteTimeline :many
+SELECT remote_posts.id, remote_posts.uri, remote_posts.body, remote_posts.url, remote_posts.in_reply_to,
+    remote_posts.published_at, remote_actors.uri AS actor_uri, remote_actors.handle, remote_actors.domain,
+    remote_actors.display_name
+FROM remote_posts
+JOIN remote_actors ON remote_actors.id = remote_posts.actor_id
+JOIN remote_following ON remote_following.actor_id = remote_posts.actor_id
+WHERE remote_following.user_id = sqlc.arg(user_id) AND remote_following.accepted
+    AND (remote_posts.published_at, remote_posts.id) < (sqlc.arg(before_time)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY remote_posts.published_at DESC, remote_posts.id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetOutboxChirps :many
+SELECT * FROM chirps
+WHERE user_id = sqlc.arg(user_id) AND kind <> 'rechirp'
+    AND (created_at, id) < (sqlc.arg(before_time)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: CountOutboxChirps :one
+SELECT COUNT(*) FROM chirps WHERE user_id = $1 AND kind <> 'rechirp';
+
+-- name: EnqueueFederationDelivery :exec
+INSERT INTO federation_deliveries (id, user_id, inbox, payload, status, attempts, next_attempt_at, created_at)
+VALUES (gen_random_uuid(), $1, $2, $3, 'pending', 0, NOW(), NOW());
+
+-- name: EnqueueFollowerDeliveries :exec
+INSERT INTO federation_deliveries (id, user_id, inbox, payload, status, attempts, next_attempt_at, created_at)
+SELECT gen_random_uuid(), sqlc.arg(user_id), inboxes.inbox, sqlc.arg(payload)::text, 'pending', 0, NOW(), NOW()
+FROM (
+    SELECT DISTINCT COALESCE(remote_actors.shared_inbox, remote_actors.inbox) AS inbox
+    FROM remote_followers
+    JOIN remote_actors ON remote_actors.id = remote_followers.actor_id
+    WHERE remote_followers.user_id = sqlc.arg(user_id)
+) inboxes;
+
+-- name: ClaimFederationDeliveries :many
+UPDATE federation_deliveries
+SET next_attempt_at = NOW() + make_interval(secs => sqlc.arg(lease_seconds)::float8)
+FROM actor_keys
+WHERE actor_keys.user_id = federation_deliveries.user_id AND federation_deliveries.id IN (
+    SELECT due.id FROM federation_deliveries due
+    WHERE due.status = 'pending' AND due.next_attempt_at <= NOW()
+    ORDER BY due.next_attempt_at ASC
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING federation_deliveries.id, federation_deliveries.user_id, federation_deliveries.inbox,
+    federation_deliveries.payload, federation_deliveries.attempts, actor_keys.private_key_pem;
+
+-- name: FinishFederationAttempt :exec
+UPDATE federation_deliveries
+SET status = sqlc.arg(status), attempts = attempts + 1,
+    next_attempt_at = NOW() + make_interval(secs => sqlc.arg(retry_seconds)::float8), last_error = sqlc.narg(last_error)
+WHERE id = sqlc.arg(id);
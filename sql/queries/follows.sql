@@ -0,0 +1,61 @@
+-- name: FollowUser :execrows
+INSERT INTO follows (user_id, followee_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: UnfollowUser :execrows
+DELETE FROM follows WHERE user_id = $1 AND followee_id = $2;
+
+-- name: GetFollowers :many
+SELECT users.id, users.handle, users.display_name, users.avatar_url, follows.created_at AS followed_at
+FROM follows
+JOIN users ON users.id = follows.user_id
+WHERE follows.followee_id = sqlc.arg(user_id)
+    AND (follows.created_at, follows.user_id) < (sqlc.arg(before_time)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY follows.created_at DESC, follows.user_id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetFollowing :many
+SELECT users.id, users.handle, users.display_name, users.avatar_url, follows.created_at AS followed_at
+FROM follows
+JOIN users ON users.id = follows.followee_id
+WHERE follows.user_id = sqlc.arg(user_id)
+    AND (follows.created_at, follows.followee_id) < (sqlc.arg(before_time)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY follows.created_at DESC, follows.followee_id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetTimeline :many
+SELECT chirps.* FROM chirps
+JOIN follows ON follows.followee_id = chirps.user_id
+WHERE follows.user_id = sqlc.arg(user_id)
+    AND (chirps.created_at, chirps.id) < (sqlc.arg(before_time)::timestamp, sqlc.arg(before_id)::uuid)
+    AND NOT EXISTS (SELECT 1 FROM mutes WHERE mutes.muter_id = sqlc.arg(user_id) AND mutes.muted_id = chirps.user_id)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetTimelineAuthors :many
+SELECT followee_id FROM follows
+WHERE user_id = $1
+    AND NOT EXISTS (SELECT 1 FROM mutes WHERE mutes.muter_id = $1 AND mutes.muted_id = follows.followee_id);
+
+-- name: GetFollowersOfUsers :many
+SELECT subjects.id AS subject_id, page.user_id AS id, page.created_at AS followed_at
+FROM unnest(sqlc.arg(user_ids)::uuid[]) AS subjects(id)
+CROSS JOIN LATERAL (
+    SELECT follows.user_id, follows.created_at FROM follows
+    WHERE follows.followee_id = subjects.id
+        AND (follows.created_at, follows.user_id) < (sqlc.arg(before_time)::timestamp, sqlc.arg(before_id)::uuid)
+    ORDER BY follows.created_at DESC, follows.user_id DESC
+    LIMIT sqlc.arg(page_size)
+) AS page;
+
+-- name: GetFollowingOfUsers :many
+SELECT subjects.id AS subject_id, page.followee_id AS id, page.created_at AS followed_at
+FROM unnest(sqlc.arg(user_ids)::uuid[]) AS subjects(id)
+CROSS JOIN LATERAL (
+    SELECT follows.followee_id, follows.created_at FROM follows
+    WHERE follows.user_id = subjects.id
+        AND (follows.created_at, follows.followee_id) < (sqlc.arg(before_time)::timestamp, sqlc.arg(before_id)::uuid)
+    ORDER BY follows.created_at DESC, follows.followee_id DESC
+    LIMIT sqlc.arg(page_size)
+) AS page;
@@ -0,0 +1,35 @@
+-- name: AddChirpHashtags :exec
+INSERT INTO chirp_hashtags (chirp_id, tag, created_at)
+SELECT sqlc.arg(chirp_id), unnest(sqlc.arg(tags)::text[]), NOW()
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteChirpHashtags :exec
+DELETE FROM chirp_hashtags WHERE chirp_id = $1;
+
+-- name: GetNewestChirpsByHashtag :many
+-- Newest first, like GetNewestChirps.
+SELECT chirps.* FROM chirps
+JOIN chirp_hashtags ON chirp_hashtags.chirp_id = chirps.id
+WHERE chirp_hashtags.tag = sqlc.arg(tag) AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg(viewer_id) AND blocks.blocked_id = chirps.user_id)
+        OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg(viewer_id))
+)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.narg(max_chirps);
+
+-- name: ClearTrending :exec
+DELETE FROM trending_hashtags WHERE time_window = $1;
+
+-- name: RefreshTrending :exec
+INSERT INTO trending_hashtags (time_window, tag, score, uses, updated_at)
+SELECT sqlc.arg(time_window)::text, tag,
+    SUM(EXP(-EXTRACT(EPOCH FROM NOW() - created_at)::float8 / sqlc.arg(decay_seconds)::float8))::float8, COUNT(*), NOW()
+FROM chirp_hashtags
+WHERE created_at > NOW() - make_interval(secs => sqlc.arg(window_seconds)::float8)
+GROUP BY tag;
+
+-- name: GetTrending :many
+SELECT tag, score, uses FROM trending_hashtags WHERE time_window = $1
+ORDER BY score DESC, tag ASC
+LIMIT $2;
@@ -0,0 +1,26 @@
+-- name: ClaimIdempotencyKey :one
+INSERT INTO idempotency_keys (user_id, key, request_hash, locked_until, created_at)
+VALUES (sqlc.arg(user_id), sqlc.arg(key), sqlc.arg(request_hash),
+    NOW() + make_interval(secs => sqlc.arg(lease_seconds)::float8), NOW())
+ON CONFLICT (user_id, key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash, response_status = NULL, response_content_type = NULL, response_body = NULL,
+    locked_until = EXCLUDED.locked_until, created_at = EXCLUDED.created_at
+WHERE idempotency_keys.created_at <= NOW() - make_interval(secs => sqlc.arg(ttl_seconds)::float8)
+    OR (idempotency_keys.response_status IS NULL AND idempotency_keys.locked_until <= NOW()
+        AND idempotency_keys.request_hash = EXCLUDED.request_hash)
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys WHERE user_id = $1 AND key = $2;
+
+-- name: SaveIdempotentResponse :exec
+UPDATE idempotency_keys
+SET response_status = sqlc.arg(response_status), response_content_type = sqlc.arg(response_content_type),
+    response_body = sqlc.arg(response_body)
+WHERE user_id = sqlc.arg(user_id) AND key = sqlc.arg(key);
+
+-- name: ReleaseIdempotencyKey :exec
+DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2 AND response_status IS NULL;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys WHERE created_at <= NOW() - make_interval(secs => sqlc.arg(ttl_seconds)::float8);
@@ -0,0 +1,86 @@
+-- name: CreateConversation :one
+INSERT INTO conversations (id, is_group, created_at, updated_at)
+VALUES (gen_random_uuid(), $1, NOW(), NOW())
+RETURNING *;
+
+-- name: AddConversationParticipants :exec
+INSERT INTO conversation_participants (conversation_id, user_id, joined_at)
+SELECT sqlc.arg(conversation_id)::uuid, unnest(sqlc.arg(user_ids)::uuid[]), NOW();
+
+-- name: FindDirectConversation :one
+SELECT conversations.id FROM conversations
+JOIN conversation_participants a ON a.conversation_id = conversations.id AND a.user_id = sqlc.arg(user_a)
+JOIN conversation_participants b ON b.conversation_id = conversations.id AND b.user_id = sqlc.arg(user_b)
+WHERE NOT conversations.is_group
+LIMIT 1;
+
+-- name: LockDirectConversation :exec
+-- Holds the pair's lock until the transaction ends, so two requests cannot both
+-- find no conversation and each create one.
+SELECT pg_advisory_xact_lock(hashtextextended(
+    LEAST(sqlc.arg(user_a)::uuid, sqlc.arg(user_b)::uuid)::text || GREATEST(sqlc.arg(user_a)::uuid, sqlc.arg(user_b)::uuid)::text,
+    0));
+
+-- name: GetConversationParticipants :many
+SELECT conversation_participants.conversation_id, users.id, users.handle, conversation_participants.last_read_at
+FROM conversation_participants
+JOIN users ON users.id = conversation_participants.user_id
+WHERE conversation_participants.conversation_id = ANY(sqlc.arg(conversation_ids)::uuid[])
+ORDER BY conversation_participants.joined_at, users.id;
+
+-- name: GetConversations :many
+SELECT conversations.id, conversations.is_group, conversations.created_at, conversations.updated_at,
+    latest.id AS message_id, latest.sender_id, latest.body, latest.created_at AS message_at,
+    (SELECT COUNT(*) FROM messages unread
+        WHERE unread.conversation_id = conversations.id
+            AND unread.created_at > COALESCE(conversation_participants.last_read_at, '-infinity'::timestamp)
+            AND unread.sender_id IS DISTINCT FROM sqlc.arg(user_id)
+            AND NOT EXISTS (
+                SELECT 1 FROM blocks
+                WHERE (blocks.blocker_id = sqlc.arg(user_id) AND blocks.blocked_id = unread.sender_id)
+                    OR (blocks.blocker_id = unread.sender_id AND blocks.blocked_id = sqlc.arg(user_id))
+            )) AS unread
+FROM conversation_participants
+JOIN conversations ON conversations.id = conversation_participants.conversation_id
+LEFT JOIN LATERAL (
+    SELECT messages.id, messages.sender_id, messages.body, messages.created_at FROM messages
+    WHERE messages.conversation_id = conversations.id AND NOT EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocks.blocker_id = sqlc.arg(user_id) AND blocks.blocked_id = messages.sender_id)
+            OR (blocks.blocker_id = messages.sender_id AND blocks.blocked_id = sqlc.arg(user_id))
+    )
+    ORDER BY messages.created_at DESC, messages.id DESC
+    LIMIT 1
+) latest ON true
+WHERE conversation_participants.user_id = sqlc.arg(user_id)
+    AND (sqlc.narg(conversation_id)::uuid IS NULL OR conversations.id = sqlc.narg(conversation_id))
+    AND (conversations.updated_at, conversations.id) < (sqlc.arg(before_time)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY conversations.updated_at DESC, conversations.id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: CreateMessage :one
+INSERT INTO messages (id, conversation_id, sender_id, body, created_at)
+VALUES (gen_random_uuid(), $1, $2, $3, NOW())
+RETURNING *;
+
+-- name: TouchConversation :exec
+UPDATE conversations SET updated_at = NOW() WHERE id = $1;
+
+-- name: GetMessage :one
+SELECT * FROM messages WHERE id = $1;
+
+-- name: GetMessages :many
+SELECT * FROM messages
+WHERE conversation_id = sqlc.arg(conversation_id)
+    AND (created_at, id) < (sqlc.arg(before_time)::timestamp, sqlc.arg(before_id)::uuid)
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocks.blocker_id = sqlc.arg(viewer_id) AND blocks.blocked_id = messages.sender_id)
+            OR (blocks.blocker_id = messages.sender_id AND blocks.blocked_id = sqlc.arg(viewer_id))
+    )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: MarkConversationRead :execrows
+UPDATE conversation_participants SET last_read_at = NOW()
+WHERE conversation_id = $1 AND user_id = $2;
@@ -0,0 +1,14 @@
+-- name: CreateMutedWord :one
+INSERT INTO muted_words (id, user_id, phrase, whole_word, action, expires_at, created_at)
+VALUES (
+    gen_random_uuid(), $1, $2, $3, $4, $5, NOW()
+)
+RETURNING *;
+
+-- name: GetMutedWords :many
+SELECT * FROM muted_words
+WHERE user_id = $1 AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY created_at ASC;
+
+-- name: DeleteMutedWord :execrows
+DELETE FROM muted_words WHERE id = $1 AND user_id = $2;
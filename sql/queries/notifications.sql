@@ -0,0 +1,37 @@
+-- name: CreateNotification :one
+INSERT INTO notifications (id, user_id, actor_id, type, chirp_id, group_key, created_at)
+VALUES (
+    gen_random_uuid(), $1, $2, $3, $4, $5, NOW()
+)
+RETURNING *;
+
+-- name: DeleteNotification :exec
+DELETE FROM notifications
+WHERE user_id = sqlc.arg(user_id) AND actor_id = sqlc.arg(actor_id) AND type = sqlc.arg(type)
+    AND chirp_id IS NOT DISTINCT FROM sqlc.narg(chirp_id);
+
+-- name: GetNotificationGroups :many
+-- Groups are ordered by their first notification, which later ones cannot move,
+-- so a group that grows while someone pages through is neither skipped nor repeated.
+SELECT group_key, type, chirp_id, COUNT(*) AS actor_count,
+    (array_agg(actor_id ORDER BY created_at DESC))[1:3]::uuid[] AS recent_actor_ids,
+    MIN(created_at)::timestamp AS first_at,
+    MAX(created_at)::timestamp AS latest_at,
+    COUNT(*) FILTER (WHERE read_at IS NULL) AS unread
+FROM notifications
+WHERE user_id = sqlc.arg(user_id)
+GROUP BY group_key, type, chirp_id
+HAVING (MIN(created_at), group_key) < (sqlc.arg(before_time)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY first_at DESC, group_key DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: CountUnreadNotifications :one
+SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL;
+
+-- name: MarkNotificationGroupRead :execrows
+UPDATE notifications SET read_at = COALESCE(read_at, NOW())
+WHERE user_id = $1 AND group_key = $2;
+
+-- name: MarkAllNotificationsRead :exec
+UPDATE notifications SET read_at = NOW()
+WHERE user_id = $1 AND read_at IS NULL;
@@ -1,16 +1,47 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password)
-VALUES (
-    gen_random_uuid(), NOW(), NOW(), $1, $2
-)
-RETURNING id, created_at, updated_at, email;
+-- An empty handle gets the default migration 008 gave existing users.
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, handle)
+SELECT id, NOW(), NOW(), $1, $2, COALESCE(NULLIF($3::text, ''), 'user_' || substr(replace(id::text, '-', ''), 1, 12))
+FROM (SELECT gen_random_uuid() AS id) AS new_user
+RETURNING id, created_at, updated_at, email, handle, is_chirpy_red;
 
 -- name: GetUserByEmail :one
 SELECT * FROM users WHERE email = $1;
 
+-- name: GetUserByID :one
+SELECT * FROM users WHERE id = $1;
+
+-- name: GetUserByHandle :one
+SELECT * FROM users WHERE lower(handle) = lower(sqlc.arg(handle));
+
+-- name: GetUserHandles :many
+SELECT id, handle FROM users WHERE id = ANY(sqlc.arg(ids)::uuid[]);
+
 -- name: ResetUsers :exec
 DELETE FROM users;
 
 -- name: UpdateUser :one
 UPDATE users SET email = $1, updated_at = NOW(), hashed_password = $2 WHERE id = $3
-RETURNING id, created_at, updated_at, email;
+RETURNING id, created_at, updated_at, email, handle, is_chirpy_red;
+
+-- name: UpdateUserHandle :one
+UPDATE users SET handle = $1, handle_changed_at = NOW(), updated_at = NOW() WHERE id = $2
+RETURNING id, created_at, updated_at, email, handle, is_chirpy_red;
+
+-- name: GetUserProfile :one
+SELECT users.id, users.created_at, users.handle, users.display_name, users.bio, users.avatar_url,
+    (SELECT COUNT(*) FROM chirps WHERE chirps.user_id = users.id) AS chirp_count
+FROM users WHERE users.id = $1;
+
+-- name: GetUserProfiles :many
+SELECT users.id, users.created_at, users.handle, users.display_name, users.bio, users.avatar_url,
+    (SELECT COUNT(*) FROM chirps WHERE chirps.user_id = users.id) AS chirp_count
+FROM users WHERE users.id = ANY(sqlc.arg(ids)::uuid[]);
+
+-- name: UpdateUserProfile :exec
+UPDATE users SET display_name = COALESCE(sqlc.narg(display_name), display_name), bio = COALESCE(sqlc.narg(bio), bio),
+    avatar_url = COALESCE(sqlc.narg(avatar_url), avatar_url), updated_at = NOW()
+WHERE id = sqlc.arg(id);
+
+-- name: SetChirpyRed :execrows
+UPDATE users SET is_chirpy_red = $1, updated_at = NOW() WHERE id = $2;
@@ -0,0 +1,58 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (id, user_id, url, event_types, secret, created_at, updated_at)
+VALUES (gen_random_uuid(), sqlc.arg(user_id), sqlc.arg(url), sqlc.arg(event_types)::text[], sqlc.arg(secret), NOW(), NOW())
+RETURNING *;
+
+-- name: GetWebhooks :many
+SELECT * FROM webhooks WHERE user_id = $1
+ORDER BY created_at ASC, id ASC;
+
+-- name: GetWebhook :one
+SELECT * FROM webhooks WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks WHERE id = $1 AND user_id = $2;
+
+-- name: EnqueueWebhookDeliveries :exec
+INSERT INTO webhook_deliveries (id, webhook_id, event_type, payload, status, attempts, next_attempt_at, created_at)
+SELECT gen_random_uuid(), webhooks.id, sqlc.arg(event_type)::text, sqlc.arg(payload)::text, 'pending', 0, NOW(), NOW()
+FROM webhooks
+WHERE sqlc.arg(event_type)::text = ANY(webhooks.event_types) AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = webhooks.user_id AND blocks.blocked_id = sqlc.arg(actor_id))
+        OR (blocks.blocker_id = sqlc.arg(actor_id) AND blocks.blocked_id = webhooks.user_id)
+);
+
+-- name: ClaimWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET next_attempt_at = NOW() + make_interval(secs => sqlc.arg(lease_seconds)::float8)
+FROM webhooks
+WHERE webhooks.id = webhook_deliveries.webhook_id AND webhook_deliveries.id IN (
+    SELECT due.id FROM webhook_deliveries due
+    WHERE due.status = 'pending' AND due.next_attempt_at <= NOW()
+    ORDER BY due.next_attempt_at ASC
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING webhook_deliveries.id, webhook_deliveries.event_type, webhook_deliveries.payload, webhook_deliveries.attempts,
+    webhooks.url, webhooks.secret;
+
+-- name: FinishWebhookAttempt :exec
+UPDATE webhook_deliveries
+SET status = sqlc.arg(status), attempts = attempts + 1, last_attempt_at = NOW(),
+    next_attempt_at = NOW() + make_interval(secs => sqlc.arg(retry_seconds)::float8),
+    response_status = sqlc.narg(response_status), last_error = sqlc.narg(last_error)
+WHERE id = sqlc.arg(id);
+
+-- name: GetWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE webhook_id = sqlc.arg(webhook_id) AND (created_at, id) < (sqlc.arg(before_time)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: RedeliverWebhookDelivery :one
+INSERT INTO webhook_deliveries (id, webhook_id, event_type, payload, status, attempts, next_attempt_at, created_at)
+SELECT gen_random_uuid(), webhook_id, event_type, payload, 'pending', 0, NOW(), NOW()
+FROM webhook_deliveries
+WHERE webhook_deliveries.id = sqlc.arg(id) AND webhook_deliveries.webhook_id = sqlc.arg(webhook_id)
+RETURNING *;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE chirps ADD kind TEXT NOT NULL DEFAULT 'chirp' CHECK (kind IN ('chirp', 'rechirp', 'quote'));
+ALTER TABLE chirps ADD ref_chirp_id UUID;
+CREATE INDEX chirps_ref_chirp_id_idx ON chirps (ref_chirp_id);
+CREATE UNIQUE INDEX chirps_rechirp_once_idx ON chirps (user_id, ref_chirp_id) WHERE kind = 'rechirp';
+
+-- +goose Down
+DROP INDEX chirps_rechirp_once_idx;
+DROP INDEX chirps_ref_chirp_id_idx;
+ALTER TABLE chirps DROP COLUMN ref_chirp_id;
+ALTER TABLE chirps DROP COLUMN kind;
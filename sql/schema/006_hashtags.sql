@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE chirp_hashtags (chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE, tag TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL, PRIMARY KEY (chirp_id, tag));
+CREATE INDEX chirp_hashtags_tag_idx ON chirp_hashtags (tag, created_at);
+CREATE INDEX chirp_hashtags_created_at_idx ON chirp_hashtags (created_at);
+CREATE TABLE trending_hashtags (time_window TEXT NOT NULL, tag TEXT NOT NULL, score DOUBLE PRECISION NOT NULL,
+    uses BIGINT NOT NULL, updated_at TIMESTAMP NOT NULL, PRIMARY KEY (time_window, tag));
+
+-- +goose Down
+DROP TABLE trending_hashtags;
+DROP TABLE chirp_hashtags;
@@ -0,0 +1,12 @@
+-- +goose Up
+ALTER TABLE users ADD handle TEXT;
+CREATE UNIQUE INDEX users_handle_lower_idx ON users (lower(handle));
+CREATE TABLE chirp_mentions (chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE, start_offset INTEGER NOT NULL, end_offset INTEGER NOT NULL,
+    PRIMARY KEY (chirp_id, start_offset));
+CREATE INDEX chirp_mentions_user_id_idx ON chirp_mentions (user_id);
+
+-- +goose Down
+DROP TABLE chirp_mentions;
+DROP INDEX users_handle_lower_idx;
+ALTER TABLE users DROP COLUMN handle;
@@ -0,0 +1,8 @@
+-- +goose Up
+UPDATE users SET handle = 'user_' || substr(replace(id::text, '-', ''), 1, 12) WHERE handle IS NULL;
+ALTER TABLE users ALTER COLUMN handle SET NOT NULL;
+ALTER TABLE users ADD handle_changed_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN handle_changed_at;
+ALTER TABLE users ALTER COLUMN handle DROP NOT NULL;
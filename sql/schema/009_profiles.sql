@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE users ADD display_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD bio TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD avatar_url TEXT NOT NULL DEFAULT '';
+CREATE INDEX chirps_user_id_idx ON chirps (user_id);
+
+-- +goose Down
+DROP INDEX chirps_user_id_idx;
+ALTER TABLE users DROP COLUMN avatar_url;
+ALTER TABLE users DROP COLUMN bio;
+ALTER TABLE users DROP COLUMN display_name;
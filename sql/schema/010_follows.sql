@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE follows (user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    followee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE, created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, followee_id), CHECK (user_id <> followee_id));
+CREATE INDEX follows_followee_idx ON follows (followee_id, created_at DESC, user_id DESC);
+CREATE INDEX follows_user_created_idx ON follows (user_id, created_at DESC, followee_id DESC);
+CREATE INDEX chirps_user_created_idx ON chirps (user_id, created_at DESC, id DESC);
+
+-- +goose Down
+DROP INDEX chirps_user_created_idx;
+DROP TABLE follows;
@@ -0,0 +1,8 @@
+-- +goose Up
+CREATE TABLE muted_words (id UUID PRIMARY KEY, user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    phrase TEXT NOT NULL, whole_word BOOLEAN NOT NULL, action TEXT NOT NULL CHECK (action IN ('hide', 'collapse')),
+    expires_at TIMESTAMP, created_at TIMESTAMP NOT NULL);
+CREATE INDEX muted_words_user_idx ON muted_words (user_id);
+
+-- +goose Down
+DROP TABLE muted_words;
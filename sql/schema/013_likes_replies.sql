@@ -0,0 +1,12 @@
+-- +goose Up
+ALTER TABLE chirps DROP CONSTRAINT chirps_kind_check;
+ALTER TABLE chirps ADD CONSTRAINT chirps_kind_check CHECK (kind IN ('chirp', 'rechirp', 'quote', 'reply'));
+CREATE TABLE chirp_likes (user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE, created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (chirp_id, user_id));
+
+-- +goose Down
+DROP TABLE chirp_likes;
+DELETE FROM chirps WHERE kind = 'reply';
+ALTER TABLE chirps DROP CONSTRAINT chirps_kind_check;
+ALTER TABLE chirps ADD CONSTRAINT chirps_kind_check CHECK (kind IN ('chirp', 'rechirp', 'quote'));
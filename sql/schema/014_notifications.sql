@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE notifications (id UUID PRIMARY KEY, user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    actor_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('like', 'reply', 'mention', 'follow', 'rechirp', 'quote')),
+    chirp_id UUID REFERENCES chirps(id) ON DELETE CASCADE, group_key UUID NOT NULL, created_at TIMESTAMP NOT NULL,
+    read_at TIMESTAMP);
+CREATE INDEX notifications_user_group_idx ON notifications (user_id, group_key);
+CREATE INDEX notifications_user_unread_idx ON notifications (user_id) WHERE read_at IS NULL;
+
+-- +goose Down
+DROP TABLE notifications;
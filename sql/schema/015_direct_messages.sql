@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE conversations (id UUID PRIMARY KEY, is_group BOOLEAN NOT NULL, created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL);
+CREATE TABLE conversation_participants (conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE, joined_at TIMESTAMP NOT NULL, last_read_at TIMESTAMP,
+    PRIMARY KEY (conversation_id, user_id));
+CREATE INDEX conversation_participants_user_idx ON conversation_participants (user_id);
+CREATE TABLE messages (id UUID PRIMARY KEY, conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    sender_id UUID REFERENCES users(id) ON DELETE SET NULL, body TEXT NOT NULL, created_at TIMESTAMP NOT NULL);
+CREATE INDEX messages_conversation_created_idx ON messages (conversation_id, created_at DESC, id DESC);
+
+-- +goose Down
+DROP TABLE messages;
+DROP TABLE conversation_participants;
+DROP TABLE conversations;
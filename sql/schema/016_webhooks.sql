@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE webhooks (id UUID PRIMARY KEY, user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url TEXT NOT NULL, event_types TEXT[] NOT NULL, secret TEXT NOT NULL, created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL);
+CREATE INDEX webhooks_user_idx ON webhooks (user_id);
+CREATE TABLE webhook_deliveries (id UUID PRIMARY KEY, webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL, payload TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('pending', 'succeeded', 'failed')), attempts INTEGER NOT NULL,
+    next_attempt_at TIMESTAMP NOT NULL, last_attempt_at TIMESTAMP, response_status INTEGER, last_error TEXT,
+    created_at TIMESTAMP NOT NULL);
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_deliveries_webhook_created_idx ON webhook_deliveries (webhook_id, created_at DESC, id DESC);
+
+-- +goose Down
+DROP TABLE webhook_deliveries;
+DROP TABLE webhooks;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE users ADD is_chirpy_red BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE TABLE polka_events (id TEXT PRIMARY KEY, event TEXT NOT NULL, user_id UUID NOT NULL, received_at TIMESTAMP NOT NULL);
+
+-- +goose Down
+DROP TABLE polka_events;
+ALTER TABLE users DROP COLUMN is_chirpy_red;
@@ -0,0 +1,31 @@
+-- +goose Up
+CREATE TABLE actor_keys (user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE, public_key_pem TEXT NOT NULL,
+    private_key_pem TEXT NOT NULL, created_at TIMESTAMP NOT NULL);
+CREATE TABLE remote_actors (id UUID PRIMARY KEY, uri TEXT NOT NULL UNIQUE, handle TEXT NOT NULL, domain TEXT NOT NULL,
+    display_name TEXT NOT NULL, inbox TEXT NOT NULL, shared_inbox TEXT, key_id TEXT NOT NULL, public_key_pem TEXT NOT NULL,
+    fetched_at TIMESTAMP NOT NULL);
+CREATE INDEX remote_actors_key_id_idx ON remote_actors (key_id);
+CREATE TABLE remote_followers (user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    actor_id UUID NOT NULL REFERENCES remote_actors(id) ON DELETE CASCADE, follow_uri TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL, PRIMARY KEY (user_id, actor_id));
+CREATE TABLE remote_following (user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    actor_id UUID NOT NULL REFERENCES remote_actors(id) ON DELETE CASCADE, follow_uri TEXT NOT NULL,
+    accepted BOOLEAN NOT NULL, created_at TIMESTAMP NOT NULL, PRIMARY KEY (user_id, actor_id));
+CREATE INDEX remote_following_actor_idx ON remote_following (actor_id);
+CREATE TABLE remote_posts (id UUID PRIMARY KEY, uri TEXT NOT NULL UNIQUE,
+    actor_id UUID NOT NULL REFERENCES remote_actors(id) ON DELETE CASCADE, body TEXT NOT NULL, url TEXT NOT NULL,
+    in_reply_to TEXT, published_at TIMESTAMP NOT NULL, created_at TIMESTAMP NOT NULL);
+CREATE INDEX remote_posts_actor_published_idx ON remote_posts (actor_id, published_at DESC, id DESC);
+CREATE TABLE federation_deliveries (id UUID PRIMARY KEY, user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    inbox TEXT NOT NULL, payload TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('pending', 'succeeded', 'failed')), attempts INTEGER NOT NULL,
+    next_attempt_at TIMESTAMP NOT NULL, last_error TEXT, created_at TIMESTAMP NOT NULL);
+CREATE INDEX federation_deliveries_due_idx ON federation_deliveries (next_attempt_at) WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE federation_deliveries;
+DROP TABLE remote_posts;
+DROP TABLE remote_following;
+DROP TABLE remote_followers;
+DROP TABLE remote_actors;
+DROP TABLE actor_keys;
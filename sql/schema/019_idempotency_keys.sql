@@ -0,0 +1,9 @@
+-- +goose Up
+-- user_id is whoever the key is scoped to, which is not always a user, so it has no foreign key.
+CREATE TABLE idempotency_keys (user_id UUID NOT NULL, key TEXT NOT NULL, request_hash TEXT NOT NULL,
+    response_status INTEGER, response_content_type TEXT, response_body TEXT, locked_until TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL, PRIMARY KEY (user_id, key));
+CREATE INDEX idempotency_keys_created_idx ON idempotency_keys (created_at);
+
+-- +goose Down
+DROP TABLE idempotency_keys;
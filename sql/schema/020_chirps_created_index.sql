@@ -0,0 +1,6 @@
+-- +goose Up
+-- Lets the newest chirps be read off the index instead of sorting every chirp.
+CREATE INDEX chirps_created_idx ON chirps (created_at DESC, id DESC);
+
+-- +goose Down
+DROP INDEX chirps_created_idx;
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"chirpy/internal/broker"
+	"chirpy/internal/database"
+	"chirpy/internal/entities"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const eventStreamContent = "text/event-stream"
+
+// streamBuffer is how many chirps a client may fall behind by before it is
+// dropped. A dropped client reconnects with Last-Event-ID and catches up from
+// the database instead of holding up everyone else's deliveries.
+const streamBuffer = 64
+const streamReplayPage = 100
+
+// streamHeartbeat is how often an idle stream sends a comment to keep proxies
+// from closing it. Tests shorten it.
+var streamHeartbeat = 15 * time.Second
+
+var errNotLoggedIn = errors.New("log in to stream your timeline")
+
+// streamFilter narrows a chirp stream to one author, one hashtag and/or the
+// authors on the caller's timeline. Zero values match everything.
+type streamFilter struct {
+	author  uuid.NullUUID
+	hashtag string
+	authors map[uuid.UUID]bool
+}
+
+func (filter streamFilter) matches(chirp database.Chirp) bool {
+	if filter.author.Valid && chirp.UserID != filter.author.UUID {
+		return false
+	}
+	if filter.authors != nil && !filter.authors[chirp.UserID] {
+		return false
+	}
+	return filter.hashtag == "" || slices.Contains(entities.Hashtags(chirp.Body), filter.hashtag)
+}
+
+// parseStreamFilter reads the author_id, hashtag and timeline query parameters.
+// It returns errNotLoggedIn if a timeline is asked for without a login.
+func (cfg *apiConfig) parseStreamFilter(ctx context.Context, query map[string][]string, viewer uuid.NullUUID) (streamFilter, error) {
+	first := func(name string) string {
+		if values := query[name]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	return cfg.newStreamFilter(ctx, viewer, first("author_id"), first("hashtag"), first("timeline") == "true")
+}
+
+// newStreamFilter builds a filter from an author ID and a hashtag, either of
+// which may be empty, and whether to keep to the viewer's timeline.
+func (cfg *apiConfig) newStreamFilter(ctx context.Context, viewer uuid.NullUUID, author, hashtag string, timeline bool) (streamFilter, error) {
+	filter := streamFilter{}
+	if author != "" {
+		id, err := uuid.Parse(author)
+		if err != nil {
+			return streamFilter{}, fmt.Errorf("invalid author_id")
+		}
+		filter.author = uuid.NullUUID{UUID: id, Valid: true}
+	}
+	if hashtag != "" {
+		if filter.hashtag = entities.NormalizeHashtag(hashtag); filter.hashtag == "" {
+			return streamFilter{}, fmt.Errorf("invalid hashtag")
+		}
+	}
+	if timeline {
+		if !viewer.Valid {
+			return streamFilter{}, errNotLoggedIn
+		}
+		authors, err := cfg.dbQueries.GetTimelineAuthors(ctx, viewer.UUID)
+		if err != nil {
+			return streamFilter{}, err
+		}
+		filter.authors = make(map[uuid.UUID]bool, len(authors))
+		for _, id := range authors {
+			filter.authors[id] = true
+		}
+	}
+	return filter, nil
+}
+
+// chirpCursor is where a chirp sits in creation order. It doubles as the SSE
+// event ID, so a client's Last-Event-ID says exactly where to resume.
+func chirpCursor(chirp database.Chirp) pageCursor {
+	return pageCursor{time: chirp.CreatedAt, id: chirp.ID}
+}
+
+func (cur pageCursor) before(other pageCursor) bool {
+	return cur.time.Before(other.time) || (cur.time.Equal(other.time) && bytes.Compare(cur.id[:], other.id[:]) < 0)
+}
+
+// chirpStream sends new chirps to one client, as server-sent events or over gRPC.
+type chirpStream struct {
+	cfg    *apiConfig
+	viewer uuid.NullUUID
+	filter streamFilter
+	// replayed is the newest chirp already sent from the database on resume.
+	replayed pageCursor
+	// emit sends a chirp that passed the filter, as the viewer sees it.
+	emit func(cursor pageCursor, chirp *chirpResp) error
+	// heartbeat, if set, is called whenever streamHeartbeat passes.
+	heartbeat func() error
+}
+
+// liveChirp converts a chirp pushed to a live connection, or returns nil if
+// viewer should not see it because of a block or a muted word.
+func (cfg *apiConfig) liveChirp(ctx context.Context, viewer uuid.NullUUID, chirp database.Chirp) (*chirpResp, error) {
+	blocked, err := cfg.blockedAmong(ctx, viewer, []uuid.UUID{chirp.UserID})
+	if err != nil || blocked[chirp.UserID] {
+		return nil, err
+	}
+	jsonChirps, err := cfg.chirpsConv(ctx, viewer, []database.Chirp{chirp})
+	if err == nil {
+		jsonChirps, err = cfg.filterChirps(ctx, viewer, jsonChirps)
+	}
+	if err != nil || len(jsonChirps) == 0 {
+		return nil, err
+	}
+	return &jsonChirps[0], nil
+}
+
+// send emits chirp if it passes the filter and is visible to the viewer.
+func (stream *chirpStream) send(ctx context.Context, chirp database.Chirp) error {
+	if !stream.filter.matches(chirp) {
+		return nil
+	}
+	jsonChirp, err := stream.cfg.liveChirp(ctx, stream.viewer, chirp)
+	if err != nil || jsonChirp == nil {
+		return err
+	}
+	return stream.emit(chirpCursor(chirp), jsonChirp)
+}
+
+// replay sends everything created after the client's last event, a page at a time.
+func (stream *chirpStream) replay(ctx context.Context) error {
+	for {
+		chirps, err := stream.cfg.dbQueries.GetChirpsAfter(ctx, database.GetChirpsAfterParams{AfterTime: stream.replayed.time,
+			AfterID: stream.replayed.id, ViewerID: stream.viewer, PageSize: streamReplayPage})
+		if err != nil {
+			return err
+		}
+		for _, chirp := range chirps {
+			if err = stream.send(ctx, chirp); err != nil {
+				return err
+			}
+			stream.replayed = chirpCursor(chirp)
+		}
+		if len(chirps) < streamReplayPage {
+			return nil
+		}
+	}
+}
+
+// run sends chirps from events until ctx is done or the client falls too far
+// behind. If the client is resuming, it first replays what was missed; it must
+// subscribe to events before calling run so nothing created in between is lost.
+func (stream *chirpStream) run(ctx context.Context, events <-chan broker.Event, resume bool) error {
+	if resume {
+		if err := stream.replay(ctx); err != nil {
+			return err
+		}
+	}
+	var beats <-chan time.Time
+	if stream.heartbeat != nil {
+		ticker := time.NewTicker(streamHeartbeat)
+		defer ticker.Stop()
+		beats = ticker.C
+	}
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-beats:
+			err = stream.heartbeat()
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			// Skip live chirps the replay already covered.
+			if event.Chirp != nil && stream.replayed.before(chirpCursor(*event.Chirp)) {
+				err = stream.send(ctx, *event.Chirp)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handleStreamChirps pushes new chirps as server-sent events until the client goes
+// away or falls too far behind.
+func (cfg *apiConfig) handleStreamChirps(writer http.ResponseWriter, req *http.Request) {
+	viewer := cfg.optionalUser(req.Header)
+	filter, err := cfg.parseStreamFilter(req.Context(), req.URL.Query(), viewer)
+	if errors.Is(err, errNotLoggedIn) {
+		writer.Header()["Content-Type"] = []string{jsonContent}
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	} else if err != nil {
+		writer.Header()["Content-Type"] = []string{jsonContent}
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	controller := http.NewResponseController(writer)
+	stream := &chirpStream{cfg: cfg, viewer: viewer, filter: filter}
+	stream.emit = func(cursor pageCursor, chirp *chirpResp) error {
+		data, err := json.Marshal(chirp)
+		if err != nil {
+			return err
+		}
+		if _, err = fmt.Fprintf(writer, "id: %s\nevent: chirp\ndata: %s\n\n", cursor, data); err != nil {
+			return err
+		}
+		return controller.Flush()
+	}
+	stream.heartbeat = func() error {
+		if _, err := fmt.Fprint(writer, ": heartbeat\n\n"); err != nil {
+			return err
+		}
+		return controller.Flush()
+	}
+	resume := req.Header.Get("Last-Event-ID")
+	if resume != "" {
+		if stream.replayed, err = parseCursor(resume); err != nil {
+			writer.Header()["Content-Type"] = []string{jsonContent}
+			handleError(writer, req, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	events, cancel := cfg.broker.Subscribe(streamBuffer, broker.Chirps)
+	defer cancel()
+	writer.Header()["Content-Type"] = []string{eventStreamContent}
+	writer.Header()["Cache-Control"] = []string{"no-cache"}
+	writer.WriteHeader(http.StatusOK)
+	if err = controller.Flush(); err != nil {
+		return
+	}
+	stream.run(req.Context(), events, resume != "")
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"chirpy/internal/broker"
+	"chirpy/internal/database"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+// streamWriter hands each write to the test as it happens, and holds the
+// stream up until the test takes it, like a client that is slow to read.
+type streamWriter struct {
+	ctx    context.Context
+	header http.Header
+	status chan int
+	writes chan string
+}
+
+func (w *streamWriter) Header() http.Header  { return w.header }
+func (w *streamWriter) WriteHeader(code int) { w.status <- code }
+func (w *streamWriter) Flush()               {}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	select {
+	case w.writes <- string(p):
+		return len(p), nil
+	case <-w.ctx.Done():
+		return 0, w.ctx.Err()
+	}
+}
+
+// next returns the next thing the stream writes.
+func (w *streamWriter) next(t *testing.T) string {
+	t.Helper()
+	select {
+	case write := <-w.writes:
+		return write
+	case <-time.After(time.Second):
+		t.Fatal("stream wrote nothing")
+		return ""
+	}
+}
+
+// nextChirp returns the ID and chirp of the next event the stream writes.
+func (w *streamWriter) nextChirp(t *testing.T) (string, chirpResp) {
+	t.Helper()
+	var id string
+	var chirp chirpResp
+	for _, line := range strings.Split(w.next(t), "\n") {
+		if value, ok := strings.CutPrefix(line, "id: "); ok {
+			id = value
+		} else if value, ok := strings.CutPrefix(line, "data: "); ok {
+			if err := json.Unmarshal([]byte(value), &chirp); err != nil {
+				t.Fatalf("event data %q: %v", value, err)
+			}
+		}
+	}
+	return id, chirp
+}
+
+// openStream starts streaming path with the given headers and waits until the
+// stream has subscribed. It returns a channel closed once the handler returns.
+func openStream(t *testing.T, cfg *apiConfig, path string, header http.Header) (*streamWriter, <-chan struct{}) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, path, nil).WithContext(ctx)
+	req.Header = header
+	writer := &streamWriter{ctx: ctx, header: http.Header{}, status: make(chan int, 1), writes: make(chan string)}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cfg.routes().ServeHTTP(writer, req)
+	}()
+	// Runs before mockConfig checks its expectations.
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	select {
+	case code := <-writer.status:
+		if code != http.StatusOK {
+			t.Fatalf("GET %s = %d, want 200", path, code)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("GET %s never started streaming", path)
+	}
+	return writer, done
+}
+
+// TestStreamReplay checks that a client resuming with Last-Event-ID gets the
+// chirps it missed from the database, then the live ones it has not seen.
+func TestStreamReplay(t *testing.T) {
+	cfg, mock := mockConfig(t)
+	author := uuid.New()
+	now := time.Now().UTC()
+	chirp := func(body string, age time.Duration) database.Chirp {
+		return database.Chirp{ID: uuid.New(), CreatedAt: now.Add(-age), UpdatedAt: now.Add(-age), Body: body,
+			UserID: author, Kind: chirpKindChirp}
+	}
+	seen, missed, live := chirp("seen", 2*time.Minute), chirp("missed", time.Minute), chirp("live", 0)
+	mock.ExpectQuery("name: GetChirpsAfter ").WithArgs(seen.CreatedAt, seen.ID, uuid.NullUUID{}, streamReplayPage).
+		WillReturnRows(chirpRows(missed))
+	expectChirpDetails(mock, database.GetUserHandlesRow{ID: author, Handle: "alice"})
+	expectChirpDetails(mock, database.GetUserHandlesRow{ID: author, Handle: "alice"})
+
+	stream, _ := openStream(t, cfg, "/api/v2/stream/chirps", http.Header{"Last-Event-Id": {chirpCursor(seen).String()}})
+	id, got := stream.nextChirp(t)
+	if id != chirpCursor(missed).String() || got.Body != missed.Body {
+		t.Errorf("replayed %s %+v, want %q", id, got, missed.Body)
+	}
+	for _, chirp := range []database.Chirp{missed, live} {
+		cfg.broker.Publish(context.Background(), broker.Event{Chirp: &chirp})
+	}
+	if id, got = stream.nextChirp(t); id != chirpCursor(live).String() || got.Body != live.Body {
+		t.Errorf("streamed %s %+v, want %q and not the replayed chirp again", id, got, live.Body)
+	}
+}
+
+// TestStreamFilters checks that a stream only sends the chirps its author,
+// hashtag or timeline filter asks for.
+func TestStreamFilters(t *testing.T) {
+	alice, bob, viewer := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now().UTC()
+	chirp := func(author uuid.UUID, body string) database.Chirp {
+		return database.Chirp{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Body: body, UserID: author,
+			Kind: chirpKindChirp}
+	}
+	tests := []struct {
+		name   string
+		query  string
+		viewer uuid.UUID
+		skip   database.Chirp
+		want   database.Chirp
+	}{
+		{"author", "?author_id=" + alice.String(), uuid.Nil, chirp(bob, "bob here"), chirp(alice, "alice here")},
+		{"hashtag", "?hashtag=%23GoLang", uuid.Nil, chirp(alice, "#rust"), chirp(bob, "#golang rocks")},
+		{"timeline", "?timeline=true", viewer, chirp(bob, "not followed"), chirp(alice, "followed")},
+	}
+	for _, tc := range tests {
+		cfg, mock := mockConfig(t)
+		header := http.Header{}
+		if tc.viewer != uuid.Nil {
+			header.Set("Authorization", authHeader(t, tc.viewer))
+			mock.ExpectQuery("name: GetTimelineAuthors ").WithArgs(tc.viewer).
+				WillReturnRows(sqlmock.NewRows([]string{"followee_id"}).AddRow(alice))
+			mock.ExpectQuery("name: GetBlockedAmong ").WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+		}
+		expectChirpDetails(mock, database.GetUserHandlesRow{ID: tc.want.UserID, Handle: "someone"})
+		if tc.viewer != uuid.Nil {
+			mock.ExpectQuery("name: GetMutedWords ").WithArgs(tc.viewer).WillReturnRows(sqlmock.NewRows(
+				[]string{"id", "user_id", "phrase", "whole_word", "action", "expires_at", "created_at"}))
+		}
+
+		stream, _ := openStream(t, cfg, "/api/v2/stream/chirps"+tc.query, header)
+		for _, chirp := range []database.Chirp{tc.skip, tc.want} {
+			cfg.broker.Publish(context.Background(), broker.Event{Chirp: &chirp})
+		}
+		if _, got := stream.nextChirp(t); got.Body != tc.want.Body {
+			t.Errorf("%s: streamed %+v, want %q", tc.name, got, tc.want.Body)
+		}
+	}
+
+	cfg, _ := mockConfig(t)
+	for query, want := range map[string]int{
+		"?timeline=true":    http.StatusUnauthorized,
+		"?author_id=nobody": http.StatusBadRequest,
+		"?hashtag=%23":      http.StatusBadRequest,
+	} {
+		recorder := httptest.NewRecorder()
+		cfg.routes().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v2/stream/chirps"+query, nil))
+		if recorder.Code != want {
+			t.Errorf("GET /api/v2/stream/chirps%s = %d, want %d", query, recorder.Code, want)
+		}
+	}
+}
+
+// TestStreamHeartbeat checks that an idle stream keeps sending comments.
+func TestStreamHeartbeat(t *testing.T) {
+	defer func(interval time.Duration) { streamHeartbeat = interval }(streamHeartbeat)
+	streamHeartbeat = 10 * time.Millisecond
+	cfg, _ := mockConfig(t)
+	stream, _ := openStream(t, cfg, "/api/v2/stream/chirps", http.Header{})
+	for range 2 {
+		if got := stream.next(t); got != ": heartbeat\n\n" {
+			t.Errorf("idle stream wrote %q, want a heartbeat", got)
+		}
+	}
+}
+
+// TestStreamDropsSlowClient checks that a client that stops reading is cut off
+// once it falls a whole buffer behind, after what it had been sent already.
+func TestStreamDropsSlowClient(t *testing.T) {
+	cfg, mock := mockConfig(t)
+	alice := uuid.New()
+	now := time.Now().UTC()
+	first := database.Chirp{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Body: "first", UserID: alice,
+		Kind: chirpKindChirp}
+	expectChirpDetails(mock, database.GetUserHandlesRow{ID: alice, Handle: "alice"})
+
+	// The stream holds on to the first chirp until it is read, so the chirps
+	// after it, which the filter leaves out, overflow its buffer.
+	stream, done := openStream(t, cfg, "/api/v2/stream/chirps?author_id="+alice.String(), http.Header{})
+	cfg.broker.Publish(context.Background(), broker.Event{Chirp: &first})
+	for range streamBuffer + 1 {
+		cfg.broker.Publish(context.Background(), broker.Event{Chirp: &database.Chirp{ID: uuid.New(),
+			CreatedAt: now, UserID: uuid.New()}})
+	}
+	if _, got := stream.nextChirp(t); got.Body != first.Body {
+		t.Errorf("streamed %+v, want %q", got, first.Body)
+	}
+	select {
+	case <-done:
+	case write := <-stream.writes:
+		t.Errorf("dropped stream wrote %q", write)
+	case <-time.After(time.Second):
+		t.Error("stream was not dropped when its buffer overflowed")
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"chirpy/internal/database"
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// userProfile is what anyone may see of a user. It must never carry the email.
+type userProfile struct {
+	Id          uuid.UUID `json:"id"`
+	Handle      string    `json:"handle"`
+	DisplayName string    `json:"display_name"`
+	Bio         string    `json:"bio"`
+	AvatarUrl   string    `json:"avatar_url"`
+	JoinedAt    time.Time `json:"joined_at"`
+	ChirpCount  int64     `json:"chirp_count"`
+}
+
+// profilePatch holds the profile fields a user may change. Fields left out of
+// the request are left as they are.
+type profilePatch struct {
+	DisplayName *string `json:"display_name"`
+	Bio         *string `json:"bio"`
+	AvatarUrl   *string `json:"avatar_url"`
+}
+
+const displayNameLimit = 50
+const bioLimit = 160
+const avatarUrlLimit = 2048
+
+func profileConv(dbProfile database.GetUserProfileRow) userProfile {
+	return userProfile{Id: dbProfile.ID, Handle: dbProfile.Handle, DisplayName: dbProfile.DisplayName, Bio: dbProfile.Bio,
+		AvatarUrl: dbProfile.AvatarUrl, JoinedAt: dbProfile.CreatedAt, ChirpCount: dbProfile.ChirpCount}
+}
+
+func optionalString(field *string) sql.NullString {
+	if field == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *field, Valid: true}
+}
+
+func (patch profilePatch) validate() error {
+	if patch.DisplayName != nil && utf8.RuneCountInString(*patch.DisplayName) > displayNameLimit {
+		return fmt.Errorf("display name must be at most %d characters", displayNameLimit)
+	}
+	if patch.Bio != nil && utf8.RuneCountInString(*patch.Bio) > bioLimit {
+		return fmt.Errorf("bio must be at most %d characters", bioLimit)
+	}
+	if patch.AvatarUrl != nil && *patch.AvatarUrl != "" {
+		if len(*patch.AvatarUrl) > avatarUrlLimit {
+			return fmt.Errorf("avatar URL must be at most %d characters", avatarUrlLimit)
+		}
+		avatar, err := url.Parse(*patch.AvatarUrl)
+		if err != nil || (avatar.Scheme != "https" && avatar.Scheme != "http") || avatar.Host == "" {
+			return fmt.Errorf("avatar URL must be an absolute http or https URL")
+		}
+	}
+	return nil
+}
+
+func (cfg *apiConfig) profileRespond(writer http.ResponseWriter, req *http.Request, code int, msg string, id uuid.UUID) {
+	profile, err := cfg.loadProfile(req.Context(), id)
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, "User not found")
+		return
+	}
+	handleJsonWrite(writer, code, msg, profile)
+}
+
+func (cfg *apiConfig) loadProfile(ctx context.Context, id uuid.UUID) (userProfile, error) {
+	profile, err := cfg.dbQueries.GetUserProfile(ctx, id)
+	if err != nil {
+		return userProfile{}, err
+	}
+	return profileConv(profile), nil
+}
+
+func (cfg *apiConfig) handleGetUser(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	id, err := parseID(req)
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, err.Error())
+		return
+	}
+	cfg.profileRespond(writer, req, http.StatusOK, "GetUser", id)
+}
+
+func (cfg *apiConfig) handleUserByHandle(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	handle := req.PathValue("handle")
+	user, err := cfg.dbQueries.GetUserByHandle(req.Context(), handle)
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, "User not found")
+		return
+	}
+	cfg.profileRespond(writer, req, http.StatusOK, handle, user.ID)
+}
+
+func (cfg *apiConfig) handlePatchProfile(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	msg := profilePatch{}
+	if !decodeRequest(writer, req, &msg) {
+		return
+	}
+	id, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	err = cfg.dbQueries.UpdateUserProfile(req.Context(), database.UpdateUserProfileParams{DisplayName: optionalString(msg.DisplayName),
+		Bio: optionalString(msg.Bio), AvatarUrl: optionalString(msg.AvatarUrl), ID: id})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	cfg.profileRespond(writer, req, http.StatusOK, "profile", id)
+}
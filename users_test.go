@@ -0,0 +1,162 @@
+package main
+
+import (
+	"chirpy/internal/database"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+var profileColumns = []string{"id", "created_at", "handle", "display_name", "bio", "avatar_url", "chirp_count"}
+
+// TestUserRoutes checks that /users/by-handle/{handle} finds a profile even for
+// a handle that names one of the lists under /users/{id}/, which still route.
+func TestUserRoutes(t *testing.T) {
+	cfg, mock := mockConfig(t)
+	routes := cfg.routes()
+	id := uuid.New()
+	now := time.Now().UTC()
+	mock.ExpectQuery("name: GetUserByHandle ").WithArgs("Followers").WillReturnRows(userRows(database.User{ID: id,
+		CreatedAt: now, UpdatedAt: now, Email: "f@example.com", Handle: "followers"}))
+	mock.ExpectQuery("name: GetUserProfile ").WithArgs(id).WillReturnRows(sqlmock.NewRows(profileColumns).
+		AddRow(id, now, "followers", "", "", "", 0))
+	mock.ExpectQuery("name: GetFollowers ").WithArgs(id, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "handle", "display_name", "avatar_url", "followed_at"}))
+	mock.ExpectQuery("name: GetUserByHandle ").WithArgs("nobody").WillReturnError(sql.ErrNoRows)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/api/v2/users/by-handle/Followers", http.StatusOK},
+		{"/api/v2/users/" + id.String() + "/followers", http.StatusOK},
+		{"/api/v2/users/" + id.String() + "/likes", http.StatusNotFound},
+		{"/api/v2/users/by-handle/nobody", http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		recorder := httptest.NewRecorder()
+		routes.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if recorder.Code != tc.want {
+			t.Errorf("GET %s = %d, want %d: %s", tc.path, recorder.Code, tc.want, recorder.Body)
+		}
+	}
+}
+
+// TestGetUser checks that a profile shows only what anyone may see of a user,
+// with their chirp count, and that a missing user is not found.
+func TestGetUser(t *testing.T) {
+	cfg, mock := mockConfig(t)
+	routes := cfg.routes()
+	id, missing := uuid.New(), uuid.New()
+	now := time.Now().UTC()
+	mock.ExpectQuery("name: GetUserProfile ").WithArgs(id).WillReturnRows(sqlmock.NewRows(profileColumns).
+		AddRow(id, now, "alice", "Alice", "hi", "https://example.com/a.png", 42))
+	mock.ExpectQuery("name: GetUserProfile ").WithArgs(missing).WillReturnError(sql.ErrNoRows)
+
+	recorder := httptest.NewRecorder()
+	routes.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/users/"+id.String(), nil))
+	var fields map[string]any
+	if err := json.Unmarshal(recorder.Body.Bytes(), &fields); err != nil || recorder.Code != http.StatusOK {
+		t.Fatalf("GET /api/users/{id} = %d, %q: %v", recorder.Code, recorder.Body, err)
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	if want := []string{"avatar_url", "bio", "chirp_count", "display_name", "handle", "id", "joined_at"}; !slices.Equal(keys, want) {
+		t.Errorf("GET /api/users/{id} fields = %v, want %v", keys, want)
+	}
+	if fields["chirp_count"] != 42.0 || fields["handle"] != "alice" {
+		t.Errorf("GET /api/users/{id} = %v, want alice with 42 chirps", fields)
+	}
+
+	for _, path := range []string{"/api/users/" + missing.String(), "/api/users/not-a-uuid"} {
+		recorder = httptest.NewRecorder()
+		routes.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", path, recorder.Code)
+		}
+	}
+}
+
+// TestPatchProfile checks that each profile field is checked on its own, that
+// fields left out are left alone, and that the email and password cannot be
+// changed this way.
+func TestPatchProfile(t *testing.T) {
+	id := uuid.New()
+	now := time.Now().UTC()
+	set := func(value string) sql.NullString { return sql.NullString{String: value, Valid: true} }
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		want   int
+		detail string
+		update database.UpdateUserProfileParams
+	}{
+		{"display name", "/api/v2/users/me", `{"display_name":"` + strings.Repeat("é", displayNameLimit) + `"}`,
+			http.StatusOK, "", database.UpdateUserProfileParams{DisplayName: set(strings.Repeat("é", displayNameLimit))}},
+		{"display name too long", "/api/v2/users/me", `{"display_name":"` + strings.Repeat("é", displayNameLimit+1) + `"}`,
+			http.StatusBadRequest, "display name must be at most 50 characters", database.UpdateUserProfileParams{}},
+		{"bio", "/api/v2/users/me", `{"bio":"hello"}`,
+			http.StatusOK, "", database.UpdateUserProfileParams{Bio: set("hello")}},
+		{"bio too long", "/api/v2/users/me", `{"bio":"` + strings.Repeat("b", bioLimit+1) + `"}`,
+			http.StatusBadRequest, "bio must be at most 160 characters", database.UpdateUserProfileParams{}},
+		{"avatar", "/api/v2/users/me", `{"avatar_url":"https://example.com/a.png"}`,
+			http.StatusOK, "", database.UpdateUserProfileParams{AvatarUrl: set("https://example.com/a.png")}},
+		{"avatar cleared", "/api/v2/users/me", `{"avatar_url":""}`,
+			http.StatusOK, "", database.UpdateUserProfileParams{AvatarUrl: set("")}},
+		{"avatar not http", "/api/v2/users/me", `{"avatar_url":"ftp://example.com/a.png"}`,
+			http.StatusBadRequest, "avatar URL must be an absolute http or https URL", database.UpdateUserProfileParams{}},
+		{"avatar too long", "/api/v2/users/me", `{"avatar_url":"https://example.com/` + strings.Repeat("a", avatarUrlLimit) + `"}`,
+			http.StatusBadRequest, "avatar URL must be at most 2048 characters", database.UpdateUserProfileParams{}},
+		{"email on v2", "/api/v2/users/me", `{"bio":"hello","email":"mallory@example.com"}`,
+			http.StatusBadRequest, "email is not a known field", database.UpdateUserProfileParams{}},
+		{"email and password on v1", "/api/users/me", `{"bio":"hello","email":"mallory@example.com","password":"pwned"}`,
+			http.StatusOK, "", database.UpdateUserProfileParams{Bio: set("hello")}},
+	}
+	for _, tc := range tests {
+		cfg, mock := mockConfig(t)
+		if tc.want == http.StatusOK {
+			tc.update.ID = id
+			// The update only ever names the profile columns.
+			mock.ExpectExec(`name: UpdateUserProfile [^$]*SET display_name = COALESCE\(\$1, display_name\), `+
+				`bio = COALESCE\(\$2, bio\),\s+avatar_url = COALESCE\(\$3, avatar_url\), updated_at = NOW\(\)\s+WHERE id = \$4`).
+				WithArgs(tc.update.DisplayName, tc.update.Bio, tc.update.AvatarUrl, tc.update.ID).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery("name: GetUserProfile ").WithArgs(id).WillReturnRows(sqlmock.NewRows(profileColumns).
+				AddRow(id, now, "alice", "", "", "", 0))
+		}
+
+		req := httptest.NewRequest(http.MethodPatch, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", jsonContent)
+		req.Header.Set("Authorization", authHeader(t, id))
+		recorder := httptest.NewRecorder()
+		cfg.routes().ServeHTTP(recorder, req)
+		if recorder.Code != tc.want {
+			t.Errorf("%s: PATCH %s = %d, want %d: %s", tc.name, tc.path, recorder.Code, tc.want, recorder.Body)
+		}
+		var got problem
+		if err := json.Unmarshal(recorder.Body.Bytes(), &got); tc.detail != "" && (err != nil || got.Detail != tc.detail) {
+			t.Errorf("%s: PATCH %s = %s, want %q", tc.name, tc.path, recorder.Body, tc.detail)
+		}
+	}
+
+	cfg, _ := mockConfig(t)
+	req := httptest.NewRequest(http.MethodPatch, "/api/v2/users/me", strings.NewReader(`{"bio":"hello"}`))
+	req.Header.Set("Content-Type", jsonContent)
+	recorder := httptest.NewRecorder()
+	cfg.routes().ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("PATCH /api/v2/users/me without a token = %d, want 401", recorder.Code)
+	}
+}
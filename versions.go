@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiVersion selects which behaviour a shared handler gives. Version 1 is
+// what the API did before it was versioned and must not change; fixes that
+// would break its clients go into version 2 only.
+type apiVersion int
+
+const apiV1 apiVersion = 1
+const apiV2 apiVersion = 2
+
+// apiSurface is the prefix a version's routes are served under.
+type apiSurface struct {
+	prefix  string
+	version apiVersion
+}
+
+// apiVersions lists every surface. Unversioned /api is the original API, kept
+// as an alias of v1 so existing clients keep working.
+var apiVersions = []apiSurface{{"/api", apiV1}, {"/api/v1", apiV1}, {"/api/v2", apiV2}}
+
+const apiV1DeprecatedEnv = "API_V1_DEPRECATED"
+const apiV1SunsetEnv = "API_V1_SUNSET"
+
+// apiDeprecation announces that a version is going away. A zero time is not
+// announced.
+type apiDeprecation struct {
+	deprecated time.Time
+	sunset     time.Time
+}
+
+// parseDeprecation reads the RFC 3339 times in the named environment variables.
+func parseDeprecation(deprecatedEnv, sunsetEnv string) (apiDeprecation, error) {
+	dep := apiDeprecation{}
+	for _, field := range []struct {
+		env string
+		at  *time.Time
+	}{{deprecatedEnv, &dep.deprecated}, {sunsetEnv, &dep.sunset}} {
+		value := os.Getenv(field.env)
+		if value == "" {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return apiDeprecation{}, fmt.Errorf("%s: %w", field.env, err)
+		}
+		*field.at = at
+	}
+	return dep, nil
+}
+
+type versionKey struct{}
+
+// requestVersion is the API version a request was routed to. Requests that
+// did not come through a versioned route get version 1.
+func requestVersion(req *http.Request) apiVersion {
+	if version, ok := req.Context().Value(versionKey{}).(apiVersion); ok {
+		return version
+	}
+	return apiV1
+}
+
+// versioned records the version in the request and, for a deprecated version,
+// adds the Deprecation (RFC 9745), Sunset (RFC 8594) and successor Link headers.
+func (cfg *apiConfig) versioned(surface apiSurface, next func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(writer http.ResponseWriter, req *http.Request) {
+		if surface.version == apiV1 {
+			dep := cfg.v1Deprecation
+			if !dep.deprecated.IsZero() {
+				writer.Header().Set("Deprecation", "@"+strconv.FormatInt(dep.deprecated.Unix(), 10))
+			}
+			if !dep.sunset.IsZero() {
+				writer.Header().Set("Sunset", dep.sunset.UTC().Format(http.TimeFormat))
+			}
+			if !dep.deprecated.IsZero() || !dep.sunset.IsZero() {
+				successor := "/api/v2" + strings.TrimPrefix(req.URL.Path, surface.prefix)
+				writer.Header().Add("Link", "<"+cfg.publicURL+successor+`>; rel="successor-version"`)
+			}
+		}
+		next(writer, req.WithContext(context.WithValue(req.Context(), versionKey{}, surface.version)))
+	}
+}
+
+// routePattern is the path of the route req was served by, without the prefix
+// of its version, so that /api/chirps and /api/v1/chirps are both /chirps. A
+// request that did not come through a route gets its own path.
+func routePattern(req *http.Request) string {
+	_, path, found := strings.Cut(req.Pattern, " ")
+	if !found {
+		path = req.Pattern
+	}
+	if path == "" {
+		return req.URL.Path
+	}
+	prefix := ""
+	for _, surface := range apiVersions {
+		if strings.HasPrefix(path, surface.prefix+"/") && len(surface.prefix) > len(prefix) {
+			prefix = surface.prefix
+		}
+	}
+	return strings.TrimPrefix(path, prefix)
+}
+
+// versionMux registers routes relative to one version's prefix.
+type versionMux struct {
+	cfg     *apiConfig
+	mux     *routeMux
+	surface apiSurface
+}
+
+func (api versionMux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	method, path, _ := strings.Cut(pattern, " ")
+	api.mux.HandleFunc(method+" "+api.surface.prefix+path,
+		api.cfg.middlewareMetricsInc(api.cfg.versioned(api.surface, handler)))
+}
+
+// HandleLists registers the lists under pattern as a single {list} route that
+// dispatches on the last path segment, so that a literal sibling of a wildcard
+// in pattern, such as /users/by-handle/{handle} beside /users/{id}, is the more
+// specific route instead of a conflicting one. Each list is still recorded
+// under its own pattern.
+func (api versionMux) HandleLists(pattern string, lists map[string]http.HandlerFunc) {
+	method, path, _ := strings.Cut(pattern, " ")
+	path = api.surface.prefix + path
+	for list := range lists {
+		api.mux.patterns = append(api.mux.patterns, method+" "+path+"/"+list)
+	}
+	api.mux.ServeMux.HandleFunc(method+" "+path+"/{list}", api.cfg.middlewareMetricsInc(api.cfg.versioned(api.surface,
+		func(writer http.ResponseWriter, req *http.Request) {
+			handler, ok := lists[req.PathValue("list")]
+			if !ok {
+				writer.Header()["Content-Type"] = []string{jsonContent}
+				handleError(writer, req, http.StatusNotFound, "Not found")
+				return
+			}
+			handler(writer, req)
+		})))
+}
+
+// statusError writes a bare status code in v1, which clients of some endpoints
+// rely on, and a problem document from v2 on.
+func statusError(writer http.ResponseWriter, req *http.Request, code int, msg string) {
+	if requestVersion(req) == apiV1 {
+		writer.WriteHeader(code)
+		return
+	}
+	handleError(writer, req, code, msg)
+}
+
+// statusFault is statusError for a fault on our side, which is logged rather than sent.
+func statusFault(writer http.ResponseWriter, req *http.Request, code int, err error) {
+	if requestVersion(req) == apiV1 {
+		logFault(req, err)
+		writer.WriteHeader(code)
+		return
+	}
+	handleFault(writer, req, code, err)
+}
+
+// versionedStatus is v1's status code in v1 and fixed's from v2 on.
+func versionedStatus(req *http.Request, v1, fixed int) int {
+	if requestVersion(req) == apiV1 {
+		return v1
+	}
+	return fixed
+}
+
+// apiRoutes registers one version's routes. Only the health check and the
+// OpenAPI document are outside the versioned API.
+func (cfg *apiConfig) apiRoutes(mux *routeMux, surface apiSurface) {
+	api := versionMux{cfg: cfg, mux: mux, surface: surface}
+	// Only writes that would happen twice take an Idempotency-Key. Logging in and
+	// refreshing answer with tokens, which must not be kept, and revoking and
+	// PUT /users come out the same however often they are sent.
+	api.HandleFunc("POST /chirps", cfg.idempotent(cfg.callerKeys, cfg.handleMakeChirp))
+	api.HandleFunc("POST /users", cfg.idempotent(signupKeys, cfg.handleCreateUser))
+	api.HandleFunc("GET /chirps", cfg.handleGetChirps)
+	api.HandleFunc("GET /chirps/{id}", cfg.handleGetChirp)
+	api.HandleFunc("POST /login", cfg.handleLogin)
+	api.HandleFunc("POST /refresh", cfg.handleRefresh)
+	api.HandleFunc("POST /revoke", cfg.handleRevoke)
+	api.HandleFunc("PUT /users", cfg.handleUserPut)
+	api.HandleFunc("PUT /chirps/{id}", cfg.handleEditChirp)
+	api.HandleFunc("DELETE /chirps/{id}", cfg.handleDeleteChirp)
+	api.HandleFunc("POST /chirps/{id}/rechirp", cfg.handleRechirp)
+	api.HandleFunc("POST /chirps/{id}/quote", cfg.handleQuoteChirp)
+	api.HandleFunc("POST /chirps/{id}/replies", cfg.handleReply)
+	api.HandleFunc("GET /chirps/{id}/replies", cfg.handleGetReplies)
+	api.HandleFunc("POST /chirps/{id}/like", cfg.handleLike)
+	api.HandleFunc("DELETE /chirps/{id}/like", cfg.handleUnlike)
+	api.HandleFunc("GET /hashtags/{tag}/chirps", cfg.handleHashtagChirps)
+	api.HandleFunc("GET /trending", cfg.handleTrending)
+	api.HandleFunc("GET /users/{id}", cfg.handleGetUser)
+	api.HandleFunc("PATCH /users/me", cfg.handlePatchProfile)
+	api.HandleFunc("GET /users/by-handle/{handle}", cfg.handleUserByHandle)
+	api.HandleLists("GET /users/{id}", map[string]http.HandlerFunc{"mentions": cfg.handleUserMentions,
+		"followers": cfg.handleFollowers, "following": cfg.handleFollowing})
+	api.HandleFunc("POST /users/{id}/follow", cfg.handleFollow)
+	api.HandleFunc("DELETE /users/{id}/follow", cfg.handleUnfollow)
+	api.HandleFunc("POST /users/{id}/block", cfg.handleBlock)
+	api.HandleFunc("DELETE /users/{id}/block", cfg.handleUnblock)
+	api.HandleFunc("POST /users/{id}/mute", cfg.handleMute)
+	api.HandleFunc("DELETE /users/{id}/mute", cfg.handleUnmute)
+	api.HandleFunc("GET /blocks", cfg.handleListBlocks)
+	api.HandleFunc("GET /mutes", cfg.handleListMutes)
+	api.HandleFunc("GET /muted-words", cfg.handleListMutedWords)
+	api.HandleFunc("POST /muted-words", cfg.handleAddMutedWord)
+	api.HandleFunc("DELETE /muted-words/{id}", cfg.handleDeleteMutedWord)
+	api.HandleFunc("GET /timeline", cfg.handleTimeline)
+	api.HandleFunc("POST /conversations", cfg.handleStartConversation)
+	api.HandleFunc("GET /conversations", cfg.handleGetConversations)
+	api.HandleFunc("GET /conversations/{id}/messages", cfg.handleGetMessages)
+	api.HandleFunc("POST /conversations/{id}/messages", cfg.handleSendMessage)
+	api.HandleFunc("POST /conversations/{id}/read", cfg.handleReadConversation)
+	api.HandleFunc("GET /stream/chirps", cfg.handleStreamChirps)
+	api.HandleFunc("GET /ws", cfg.handleWebSocket)
+	api.HandleFunc("POST /polka/webhooks", cfg.handlePolkaWebhook)
+	api.HandleFunc("GET /webhooks", cfg.handleListWebhooks)
+	api.HandleFunc("POST /webhooks", cfg.handleAddWebhook)
+	api.HandleFunc("DELETE /webhooks/{id}", cfg.handleDeleteWebhook)
+	api.HandleFunc("GET /webhooks/{id}/deliveries", cfg.handleWebhookDeliveries)
+	api.HandleFunc("POST /webhooks/{id}/deliveries/{delivery_id}/redeliver", cfg.handleRedeliver)
+	api.HandleFunc("GET /notifications", cfg.handleNotifications)
+	api.HandleFunc("POST /notifications/read", cfg.handleReadAllNotifications)
+	api.HandleFunc("POST /notifications/{id}/read", cfg.handleReadNotification)
+	api.HandleFunc("GET /federation/follows", cfg.handleListRemoteFollows)
+	api.HandleFunc("POST /federation/follows", cfg.handleFollowRemote)
+	api.HandleFunc("DELETE /federation/follows/{id}", cfg.handleUnfollowRemote)
+	api.HandleFunc("GET /federation/timeline", cfg.handleRemoteTimeline)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"chirpy/internal/database"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestVersionedRoutes(t *testing.T) {
+	deprecated := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC)
+	cfg := &apiConfig{publicURL: "https://chirpy.test",
+		v1Deprecation: apiDeprecation{deprecated: deprecated, sunset: sunset}}
+	routes := cfg.routes()
+	tests := []struct {
+		path       string
+		deprecated bool
+		body       bool
+	}{
+		{"/api/refresh", true, false},
+		{"/api/v1/refresh", true, false},
+		{"/api/v2/refresh", false, true},
+	}
+	for _, tc := range tests {
+		recorder := httptest.NewRecorder()
+		routes.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, tc.path, nil))
+		if recorder.Code != http.StatusUnauthorized {
+			t.Errorf("POST %s = %d, want 401", tc.path, recorder.Code)
+		}
+		if got := recorder.Body.Len() > 0; got != tc.body {
+			t.Errorf("POST %s body = %q", tc.path, recorder.Body)
+		} else if tc.body {
+			var msg problem
+			if err := json.Unmarshal(recorder.Body.Bytes(), &msg); err != nil || msg.Status != http.StatusUnauthorized {
+				t.Errorf("POST %s body = %q, want a problem", tc.path, recorder.Body)
+			}
+		}
+		header := recorder.Header()
+		if !tc.deprecated {
+			if header.Get("Deprecation") != "" || header.Get("Sunset") != "" || header.Get("Link") != "" {
+				t.Errorf("POST %s announces deprecation: %v", tc.path, header)
+			}
+			continue
+		}
+		if got := header.Get("Deprecation"); got != "@1767225600" {
+			t.Errorf("POST %s Deprecation = %q", tc.path, got)
+		}
+		if got := header.Get("Sunset"); got != "Wed, 01 Jul 2026 00:00:00 GMT" {
+			t.Errorf("POST %s Sunset = %q", tc.path, got)
+		}
+		if got := header.Get("Link"); got != `<https://chirpy.test/api/v2/refresh>; rel="successor-version"` {
+			t.Errorf("POST %s Link = %q", tc.path, got)
+		}
+	}
+}
+
+func TestGetChirpsFaultStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() returned error %v", err)
+	}
+	defer db.Close()
+	routes := (&apiConfig{db: db, dbQueries: database.New(db)}).routes()
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/api/chirps", http.StatusBadRequest},
+		{"/api/v1/chirps", http.StatusBadRequest},
+		{"/api/v2/chirps", http.StatusInternalServerError},
+	}
+	for _, tc := range tests {
+		mock.ExpectQuery("name: GetNewestChirps ").WillReturnError(errors.New("connection reset"))
+		recorder := httptest.NewRecorder()
+		routes.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if recorder.Code != tc.want {
+			t.Errorf("GET %s = %d, want %d", tc.path, recorder.Code, tc.want)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRequestVersion(t *testing.T) {
+	if got := requestVersion(httptest.NewRequest(http.MethodGet, "/api/chirps", nil)); got != apiV1 {
+		t.Errorf("unrouted request is version %d, want 1", got)
+	}
+	var got apiVersion
+	handler := (&apiConfig{}).versioned(apiSurface{"/api/v2", apiV2}, func(writer http.ResponseWriter, req *http.Request) {
+		got = requestVersion(req)
+	})
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/api/v2/chirps", nil))
+	if got != apiV2 {
+		t.Errorf("requestVersion() = %d, want 2", got)
+	}
+	if recorder.Header().Get("Deprecation") != "" {
+		t.Error("v1 is announced as deprecated without being configured to be")
+	}
+}
+
+func TestParseDeprecation(t *testing.T) {
+	t.Setenv(apiV1DeprecatedEnv, "2026-01-01T00:00:00Z")
+	t.Setenv(apiV1SunsetEnv, "")
+	dep, err := parseDeprecation(apiV1DeprecatedEnv, apiV1SunsetEnv)
+	if err != nil || !dep.deprecated.Equal(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)) ||
+		!dep.sunset.IsZero() {
+		t.Errorf("parseDeprecation() = %+v, %v", dep, err)
+	}
+	t.Setenv(apiV1SunsetEnv, "next summer")
+	if _, err = parseDeprecation(apiV1DeprecatedEnv, apiV1SunsetEnv); err == nil {
+		t.Error("parseDeprecation() accepts a sunset that is not a time")
+	}
+}
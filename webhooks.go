@@ -0,0 +1,369 @@
+package main
+
+import (
+	"chirpy/internal/auth"
+	"chirpy/internal/database"
+	"chirpy/internal/netguard"
+	"chirpy/internal/webhooks"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type webhookMsg struct {
+	Url        string   `json:"url" validate:"required"`
+	EventTypes []string `json:"event_types" validate:"required"`
+	Secret     string   `json:"secret,omitempty"`
+}
+
+type webhookResp struct {
+	Id        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	webhookMsg
+}
+
+type deliveryResp struct {
+	Id             uuid.UUID       `json:"id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	ResponseStatus *int32          `json:"response_status,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type deliveryPage struct {
+	Deliveries []deliveryResp `json:"deliveries"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// webhookEvent is the body of every delivery. Its Id stays the same when a delivery
+// is retried or redelivered, so receivers can use it to drop duplicates.
+type webhookEvent struct {
+	Id        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// webhookUser is what user events carry; subscribers see the same public fields
+// as anyone looking up a profile, never an email address.
+type webhookUser struct {
+	Id        uuid.UUID `json:"id"`
+	Handle    string    `json:"handle"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const webhookChirpCreated = "chirp.created"
+const webhookChirpUpdated = "chirp.updated"
+const webhookChirpDeleted = "chirp.deleted"
+const webhookUserCreated = "user.created"
+
+const deliveryPending = "pending"
+const deliverySucceeded = "succeeded"
+const deliveryFailed = "failed"
+
+const webhookInterval = 5 * time.Second
+const webhookBatch = 20
+const webhookMaxAttempts = 10
+const webhookSecretMin = 16
+
+// webhookLease is how long a claimed delivery stays hidden from other workers. It
+// only matters if a worker dies mid-attempt; the delivery is then retried once the
+// lease runs out.
+const webhookLease = 5 * time.Minute
+
+var webhookEventTypes = []string{webhookChirpCreated, webhookChirpUpdated, webhookChirpDeleted, webhookUserCreated}
+
+// webhookGuard keeps subscribers from pointing deliveries at this host or the
+// network behind it.
+var webhookGuard = netguard.Guard{}
+
+// webhookClient does not follow redirects: a subscriber has to register the URL
+// that actually accepts deliveries. Its dialer refuses addresses webhookGuard
+// does not allow, however the URL's host resolves by the time it is sent to.
+var webhookClient = &http.Client{
+	Transport:     webhookGuard.Transport(),
+	Timeout:       10 * time.Second,
+	CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+}
+
+func webhookConv(dbHook database.Webhook) webhookResp {
+	return webhookResp{Id: dbHook.ID, CreatedAt: dbHook.CreatedAt, webhookMsg: webhookMsg{Url: dbHook.Url,
+		EventTypes: dbHook.EventTypes}}
+}
+
+func deliveryConv(dbDelivery database.WebhookDelivery) deliveryResp {
+	resp := deliveryResp{Id: dbDelivery.ID, EventType: dbDelivery.EventType, Payload: json.RawMessage(dbDelivery.Payload),
+		Status: dbDelivery.Status, Attempts: dbDelivery.Attempts, CreatedAt: dbDelivery.CreatedAt}
+	if dbDelivery.Status == deliveryPending {
+		resp.NextAttemptAt = &dbDelivery.NextAttemptAt
+	}
+	if dbDelivery.LastAttemptAt.Valid {
+		resp.LastAttemptAt = &dbDelivery.LastAttemptAt.Time
+	}
+	if dbDelivery.ResponseStatus.Valid {
+		resp.ResponseStatus = &dbDelivery.ResponseStatus.Int32
+	}
+	resp.LastError = dbDelivery.LastError.String
+	return resp
+}
+
+func (msg *webhookMsg) validate() error {
+	target, err := url.Parse(msg.Url)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	if len(msg.EventTypes) == 0 {
+		return fmt.Errorf("event_types must name at least one of %v", webhookEventTypes)
+	}
+	for _, kind := range msg.EventTypes {
+		if !slices.Contains(webhookEventTypes, kind) {
+			return fmt.Errorf("unknown event type %q", kind)
+		}
+	}
+	slices.Sort(msg.EventTypes)
+	msg.EventTypes = slices.Compact(msg.EventTypes)
+	if msg.Secret == "" {
+		msg.Secret = auth.MakeRefreshToken()
+	} else if len(msg.Secret) < webhookSecretMin {
+		return fmt.Errorf("secret must be at least %d characters", webhookSecretMin)
+	}
+	return nil
+}
+
+// enqueueWebhooks queues a delivery of the event to every subscription for its type,
+// except those whose owner has a block with actor. It must be called with the
+// transaction of the write the event is about, so an event is only ever delivered
+// if that write commits, and is never lost if it does.
+func enqueueWebhooks(ctx context.Context, qtx *database.Queries, kind string, actor uuid.UUID, data any) error {
+	payload, err := json.Marshal(webhookEvent{Id: uuid.New(), Type: kind, CreatedAt: time.Now().UTC(), Data: data})
+	if err != nil {
+		return err
+	}
+	return qtx.EnqueueWebhookDeliveries(ctx, database.EnqueueWebhookDeliveriesParams{EventType: kind,
+		Payload: string(payload), ActorID: actor})
+}
+
+// attemptWebhook sends one claimed delivery and records the outcome. A failed
+// delivery is retried with exponential backoff until it has been tried
+// webhookMaxAttempts times.
+func (cfg *apiConfig) attemptWebhook(ctx context.Context, claimed database.ClaimWebhookDeliveriesRow) error {
+	status, err := webhooks.Send(ctx, webhookClient, webhooks.Delivery{ID: claimed.ID, Event: claimed.EventType,
+		URL: claimed.Url, Secret: claimed.Secret, Payload: []byte(claimed.Payload)})
+	params := database.FinishWebhookAttemptParams{ID: claimed.ID, Status: deliverySucceeded}
+	if status != 0 {
+		params.ResponseStatus = sql.NullInt32{Int32: int32(status), Valid: true}
+	}
+	if err != nil {
+		attempts := int(claimed.Attempts) + 1
+		fmt.Printf("webhook delivery %s failed: %v\n", claimed.ID, err)
+		params.LastError = sql.NullString{String: deliveryError(err), Valid: true}
+		params.Status = deliveryPending
+		params.RetrySeconds = webhooks.Backoff(attempts).Seconds()
+		if attempts >= webhookMaxAttempts {
+			params.Status = deliveryFailed
+		}
+	}
+	return cfg.dbQueries.FinishWebhookAttempt(ctx, params)
+}
+
+// deliveryError describes a failed attempt for last_error, which the subscriber
+// can read. Short of the receiver's own answer, it only says what kind of failure
+// it was, so the text of a dial error never tells them about our network.
+func deliveryError(err error) string {
+	var status *webhooks.StatusError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &status):
+		return status.Error()
+	case errors.Is(err, netguard.ErrForbidden):
+		return "receiver address is not allowed"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "receiver timed out"
+	default:
+		return "could not reach receiver"
+	}
+}
+
+// runWebhooks works through the delivery queue each interval until ctx is done.
+func (cfg *apiConfig) runWebhooks(ctx context.Context, interval time.Duration) {
+	queue := deliveryQueue[database.ClaimWebhookDeliveriesRow]{
+		name:  "webhook",
+		batch: webhookBatch,
+		claim: func(ctx context.Context) ([]database.ClaimWebhookDeliveriesRow, error) {
+			return cfg.dbQueries.ClaimWebhookDeliveries(ctx, database.ClaimWebhookDeliveriesParams{
+				LeaseSeconds: webhookLease.Seconds(), BatchSize: webhookBatch})
+		},
+		attempt: cfg.attemptWebhook,
+		id:      func(delivery database.ClaimWebhookDeliveriesRow) uuid.UUID { return delivery.ID },
+	}
+	queue.run(ctx, interval)
+}
+
+// ownWebhook loads the webhook named in the request path if it belongs to user.
+func (cfg *apiConfig) ownWebhook(req *http.Request, user uuid.UUID) (database.Webhook, error) {
+	id, err := parseID(req)
+	if err != nil {
+		return database.Webhook{}, sql.ErrNoRows
+	}
+	return cfg.dbQueries.GetWebhook(req.Context(), database.GetWebhookParams{ID: id, UserID: user})
+}
+
+func (cfg *apiConfig) handleAddWebhook(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	msg := webhookMsg{}
+	if !decodeRequest(writer, req, &msg) {
+		return
+	}
+	id, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	// The host is checked again on every delivery; this only turns a bad one away
+	// while someone is there to see why.
+	target, _ := url.Parse(msg.Url)
+	if err := webhookGuard.Check(req.Context(), target.Hostname()); errors.Is(err, netguard.ErrForbidden) {
+		handleError(writer, req, http.StatusBadRequest, "url must point at a public address")
+		return
+	} else if err != nil {
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	hook, err := cfg.dbQueries.CreateWebhook(req.Context(), database.CreateWebhookParams{UserID: id, Url: msg.Url,
+		EventTypes: msg.EventTypes, Secret: msg.Secret})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	// The secret is only ever shown here, to whoever set the webhook up.
+	resp := webhookConv(hook)
+	resp.Secret = hook.Secret
+	handleJsonWrite(writer, http.StatusCreated, msg.Url, resp)
+}
+
+func (cfg *apiConfig) handleListWebhooks(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	id, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	hooks, err := cfg.dbQueries.GetWebhooks(req.Context(), id)
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	jsonHooks := make([]webhookResp, len(hooks))
+	for i := range hooks {
+		jsonHooks[i] = webhookConv(hooks[i])
+	}
+	handleJsonWrite(writer, http.StatusOK, "webhooks", jsonHooks)
+}
+
+func (cfg *apiConfig) handleDeleteWebhook(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	userID, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	id, err := parseID(req)
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, err.Error())
+		return
+	}
+	deleted, err := cfg.dbQueries.DeleteWebhook(req.Context(), database.DeleteWebhookParams{ID: id, UserID: userID})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	} else if deleted == 0 {
+		handleError(writer, req, http.StatusNotFound, "Webhook not found")
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleWebhookDeliveries(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	userID, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	cur, limit, err := parsePage(req)
+	if err != nil {
+		handleError(writer, req, http.StatusBadRequest, err.Error())
+		return
+	}
+	hook, err := cfg.ownWebhook(req, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		handleError(writer, req, http.StatusNotFound, "Webhook not found")
+		return
+	} else if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	deliveries, err := cfg.dbQueries.GetWebhookDeliveries(req.Context(), database.GetWebhookDeliveriesParams{
+		WebhookID: hook.ID, BeforeTime: cur.time, BeforeID: cur.id, PageSize: limit})
+	if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	page := deliveryPage{Deliveries: make([]deliveryResp, len(deliveries))}
+	for i := range deliveries {
+		page.Deliveries[i] = deliveryConv(deliveries[i])
+	}
+	if len(deliveries) > 0 {
+		last := deliveries[len(deliveries)-1]
+		page.NextCursor = nextCursor(len(deliveries), limit, last.CreatedAt, last.ID)
+	}
+	handleJsonWrite(writer, http.StatusOK, "deliveries", page)
+}
+
+// handleRedeliver queues a fresh copy of a past delivery, leaving the original in
+// the log as it was. The payload, and so the event id, is unchanged.
+func (cfg *apiConfig) handleRedeliver(writer http.ResponseWriter, req *http.Request) {
+	writer.Header()["Content-Type"] = []string{jsonContent}
+	userID, err := cfg.validateUser(req.Header)
+	if err != nil {
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	hook, err := cfg.ownWebhook(req, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		handleError(writer, req, http.StatusNotFound, "Webhook not found")
+		return
+	} else if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	deliveryID, err := uuid.Parse(req.PathValue("delivery_id"))
+	if err != nil {
+		handleError(writer, req, http.StatusNotFound, err.Error())
+		return
+	}
+	delivery, err := cfg.dbQueries.RedeliverWebhookDelivery(req.Context(), database.RedeliverWebhookDeliveryParams{
+		ID: deliveryID, WebhookID: hook.ID})
+	if errors.Is(err, sql.ErrNoRows) {
+		handleError(writer, req, http.StatusNotFound, "Delivery not found")
+		return
+	} else if err != nil {
+		handleFault(writer, req, http.StatusInternalServerError, err)
+		return
+	}
+	handleJsonWrite(writer, http.StatusAccepted, "redeliver", deliveryConv(delivery))
+}
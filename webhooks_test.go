@@ -0,0 +1,64 @@
+package main
+
+import (
+	"chirpy/internal/auth"
+	"chirpy/internal/netguard"
+	"chirpy/internal/webhooks"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestDeliveryErrorHidesDialErrors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&webhooks.StatusError{StatusCode: http.StatusGone}, "receiver answered 410 Gone"},
+		{fmt.Errorf("dial tcp 10.0.0.5:443: %w", netguard.ErrForbidden), "receiver address is not allowed"},
+		{errors.New("dial tcp 10.0.0.5:443: connect: connection refused"), "could not reach receiver"},
+	}
+	for _, tc := range tests {
+		if got := deliveryError(tc.err); got != tc.want {
+			t.Errorf("deliveryError(%v) = %q, want %q", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestWebhookClientRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	_, err := webhooks.Send(context.Background(), webhookClient, webhooks.Delivery{Event: webhookChirpCreated,
+		URL: server.URL, Secret: "whsec-test", Payload: []byte(`{}`)})
+	if got := deliveryError(err); got != "receiver address is not allowed" {
+		t.Errorf("delivery to %s: %v (%q)", server.URL, err, got)
+	}
+}
+
+func TestAddWebhookRefusesPrivateAddress(t *testing.T) {
+	cfg := &apiConfig{sekrit: testSecret}
+	token, err := auth.MakeJWT(uuid.New(), testSecret, time.Minute)
+	if err != nil {
+		t.Fatalf("MakeJWT() returned error %v", err)
+	}
+	for _, target := range []string{"http://127.0.0.1:9000/hook", "https://169.254.169.254/latest", "http://localhost/"} {
+		body := fmt.Sprintf(`{"url":%q,"event_types":["chirp.created"]}`, target)
+		req := httptest.NewRequest(http.MethodPost, "/api/webhooks", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		cfg.handleAddWebhook(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("POST webhook for %s = %d, want %d", target, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
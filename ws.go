@@ -0,0 +1,308 @@
+package main
+
+import (
+	"chirpy/internal/auth"
+	"chirpy/internal/broker"
+	"chirpy/internal/database"
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// wsCommand is what clients send: {"type": "subscribe", "channel": "timeline"}.
+type wsCommand struct {
+	Type    string `json:"type"`
+	Channel string `json:"channel"`
+}
+
+// wsEvent is what the server sends. Data holds the same JSON the REST API
+// returns for the thing the event is about.
+type wsEvent struct {
+	Type    string `json:"type"`
+	Channel string `json:"channel,omitempty"`
+	Data    any    `json:"data,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// wsNotification is pushed as it happens, before it is grouped with others; Id
+// is the group key that POST /api/notifications/{id}/read takes.
+type wsNotification struct {
+	Id        uuid.UUID  `json:"id"`
+	Type      string     `json:"type"`
+	ActorId   uuid.UUID  `json:"actor_id"`
+	ChirpId   *uuid.UUID `json:"chirp_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+const wsSubscribe = "subscribe"
+const wsUnsubscribe = "unsubscribe"
+const wsChannelTimeline = "timeline"
+const wsChannelNotifications = "notifications"
+const wsChannelChirp = "chirp:"
+const wsChannelConversation = "conversation:"
+
+// wsCloseTokenExpired is sent, from the range reserved for applications, when the
+// token the connection was opened with expires. Clients reconnect with a fresh one.
+const wsCloseTokenExpired = 4001
+const wsReadLimit = 4096
+const wsBuffer = 64
+
+// How often the server pings, how long it waits for any frame before giving up
+// on the client, and how long a single write may take. Each connection keeps the
+// values it opened with; tests shorten them.
+var wsPingPeriod = 30 * time.Second
+var wsPongWait = 60 * time.Second
+var wsWriteWait = 10 * time.Second
+
+var wsUpgrader = websocket.Upgrader{}
+
+// wsConn is one client's connection. Its subscriptions are only touched by the
+// goroutine running serve.
+type wsConn struct {
+	cfg           *apiConfig
+	conn          *websocket.Conn
+	self          uuid.UUID
+	pingPeriod    time.Duration
+	pongWait      time.Duration
+	writeWait     time.Duration
+	timeline      map[uuid.UUID]bool
+	notifications bool
+	chirps        map[uuid.UUID]bool
+	conversations map[uuid.UUID]bool
+}
+
+func (cfg *apiConfig) handleWebSocket(writer http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	var self uuid.UUID
+	var expires time.Time
+	if err == nil {
+		self, err = auth.ValidateJWT(token, cfg.sekrit)
+	}
+	if err == nil {
+		expires, err = auth.JWTExpiry(token, cfg.sekrit)
+	}
+	if err != nil {
+		writer.Header()["Content-Type"] = []string{jsonContent}
+		handleError(writer, req, http.StatusUnauthorized, err.Error())
+		return
+	}
+	conn, err := wsUpgrader.Upgrade(writer, req, nil)
+	if err != nil {
+		// Upgrade has already answered the client.
+		return
+	}
+	defer conn.Close()
+	ws := &wsConn{cfg: cfg, conn: conn, self: self, pingPeriod: wsPingPeriod, pongWait: wsPongWait, writeWait: wsWriteWait,
+		chirps: map[uuid.UUID]bool{}, conversations: map[uuid.UUID]bool{}}
+	ws.serve(req.Context(), expires)
+}
+
+// read passes the client's commands to serve until the connection fails, which
+// includes the client going quiet for longer than its pong wait, or serve stops.
+func (ws *wsConn) read(commands chan<- wsCommand, done chan<- struct{}, stop <-chan struct{}) {
+	defer close(done)
+	ws.conn.SetReadLimit(wsReadLimit)
+	ws.conn.SetReadDeadline(time.Now().Add(ws.pongWait))
+	ws.conn.SetPongHandler(func(string) error {
+		return ws.conn.SetReadDeadline(time.Now().Add(ws.pongWait))
+	})
+	for {
+		_, msg, err := ws.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		ws.conn.SetReadDeadline(time.Now().Add(ws.pongWait))
+		var cmd wsCommand
+		if err = json.Unmarshal(msg, &cmd); err != nil {
+			cmd = wsCommand{}
+		}
+		select {
+		case commands <- cmd:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// serve owns every write to the connection. It ends the connection when the
+// token expires or when the client falls wsBuffer events behind.
+func (ws *wsConn) serve(ctx context.Context, expires time.Time) {
+	events, cancel := ws.cfg.broker.Subscribe(wsBuffer, broker.Chirps, broker.User(ws.self))
+	defer cancel()
+	commands := make(chan wsCommand)
+	done := make(chan struct{})
+	stop := make(chan struct{})
+	defer close(stop)
+	go ws.read(commands, done, stop)
+	ping := time.NewTicker(ws.pingPeriod)
+	defer ping.Stop()
+	expiry := time.NewTimer(time.Until(expires))
+	defer expiry.Stop()
+	for {
+		var err error
+		select {
+		case <-done:
+			return
+		case <-expiry.C:
+			ws.close(websocket.FormatCloseMessage(wsCloseTokenExpired, "token expired"), done)
+			return
+		case <-ping.C:
+			err = ws.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(ws.writeWait))
+		case cmd := <-commands:
+			err = ws.write(ws.command(ctx, cmd))
+		case event, ok := <-events:
+			if !ok {
+				ws.close(websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "too far behind"), done)
+				return
+			}
+			var out *wsEvent
+			if out, err = ws.route(ctx, event); err == nil && out != nil {
+				err = ws.write(*out)
+			}
+		}
+		if err != nil {
+			ws.close(websocket.FormatCloseMessage(websocket.CloseInternalServerErr, ""), done)
+			return
+		}
+	}
+}
+
+func (ws *wsConn) write(event wsEvent) error {
+	ws.conn.SetWriteDeadline(time.Now().Add(ws.writeWait))
+	return ws.conn.WriteJSON(event)
+}
+
+// close sends a close frame and gives the client a moment to answer it.
+func (ws *wsConn) close(msg []byte, done <-chan struct{}) {
+	if err := ws.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(ws.writeWait)); err != nil {
+		return
+	}
+	select {
+	case <-done:
+	case <-time.After(ws.writeWait):
+	}
+}
+
+func (ws *wsConn) command(ctx context.Context, cmd wsCommand) wsEvent {
+	if cmd.Type != wsSubscribe && cmd.Type != wsUnsubscribe {
+		return wsEvent{Type: "error", Channel: cmd.Channel, Error: "unknown command type"}
+	}
+	subscribe := cmd.Type == wsSubscribe
+	switch {
+	case cmd.Channel == wsChannelTimeline:
+		ws.timeline = nil
+		if subscribe {
+			authors, err := ws.cfg.dbQueries.GetTimelineAuthors(ctx, ws.self)
+			if err != nil {
+				return wsEvent{Type: "error", Channel: cmd.Channel, Error: err.Error()}
+			}
+			ws.timeline = make(map[uuid.UUID]bool, len(authors))
+			for _, id := range authors {
+				ws.timeline[id] = true
+			}
+		}
+	case cmd.Channel == wsChannelNotifications:
+		ws.notifications = subscribe
+	case strings.HasPrefix(cmd.Channel, wsChannelChirp):
+		id, err := uuid.Parse(strings.TrimPrefix(cmd.Channel, wsChannelChirp))
+		if err != nil {
+			return wsEvent{Type: "error", Channel: cmd.Channel, Error: "invalid chirp id"}
+		}
+		delete(ws.chirps, id)
+		if subscribe {
+			if err = ws.canSeeChirp(ctx, id); err != nil {
+				return wsEvent{Type: "error", Channel: cmd.Channel, Error: "Chirp not found"}
+			}
+			ws.chirps[id] = true
+		}
+	case strings.HasPrefix(cmd.Channel, wsChannelConversation):
+		id, err := uuid.Parse(strings.TrimPrefix(cmd.Channel, wsChannelConversation))
+		if err != nil {
+			return wsEvent{Type: "error", Channel: cmd.Channel, Error: "invalid conversation id"}
+		}
+		delete(ws.conversations, id)
+		if subscribe {
+			participants, err := ws.cfg.conversationParticipants(ctx, []uuid.UUID{id})
+			if err != nil {
+				return wsEvent{Type: "error", Channel: cmd.Channel, Error: err.Error()}
+			}
+			isMember := slices.ContainsFunc(participants[id], func(p participantResp) bool { return p.Id == ws.self })
+			if !isMember {
+				return wsEvent{Type: "error", Channel: cmd.Channel, Error: "Conversation not found"}
+			}
+			ws.conversations[id] = true
+		}
+	default:
+		return wsEvent{Type: "error", Channel: cmd.Channel, Error: "unknown channel"}
+	}
+	return wsEvent{Type: cmd.Type + "d", Channel: cmd.Channel}
+}
+
+// canSeeChirp returns an error unless the chirp exists and there is no block
+// between its author and the connected user.
+func (ws *wsConn) canSeeChirp(ctx context.Context, id uuid.UUID) error {
+	chirp, err := ws.cfg.dbQueries.GetChirp(ctx, id)
+	if err != nil {
+		return err
+	}
+	blocked, err := ws.cfg.dbQueries.IsBlocked(ctx, database.IsBlockedParams{UserA: ws.self, UserB: chirp.UserID})
+	if err != nil {
+		return err
+	} else if blocked {
+		return errBlocked
+	}
+	return nil
+}
+
+// route turns a broker event into the event for this client, or nil if none of
+// its subscriptions cover it.
+func (ws *wsConn) route(ctx context.Context, event broker.Event) (*wsEvent, error) {
+	viewer := uuid.NullUUID{UUID: ws.self, Valid: true}
+	switch {
+	case event.Chirp != nil:
+		chirp := *event.Chirp
+		var out wsEvent
+		if chirp.Kind == chirpKindReply && ws.chirps[chirp.RefChirpID.UUID] {
+			out = wsEvent{Type: "reply", Channel: wsChannelChirp + chirp.RefChirpID.UUID.String()}
+		} else if ws.timeline[chirp.UserID] {
+			out = wsEvent{Type: "chirp", Channel: wsChannelTimeline}
+		} else {
+			return nil, nil
+		}
+		jsonChirp, err := ws.cfg.liveChirp(ctx, viewer, chirp)
+		if err != nil || jsonChirp == nil {
+			return nil, err
+		}
+		out.Data = jsonChirp
+		return &out, nil
+	case event.Notification != nil:
+		n := event.Notification
+		if !ws.notifications || n.UserID != ws.self {
+			return nil, nil
+		}
+		data := wsNotification{Id: n.GroupKey, Type: n.Type, ActorId: n.ActorID, CreatedAt: n.CreatedAt}
+		if n.ChirpID.Valid {
+			data.ChirpId = &n.ChirpID.UUID
+		}
+		return &wsEvent{Type: "notification", Channel: wsChannelNotifications, Data: data}, nil
+	case event.Message != nil:
+		m := event.Message
+		if !ws.conversations[m.ConversationID] {
+			return nil, nil
+		}
+		if m.SenderID.Valid && m.SenderID.UUID != ws.self {
+			blocked, err := ws.cfg.blockedAmong(ctx, viewer, []uuid.UUID{m.SenderID.UUID})
+			if err != nil || blocked[m.SenderID.UUID] {
+				return nil, err
+			}
+		}
+		return &wsEvent{Type: "message", Channel: wsChannelConversation + m.ConversationID.String(), Data: messageConv(*m)}, nil
+	}
+	return nil, nil
+}
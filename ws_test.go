@@ -0,0 +1,205 @@
+package main
+
+import (
+	"chirpy/internal/auth"
+	"chirpy/internal/broker"
+	"chirpy/internal/database"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const testSecret = "hiT6/qkbcpGn8LokB7qLxgNDADBn1IvjBtB2W7iMd84vXebR8Vd2TGDs2NURfVebJISiBsE16txLRV8xt9GnSQ=="
+
+// wsTestServer serves handleWebSocket without a database, which is enough for
+// the notifications channel.
+func wsTestServer(t *testing.T) (*apiConfig, *httptest.Server) {
+	t.Helper()
+	cfg := &apiConfig{sekrit: testSecret, broker: broker.NewMemory()}
+	server := httptest.NewServer(http.HandlerFunc(cfg.handleWebSocket))
+	t.Cleanup(server.Close)
+	return cfg, server
+}
+
+// shortTimings speeds up pings for the length of a test.
+func shortTimings(t *testing.T, ping, pongWait time.Duration) {
+	t.Helper()
+	oldPing, oldPongWait := wsPingPeriod, wsPongWait
+	wsPingPeriod, wsPongWait = ping, pongWait
+	t.Cleanup(func() { wsPingPeriod, wsPongWait = oldPing, oldPongWait })
+}
+
+func wsDial(t *testing.T, server *httptest.Server, user uuid.UUID, expiresIn time.Duration) *websocket.Conn {
+	t.Helper()
+	token, err := auth.MakeJWT(user, testSecret, expiresIn)
+	if err != nil {
+		t.Fatalf("MakeJWT() returned error %v", err)
+	}
+	head := http.Header{"Authorization": []string{"Bearer " + token}}
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), head)
+	if err != nil {
+		t.Fatalf("Dial() returned error %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func wsSubscribeNotifications(t *testing.T, conn *websocket.Conn) {
+	t.Helper()
+	if err := conn.WriteJSON(wsCommand{Type: wsSubscribe, Channel: wsChannelNotifications}); err != nil {
+		t.Fatalf("WriteJSON() returned error %v", err)
+	}
+	var reply wsEvent
+	if err := conn.ReadJSON(&reply); err != nil {
+		t.Fatalf("ReadJSON() returned error %v", err)
+	}
+	if reply.Type != "subscribed" || reply.Channel != wsChannelNotifications {
+		t.Fatalf("subscribe reply = %+v", reply)
+	}
+}
+
+func notificationEvent(user uuid.UUID) broker.Event {
+	return broker.Event{Notification: &database.Notification{ID: uuid.New(), UserID: user, ActorID: uuid.New(),
+		Type: notifyFollow, GroupKey: uuid.New(), CreatedAt: time.Now()}}
+}
+
+// wsCloseCode reads until the connection fails and returns the close code the
+// server sent, or 0 if it went away without one.
+func wsCloseCode(t *testing.T, conn *websocket.Conn, within time.Duration) (int, int) {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(within))
+	messages := 0
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			var closeErr *websocket.CloseError
+			if errors.As(err, &closeErr) {
+				return closeErr.Code, messages
+			}
+			return 0, messages
+		}
+		messages++
+	}
+}
+
+func TestWebSocketRequiresToken(t *testing.T) {
+	_, server := wsTestServer(t)
+	_, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err == nil {
+		t.Fatal("Dial() succeeded without a token")
+	}
+	if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Dial() without a token got %v, want 401", resp)
+	}
+}
+
+func TestWebSocketDeliversNotifications(t *testing.T) {
+	cfg, server := wsTestServer(t)
+	self := uuid.New()
+	conn := wsDial(t, server, self, time.Hour)
+	wsSubscribeNotifications(t, conn)
+	cfg.broker.Publish(context.Background(), notificationEvent(uuid.New()))
+	want := notificationEvent(self)
+	cfg.broker.Publish(context.Background(), want)
+	var got struct {
+		Type string         `json:"type"`
+		Data wsNotification `json:"data"`
+	}
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if err := conn.ReadJSON(&got); err != nil {
+		t.Fatalf("ReadJSON() returned error %v", err)
+	}
+	if got.Type != "notification" || got.Data.Id != want.Notification.GroupKey {
+		t.Errorf("received %+v, want the notification for this user only", got)
+	}
+}
+
+func TestWebSocketPingPong(t *testing.T) {
+	shortTimings(t, 20*time.Millisecond, 200*time.Millisecond)
+	_, server := wsTestServer(t)
+	conn := wsDial(t, server, uuid.New(), time.Hour)
+	pinged := make(chan struct{}, 1)
+	conn.SetPingHandler(func(data string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+	ponged := make(chan struct{}, 1)
+	conn.SetPongHandler(func(string) error {
+		select {
+		case ponged <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	// Control frames are only handled while reading.
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("WriteControl() returned error %v", err)
+	}
+	for name, ch := range map[string]chan struct{}{"server ping": pinged, "pong to our ping": ponged} {
+		select {
+		case <-ch:
+		case <-time.After(time.Second):
+			t.Errorf("no %s", name)
+		}
+	}
+	// Answering pings keeps the connection open well past the pong wait.
+	time.Sleep(3 * wsPongWait)
+	if err := conn.WriteJSON(wsCommand{Type: wsSubscribe, Channel: wsChannelNotifications}); err != nil {
+		t.Errorf("connection closed although pings were answered: %v", err)
+	}
+}
+
+func TestWebSocketPongTimeout(t *testing.T) {
+	shortTimings(t, 20*time.Millisecond, 200*time.Millisecond)
+	_, server := wsTestServer(t)
+	conn := wsDial(t, server, uuid.New(), time.Hour)
+	conn.SetPingHandler(func(string) error { return nil })
+	start := time.Now()
+	wsCloseCode(t, conn, 2*time.Second)
+	if elapsed := time.Since(start); elapsed >= 2*time.Second {
+		t.Errorf("server kept a silent client for %v", elapsed)
+	}
+}
+
+func TestWebSocketBackpressure(t *testing.T) {
+	cfg, server := wsTestServer(t)
+	self := uuid.New()
+	conn := wsDial(t, server, self, time.Hour)
+	wsSubscribeNotifications(t, conn)
+	// Publish far faster than anything is read, so the connection's buffer overflows.
+	const published = 50000
+	for i := 0; i < published; i++ {
+		cfg.broker.Publish(context.Background(), notificationEvent(self))
+	}
+	code, received := wsCloseCode(t, conn, 10*time.Second)
+	if code != websocket.CloseTryAgainLater {
+		t.Errorf("close code = %d, want %d", code, websocket.CloseTryAgainLater)
+	}
+	if received >= published {
+		t.Errorf("received all %d events, want the slow client dropped", received)
+	}
+}
+
+func TestWebSocketTokenExpiry(t *testing.T) {
+	_, server := wsTestServer(t)
+	conn := wsDial(t, server, uuid.New(), time.Second)
+	if code, _ := wsCloseCode(t, conn, 3*time.Second); code != wsCloseTokenExpired {
+		t.Errorf("close code = %d, want %d", code, wsCloseTokenExpired)
+	}
+}